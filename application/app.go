@@ -3,9 +3,11 @@ package application
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/helpers"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
@@ -16,25 +18,46 @@ type usage interface {
 	PrintCommandUsage(command, message string)
 }
 
+type auditor interface {
+	Record(configuration Configuration, startedAt time.Time, commandErr error) error
+}
+
 type App struct {
 	commands      CommandSet
 	configuration Configuration
 	stateStore    stateStore
 	usage         usage
+	auditor       auditor
 }
 
 func New(commands CommandSet, configuration Configuration, stateStore stateStore,
-	usage usage) App {
+	usage usage, auditor auditor) App {
 	return App{
 		commands:      commands,
 		configuration: configuration,
 		stateStore:    stateStore,
 		usage:         usage,
+		auditor:       auditor,
 	}
 }
 
 func (a App) Run() error {
+	startedAt := now()
 	err := a.execute()
+
+	if a.audited() {
+		auditErr := a.auditor.Record(a.configuration, startedAt, err)
+		if auditErr != nil {
+			if err != nil {
+				errorList := helpers.Errors{}
+				errorList.Add(err)
+				errorList.Add(auditErr)
+				return errorList
+			}
+			return auditErr
+		}
+	}
+
 	if err != nil {
 		return err
 	}
@@ -42,6 +65,17 @@ func (a App) Run() error {
 	return nil
 }
 
+// audited reports whether the run is recorded in the audit log. Printing
+// help, the version, completion scripts or the audit log itself is not.
+func (a App) audited() bool {
+	switch a.configuration.Command {
+	case commands.HelpCommand, commands.VersionCommand, commands.AuditCommand, commands.CompletionCommand, "--version", "-v":
+		return false
+	}
+
+	return !a.configuration.SubcommandFlags.ContainsAny("--help", "-h", "--version", "-v")
+}
+
 func (a App) getCommand(commandString string) (commands.Command, error) {
 	command, ok := a.commands[commandString]
 	if !ok {
@@ -40,6 +40,7 @@ var _ = Describe("App", func() {
 		errorCmd   *fakes.Command
 		usage      *fakes.Usage
 		stateStore *fakes.StateStore
+		auditor    *fakes.Auditor
 	)
 
 	var NewAppWithConfiguration = func(configuration application.Configuration) application.App {
@@ -48,12 +49,14 @@ var _ = Describe("App", func() {
 			"version":              versionCmd,
 			"--version":            versionCmd,
 			"some":                 someCmd,
+			"completion":           someCmd,
 			"error":                errorCmd,
 			"set-new-keypair-name": setNewKeyPairName{},
 		},
 			configuration,
 			stateStore,
 			usage,
+			auditor,
 		)
 	}
 
@@ -67,6 +70,7 @@ var _ = Describe("App", func() {
 
 		usage = &fakes.Usage{}
 		stateStore = &fakes.StateStore{}
+		auditor = &fakes.Auditor{}
 
 		app = NewAppWithConfiguration(application.Configuration{})
 	})
@@ -102,6 +106,64 @@ var _ = Describe("App", func() {
 			})
 		})
 
+		Context("auditing", func() {
+			It("records the command in the audit log", func() {
+				configuration := application.Configuration{
+					Command:         "some",
+					SubcommandFlags: []string{"--some-flag"},
+				}
+				app = NewAppWithConfiguration(configuration)
+
+				Expect(app.Run()).To(Succeed())
+
+				Expect(auditor.RecordCall.CallCount).To(Equal(1))
+				Expect(auditor.RecordCall.Receives.Configuration).To(Equal(configuration))
+				Expect(auditor.RecordCall.Receives.StartedAt).NotTo(BeZero())
+				Expect(auditor.RecordCall.Receives.CommandErr).NotTo(HaveOccurred())
+			})
+
+			It("records the error of a failed command", func() {
+				errorCmd.ExecuteCall.Returns.Error = errors.New("error executing command")
+				app = NewAppWithConfiguration(application.Configuration{Command: "error"})
+
+				Expect(app.Run()).To(MatchError("error executing command"))
+
+				Expect(auditor.RecordCall.Receives.CommandErr).To(MatchError("error executing command"))
+			})
+
+			DescribeTable("does not record printing help, the version or completion scripts", func(configuration application.Configuration) {
+				app = NewAppWithConfiguration(configuration)
+
+				Expect(app.Run()).To(Succeed())
+
+				Expect(auditor.RecordCall.CallCount).To(Equal(0))
+			},
+				Entry("help", application.Configuration{Command: "help"}),
+				Entry("version", application.Configuration{Command: "version"}),
+				Entry("completion", application.Configuration{Command: "completion"}),
+				Entry("--help", application.Configuration{Command: "some", SubcommandFlags: []string{"--help"}}),
+			)
+
+			Context("failure cases", func() {
+				BeforeEach(func() {
+					auditor.RecordCall.Returns.Error = errors.New("failed to record")
+				})
+
+				It("returns an error when the command cannot be recorded", func() {
+					app = NewAppWithConfiguration(application.Configuration{Command: "some"})
+
+					Expect(app.Run()).To(MatchError("failed to record"))
+				})
+
+				It("returns both errors when a failed command cannot be recorded", func() {
+					errorCmd.ExecuteCall.Returns.Error = errors.New("error executing command")
+					app = NewAppWithConfiguration(application.Configuration{Command: "error"})
+
+					Expect(app.Run()).To(MatchError("the following errors occurred:\nerror executing command,\nfailed to record"))
+				})
+			})
+		})
+
 		Context("when subcommand flags contains help", func() {
 			DescribeTable("prints command specific usage when help subcommand flag is provided", func(helpFlag string) {
 				someCmd.UsageCall.Returns.Usage = "some usage message"
@@ -196,7 +258,7 @@ var _ = Describe("App", func() {
 					}, application.Configuration{
 						Command:         "some",
 						SubcommandFlags: []string{"-v"},
-					}, storage.Store{}, usage)
+					}, storage.Store{}, usage, auditor)
 
 					err := app.Run()
 					Expect(err).To(MatchError("unknown command: version"))
@@ -0,0 +1,186 @@
+package application
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const redactedFlagValue = "[REDACTED]"
+
+var (
+	currentUser = user.Current
+	hostname    = os.Hostname
+)
+
+// sensitiveFlagWords mark the flags whose values are left out of the audit
+// log.
+var sensitiveFlagWords = []string{"secret", "password", "key", "token", "credentials"}
+
+type auditLog interface {
+	Append(storage.AuditEntry) error
+}
+
+type stepRecorder interface {
+	StepDurations() []storage.AuditStep
+}
+
+type versionGetter interface {
+	Version() (string, error)
+}
+
+type flagDefiner interface {
+	Flags() flags.Flags
+}
+
+// Auditor appends an entry for every bbl command run against an environment
+// to the audit log kept in its state directory.
+type Auditor struct {
+	commands     CommandSet
+	auditLog     auditLog
+	stepRecorder stepRecorder
+	terraform    versionGetter
+	bosh         versionGetter
+	bblVersion   string
+}
+
+// NewAuditor returns an Auditor. The command set is used to tell the switches
+// of a command from the flags that take a value, and is read when a command
+// is recorded.
+func NewAuditor(commands CommandSet, auditLog auditLog, stepRecorder stepRecorder, terraform, bosh versionGetter, bblVersion string) Auditor {
+	return Auditor{
+		commands:     commands,
+		auditLog:     auditLog,
+		stepRecorder: stepRecorder,
+		terraform:    terraform,
+		bosh:         bosh,
+		bblVersion:   bblVersion,
+	}
+}
+
+// Record appends the entry for a command that started at startedAt and
+// returned commandErr. Commands run outside of an environment, where there
+// was no state before or after, are not recorded.
+func (a Auditor) Record(configuration Configuration, startedAt time.Time, commandErr error) error {
+	if reflect.DeepEqual(configuration.State, storage.State{}) && !stateFileExists(configuration.Global.StateDir) {
+		return nil
+	}
+
+	entry := storage.AuditEntry{
+		Command:    configuration.Command,
+		Flags:      sanitizeFlags(configuration.SubcommandFlags, a.boolFlags(configuration.Command)),
+		User:       auditUser(),
+		BBLVersion: a.bblVersion,
+		StartedAt:  startedAt.UTC(),
+		Steps:      a.stepRecorder.StepDurations(),
+		Outcome:    "success",
+	}
+
+	entry.Host, _ = hostname()
+	entry.TerraformVersion = toolVersion(a.terraform)
+	entry.BOSHVersion = toolVersion(a.bosh)
+
+	if commandErr != nil {
+		entry.Outcome = "failure"
+		entry.Error = commandErr.Error()
+	}
+
+	entry.FinishedAt = now().UTC()
+
+	return a.auditLog.Append(entry)
+}
+
+func auditUser() string {
+	u, err := currentUser()
+	if err != nil {
+		return os.Getenv("USER")
+	}
+
+	return u.Username
+}
+
+// toolVersion leaves out the version of a tool that cannot be run, so that
+// a missing tool does not keep the command from being recorded.
+func toolVersion(tool versionGetter) string {
+	version, err := tool.Version()
+	if err != nil {
+		return ""
+	}
+
+	return version
+}
+
+func stateFileExists(stateDir string) bool {
+	_, err := os.Stat(filepath.Join(stateDir, storage.StateFileName))
+	return err == nil
+}
+
+// boolFlags returns the names of the switches of a command, which take no
+// value.
+func (a Auditor) boolFlags(command string) map[string]bool {
+	boolFlags := map[string]bool{}
+
+	definer, ok := a.commands[command].(flagDefiner)
+	if !ok {
+		return boolFlags
+	}
+
+	for _, definition := range definer.Flags().Definitions() {
+		if !definition.Bool {
+			continue
+		}
+
+		boolFlags[definition.Name] = true
+		if definition.Short != "" {
+			boolFlags[definition.Short] = true
+		}
+	}
+
+	return boolFlags
+}
+
+// sanitizeFlags replaces the values of flags that may hold secrets, given
+// either as --flag=value or as --flag value. The argument after a sensitive
+// flag is its value whatever it starts with, unless the flag is a switch.
+func sanitizeFlags(subcommandFlags []string, boolFlags map[string]bool) []string {
+	sanitized := []string{}
+	redactNext := false
+	for _, flag := range subcommandFlags {
+		if redactNext {
+			sanitized = append(sanitized, redactedFlagValue)
+			redactNext = false
+			continue
+		}
+
+		switch {
+		case !strings.HasPrefix(flag, "-"):
+			sanitized = append(sanitized, flag)
+		case strings.Contains(flag, "="):
+			i := strings.Index(flag, "=")
+			if sensitiveFlag(flag[:i]) {
+				flag = flag[:i+1] + redactedFlagValue
+			}
+			sanitized = append(sanitized, flag)
+		default:
+			sanitized = append(sanitized, flag)
+			redactNext = sensitiveFlag(flag) && !boolFlags[strings.TrimLeft(flag, "-")]
+		}
+	}
+
+	return sanitized
+}
+
+func sensitiveFlag(flag string) bool {
+	for _, word := range sensitiveFlagWords {
+		if strings.Contains(strings.ToLower(flag), word) {
+			return true
+		}
+	}
+	return false
+}
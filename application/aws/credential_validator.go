@@ -16,13 +16,17 @@ func NewCredentialValidator(configuration application.Configuration) CredentialV
 	}
 }
 
+// Validate checks the aws credentials in the state. The access keys are only
+// required when there is no profile, credential chain or role to use instead.
 func (c CredentialValidator) Validate() error {
-	if c.configuration.State.AWS.AccessKeyID == "" {
-		return errors.New("AWS access key ID must be provided")
-	}
-
-	if c.configuration.State.AWS.SecretAccessKey == "" {
-		return errors.New("AWS secret access key must be provided")
+	if c.configuration.State.AWS.UsesStaticKeys() {
+		if c.configuration.State.AWS.AccessKeyID == "" {
+			return errors.New("AWS access key ID must be provided")
+		}
+
+		if c.configuration.State.AWS.SecretAccessKey == "" {
+			return errors.New("AWS secret access key must be provided")
+		}
 	}
 
 	if c.configuration.State.AWS.Region == "" {
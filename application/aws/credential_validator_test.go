@@ -26,6 +26,19 @@ var _ = Describe("CredentialValidator", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("does not require access keys when a profile is used", func() {
+			credentialValidator = aws.NewCredentialValidator(application.Configuration{
+				State: storage.State{
+					AWS: storage.AWS{
+						Profile: "some-profile",
+						Region:  "some-region",
+					},
+				},
+			})
+			err := credentialValidator.Validate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when the access key id is missing", func() {
 				credentialValidator = aws.NewCredentialValidator(application.Configuration{
@@ -62,6 +75,17 @@ var _ = Describe("CredentialValidator", func() {
 				})
 				Expect(credentialValidator.Validate()).To(MatchError("AWS region must be provided"))
 			})
+
+			It("returns an error when the region is missing for an assumed role", func() {
+				credentialValidator = aws.NewCredentialValidator(application.Configuration{
+					State: storage.State{
+						AWS: storage.AWS{
+							AssumeRoleARN: "some-role-arn",
+						},
+					},
+				})
+				Expect(credentialValidator.Validate()).To(MatchError("AWS region must be provided"))
+			})
 		})
 	})
 })
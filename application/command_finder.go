@@ -27,7 +27,7 @@ func (finder commandFinder) FindCommand(input []string) CommandFinderResult {
 	commandFound := false
 	for index, word := range input {
 		if !strings.HasPrefix(word, "-") {
-			if previousCommand != "--state-dir" && previousCommand != "-state-dir" {
+			if !globalFlagTakesValue(previousCommand) {
 				commandIndex = index
 				commandFound = true
 				break
@@ -46,3 +46,11 @@ func (finder commandFinder) FindCommand(input []string) CommandFinderResult {
 
 	return commandFinderResult
 }
+
+func globalFlagTakesValue(flag string) bool {
+	switch strings.TrimLeft(flag, "-") {
+	case "state-dir", "log-format":
+		return true
+	}
+	return false
+}
@@ -25,6 +25,9 @@ var _ = Describe("CommandFinder", func() {
 		Entry("parses the first non-hyphenated word as the attempted command if --state-dir=x is provided",
 			[]string{"--state-dir=some-dir", "help", "--other-flag"},
 			application.CommandFinderResult{GlobalFlags: []string{"--state-dir=some-dir"}, Command: "help", OtherArgs: []string{"--other-flag"}}),
+		Entry("parses the first non-hyphenated word as the log format if it directly follows log-format",
+			[]string{"--log-format", "json", "up", "--other-flag"},
+			application.CommandFinderResult{GlobalFlags: []string{"--log-format", "json"}, Command: "up", OtherArgs: []string{"--other-flag"}}),
 		Entry("parses correctly if no global flags given",
 			[]string{"help", "foo", "--other-flag"},
 			application.CommandFinderResult{GlobalFlags: []string{}, Command: "help", OtherArgs: []string{"foo", "--other-flag"}}),
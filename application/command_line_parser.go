@@ -14,6 +14,7 @@ type CommandLineConfiguration struct {
 	SubcommandFlags []string
 	StateDir        string
 	Debug           bool
+	LogFormat       string
 
 	help    bool
 	version bool
@@ -86,22 +87,40 @@ func (p CommandLineParser) Parse(arguments []string) (CommandLineConfiguration,
 func (c CommandLineParser) parseGlobalFlags(commandLineConfiguration CommandLineConfiguration, arguments []string) (CommandLineConfiguration, []string, error) {
 	debugEnv := c.envGetter.Get("BBL_DEBUG")
 
-	globalFlags := flags.New("global")
-
-	globalFlags.String(&commandLineConfiguration.StateDir, "state-dir", "")
-	globalFlags.Bool(&commandLineConfiguration.Debug, "d", "debug", (debugEnv == "true"))
-
-	globalFlags.Bool(&commandLineConfiguration.help, "h", "help", false)
-	globalFlags.Bool(&commandLineConfiguration.version, "v", "version", false)
+	globalFlags := newGlobalFlags(&commandLineConfiguration, debugEnv == "true")
 
 	err := globalFlags.Parse(arguments)
 	if err != nil {
 		return CommandLineConfiguration{}, []string{}, err
 	}
 
+	if commandLineConfiguration.LogFormat != TextLogFormat && commandLineConfiguration.LogFormat != JSONLogFormat {
+		return CommandLineConfiguration{}, []string{}, fmt.Errorf("--log-format must be %q or %q", TextLogFormat, JSONLogFormat)
+	}
+
 	return commandLineConfiguration, globalFlags.Args(), nil
 }
 
+// GlobalFlags returns the flags that come before the command, for shell
+// completion.
+func GlobalFlags() flags.Flags {
+	return newGlobalFlags(&CommandLineConfiguration{}, false)
+}
+
+func newGlobalFlags(commandLineConfiguration *CommandLineConfiguration, debug bool) flags.Flags {
+	globalFlags := flags.New("global")
+
+	globalFlags.String(&commandLineConfiguration.StateDir, "state-dir", "")
+	globalFlags.Bool(&commandLineConfiguration.Debug, "d", "debug", debug)
+	globalFlags.String(&commandLineConfiguration.LogFormat, "log-format", TextLogFormat)
+	globalFlags.Values("log-format", TextLogFormat, JSONLogFormat)
+
+	globalFlags.Bool(&commandLineConfiguration.help, "h", "help", false)
+	globalFlags.Bool(&commandLineConfiguration.version, "v", "version", false)
+
+	return globalFlags
+}
+
 func setDefaultStateDirectory(commandLineConfiguration CommandLineConfiguration) (CommandLineConfiguration, error) {
 	if commandLineConfiguration.StateDir == "" {
 		wd, err := getwd()
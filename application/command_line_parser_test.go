@@ -6,6 +6,7 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/application"
 	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/flags"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -46,6 +47,20 @@ var _ = Describe("CommandLineParser", func() {
 
 			Expect(commandLineConfiguration.StateDir).To(Equal("some/state/dir"))
 			Expect(commandLineConfiguration.Debug).To(BeTrue())
+			Expect(commandLineConfiguration.LogFormat).To(Equal("text"))
+		})
+
+		It("returns a command line configuration with the log format", func() {
+			commandLineConfiguration, err := commandLineParser.Parse([]string{"--log-format", "json", "up"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(commandLineConfiguration.LogFormat).To(Equal("json"))
+			Expect(commandLineConfiguration.Command).To(Equal("up"))
+		})
+
+		It("returns an error when the log format is not supported", func() {
+			_, err := commandLineParser.Parse([]string{"--log-format", "xml", "up"})
+			Expect(err).To(MatchError(`--log-format must be "text" or "json"`))
 		})
 
 		It("returns a command line configuration with correct command with subcommand flags based on arguments passed in", func() {
@@ -181,4 +196,16 @@ var _ = Describe("CommandLineParser", func() {
 			})
 		})
 	})
+
+	Describe("GlobalFlags", func() {
+		It("describes the global flags", func() {
+			Expect(application.GlobalFlags().Definitions()).To(Equal([]flags.Definition{
+				{Name: "debug", Short: "d", Bool: true},
+				{Name: "help", Short: "h", Bool: true},
+				{Name: "log-format", Values: []string{"text", "json"}},
+				{Name: "state-dir"},
+				{Name: "version", Short: "v", Bool: true},
+			}))
+		})
+	})
 })
@@ -3,8 +3,9 @@ package application
 import "github.com/cloudfoundry/bosh-bootloader/storage"
 
 type GlobalConfiguration struct {
-	StateDir string
-	Debug    bool
+	StateDir  string
+	Debug     bool
+	LogFormat string
 }
 
 type StringSlice []string
@@ -30,8 +30,9 @@ func (p ConfigurationParser) Parse(arguments []string) (Configuration, error) {
 
 	configuration := Configuration{
 		Global: GlobalConfiguration{
-			StateDir: commandLineConfiguration.StateDir,
-			Debug:    commandLineConfiguration.Debug,
+			StateDir:  commandLineConfiguration.StateDir,
+			Debug:     commandLineConfiguration.Debug,
+			LogFormat: commandLineConfiguration.LogFormat,
 		},
 		Command:         commandLineConfiguration.Command,
 		SubcommandFlags: commandLineConfiguration.SubcommandFlags,
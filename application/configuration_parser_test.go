@@ -36,6 +36,7 @@ var _ = Describe("ConfigurationParser", func() {
 				SubcommandFlags: []string{"--some-flag", "some-value"},
 				StateDir:        "some/state/dir",
 				Debug:           true,
+				LogFormat:       "json",
 			}
 			configuration, err := configurationParser.Parse([]string{"up"})
 			Expect(err).NotTo(HaveOccurred())
@@ -43,8 +44,9 @@ var _ = Describe("ConfigurationParser", func() {
 			Expect(configuration.Command).To(Equal("up"))
 			Expect(configuration.SubcommandFlags).To(Equal(application.StringSlice{"--some-flag", "some-value"}))
 			Expect(configuration.Global).To(Equal(application.GlobalConfiguration{
-				StateDir: "some/state/dir",
-				Debug:    true,
+				StateDir:  "some/state/dir",
+				Debug:     true,
+				LogFormat: "json",
 			}))
 
 			Expect(commandLineParser.ParseCall.Receives.Arguments).To(Equal([]string{"up"}))
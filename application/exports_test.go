@@ -2,6 +2,8 @@ package application
 
 import (
 	"os"
+	"os/user"
+	"time"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
@@ -21,3 +23,27 @@ func SetGetState(f func(string) (storage.State, error)) {
 func ResetGetState() {
 	getState = storage.GetState
 }
+
+func SetNow(f func() time.Time) {
+	now = f
+}
+
+func ResetNow() {
+	now = time.Now
+}
+
+func SetCurrentUser(f func() (*user.User, error)) {
+	currentUser = f
+}
+
+func ResetCurrentUser() {
+	currentUser = user.Current
+}
+
+func SetHostname(f func() (string, error)) {
+	hostname = f
+}
+
+func ResetHostname() {
+	hostname = os.Hostname
+}
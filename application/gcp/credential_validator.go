@@ -21,7 +21,7 @@ func (c CredentialValidator) Validate() error {
 		return errors.New("GCP project ID must be provided")
 	}
 
-	if c.configuration.State.GCP.ServiceAccountKey == "" {
+	if c.configuration.State.GCP.ServiceAccountKey == "" && c.configuration.State.GCP.UsesServiceAccountKey() {
 		return errors.New("GCP service account key must be provided")
 	}
 
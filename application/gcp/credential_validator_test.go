@@ -27,6 +27,21 @@ var _ = Describe("CredentialValidator", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("does not require a service account key with application default credentials", func() {
+			credentialValidator = gcp.NewCredentialValidator(application.Configuration{
+				State: storage.State{
+					GCP: storage.GCP{
+						ProjectID:                     "some-project-id",
+						ApplicationDefaultCredentials: true,
+						Region:                        "some-region",
+						Zone:                          "some-zone",
+					},
+				},
+			})
+			err := credentialValidator.Validate()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when the project id is missing", func() {
 				credentialValidator = gcp.NewCredentialValidator(application.Configuration{
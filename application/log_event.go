@@ -0,0 +1,108 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	TextLogFormat = "text"
+	JSONLogFormat = "json"
+)
+
+var now = time.Now
+
+// LogEvent is a line of output in the json log format.
+type LogEvent struct {
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	Command   string `json:"command"`
+	EnvID     string `json:"envID,omitempty"`
+	Step      string `json:"step,omitempty"`
+	Tool      string `json:"tool,omitempty"`
+	Message   string `json:"message"`
+}
+
+// EventEncoder writes log events as lines of json. It is safe for concurrent
+// use, so that a logger and the tools bbl runs can share one.
+type EventEncoder struct {
+	mutex   sync.Mutex
+	writer  io.Writer
+	command string
+	envID   string
+}
+
+func NewEventEncoder(writer io.Writer, command, envID string) *EventEncoder {
+	return &EventEncoder{
+		writer:  writer,
+		command: command,
+		envID:   envID,
+	}
+}
+
+func (e *EventEncoder) Encode(event LogEvent) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	event.Timestamp = now().UTC().Format(time.RFC3339Nano)
+	event.Command = e.command
+	event.EnvID = e.envID
+
+	contents, err := json.Marshal(event)
+	if err != nil {
+		return //not tested
+	}
+
+	e.writer.Write(append(contents, '\n'))
+}
+
+// EventWriter wraps each line written to it as a log event from the given
+// tool.
+type EventWriter struct {
+	mutex   sync.Mutex
+	encoder *EventEncoder
+	tool    string
+	partial []byte
+}
+
+func NewEventWriter(encoder *EventEncoder, tool string) *EventWriter {
+	return &EventWriter{
+		encoder: encoder,
+		tool:    tool,
+	}
+}
+
+func (w *EventWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i == -1 {
+			break
+		}
+
+		w.encode(string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+	}
+
+	return len(p), nil
+}
+
+func (w *EventWriter) encode(line string) {
+	line = strings.TrimSuffix(line, "\r")
+	if line == "" {
+		return
+	}
+
+	w.encoder.Encode(LogEvent{
+		Level:   "info",
+		Tool:    w.tool,
+		Message: line,
+	})
+}
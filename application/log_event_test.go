@@ -0,0 +1,51 @@
+package application_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/application"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EventWriter", func() {
+	var (
+		buffer *bytes.Buffer
+		writer *application.EventWriter
+	)
+
+	BeforeEach(func() {
+		application.SetNow(func() time.Time {
+			return time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+		})
+
+		buffer = bytes.NewBuffer([]byte{})
+		writer = application.NewEventWriter(application.NewEventEncoder(buffer, "up", ""), "terraform")
+	})
+
+	AfterEach(func() {
+		application.ResetNow()
+	})
+
+	It("writes each line as an event tagged with the tool", func() {
+		n, err := writer.Write([]byte("Initializing modules...\n\nApply complete!\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(41))
+
+		Expect(buffer.String()).To(Equal(`{"timestamp":"2017-08-01T12:00:00Z","level":"info","command":"up","tool":"terraform","message":"Initializing modules..."}
+{"timestamp":"2017-08-01T12:00:00Z","level":"info","command":"up","tool":"terraform","message":"Apply complete!"}
+`))
+	})
+
+	It("waits for the rest of a line before writing it", func() {
+		_, err := writer.Write([]byte("Apply "))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(BeEmpty())
+
+		_, err = writer.Write([]byte("complete!\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(ContainSubstring(`"message":"Apply complete!"`))
+	})
+})
@@ -3,11 +3,29 @@ package application
 import (
 	"fmt"
 	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
+// Logger is safe for concurrent use, so that steps of up running at the same
+// time do not interleave their output.
 type Logger struct {
+	mutex   sync.Mutex
 	newline bool
 	writer  io.Writer
+
+	// events is set in the json log format, where every message is written
+	// as a log event tagged with the step it belongs to.
+	events *EventEncoder
+	step   string
+
+	// steps are the steps logged so far, each lasting until the next one
+	// starts.
+	steps         []storage.AuditStep
+	stepStartedAt time.Time
 }
 
 func NewLogger(writer io.Writer) *Logger {
@@ -17,6 +35,13 @@ func NewLogger(writer io.Writer) *Logger {
 	}
 }
 
+func NewJSONLogger(events *EventEncoder) *Logger {
+	return &Logger{
+		newline: true,
+		events:  events,
+	}
+}
+
 func (l *Logger) clear() {
 	if l.newline {
 		return
@@ -27,28 +52,107 @@ func (l *Logger) clear() {
 }
 
 func (l *Logger) Step(message string, a ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.startStep(fmt.Sprintf(message, a...))
+
+	if l.events != nil {
+		l.step = fmt.Sprintf(message, a...)
+		l.event(l.step)
+		return
+	}
+
 	l.clear()
 	fmt.Fprintf(l.writer, "step: %s\n", fmt.Sprintf(message, a...))
 	l.newline = true
 }
 
 func (l *Logger) Dot() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.events != nil {
+		return
+	}
+
 	l.writer.Write([]byte("\u2022"))
 	l.newline = false
 }
 
 func (l *Logger) Printf(message string, a ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.events != nil {
+		l.event(fmt.Sprintf(message, a...))
+		return
+	}
+
 	l.clear()
 	fmt.Fprintf(l.writer, "%s", fmt.Sprintf(message, a...))
 }
 
 func (l *Logger) Println(message string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.events != nil {
+		l.event(message)
+		return
+	}
+
 	l.clear()
 	fmt.Fprintf(l.writer, "%s\n", message)
 }
 
 func (l *Logger) Prompt(message string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.events != nil {
+		l.event(fmt.Sprintf("%s (y/N)", message))
+		return
+	}
+
 	l.clear()
 	fmt.Fprintf(l.writer, "%s (y/N): ", message)
 	l.newline = true
 }
+
+// StepDurations returns how long each step logged so far took, the last one
+// lasting until now.
+func (l *Logger) StepDurations() []storage.AuditStep {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	steps := append([]storage.AuditStep{}, l.steps...)
+	if len(steps) > 0 {
+		steps[len(steps)-1].Duration = now().Sub(l.stepStartedAt)
+	}
+
+	return steps
+}
+
+func (l *Logger) startStep(name string) {
+	startedAt := now()
+	if len(l.steps) > 0 {
+		l.steps[len(l.steps)-1].Duration = startedAt.Sub(l.stepStartedAt)
+	}
+
+	l.steps = append(l.steps, storage.AuditStep{Name: name})
+	l.stepStartedAt = startedAt
+}
+
+func (l *Logger) event(message string) {
+	message = strings.TrimRight(message, "\n")
+	if message == "" {
+		return
+	}
+
+	l.events.Encode(LogEvent{
+		Level:   "info",
+		Step:    l.step,
+		Message: message,
+	})
+}
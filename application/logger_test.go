@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"math/rand"
+	"time"
 
 	"github.com/cloudfoundry/bosh-bootloader/application"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -92,4 +94,57 @@ SUCCESS!
 `))
 		})
 	})
+
+	Describe("json log format", func() {
+		BeforeEach(func() {
+			application.SetNow(func() time.Time {
+				return time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+			})
+
+			logger = application.NewJSONLogger(application.NewEventEncoder(buffer, "up", "some-env-id"))
+		})
+
+		AfterEach(func() {
+			application.ResetNow()
+		})
+
+		It("prints every message as an event tagged with the current step", func() {
+			logger.Println("before any step")
+			logger.Step("creating %s", "keypair")
+			logger.Dot()
+			logger.Printf("some %s\n", "details")
+			logger.Prompt("do you like turtles?")
+
+			Expect(buffer.String()).To(Equal(`{"timestamp":"2017-08-01T12:00:00Z","level":"info","command":"up","envID":"some-env-id","message":"before any step"}
+{"timestamp":"2017-08-01T12:00:00Z","level":"info","command":"up","envID":"some-env-id","step":"creating keypair","message":"creating keypair"}
+{"timestamp":"2017-08-01T12:00:00Z","level":"info","command":"up","envID":"some-env-id","step":"creating keypair","message":"some details"}
+{"timestamp":"2017-08-01T12:00:00Z","level":"info","command":"up","envID":"some-env-id","step":"creating keypair","message":"do you like turtles? (y/N)"}
+`))
+		})
+	})
+
+	Describe("StepDurations", func() {
+		var currentTime time.Time
+
+		BeforeEach(func() {
+			currentTime = time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+			application.SetNow(func() time.Time { return currentTime })
+		})
+
+		AfterEach(func() {
+			application.ResetNow()
+		})
+
+		It("returns how long each step took, the last one lasting until now", func() {
+			logger.Step("creating keypair")
+			currentTime = currentTime.Add(2 * time.Second)
+			logger.Step("applying terraform")
+			currentTime = currentTime.Add(time.Minute)
+
+			Expect(logger.StepDurations()).To(Equal([]storage.AuditStep{
+				{Name: "creating keypair", Duration: 2 * time.Second},
+				{Name: "applying terraform", Duration: time.Minute},
+			}))
+		})
+	})
 })
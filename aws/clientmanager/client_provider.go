@@ -1,6 +1,9 @@
 package clientmanager
 
 import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/cloudfoundry/bosh-bootloader/aws"
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
 	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
@@ -8,12 +11,16 @@ import (
 )
 
 type ClientProvider struct {
+	config               aws.Config
+	credentials          *credentials.Credentials
 	ec2Client            ec2.Client
 	cloudformationClient cloudformation.Client
 	iamClient            iam.Client
 }
 
 func (c *ClientProvider) SetConfig(config aws.Config) {
+	c.config = config
+	c.credentials = config.ClientConfig().Credentials
 	c.ec2Client = ec2.NewClient(config)
 	c.cloudformationClient = cloudformation.NewClient(config)
 	c.iamClient = iam.NewClient(config)
@@ -30,3 +37,42 @@ func (c *ClientProvider) GetCloudFormationClient() cloudformation.Client {
 func (c *ClientProvider) GetIAMClient() iam.Client {
 	return c.iamClient
 }
+
+// GetCredentials resolves the credentials of the config that was last set.
+// Short-lived credentials are resolved again once they expire.
+func (c *ClientProvider) GetCredentials() (aws.Credentials, error) {
+	value, err := c.credentials.Get()
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+
+	return aws.Credentials{
+		AccessKeyID:     value.AccessKeyID,
+		SecretAccessKey: value.SecretAccessKey,
+		SessionToken:    value.SessionToken,
+	}, nil
+}
+
+// CredentialsEnv is the environment that tools bbl runs, such as terraform,
+// authenticate to AWS with. It is empty for static credentials, which are
+// passed to the tools directly.
+func (c *ClientProvider) CredentialsEnv() ([]string, error) {
+	if c.config.Static() {
+		return nil, nil
+	}
+
+	resolved, err := c.GetCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	env := []string{
+		fmt.Sprintf("AWS_ACCESS_KEY_ID=%s", resolved.AccessKeyID),
+		fmt.Sprintf("AWS_SECRET_ACCESS_KEY=%s", resolved.SecretAccessKey),
+	}
+	if resolved.SessionToken != "" {
+		env = append(env, fmt.Sprintf("AWS_SESSION_TOKEN=%s", resolved.SessionToken))
+	}
+
+	return env, nil
+}
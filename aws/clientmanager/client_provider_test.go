@@ -0,0 +1,110 @@
+package clientmanager_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws"
+	"github.com/cloudfoundry/bosh-bootloader/aws/clientmanager"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClientProvider", func() {
+	var clientProvider *clientmanager.ClientProvider
+
+	BeforeEach(func() {
+		clientProvider = &clientmanager.ClientProvider{}
+	})
+
+	Context("when the config has static credentials", func() {
+		BeforeEach(func() {
+			clientProvider.SetConfig(aws.Config{
+				AccessKeyID:     "some-access-key-id",
+				SecretAccessKey: "some-secret-access-key",
+				Region:          "some-region",
+			})
+		})
+
+		It("returns the access keys as the credentials", func() {
+			credentials, err := clientProvider.GetCredentials()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(credentials).To(Equal(aws.Credentials{
+				AccessKeyID:     "some-access-key-id",
+				SecretAccessKey: "some-secret-access-key",
+			}))
+		})
+
+		It("returns no credentials env", func() {
+			env, err := clientProvider.CredentialsEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env).To(BeEmpty())
+		})
+	})
+
+	Context("when the config has a profile", func() {
+		var (
+			credentialsDir          string
+			originalCredentialsFile string
+		)
+
+		BeforeEach(func() {
+			var err error
+			credentialsDir, err = ioutil.TempDir("", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			credentialsFile := filepath.Join(credentialsDir, "credentials")
+			err = ioutil.WriteFile(credentialsFile, []byte(`[some-profile]
+aws_access_key_id = profile-access-key-id
+aws_secret_access_key = profile-secret-access-key
+aws_session_token = profile-session-token
+`), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			originalCredentialsFile = os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+			os.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+
+			clientProvider.SetConfig(aws.Config{
+				Profile: "some-profile",
+				Region:  "some-region",
+			})
+		})
+
+		AfterEach(func() {
+			os.Setenv("AWS_SHARED_CREDENTIALS_FILE", originalCredentialsFile)
+			os.RemoveAll(credentialsDir)
+		})
+
+		It("returns the credentials of the profile", func() {
+			credentials, err := clientProvider.GetCredentials()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(credentials).To(Equal(aws.Credentials{
+				AccessKeyID:     "profile-access-key-id",
+				SecretAccessKey: "profile-secret-access-key",
+				SessionToken:    "profile-session-token",
+			}))
+		})
+
+		It("returns the credentials of the profile as the credentials env", func() {
+			env, err := clientProvider.CredentialsEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env).To(Equal([]string{
+				"AWS_ACCESS_KEY_ID=profile-access-key-id",
+				"AWS_SECRET_ACCESS_KEY=profile-secret-access-key",
+				"AWS_SESSION_TOKEN=profile-session-token",
+			}))
+		})
+
+		It("returns an error when the profile does not exist", func() {
+			clientProvider.SetConfig(aws.Config{
+				Profile: "some-missing-profile",
+				Region:  "some-region",
+			})
+
+			_, err := clientProvider.CredentialsEnv()
+			Expect(err).To(MatchError(ContainSubstring("some-missing-profile")))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package clientmanager_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestClientManager(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "aws/clientmanager")
+}
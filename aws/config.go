@@ -3,19 +3,76 @@ package aws
 import (
 	goaws "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/session"
 )
 
 type Config struct {
 	AccessKeyID     string
 	SecretAccessKey string
 	Region          string
+	Profile         string
+	CredentialChain bool
+	AssumeRoleARN   string
+	ExternalID      string
+	SessionName     string
+}
+
+// Credentials are the keys a Config's credentials resolve to. The session
+// token is only set for short-lived credentials.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// Static reports whether the credentials are the access keys themselves,
+// rather than credentials that are resolved from a profile, the default
+// credential chain or an assumed role.
+func (c Config) Static() bool {
+	return c.Profile == "" && !c.CredentialChain && c.AssumeRoleARN == ""
 }
 
 func (c Config) ClientConfig() *goaws.Config {
 	awsConfig := &goaws.Config{
-		Credentials: credentials.NewStaticCredentials(c.AccessKeyID, c.SecretAccessKey, ""),
+		Credentials: c.credentials(),
 		Region:      goaws.String(c.Region),
 	}
 
 	return awsConfig
 }
+
+// credentials are the static keys when they are given, and otherwise the
+// named profile or the SDK's default chain of environment variables, shared
+// credentials file and instance metadata. When a role is given they are the
+// credentials the role is assumed with.
+func (c Config) credentials() *credentials.Credentials {
+	var creds *credentials.Credentials
+	switch {
+	case c.AccessKeyID != "" || c.SecretAccessKey != "":
+		creds = credentials.NewStaticCredentials(c.AccessKeyID, c.SecretAccessKey, "")
+	case c.Profile != "":
+		creds = credentials.NewSharedCredentials("", c.Profile)
+	default:
+		creds = defaults.CredChain(defaults.Config(), defaults.Handlers())
+	}
+
+	if c.AssumeRoleARN == "" {
+		return creds
+	}
+
+	stsSession := session.New(&goaws.Config{
+		Credentials: creds,
+		Region:      goaws.String(c.Region),
+	})
+
+	return stscreds.NewCredentials(stsSession, c.AssumeRoleARN, func(provider *stscreds.AssumeRoleProvider) {
+		if c.ExternalID != "" {
+			provider.ExternalID = goaws.String(c.ExternalID)
+		}
+		if c.SessionName != "" {
+			provider.RoleSessionName = c.SessionName
+		}
+	})
+}
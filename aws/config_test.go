@@ -25,5 +25,35 @@ var _ = Describe("Config", func() {
 
 			Expect(config.ClientConfig()).To(Equal(awsConfig))
 		})
+
+		It("uses the shared credentials of the profile when one is given", func() {
+			config := aws.Config{
+				Profile: "some-profile",
+				Region:  "some-region",
+			}
+
+			Expect(config.ClientConfig().Credentials).To(Equal(credentials.NewSharedCredentials("", "some-profile")))
+		})
+	})
+
+	Describe("Static", func() {
+		It("returns true when the credentials are access keys", func() {
+			config := aws.Config{
+				AccessKeyID:     "some-access-key-id",
+				SecretAccessKey: "some-secret-access-key",
+			}
+
+			Expect(config.Static()).To(BeTrue())
+		})
+
+		It("returns false when the credentials are resolved from a profile, the credential chain or a role", func() {
+			Expect(aws.Config{Profile: "some-profile"}.Static()).To(BeFalse())
+			Expect(aws.Config{CredentialChain: true}.Static()).To(BeFalse())
+			Expect(aws.Config{
+				AccessKeyID:     "some-access-key-id",
+				SecretAccessKey: "some-secret-access-key",
+				AssumeRoleARN:   "some-role-arn",
+			}.Static()).To(BeFalse())
+		})
 	})
 })
@@ -15,6 +15,19 @@ type Client interface {
 	DeleteKeyPair(*awsec2.DeleteKeyPairInput) (*awsec2.DeleteKeyPairOutput, error)
 	DescribeInstances(*awsec2.DescribeInstancesInput) (*awsec2.DescribeInstancesOutput, error)
 	DescribeVpcs(*awsec2.DescribeVpcsInput) (*awsec2.DescribeVpcsOutput, error)
+	DescribeImages(*awsec2.DescribeImagesInput) (*awsec2.DescribeImagesOutput, error)
+	DescribeVolumes(*awsec2.DescribeVolumesInput) (*awsec2.DescribeVolumesOutput, error)
+	StopInstances(*awsec2.StopInstancesInput) (*awsec2.StopInstancesOutput, error)
+	WaitUntilInstanceStopped(*awsec2.DescribeInstancesInput) error
+	TerminateInstances(*awsec2.TerminateInstancesInput) (*awsec2.TerminateInstancesOutput, error)
+	WaitUntilInstanceTerminated(*awsec2.DescribeInstancesInput) error
+	CreateSnapshot(*awsec2.CreateSnapshotInput) (*awsec2.Snapshot, error)
+	WaitUntilSnapshotCompleted(*awsec2.DescribeSnapshotsInput) error
+	CreateVolume(*awsec2.CreateVolumeInput) (*awsec2.Volume, error)
+	WaitUntilVolumeAvailable(*awsec2.DescribeVolumesInput) error
+	DeleteVolume(*awsec2.DeleteVolumeInput) (*awsec2.DeleteVolumeOutput, error)
+	DescribeAddresses(*awsec2.DescribeAddressesInput) (*awsec2.DescribeAddressesOutput, error)
+	ReleaseAddress(*awsec2.ReleaseAddressInput) (*awsec2.ReleaseAddressOutput, error)
 }
 
 func NewClient(config aws.Config) Client {
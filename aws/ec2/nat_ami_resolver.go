@@ -0,0 +1,61 @@
+package ec2
+
+import (
+	"errors"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const natAMINameFilter = "amzn-ami-vpc-nat-hvm-*"
+
+type NATAMIResolver struct {
+	ec2ClientProvider ec2ClientProvider
+}
+
+func NewNATAMIResolver(ec2ClientProvider ec2ClientProvider) NATAMIResolver {
+	return NATAMIResolver{
+		ec2ClientProvider: ec2ClientProvider,
+	}
+}
+
+func (r NATAMIResolver) Resolve() (string, error) {
+	output, err := r.ec2ClientProvider.GetEC2Client().DescribeImages(&awsec2.DescribeImagesInput{
+		Owners: []*string{goaws.String("amazon")},
+		Filters: []*awsec2.Filter{
+			{
+				Name:   goaws.String("name"),
+				Values: []*string{goaws.String(natAMINameFilter)},
+			},
+			{
+				Name:   goaws.String("architecture"),
+				Values: []*string{goaws.String("x86_64")},
+			},
+			{
+				Name:   goaws.String("state"),
+				Values: []*string{goaws.String("available")},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var latest *awsec2.Image
+	for _, image := range output.Images {
+		if image == nil || image.ImageId == nil || image.CreationDate == nil {
+			continue
+		}
+
+		// CreationDate is an ISO 8601 timestamp, so it sorts lexically.
+		if latest == nil || *image.CreationDate > *latest.CreationDate {
+			latest = image
+		}
+	}
+
+	if latest == nil {
+		return "", errors.New("no NAT AMI could be found in this region, provide one with --aws-nat-ami")
+	}
+
+	return *latest.ImageId, nil
+}
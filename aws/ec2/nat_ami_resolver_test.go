@@ -0,0 +1,78 @@
+package ec2_test
+
+import (
+	"errors"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NATAMIResolver", func() {
+	var (
+		natAMIResolver    ec2.NATAMIResolver
+		ec2Client         *fakes.EC2Client
+		awsClientProvider *fakes.AWSClientProvider
+	)
+
+	BeforeEach(func() {
+		ec2Client = &fakes.EC2Client{}
+		awsClientProvider = &fakes.AWSClientProvider{}
+		awsClientProvider.GetEC2ClientCall.Returns.EC2Client = ec2Client
+		natAMIResolver = ec2.NewNATAMIResolver(awsClientProvider)
+	})
+
+	It("returns the most recently created NAT AMI", func() {
+		ec2Client.DescribeImagesCall.Returns.Output = &awsec2.DescribeImagesOutput{
+			Images: []*awsec2.Image{
+				{ImageId: goaws.String("ami-old"), CreationDate: goaws.String("2016-10-26T22:32:29.000Z")},
+				{ImageId: goaws.String("ami-latest"), CreationDate: goaws.String("2017-06-22T22:12:07.000Z")},
+				{ImageId: goaws.String("ami-older"), CreationDate: goaws.String("2016-03-16T21:12:33.000Z")},
+			},
+		}
+
+		ami, err := natAMIResolver.Resolve()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ami).To(Equal("ami-latest"))
+
+		Expect(ec2Client.DescribeImagesCall.Receives.Input).To(Equal(&awsec2.DescribeImagesInput{
+			Owners: []*string{goaws.String("amazon")},
+			Filters: []*awsec2.Filter{
+				{
+					Name:   goaws.String("name"),
+					Values: []*string{goaws.String("amzn-ami-vpc-nat-hvm-*")},
+				},
+				{
+					Name:   goaws.String("architecture"),
+					Values: []*string{goaws.String("x86_64")},
+				},
+				{
+					Name:   goaws.String("state"),
+					Values: []*string{goaws.String("available")},
+				},
+			},
+		}))
+	})
+
+	Describe("failure cases", func() {
+		It("returns an error when no NAT AMI is found", func() {
+			ec2Client.DescribeImagesCall.Returns.Output = &awsec2.DescribeImagesOutput{
+				Images: []*awsec2.Image{nil, {ImageId: nil}},
+			}
+
+			_, err := natAMIResolver.Resolve()
+			Expect(err).To(MatchError("no NAT AMI could be found in this region, provide one with --aws-nat-ami"))
+		})
+
+		It("returns an error when describe images fails", func() {
+			ec2Client.DescribeImagesCall.Returns.Error = errors.New("describe images failed")
+
+			_, err := natAMIResolver.Resolve()
+			Expect(err).To(MatchError("describe images failed"))
+		})
+	})
+})
@@ -0,0 +1,181 @@
+package ec2
+
+import (
+	"fmt"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// OrphanSweeper finds and deletes the instances, volumes and elastic IPs
+// that carry the "director" tag the BOSH director puts on everything it
+// creates.
+type OrphanSweeper struct {
+	ec2ClientProvider ec2ClientProvider
+	logger            logger
+}
+
+func NewOrphanSweeper(ec2ClientProvider ec2ClientProvider, logger logger) OrphanSweeper {
+	return OrphanSweeper{
+		ec2ClientProvider: ec2ClientProvider,
+		logger:            logger,
+	}
+}
+
+// Orphans describes every resource tagged for the director.
+func (o OrphanSweeper) Orphans(directorName string) ([]string, error) {
+	client := o.ec2ClientProvider.GetEC2Client()
+
+	instances, err := o.instances(client, directorName)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := o.volumes(client, directorName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := o.addresses(client, directorName)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := []string{}
+	for _, instance := range instances {
+		orphans = append(orphans, fmt.Sprintf("instance %s", goaws.StringValue(instance.InstanceId)))
+	}
+	for _, volume := range volumes {
+		orphans = append(orphans, fmt.Sprintf("volume %s", goaws.StringValue(volume.VolumeId)))
+	}
+	for _, address := range addresses {
+		orphans = append(orphans, fmt.Sprintf("elastic ip %s", goaws.StringValue(address.PublicIp)))
+	}
+
+	return orphans, nil
+}
+
+// DeleteOrphans terminates the tagged instances and then deletes the tagged
+// volumes, which are only detached once their instance is gone, and
+// releases the tagged elastic IPs.
+func (o OrphanSweeper) DeleteOrphans(directorName string) error {
+	client := o.ec2ClientProvider.GetEC2Client()
+
+	instances, err := o.instances(client, directorName)
+	if err != nil {
+		return err
+	}
+
+	if len(instances) > 0 {
+		instanceIDs := []*string{}
+		for _, instance := range instances {
+			instanceIDs = append(instanceIDs, instance.InstanceId)
+		}
+
+		o.logger.Step("terminating %d instance(s)", len(instanceIDs))
+		_, err = client.TerminateInstances(&awsec2.TerminateInstancesInput{
+			InstanceIds: instanceIDs,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = client.WaitUntilInstanceTerminated(&awsec2.DescribeInstancesInput{
+			InstanceIds: instanceIDs,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	volumes, err := o.volumes(client, directorName, true)
+	if err != nil {
+		return err
+	}
+
+	for _, volume := range volumes {
+		o.logger.Step("deleting volume %s", goaws.StringValue(volume.VolumeId))
+		_, err = client.DeleteVolume(&awsec2.DeleteVolumeInput{
+			VolumeId: volume.VolumeId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	addresses, err := o.addresses(client, directorName)
+	if err != nil {
+		return err
+	}
+
+	for _, address := range addresses {
+		o.logger.Step("releasing elastic ip %s", goaws.StringValue(address.PublicIp))
+		_, err = client.ReleaseAddress(&awsec2.ReleaseAddressInput{
+			AllocationId: address.AllocationId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o OrphanSweeper) instances(client Client, directorName string) ([]*awsec2.Instance, error) {
+	output, err := client.DescribeInstances(&awsec2.DescribeInstancesInput{
+		Filters: []*awsec2.Filter{
+			directorFilter(directorName),
+			{
+				Name:   goaws.String("instance-state-name"),
+				Values: goaws.StringSlice([]string{"pending", "running", "stopping", "stopped"}),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	instances := []*awsec2.Instance{}
+	for _, reservation := range output.Reservations {
+		instances = append(instances, reservation.Instances...)
+	}
+
+	return instances, nil
+}
+
+func (o OrphanSweeper) volumes(client Client, directorName string, availableOnly bool) ([]*awsec2.Volume, error) {
+	filters := []*awsec2.Filter{directorFilter(directorName)}
+	if availableOnly {
+		filters = append(filters, &awsec2.Filter{
+			Name:   goaws.String("status"),
+			Values: goaws.StringSlice([]string{"available"}),
+		})
+	}
+
+	output, err := client.DescribeVolumes(&awsec2.DescribeVolumesInput{
+		Filters: filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Volumes, nil
+}
+
+func (o OrphanSweeper) addresses(client Client, directorName string) ([]*awsec2.Address, error) {
+	output, err := client.DescribeAddresses(&awsec2.DescribeAddressesInput{
+		Filters: []*awsec2.Filter{directorFilter(directorName)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Addresses, nil
+}
+
+func directorFilter(directorName string) *awsec2.Filter {
+	return &awsec2.Filter{
+		Name:   goaws.String("tag:director"),
+		Values: goaws.StringSlice([]string{directorName}),
+	}
+}
@@ -0,0 +1,145 @@
+package ec2_test
+
+import (
+	"errors"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OrphanSweeper", func() {
+	var (
+		orphanSweeper     ec2.OrphanSweeper
+		ec2Client         *fakes.EC2Client
+		awsClientProvider *fakes.AWSClientProvider
+		logger            *fakes.Logger
+
+		directorFilter *awsec2.Filter
+	)
+
+	BeforeEach(func() {
+		ec2Client = &fakes.EC2Client{}
+		awsClientProvider = &fakes.AWSClientProvider{}
+		awsClientProvider.GetEC2ClientCall.Returns.EC2Client = ec2Client
+		logger = &fakes.Logger{}
+
+		ec2Client.DescribeInstancesCall.Returns.Output = &awsec2.DescribeInstancesOutput{
+			Reservations: []*awsec2.Reservation{{
+				Instances: []*awsec2.Instance{
+					{InstanceId: goaws.String("i-1")},
+					{InstanceId: goaws.String("i-2")},
+				},
+			}},
+		}
+		ec2Client.DescribeVolumesCall.Returns.Output = &awsec2.DescribeVolumesOutput{
+			Volumes: []*awsec2.Volume{{VolumeId: goaws.String("vol-1")}},
+		}
+		ec2Client.DescribeAddressesCall.Returns.Output = &awsec2.DescribeAddressesOutput{
+			Addresses: []*awsec2.Address{{
+				AllocationId: goaws.String("eipalloc-1"),
+				PublicIp:     goaws.String("1.2.3.4"),
+			}},
+		}
+
+		directorFilter = &awsec2.Filter{
+			Name:   goaws.String("tag:director"),
+			Values: []*string{goaws.String("bosh-some-env-id")},
+		}
+
+		orphanSweeper = ec2.NewOrphanSweeper(awsClientProvider, logger)
+	})
+
+	Describe("Orphans", func() {
+		It("describes every resource tagged for the director", func() {
+			orphans, err := orphanSweeper.Orphans("bosh-some-env-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(orphans).To(Equal([]string{
+				"instance i-1",
+				"instance i-2",
+				"volume vol-1",
+				"elastic ip 1.2.3.4",
+			}))
+
+			Expect(ec2Client.DescribeInstancesCall.Receives.Input.Filters).To(ContainElement(directorFilter))
+			Expect(ec2Client.DescribeVolumesCall.Receives.Input.Filters).To(Equal([]*awsec2.Filter{directorFilter}))
+			Expect(ec2Client.DescribeAddressesCall.Receives.Input.Filters).To(Equal([]*awsec2.Filter{directorFilter}))
+		})
+
+		It("returns an error when the instances cannot be described", func() {
+			ec2Client.DescribeInstancesCall.Returns.Error = errors.New("failed to describe instances")
+
+			_, err := orphanSweeper.Orphans("bosh-some-env-id")
+			Expect(err).To(MatchError("failed to describe instances"))
+		})
+	})
+
+	Describe("DeleteOrphans", func() {
+		It("terminates the instances and then deletes the volumes and elastic ips", func() {
+			err := orphanSweeper.DeleteOrphans("bosh-some-env-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ec2Client.TerminateInstancesCall.Receives.Input).To(Equal(&awsec2.TerminateInstancesInput{
+				InstanceIds: []*string{goaws.String("i-1"), goaws.String("i-2")},
+			}))
+			Expect(ec2Client.WaitUntilInstanceTerminatedCall.Receives.Input).To(Equal(&awsec2.DescribeInstancesInput{
+				InstanceIds: []*string{goaws.String("i-1"), goaws.String("i-2")},
+			}))
+			Expect(ec2Client.DescribeVolumesCall.Receives.Input.Filters).To(ContainElement(&awsec2.Filter{
+				Name:   goaws.String("status"),
+				Values: []*string{goaws.String("available")},
+			}))
+			Expect(ec2Client.DeleteVolumeCall.Receives.Inputs).To(Equal([]*awsec2.DeleteVolumeInput{
+				{VolumeId: goaws.String("vol-1")},
+			}))
+			Expect(ec2Client.ReleaseAddressCall.Receives.Inputs).To(Equal([]*awsec2.ReleaseAddressInput{
+				{AllocationId: goaws.String("eipalloc-1")},
+			}))
+
+			Expect(logger.StepCall.Messages).To(Equal([]string{
+				"terminating 2 instance(s)",
+				"deleting volume vol-1",
+				"releasing elastic ip 1.2.3.4",
+			}))
+		})
+
+		It("does not terminate anything when there are no tagged instances", func() {
+			ec2Client.DescribeInstancesCall.Returns.Output = &awsec2.DescribeInstancesOutput{}
+
+			err := orphanSweeper.DeleteOrphans("bosh-some-env-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ec2Client.TerminateInstancesCall.Receives.Input).To(BeNil())
+			Expect(ec2Client.DeleteVolumeCall.CallCount).To(Equal(1))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the instances cannot be terminated", func() {
+				ec2Client.TerminateInstancesCall.Returns.Error = errors.New("failed to terminate")
+
+				err := orphanSweeper.DeleteOrphans("bosh-some-env-id")
+				Expect(err).To(MatchError("failed to terminate"))
+				Expect(ec2Client.DeleteVolumeCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when a volume cannot be deleted", func() {
+				ec2Client.DeleteVolumeCall.Returns.Error = errors.New("failed to delete volume")
+
+				err := orphanSweeper.DeleteOrphans("bosh-some-env-id")
+				Expect(err).To(MatchError("failed to delete volume"))
+			})
+
+			It("returns an error when an elastic ip cannot be released", func() {
+				ec2Client.ReleaseAddressCall.Returns.Error = errors.New("failed to release")
+
+				err := orphanSweeper.DeleteOrphans("bosh-some-env-id")
+				Expect(err).To(MatchError("failed to release"))
+			})
+		})
+	})
+})
@@ -0,0 +1,125 @@
+package ec2
+
+import (
+	"errors"
+	"fmt"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+)
+
+type VolumeMigrator struct {
+	ec2ClientProvider ec2ClientProvider
+	logger            logger
+}
+
+func NewVolumeMigrator(ec2ClientProvider ec2ClientProvider, logger logger) VolumeMigrator {
+	return VolumeMigrator{
+		ec2ClientProvider: ec2ClientProvider,
+		logger:            logger,
+	}
+}
+
+// Migrate copies the volume into the given availability zone through a
+// snapshot and returns the ID of the new volume. The instance, when given, is
+// stopped before the snapshot is taken and terminated once the copy exists.
+// The original volume and the snapshot are left in place.
+func (m VolumeMigrator) Migrate(instanceID, volumeID, availabilityZone string) (string, error) {
+	client := m.ec2ClientProvider.GetEC2Client()
+
+	volumes, err := client.DescribeVolumes(&awsec2.DescribeVolumesInput{
+		VolumeIds: []*string{goaws.String(volumeID)},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(volumes.Volumes) != 1 {
+		return "", fmt.Errorf("volume %s could not be found", volumeID)
+	}
+	volume := volumes.Volumes[0]
+
+	if instanceID != "" {
+		m.logger.Step("stopping instance %s", instanceID)
+		_, err = client.StopInstances(&awsec2.StopInstancesInput{
+			InstanceIds: []*string{goaws.String(instanceID)},
+		})
+		if err != nil {
+			return "", err
+		}
+
+		err = client.WaitUntilInstanceStopped(&awsec2.DescribeInstancesInput{
+			InstanceIds: []*string{goaws.String(instanceID)},
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	m.logger.Step("creating snapshot of volume %s", volumeID)
+	snapshot, err := client.CreateSnapshot(&awsec2.CreateSnapshotInput{
+		VolumeId:    goaws.String(volumeID),
+		Description: goaws.String(fmt.Sprintf("bbl move-director copy of %s", volumeID)),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if snapshot.SnapshotId == nil {
+		return "", errors.New("snapshot was created without an id")
+	}
+
+	err = client.WaitUntilSnapshotCompleted(&awsec2.DescribeSnapshotsInput{
+		SnapshotIds: []*string{snapshot.SnapshotId},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	m.logger.Step("creating volume in %s from snapshot %s", availabilityZone, *snapshot.SnapshotId)
+	createVolumeInput := &awsec2.CreateVolumeInput{
+		AvailabilityZone: goaws.String(availabilityZone),
+		SnapshotId:       snapshot.SnapshotId,
+		VolumeType:       volume.VolumeType,
+	}
+	if goaws.StringValue(volume.VolumeType) == awsec2.VolumeTypeIo1 {
+		createVolumeInput.Iops = volume.Iops
+	}
+
+	newVolume, err := client.CreateVolume(createVolumeInput)
+	if err != nil {
+		return "", err
+	}
+
+	if newVolume.VolumeId == nil {
+		return "", errors.New("volume was created without an id")
+	}
+
+	err = client.WaitUntilVolumeAvailable(&awsec2.DescribeVolumesInput{
+		VolumeIds: []*string{newVolume.VolumeId},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if instanceID != "" {
+		m.logger.Step("terminating instance %s", instanceID)
+		_, err = client.TerminateInstances(&awsec2.TerminateInstancesInput{
+			InstanceIds: []*string{goaws.String(instanceID)},
+		})
+		if err != nil {
+			return "", err
+		}
+
+		err = client.WaitUntilInstanceTerminated(&awsec2.DescribeInstancesInput{
+			InstanceIds: []*string{goaws.String(instanceID)},
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	m.logger.Step("volume %s and snapshot %s were kept and can be deleted once the director is healthy", volumeID, *snapshot.SnapshotId)
+
+	return *newVolume.VolumeId, nil
+}
@@ -0,0 +1,128 @@
+package ec2_test
+
+import (
+	"errors"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VolumeMigrator", func() {
+	var (
+		volumeMigrator    ec2.VolumeMigrator
+		ec2Client         *fakes.EC2Client
+		awsClientProvider *fakes.AWSClientProvider
+		logger            *fakes.Logger
+	)
+
+	BeforeEach(func() {
+		ec2Client = &fakes.EC2Client{}
+		awsClientProvider = &fakes.AWSClientProvider{}
+		awsClientProvider.GetEC2ClientCall.Returns.EC2Client = ec2Client
+		logger = &fakes.Logger{}
+
+		ec2Client.DescribeVolumesCall.Returns.Output = &awsec2.DescribeVolumesOutput{
+			Volumes: []*awsec2.Volume{
+				{
+					VolumeId:   goaws.String("vol-old"),
+					VolumeType: goaws.String("gp2"),
+				},
+			},
+		}
+		ec2Client.CreateSnapshotCall.Returns.Output = &awsec2.Snapshot{
+			SnapshotId: goaws.String("snap-1"),
+		}
+		ec2Client.CreateVolumeCall.Returns.Output = &awsec2.Volume{
+			VolumeId: goaws.String("vol-new"),
+		}
+
+		volumeMigrator = ec2.NewVolumeMigrator(awsClientProvider, logger)
+	})
+
+	It("copies the volume into the availability zone and terminates the instance", func() {
+		volumeID, err := volumeMigrator.Migrate("i-director", "vol-old", "some-az")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(volumeID).To(Equal("vol-new"))
+
+		Expect(ec2Client.DescribeVolumesCall.Receives.Input).To(Equal(&awsec2.DescribeVolumesInput{
+			VolumeIds: []*string{goaws.String("vol-old")},
+		}))
+		Expect(ec2Client.StopInstancesCall.Receives.Input).To(Equal(&awsec2.StopInstancesInput{
+			InstanceIds: []*string{goaws.String("i-director")},
+		}))
+		Expect(ec2Client.WaitUntilInstanceStoppedCall.Receives.Input).To(Equal(&awsec2.DescribeInstancesInput{
+			InstanceIds: []*string{goaws.String("i-director")},
+		}))
+		Expect(ec2Client.CreateSnapshotCall.Receives.Input.VolumeId).To(Equal(goaws.String("vol-old")))
+		Expect(ec2Client.WaitUntilSnapshotCompletedCall.Receives.Input).To(Equal(&awsec2.DescribeSnapshotsInput{
+			SnapshotIds: []*string{goaws.String("snap-1")},
+		}))
+		Expect(ec2Client.CreateVolumeCall.Receives.Input).To(Equal(&awsec2.CreateVolumeInput{
+			AvailabilityZone: goaws.String("some-az"),
+			SnapshotId:       goaws.String("snap-1"),
+			VolumeType:       goaws.String("gp2"),
+		}))
+		Expect(ec2Client.WaitUntilVolumeAvailableCall.Receives.Input).To(Equal(&awsec2.DescribeVolumesInput{
+			VolumeIds: []*string{goaws.String("vol-new")},
+		}))
+		Expect(ec2Client.TerminateInstancesCall.Receives.Input).To(Equal(&awsec2.TerminateInstancesInput{
+			InstanceIds: []*string{goaws.String("i-director")},
+		}))
+		Expect(ec2Client.WaitUntilInstanceTerminatedCall.Receives.Input).To(Equal(&awsec2.DescribeInstancesInput{
+			InstanceIds: []*string{goaws.String("i-director")},
+		}))
+
+		Expect(logger.StepCall.Messages).To(ContainElement("volume vol-old and snapshot snap-1 were kept and can be deleted once the director is healthy"))
+	})
+
+	It("keeps the provisioned iops of io1 volumes", func() {
+		ec2Client.DescribeVolumesCall.Returns.Output.Volumes[0].VolumeType = goaws.String("io1")
+		ec2Client.DescribeVolumesCall.Returns.Output.Volumes[0].Iops = goaws.Int64(1000)
+
+		_, err := volumeMigrator.Migrate("i-director", "vol-old", "some-az")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ec2Client.CreateVolumeCall.Receives.Input.Iops).To(Equal(goaws.Int64(1000)))
+	})
+
+	Context("when there is no instance", func() {
+		It("only copies the volume", func() {
+			_, err := volumeMigrator.Migrate("", "vol-old", "some-az")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ec2Client.StopInstancesCall.Receives.Input).To(BeNil())
+			Expect(ec2Client.TerminateInstancesCall.Receives.Input).To(BeNil())
+			Expect(ec2Client.CreateVolumeCall.Receives.Input).NotTo(BeNil())
+		})
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when the volume cannot be found", func() {
+			ec2Client.DescribeVolumesCall.Returns.Output = &awsec2.DescribeVolumesOutput{}
+
+			_, err := volumeMigrator.Migrate("i-director", "vol-old", "some-az")
+			Expect(err).To(MatchError("volume vol-old could not be found"))
+		})
+
+		It("returns an error when the snapshot cannot be created", func() {
+			ec2Client.CreateSnapshotCall.Returns.Error = errors.New("failed to snapshot")
+
+			_, err := volumeMigrator.Migrate("i-director", "vol-old", "some-az")
+			Expect(err).To(MatchError("failed to snapshot"))
+			Expect(ec2Client.TerminateInstancesCall.Receives.Input).To(BeNil())
+		})
+
+		It("does not terminate the instance when the volume cannot be created", func() {
+			ec2Client.CreateVolumeCall.Returns.Error = errors.New("failed to create volume")
+
+			_, err := volumeMigrator.Migrate("i-director", "vol-old", "some-az")
+			Expect(err).To(MatchError("failed to create volume"))
+			Expect(ec2Client.TerminateInstancesCall.Receives.Input).To(BeNil())
+		})
+	})
+})
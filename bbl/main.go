@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -19,6 +20,7 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
 	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/cascade"
 	"github.com/cloudfoundry/bosh-bootloader/certs"
 	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
 	"github.com/cloudfoundry/bosh-bootloader/commands"
@@ -27,6 +29,8 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/keypair"
 	"github.com/cloudfoundry/bosh-bootloader/proxy"
 	"github.com/cloudfoundry/bosh-bootloader/stack"
+	"github.com/cloudfoundry/bosh-bootloader/status"
+	"github.com/cloudfoundry/bosh-bootloader/stemcell"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 	"github.com/cloudfoundry/bosh-bootloader/terraform"
 
@@ -58,6 +62,10 @@ func main() {
 		commands.DirectorUsernameCommand:   nil,
 		commands.DirectorPasswordCommand:   nil,
 		commands.DirectorCACertCommand:     nil,
+		commands.CredHubServerCommand:      nil,
+		commands.CredHubSecretCommand:      nil,
+		commands.CredHubCACertCommand:      nil,
+		commands.UAAAdminSecretCommand:     nil,
 		commands.SSHKeyCommand:             nil,
 		commands.CreateLBsCommand:          nil,
 		commands.UpdateLBsCommand:          nil,
@@ -67,8 +75,18 @@ func main() {
 		commands.LatestErrorCommand:        nil,
 		commands.PrintEnvCommand:           nil,
 		commands.CloudConfigCommand:        nil,
+		commands.RuntimeConfigCommand:      nil,
+		commands.CPIConfigCommand:          nil,
 		commands.BOSHDeploymentVarsCommand: nil,
 		commands.RotateCommand:             nil,
+		commands.RotateCertsCommand:        nil,
+		commands.MoveDirectorCommand:       nil,
+		commands.UploadStemcellCommand:     nil,
+		commands.StatusCommand:             nil,
+		commands.AuditCommand:              nil,
+		commands.ProtectCommand:            nil,
+		commands.UnprotectCommand:          nil,
+		commands.CompletionCommand:         nil,
 	}
 
 	// Utilities
@@ -82,10 +100,28 @@ func main() {
 
 	configuration := getConfiguration(usage.Print, commandSet, envGetter)
 
+	// Tool output
+	terraformStdout, terraformStderr := io.Writer(os.Stdout), io.Writer(os.Stderr)
+	boshStdout, boshStderr := io.Writer(os.Stdout), io.Writer(os.Stderr)
+
+	var stderrEvents *application.EventEncoder
+	if configuration.Global.LogFormat == application.JSONLogFormat {
+		stdoutEvents := application.NewEventEncoder(os.Stdout, configuration.Command, configuration.State.EnvID)
+		stderrEvents = application.NewEventEncoder(os.Stderr, configuration.Command, configuration.State.EnvID)
+
+		logger = application.NewJSONLogger(stdoutEvents)
+		stderrLogger = application.NewJSONLogger(stderrEvents)
+
+		terraformStdout, terraformStderr = application.NewEventWriter(stdoutEvents, "terraform"), application.NewEventWriter(stderrEvents, "terraform")
+		boshStdout, boshStderr = application.NewEventWriter(stdoutEvents, "bosh"), application.NewEventWriter(stderrEvents, "bosh")
+	}
+
 	storage.GetStateLogger = stderrLogger
 
 	stateStore := storage.NewStore(configuration.Global.StateDir)
+	auditLog := storage.NewAuditLog(configuration.Global.StateDir)
 	stateValidator := application.NewStateValidator(configuration.Global.StateDir)
+	configFileLoader := storage.NewConfigFileLoader(configuration.Global.StateDir)
 
 	awsCredentialValidator := awsapplication.NewCredentialValidator(configuration)
 	gcpCredentialValidator := gcpapplication.NewCredentialValidator(configuration)
@@ -96,6 +132,11 @@ func main() {
 		AccessKeyID:     configuration.State.AWS.AccessKeyID,
 		SecretAccessKey: configuration.State.AWS.SecretAccessKey,
 		Region:          configuration.State.AWS.Region,
+		Profile:         configuration.State.AWS.Profile,
+		CredentialChain: configuration.State.AWS.CredentialChain,
+		AssumeRoleARN:   configuration.State.AWS.AssumeRoleARN,
+		ExternalID:      configuration.State.AWS.ExternalID,
+		SessionName:     configuration.State.AWS.SessionName,
 	}
 
 	clientProvider := &clientmanager.ClientProvider{}
@@ -108,6 +149,9 @@ func main() {
 	keyPairSynchronizer := ec2.NewKeyPairSynchronizer(awsKeyPairCreator, keyPairChecker, logger)
 	awsKeyPairManager := awskeypair.NewManager(keyPairSynchronizer, awsKeyPairDeleter, clientProvider)
 	awsAvailabilityZoneRetriever := ec2.NewAvailabilityZoneRetriever(clientProvider)
+	awsNATAMIResolver := ec2.NewNATAMIResolver(clientProvider)
+	awsVolumeMigrator := ec2.NewVolumeMigrator(clientProvider, logger)
+	awsOrphanSweeper := ec2.NewOrphanSweeper(clientProvider, logger)
 	templateBuilder := templates.NewTemplateBuilder(logger)
 	stackManager := cloudformation.NewStackManager(clientProvider, logger)
 	infrastructureManager := cloudformation.NewInfrastructureManager(templateBuilder, stackManager)
@@ -118,12 +162,20 @@ func main() {
 
 	// GCP
 	gcpClientProvider := gcp.NewClientProvider(gcpBasePath)
-	gcpClientProvider.SetConfig(configuration.State.GCP.ServiceAccountKey, configuration.State.GCP.ProjectID, configuration.State.GCP.Region, configuration.State.GCP.Zone)
+	gcpClientProvider.SetConfig(gcp.Config{
+		ServiceAccountKey:             configuration.State.GCP.ServiceAccountKey,
+		ProjectID:                     configuration.State.GCP.ProjectID,
+		Region:                        configuration.State.GCP.Region,
+		Zone:                          configuration.State.GCP.Zone,
+		ApplicationDefaultCredentials: configuration.State.GCP.ApplicationDefaultCredentials,
+		ImpersonateServiceAccount:     configuration.State.GCP.ImpersonateServiceAccount,
+	})
 	gcpKeyPairUpdater := gcp.NewKeyPairUpdater(rand.Reader, rsa.GenerateKey, ssh.NewPublicKey, gcpClientProvider, logger)
 	gcpKeyPairDeleter := gcp.NewKeyPairDeleter(gcpClientProvider, logger)
 	gcpNetworkInstancesChecker := gcp.NewNetworkInstancesChecker(gcpClientProvider)
 	gcpKeyPairManager := gcpkeypair.NewManager(gcpKeyPairUpdater, gcpKeyPairDeleter, gcpClientProvider)
 	gcpAvailabilityZoneRetriever := gcp.NewZones(gcpClientProvider)
+	gcpOrphanSweeper := gcp.NewOrphanSweeper(gcpClientProvider, logger)
 
 	// EnvID
 	envIDManager := helpers.NewEnvIDManager(envIDGenerator, gcpClientProvider, infrastructureManager)
@@ -134,7 +186,7 @@ func main() {
 	// Terraform
 	terraformOutputBuffer := bytes.NewBuffer([]byte{})
 
-	terraformCmd := terraform.NewCmd(os.Stderr, terraformOutputBuffer)
+	terraformCmd := terraform.NewCmd(terraformStdout, terraformStderr, terraformOutputBuffer, clientProvider, gcpClientProvider)
 	terraformExecutor := terraform.NewExecutor(terraformCmd, configuration.Global.Debug)
 	gcpTemplateGenerator := gcpterraform.NewTemplateGenerator()
 	gcpInputGenerator := gcpterraform.NewInputGenerator()
@@ -159,10 +211,10 @@ func main() {
 	// BOSH
 	hostKeyGetter := proxy.NewHostKeyGetter()
 	socks5Proxy := proxy.NewSocks5Proxy(logger, hostKeyGetter, 0)
-	boshCommand := bosh.NewCmd(os.Stderr)
+	boshCommand := bosh.NewCmd(boshStdout, boshStderr)
 	boshExecutor := bosh.NewExecutor(boshCommand, ioutil.TempDir, ioutil.ReadFile, json.Unmarshal,
 		json.Marshal, ioutil.WriteFile)
-	boshManager := bosh.NewManager(boshExecutor, logger, socks5Proxy)
+	boshManager := bosh.NewManager(boshExecutor, logger, socks5Proxy, clientProvider)
 	boshClientProvider := bosh.NewClientProvider()
 
 	// Environment Validators
@@ -171,16 +223,29 @@ func main() {
 
 	// Cloud Config
 	sshKeyGetter := bosh.NewSSHKeyGetter()
+	proxiedBOSHClientProvider := bosh.NewProxiedClientProvider(logger, boshClientProvider, socks5Proxy, terraformManager, sshKeyGetter)
 	awsCloudFormationOpsGenerator := awscloudconfig.NewCloudFormationOpsGenerator(awsAvailabilityZoneRetriever, infrastructureManager)
 	awsTerraformOpsGenerator := awscloudconfig.NewTerraformOpsGenerator(terraformManager)
 	gcpOpsGenerator := gcpcloudconfig.NewOpsGenerator(terraformManager)
 	cloudConfigOpsGenerator := cloudconfig.NewOpsGenerator(awsCloudFormationOpsGenerator, awsTerraformOpsGenerator, gcpOpsGenerator)
-	cloudConfigManager := cloudconfig.NewManager(logger, boshCommand, cloudConfigOpsGenerator, boshClientProvider, socks5Proxy, terraformManager, sshKeyGetter)
+	cloudConfigManager := cloudconfig.NewManager(logger, cloudConfigOpsGenerator, proxiedBOSHClientProvider, terraformManager)
+
+	// Stemcells
+	stemcellUploader := stemcell.NewUploader(logger, proxiedBOSHClientProvider)
+
+	// Status
+	statusChecker := status.NewChecker(proxiedBOSHClientProvider, terraformManager, sshKeyGetter, hostKeyGetter, cloudConfigManager)
+
+	// Cascade
+	cascadeDeleter := cascade.NewDeploymentDeleter(logger, proxiedBOSHClientProvider)
 
 	// Subcommands
+	envIDConfirmer := commands.NewEnvIDConfirmer(logger, os.Stdin)
+
 	awsUp := commands.NewAWSUp(
 		awsCredentialValidator, keyPairManager, boshManager,
-		cloudConfigManager, stateStore, clientProvider, envIDManager, terraformManager, awsBrokenEnvironmentValidator)
+		cloudConfigManager, stateStore, clientProvider, envIDManager, terraformManager, awsBrokenEnvironmentValidator,
+		awsNATAMIResolver, stemcellUploader, logger, envIDConfirmer, Version)
 
 	awsCreateLBs := commands.NewAWSCreateLBs(
 		logger, awsCredentialValidator, cloudConfigManager,
@@ -208,6 +273,9 @@ func main() {
 		EnvIDManager:                 envIDManager,
 		CloudConfigManager:           cloudConfigManager,
 		GCPAvailabilityZoneRetriever: gcpAvailabilityZoneRetriever,
+		StemcellUploader:             stemcellUploader,
+		EnvIDConfirmer:               envIDConfirmer,
+		BBLVersion:                   Version,
 	})
 
 	gcpCreateLBs := commands.NewGCPCreateLBs(terraformManager, cloudConfigManager, stateStore, logger, gcpAvailabilityZoneRetriever)
@@ -216,37 +284,62 @@ func main() {
 
 	gcpUpdateLBs := commands.NewGCPUpdateLBs(gcpCreateLBs)
 
+	createLBs := commands.NewCreateLBs(awsCreateLBs, gcpCreateLBs, stateValidator, certificateValidator, boshManager, configFileLoader)
+
+	updateLBs := commands.NewUpdateLBs(awsUpdateLBs, gcpUpdateLBs, certificateValidator, stateValidator, logger, boshManager)
+
 	// Commands
 	commandSet[commands.HelpCommand] = usage
 	commandSet[commands.VersionCommand] = commands.NewVersion(Version, logger)
-	commandSet[commands.UpCommand] = commands.NewUp(awsUp, gcpUp, envGetter, boshManager)
+	commandSet[commands.UpCommand] = commands.NewUp(awsUp, gcpUp, envGetter, boshManager, configFileLoader, stateStore, createLBs, updateLBs)
 	commandSet[commands.DestroyCommand] = commands.NewDestroy(
 		credentialValidator, logger, os.Stdin, boshManager, vpcStatusChecker, stackManager,
 		infrastructureManager, awsKeyPairDeleter, gcpKeyPairDeleter, certificateDeleter,
-		stateStore, stateValidator, terraformManager, gcpNetworkInstancesChecker,
+		stateStore, stateValidator, terraformManager, gcpNetworkInstancesChecker, cascadeDeleter,
+		awsOrphanSweeper, gcpOrphanSweeper, envIDConfirmer,
 	)
 	commandSet[commands.DownCommand] = commandSet[commands.DestroyCommand]
-	commandSet[commands.CreateLBsCommand] = commands.NewCreateLBs(awsCreateLBs, gcpCreateLBs, stateValidator, certificateValidator, boshManager)
-	commandSet[commands.UpdateLBsCommand] = commands.NewUpdateLBs(awsUpdateLBs, gcpUpdateLBs, certificateValidator, stateValidator, logger, boshManager)
-	commandSet[commands.DeleteLBsCommand] = commands.NewDeleteLBs(gcpDeleteLBs, awsDeleteLBs, logger, stateValidator, boshManager)
+	commandSet[commands.CreateLBsCommand] = createLBs
+	commandSet[commands.UpdateLBsCommand] = updateLBs
+	commandSet[commands.DeleteLBsCommand] = commands.NewDeleteLBs(gcpDeleteLBs, awsDeleteLBs, logger, stateValidator, boshManager, envIDConfirmer)
 	commandSet[commands.LBsCommand] = commands.NewLBs(gcpLBs, awsLBs, stateValidator, logger)
 	commandSet[commands.JumpboxAddressCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.JumpboxAddressPropertyName)
 	commandSet[commands.DirectorAddressCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.DirectorAddressPropertyName)
 	commandSet[commands.DirectorUsernameCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.DirectorUsernamePropertyName)
 	commandSet[commands.DirectorPasswordCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.DirectorPasswordPropertyName)
 	commandSet[commands.DirectorCACertCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.DirectorCACertPropertyName)
+	commandSet[commands.CredHubServerCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.CredHubServerPropertyName)
+	commandSet[commands.CredHubSecretCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.CredHubSecretPropertyName)
+	commandSet[commands.CredHubCACertCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.CredHubCACertPropertyName)
+	commandSet[commands.UAAAdminSecretCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.UAAAdminSecretPropertyName)
 	commandSet[commands.SSHKeyCommand] = commands.NewSSHKey(logger, stateValidator, sshKeyGetter)
 	commandSet[commands.EnvIDCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.EnvIDPropertyName)
 	commandSet[commands.LatestErrorCommand] = commands.NewLatestError(logger, stateValidator)
 	commandSet[commands.PrintEnvCommand] = commands.NewPrintEnv(logger, stateValidator, terraformManager)
 	commandSet[commands.CloudConfigCommand] = commands.NewCloudConfig(logger, stateValidator, cloudConfigManager)
+	commandSet[commands.RuntimeConfigCommand] = commands.NewRuntimeConfig(logger, stateValidator, cloudConfigManager)
+	commandSet[commands.CPIConfigCommand] = commands.NewCPIConfig(logger, stateValidator, cloudConfigManager)
 	commandSet[commands.BOSHDeploymentVarsCommand] = commands.NewBOSHDeploymentVars(logger, boshManager, stateValidator, terraformManager)
 	commandSet[commands.RotateCommand] = commands.NewRotate(stateStore, keyPairManager, terraformManager, boshManager, stateValidator)
+	commandSet[commands.RotateCertsCommand] = commands.NewRotateCerts(logger, stateStore, stateValidator, terraformManager, boshManager)
+	commandSet[commands.UploadStemcellCommand] = commands.NewUploadStemcell(stateValidator, stemcellUploader)
+	commandSet[commands.StatusCommand] = commands.NewStatus(logger, stateValidator, statusChecker)
+	commandSet[commands.AuditCommand] = commands.NewAudit(logger, auditLog)
+	commandSet[commands.ProtectCommand] = commands.NewProtect(logger, stateStore, stateValidator)
+	commandSet[commands.UnprotectCommand] = commands.NewUnprotect(logger, stateStore, stateValidator, envIDConfirmer)
+	commandSet[commands.CompletionCommand] = commands.NewCompletion(logger, application.GlobalFlags(), commandSet)
+	commandSet[commands.MoveDirectorCommand] = commands.NewMoveDirector(logger, stateStore, stateValidator, terraformManager, boshManager, cloudConfigManager, awsVolumeMigrator)
+
+	auditor := application.NewAuditor(commandSet, auditLog, logger, terraformExecutor, boshExecutor, Version)
 
-	app := application.New(commandSet, configuration, stateStore, usage)
+	app := application.New(commandSet, configuration, stateStore, usage, auditor)
 
 	err := app.Run()
 	if err != nil {
+		if stderrEvents != nil {
+			stderrEvents.Encode(application.LogEvent{Level: "error", Message: err.Error()})
+			os.Exit(1)
+		}
 		log.Fatalf("\n\n%s\n", err)
 	}
 }
@@ -0,0 +1,166 @@
+package bosh
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Certificate describes a certificate variable in a vars store.
+type Certificate struct {
+	Name     string
+	IsCA     bool
+	CAName   string
+	NotAfter time.Time
+}
+
+type certificateVariable struct {
+	CA          string `yaml:"ca"`
+	Certificate string `yaml:"certificate"`
+}
+
+// ParseCertificates returns the certificate variables in a vars store, in
+// the order they appear. CAName is set when the signing CA is itself a
+// variable in the same vars store.
+func ParseCertificates(variables string) ([]Certificate, error) {
+	variableCertificates, err := parseVariableCertificates(variables)
+	if err != nil {
+		return nil, err
+	}
+
+	certificates := []Certificate{}
+	signers := map[string]string{}
+	issuers := map[string]string{}
+	for _, cert := range variableCertificates {
+		if cert.parsed.IsCA {
+			signers[cert.certificate] = cert.name
+		}
+		issuers[cert.name] = cert.ca
+
+		certificates = append(certificates, Certificate{
+			Name:     cert.name,
+			IsCA:     cert.parsed.IsCA,
+			NotAfter: cert.parsed.NotAfter,
+		})
+	}
+
+	for i, certificate := range certificates {
+		if signer, ok := signers[issuers[certificate.Name]]; ok && signer != certificate.Name {
+			certificates[i].CAName = signer
+		}
+	}
+
+	return certificates, nil
+}
+
+// certificatesNotForIP returns the certificates in a vars store that name IP
+// addresses, but not ip. They were issued for a director at another address.
+func certificatesNotForIP(variables, ip string) ([]string, error) {
+	variableCertificates, err := parseVariableCertificates(variables)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, cert := range variableCertificates {
+		if len(cert.parsed.IPAddresses) == 0 {
+			continue
+		}
+
+		forIP := false
+		for _, address := range cert.parsed.IPAddresses {
+			if address.String() == ip {
+				forIP = true
+			}
+		}
+
+		if !forIP {
+			names = append(names, cert.name)
+		}
+	}
+
+	return names, nil
+}
+
+type variableCertificate struct {
+	name        string
+	ca          string
+	certificate string
+	parsed      *x509.Certificate
+}
+
+func parseVariableCertificates(variables string) ([]variableCertificate, error) {
+	var vars yaml.MapSlice
+	err := yaml.Unmarshal([]byte(variables), &vars)
+	if err != nil {
+		return nil, err
+	}
+
+	certificates := []variableCertificate{}
+	for _, variable := range vars {
+		name := fmt.Sprintf("%v", variable.Key)
+
+		contents, err := yaml.Marshal(variable.Value)
+		if err != nil {
+			// not tested
+			return nil, err
+		}
+
+		var cert certificateVariable
+		if yaml.Unmarshal(contents, &cert) != nil || cert.Certificate == "" {
+			continue
+		}
+
+		block, _ := pem.Decode([]byte(cert.Certificate))
+		if block == nil {
+			return nil, fmt.Errorf("certificate %s is not PEM encoded", name)
+		}
+
+		parsed, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("certificate %s could not be parsed: %s", name, err)
+		}
+
+		certificates = append(certificates, variableCertificate{
+			name:        name,
+			ca:          cert.CA,
+			certificate: cert.Certificate,
+			parsed:      parsed,
+		})
+	}
+
+	return certificates, nil
+}
+
+// RemoveVariables deletes the named variables from a vars store so that the
+// next interpolation generates them again.
+func RemoveVariables(variables string, names []string) (string, error) {
+	var vars yaml.MapSlice
+	err := yaml.Unmarshal([]byte(variables), &vars)
+	if err != nil {
+		return "", err
+	}
+
+	remove := map[string]bool{}
+	for _, name := range names {
+		remove[name] = true
+	}
+
+	kept := yaml.MapSlice{}
+	for _, variable := range vars {
+		if !remove[fmt.Sprintf("%v", variable.Key)] {
+			kept = append(kept, variable)
+		}
+	}
+
+	contents, err := yaml.Marshal(kept)
+	if err != nil {
+		// not tested
+		return "", err
+	}
+
+	return string(contents), nil
+}
@@ -0,0 +1,83 @@
+package bosh_test
+
+import (
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/testhelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Certificates", func() {
+	var variables string
+
+	BeforeEach(func() {
+		contents, err := yaml.Marshal(yaml.MapSlice{
+			{Key: "admin_password", Value: "some-admin-password"},
+			{Key: "default_ca", Value: map[string]string{
+				"ca":          testhelpers.BBL_CHAIN,
+				"certificate": testhelpers.BBL_CHAIN,
+				"private_key": "some-ca-private-key",
+			}},
+			{Key: "director_ssl", Value: map[string]string{
+				"ca":          testhelpers.BBL_CHAIN,
+				"certificate": testhelpers.BBL_CERT,
+				"private_key": "some-private-key",
+			}},
+			{Key: "consul_ssl", Value: map[string]string{
+				"ca":          testhelpers.OTHER_BBL_CHAIN,
+				"certificate": testhelpers.OTHER_BBL_CERT,
+				"private_key": "some-other-private-key",
+			}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		variables = string(contents)
+	})
+
+	Describe("ParseCertificates", func() {
+		It("returns the certificates in the vars store in order", func() {
+			certificates, err := bosh.ParseCertificates(variables)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(certificates).To(Equal([]bosh.Certificate{
+				{Name: "default_ca", IsCA: true, NotAfter: time.Date(2026, time.May, 4, 23, 26, 5, 0, time.UTC)},
+				{Name: "director_ssl", CAName: "default_ca", NotAfter: time.Date(2018, time.May, 26, 22, 13, 41, 0, time.UTC)},
+				{Name: "consul_ssl", NotAfter: time.Date(2018, time.June, 8, 17, 21, 0, 0, time.UTC)},
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the vars store is not valid yaml", func() {
+				_, err := bosh.ParseCertificates("%%%")
+				Expect(err).To(MatchError(ContainSubstring("yaml")))
+			})
+
+			It("returns an error when a certificate is not PEM encoded", func() {
+				_, err := bosh.ParseCertificates("director_ssl:\n  certificate: some-certificate\n")
+				Expect(err).To(MatchError("certificate director_ssl is not PEM encoded"))
+			})
+		})
+	})
+
+	Describe("RemoveVariables", func() {
+		It("removes the named variables and keeps the rest in order", func() {
+			remaining, err := bosh.RemoveVariables(variables, []string{"director_ssl", "consul_ssl"})
+			Expect(err).NotTo(HaveOccurred())
+
+			var vars yaml.MapSlice
+			Expect(yaml.Unmarshal([]byte(remaining), &vars)).To(Succeed())
+			Expect(vars).To(HaveLen(2))
+			Expect(vars[0].Key).To(Equal("admin_password"))
+			Expect(vars[1].Key).To(Equal("default_ca"))
+		})
+
+		It("returns an error when the vars store is not valid yaml", func() {
+			_, err := bosh.RemoveVariables("%%%", []string{})
+			Expect(err).To(MatchError(ContainSubstring("yaml")))
+		})
+	})
+})
@@ -7,13 +7,23 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"golang.org/x/net/proxy"
 )
 
 type Client interface {
-	UpdateCloudConfig(yaml []byte) error
+	UpdateConfig(configType, name string, content []byte) error
+	LatestConfig(configType, name string) (Config, error)
+	DeleteConfig(configType, name string) error
+	Stemcells() ([]Stemcell, error)
+	UploadStemcell(url, sha1 string) error
+	Deployments() ([]Deployment, error)
+	DeleteDeployment(name string) error
+	OrphanedDisks() ([]OrphanedDisk, error)
+	DeleteOrphanedDisk(diskCID string) error
 	ConfigureHTTPClient(proxy.Dialer)
 	Info() (Info, error)
 }
@@ -22,8 +32,38 @@ type Info struct {
 	Name    string `json:"name"`
 	UUID    string `json:"uuid"`
 	Version string `json:"version"`
+	CPI     string `json:"cpi"`
 }
 
+type Config struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+type Stemcell struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Deployment struct {
+	Name string `json:"name"`
+}
+
+type OrphanedDisk struct {
+	DiskCID        string `json:"disk_cid"`
+	DeploymentName string `json:"deployment_name"`
+}
+
+type task struct {
+	ID     int    `json:"id"`
+	State  string `json:"state"`
+	Result string `json:"result"`
+}
+
+var taskPollInterval = 2 * time.Second
+
 type client struct {
 	directorAddress string
 	username        string
@@ -84,12 +124,21 @@ func (c client) Info() (Info, error) {
 	return info, nil
 }
 
-func (c client) UpdateCloudConfig(yaml []byte) error {
-	request, err := http.NewRequest("POST", fmt.Sprintf("%s/cloud_configs", c.directorAddress), bytes.NewBuffer(yaml))
+func (c client) UpdateConfig(configType, name string, content []byte) error {
+	body, err := json.Marshal(Config{
+		Type:    configType,
+		Name:    name,
+		Content: string(content),
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest("POST", fmt.Sprintf("%s/configs", c.directorAddress), bytes.NewBuffer(body))
 	if err != nil {
 		return err
 	}
-	request.Header.Set("Content-Type", "text/yaml")
+	request.Header.Set("Content-Type", "application/json")
 	request.SetBasicAuth(c.username, c.password)
 
 	response, err := c.httpClient.Do(request)
@@ -97,9 +146,231 @@ func (c client) UpdateCloudConfig(yaml []byte) error {
 		return err
 	}
 
-	if response.StatusCode != http.StatusCreated {
+	if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusOK {
 		return fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
 	}
 
 	return nil
 }
+
+func (c client) LatestConfig(configType, name string) (Config, error) {
+	query := url.Values{}
+	query.Set("type", configType)
+	query.Set("name", name)
+	query.Set("latest", "true")
+
+	request, err := http.NewRequest("GET", fmt.Sprintf("%s/configs?%s", c.directorAddress, query.Encode()), strings.NewReader(""))
+	if err != nil {
+		return Config{}, err
+	}
+	request.SetBasicAuth(c.username, c.password)
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return Config{}, fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	var configs []Config
+	if err := json.NewDecoder(response.Body).Decode(&configs); err != nil {
+		return Config{}, err
+	}
+
+	if len(configs) == 0 {
+		return Config{}, nil
+	}
+
+	return configs[0], nil
+}
+
+func (c client) DeleteConfig(configType, name string) error {
+	query := url.Values{}
+	query.Set("type", configType)
+	query.Set("name", name)
+
+	request, err := http.NewRequest("DELETE", fmt.Sprintf("%s/configs?%s", c.directorAddress, query.Encode()), strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(c.username, c.password)
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	return nil
+}
+
+func (c client) Stemcells() ([]Stemcell, error) {
+	request, err := http.NewRequest("GET", fmt.Sprintf("%s/stemcells", c.directorAddress), strings.NewReader(""))
+	if err != nil {
+		return nil, err
+	}
+	request.SetBasicAuth(c.username, c.password)
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	var stemcells []Stemcell
+	if err := json.NewDecoder(response.Body).Decode(&stemcells); err != nil {
+		return nil, err
+	}
+
+	return stemcells, nil
+}
+
+// UploadStemcell asks the director to fetch the stemcell at url and waits for
+// the resulting task to finish.
+func (c client) UploadStemcell(url, sha1 string) error {
+	body, err := json.Marshal(map[string]string{
+		"location": url,
+		"sha1":     sha1,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest("POST", fmt.Sprintf("%s/stemcells", c.directorAddress), bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.SetBasicAuth(c.username, c.password)
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	var uploadTask task
+	if err := json.NewDecoder(response.Body).Decode(&uploadTask); err != nil {
+		return err
+	}
+
+	return c.waitForTask(uploadTask)
+}
+
+func (c client) Deployments() ([]Deployment, error) {
+	var deployments []Deployment
+	err := c.getJSON("/deployments", &deployments)
+	if err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
+
+// DeleteDeployment force deletes the deployment and waits for the resulting
+// task to finish.
+func (c client) DeleteDeployment(name string) error {
+	return c.deleteWithTask(fmt.Sprintf("/deployments/%s?force=true", url.PathEscape(name)))
+}
+
+func (c client) OrphanedDisks() ([]OrphanedDisk, error) {
+	var disks []OrphanedDisk
+	err := c.getJSON("/disks?orphaned=true", &disks)
+	if err != nil {
+		return nil, err
+	}
+
+	return disks, nil
+}
+
+// DeleteOrphanedDisk deletes the orphaned disk and waits for the resulting
+// task to finish.
+func (c client) DeleteOrphanedDisk(diskCID string) error {
+	return c.deleteWithTask(fmt.Sprintf("/disks/%s", url.PathEscape(diskCID)))
+}
+
+func (c client) getJSON(path string, v interface{}) error {
+	request, err := http.NewRequest("GET", fmt.Sprintf("%s%s", c.directorAddress, path), strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(c.username, c.password)
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	return json.NewDecoder(response.Body).Decode(v)
+}
+
+func (c client) deleteWithTask(path string) error {
+	request, err := http.NewRequest("DELETE", fmt.Sprintf("%s%s", c.directorAddress, path), strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(c.username, c.password)
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	var deleteTask task
+	if err := json.NewDecoder(response.Body).Decode(&deleteTask); err != nil {
+		return err
+	}
+
+	return c.waitForTask(deleteTask)
+}
+
+func (c client) waitForTask(t task) error {
+	for {
+		switch t.State {
+		case "done":
+			return nil
+		case "error", "cancelled", "timeout":
+			return fmt.Errorf("task %d finished with state %s: %s", t.ID, t.State, t.Result)
+		}
+
+		time.Sleep(taskPollInterval)
+
+		request, err := http.NewRequest("GET", fmt.Sprintf("%s/tasks/%d", c.directorAddress, t.ID), strings.NewReader(""))
+		if err != nil {
+			return err
+		}
+		request.SetBasicAuth(c.username, c.password)
+
+		response, err := c.httpClient.Do(request)
+		if err != nil {
+			return err
+		}
+
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+		}
+
+		if err := json.NewDecoder(response.Body).Decode(&t); err != nil {
+			return err
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package bosh_test
 
 import (
+	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -59,7 +60,8 @@ var _ = Describe("Client", func() {
 				responseWriter.Write([]byte(`{
 					"name": "some-bosh-director",
 					"uuid": "some-uuid",
-					"version": "some-version"
+					"version": "some-version",
+					"cpi": "some-cpi"
 				}`))
 			}))
 
@@ -70,6 +72,7 @@ var _ = Describe("Client", func() {
 				Name:    "some-bosh-director",
 				UUID:    "some-uuid",
 				Version: "some-version",
+				CPI:     "some-cpi",
 			}))
 		})
 
@@ -110,10 +113,12 @@ var _ = Describe("Client", func() {
 
 	})
 
-	Describe("UpdateCloudConfig", func() {
-		It("uploads the given cloud config", func() {
+	Describe("UpdateConfig", func() {
+		It("uploads the given config with its type and name", func() {
 			var (
-				cloudConfig []byte
+				body        []byte
+				method      string
+				path        string
 				contentType string
 				username    string
 				password    string
@@ -124,10 +129,12 @@ var _ = Describe("Client", func() {
 					err error
 				)
 
+				method = request.Method
+				path = request.URL.Path
 				username, password, _ = request.BasicAuth()
 				contentType = request.Header.Get("Content-Type")
 
-				cloudConfig, err = ioutil.ReadAll(request.Body)
+				body, err = ioutil.ReadAll(request.Body)
 				Expect(err).NotTo(HaveOccurred())
 
 				responseWriter.WriteHeader(http.StatusCreated)
@@ -135,15 +142,33 @@ var _ = Describe("Client", func() {
 
 			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password")
 
-			err := client.UpdateCloudConfig([]byte("cloud: config"))
+			err := client.UpdateConfig("cloud", "bbl", []byte("cloud: config"))
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(cloudConfig).To(Equal([]byte("cloud: config")))
-			Expect(contentType).To(Equal("text/yaml"))
+			Expect(method).To(Equal("POST"))
+			Expect(path).To(Equal("/configs"))
+			Expect(body).To(MatchJSON(`{
+				"id": "",
+				"type": "cloud",
+				"name": "bbl",
+				"content": "cloud: config"
+			}`))
+			Expect(contentType).To(Equal("application/json"))
 			Expect(username).To(Equal("some-username"))
 			Expect(password).To(Equal("some-password"))
 		})
 
+		It("does not return an error when the config is unchanged", func() {
+			fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				responseWriter.WriteHeader(http.StatusOK)
+			}))
+
+			client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+			err := client.UpdateConfig("cloud", "bbl", []byte("cloud: config"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when the status code is not StatusCreated", func() {
 				fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
@@ -152,18 +177,18 @@ var _ = Describe("Client", func() {
 
 				client := bosh.NewClient(fakeBOSH.URL, "", "")
 
-				err := client.UpdateCloudConfig([]byte("cloud: config"))
+				err := client.UpdateConfig("cloud", "bbl", []byte("cloud: config"))
 				Expect(err).To(MatchError("unexpected http response 500 Internal Server Error"))
 			})
 
 			It("returns an error when the director address is malformed", func() {
 				client := bosh.NewClient("%%%%%%%%%%%%%%%", "", "")
 
-				err := client.UpdateCloudConfig([]byte("cloud: config"))
+				err := client.UpdateConfig("cloud", "bbl", []byte("cloud: config"))
 				Expect(err.(*url.Error).Op).To(Equal("parse"))
 			})
 
-			It("returns an error when the director address is malformed", func() {
+			It("returns an error when the director cannot be reached", func() {
 				fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
 					responseWriter.WriteHeader(http.StatusInternalServerError)
 				}))
@@ -172,9 +197,381 @@ var _ = Describe("Client", func() {
 
 				fakeBOSH.Close()
 
-				err := client.UpdateCloudConfig([]byte("cloud: config"))
+				err := client.UpdateConfig("cloud", "bbl", []byte("cloud: config"))
 				Expect(err).To(MatchError(ContainSubstring("connection refused")))
 			})
 		})
 	})
+
+	Describe("LatestConfig", func() {
+		It("returns the latest config with the given type and name", func() {
+			var (
+				query    url.Values
+				username string
+				password string
+			)
+
+			fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				Expect(request.Method).To(Equal("GET"))
+				Expect(request.URL.Path).To(Equal("/configs"))
+
+				query = request.URL.Query()
+				username, password, _ = request.BasicAuth()
+
+				responseWriter.Write([]byte(`[{
+					"id": "some-id",
+					"type": "cloud",
+					"name": "bbl",
+					"content": "cloud: config"
+				}]`))
+			}))
+
+			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password")
+
+			config, err := client.LatestConfig("cloud", "bbl")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(config).To(Equal(bosh.Config{
+				ID:      "some-id",
+				Type:    "cloud",
+				Name:    "bbl",
+				Content: "cloud: config",
+			}))
+			Expect(query.Get("type")).To(Equal("cloud"))
+			Expect(query.Get("name")).To(Equal("bbl"))
+			Expect(query.Get("latest")).To(Equal("true"))
+			Expect(username).To(Equal("some-username"))
+			Expect(password).To(Equal("some-password"))
+		})
+
+		It("returns an empty config when none exists", func() {
+			fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				responseWriter.Write([]byte(`[]`))
+			}))
+
+			client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+			config, err := client.LatestConfig("cloud", "bbl")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config).To(Equal(bosh.Config{}))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the status code is not StatusOK", func() {
+				fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+					responseWriter.WriteHeader(http.StatusInternalServerError)
+				}))
+
+				client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+				_, err := client.LatestConfig("cloud", "bbl")
+				Expect(err).To(MatchError("unexpected http response 500 Internal Server Error"))
+			})
+
+			It("returns an error when the director address is malformed", func() {
+				client := bosh.NewClient("%%%%%%%%%%%%%%%", "", "")
+
+				_, err := client.LatestConfig("cloud", "bbl")
+				Expect(err.(*url.Error).Op).To(Equal("parse"))
+			})
+
+			It("returns an error when it cannot parse the configs json", func() {
+				fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+					responseWriter.Write([]byte(`%%%`))
+				}))
+
+				client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+				_, err := client.LatestConfig("cloud", "bbl")
+				Expect(err).To(MatchError(ContainSubstring("invalid character")))
+			})
+		})
+	})
+
+	Describe("DeleteConfig", func() {
+		It("deletes the config with the given type and name", func() {
+			var (
+				method string
+				query  url.Values
+			)
+
+			fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				method = request.Method
+				query = request.URL.Query()
+
+				responseWriter.WriteHeader(http.StatusNoContent)
+			}))
+
+			client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+			err := client.DeleteConfig("cloud", "default")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(method).To(Equal("DELETE"))
+			Expect(query.Get("type")).To(Equal("cloud"))
+			Expect(query.Get("name")).To(Equal("default"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the status code is not StatusNoContent", func() {
+				fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+					responseWriter.WriteHeader(http.StatusInternalServerError)
+				}))
+
+				client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+				err := client.DeleteConfig("cloud", "default")
+				Expect(err).To(MatchError("unexpected http response 500 Internal Server Error"))
+			})
+		})
+	})
+
+	Describe("Stemcells", func() {
+		It("returns the stemcells uploaded to the director", func() {
+			var (
+				username string
+				password string
+			)
+
+			fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				Expect(request.Method).To(Equal("GET"))
+				Expect(request.URL.Path).To(Equal("/stemcells"))
+
+				username, password, _ = request.BasicAuth()
+
+				responseWriter.Write([]byte(`[{
+					"name": "some-stemcell",
+					"version": "1.2",
+					"operating_system": "some-os"
+				}]`))
+			}))
+
+			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password")
+
+			stemcells, err := client.Stemcells()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stemcells).To(Equal([]bosh.Stemcell{
+				{Name: "some-stemcell", Version: "1.2"},
+			}))
+			Expect(username).To(Equal("some-username"))
+			Expect(password).To(Equal("some-password"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the status code is not StatusOK", func() {
+				fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+					responseWriter.WriteHeader(http.StatusInternalServerError)
+				}))
+
+				client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+				_, err := client.Stemcells()
+				Expect(err).To(MatchError("unexpected http response 500 Internal Server Error"))
+			})
+
+			It("returns an error when it cannot parse the stemcells json", func() {
+				fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+					responseWriter.Write([]byte(`%%%`))
+				}))
+
+				client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+				_, err := client.Stemcells()
+				Expect(err).To(MatchError(ContainSubstring("invalid character")))
+			})
+		})
+	})
+
+	Describe("UploadStemcell", func() {
+		var (
+			taskStates []string
+			uploadBody []byte
+			taskPolls  int
+			fakeBOSH   *httptest.Server
+		)
+
+		BeforeEach(func() {
+			bosh.SetTaskPollInterval(0)
+
+			taskStates = []string{"processing", "done"}
+			taskPolls = 0
+
+			fakeBOSH = httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				switch {
+				case request.Method == "POST" && request.URL.Path == "/stemcells":
+					var err error
+					uploadBody, err = ioutil.ReadAll(request.Body)
+					Expect(err).NotTo(HaveOccurred())
+
+					http.Redirect(responseWriter, request, "/tasks/7", http.StatusFound)
+				case request.Method == "GET" && request.URL.Path == "/tasks/7":
+					username, password, _ := request.BasicAuth()
+					Expect(username).To(Equal("some-username"))
+					Expect(password).To(Equal("some-password"))
+
+					state := taskStates[taskPolls]
+					taskPolls++
+					responseWriter.Write([]byte(fmt.Sprintf(`{"id": 7, "state": %q, "result": "some-result"}`, state)))
+				default:
+					responseWriter.WriteHeader(http.StatusNotFound)
+				}
+			}))
+		})
+
+		AfterEach(func() {
+			bosh.ResetTaskPollInterval()
+			fakeBOSH.Close()
+		})
+
+		It("uploads the stemcell by url and waits for the task to finish", func() {
+			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password")
+
+			err := client.UploadStemcell("some-url", "some-sha1")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(uploadBody).To(MatchJSON(`{"location": "some-url", "sha1": "some-sha1"}`))
+			Expect(taskPolls).To(Equal(2))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the task fails", func() {
+				taskStates = []string{"processing", "error"}
+
+				client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password")
+
+				err := client.UploadStemcell("some-url", "some-sha1")
+				Expect(err).To(MatchError("task 7 finished with state error: some-result"))
+			})
+
+			It("returns an error when the status code is not StatusOK", func() {
+				failingBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+					responseWriter.WriteHeader(http.StatusInternalServerError)
+				}))
+
+				client := bosh.NewClient(failingBOSH.URL, "", "")
+
+				err := client.UploadStemcell("some-url", "some-sha1")
+				Expect(err).To(MatchError("unexpected http response 500 Internal Server Error"))
+			})
+
+			It("returns an error when the director address is malformed", func() {
+				client := bosh.NewClient("%%%%%%%%%%%%%%%", "", "")
+
+				err := client.UploadStemcell("some-url", "some-sha1")
+				Expect(err.(*url.Error).Op).To(Equal("parse"))
+			})
+		})
+	})
+
+	Describe("Deployments", func() {
+		It("returns the deployments on the director", func() {
+			fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				Expect(request.Method).To(Equal("GET"))
+				Expect(request.URL.Path).To(Equal("/deployments"))
+
+				responseWriter.Write([]byte(`[{"name": "some-deployment"}, {"name": "other-deployment"}]`))
+			}))
+
+			client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+			deployments, err := client.Deployments()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(deployments).To(Equal([]bosh.Deployment{
+				{Name: "some-deployment"},
+				{Name: "other-deployment"},
+			}))
+		})
+
+		It("returns an error when the status code is not StatusOK", func() {
+			fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				responseWriter.WriteHeader(http.StatusInternalServerError)
+			}))
+
+			client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+			_, err := client.Deployments()
+			Expect(err).To(MatchError("unexpected http response 500 Internal Server Error"))
+		})
+	})
+
+	Describe("OrphanedDisks", func() {
+		It("returns the orphaned disks on the director", func() {
+			fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				Expect(request.Method).To(Equal("GET"))
+				Expect(request.URL.Path).To(Equal("/disks"))
+				Expect(request.URL.Query().Get("orphaned")).To(Equal("true"))
+
+				responseWriter.Write([]byte(`[{"disk_cid": "some-disk-cid", "deployment_name": "some-deployment", "size": 1024}]`))
+			}))
+
+			client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+			disks, err := client.OrphanedDisks()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(disks).To(Equal([]bosh.OrphanedDisk{
+				{DiskCID: "some-disk-cid", DeploymentName: "some-deployment"},
+			}))
+		})
+	})
+
+	Describe("DeleteDeployment and DeleteOrphanedDisk", func() {
+		var (
+			deleted   []string
+			taskState string
+			fakeBOSH  *httptest.Server
+		)
+
+		BeforeEach(func() {
+			bosh.SetTaskPollInterval(0)
+
+			deleted = []string{}
+			taskState = "done"
+
+			fakeBOSH = httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				switch {
+				case request.Method == "DELETE":
+					deleted = append(deleted, request.URL.String())
+					http.Redirect(responseWriter, request, "/tasks/7", http.StatusFound)
+				case request.Method == "GET" && request.URL.Path == "/tasks/7":
+					responseWriter.Write([]byte(fmt.Sprintf(`{"id": 7, "state": %q, "result": "some-result"}`, taskState)))
+				default:
+					responseWriter.WriteHeader(http.StatusNotFound)
+				}
+			}))
+		})
+
+		AfterEach(func() {
+			bosh.ResetTaskPollInterval()
+			fakeBOSH.Close()
+		})
+
+		It("force deletes the deployment and waits for the task to finish", func() {
+			client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+			err := client.DeleteDeployment("some-deployment")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(deleted).To(Equal([]string{"/deployments/some-deployment?force=true"}))
+		})
+
+		It("deletes the orphaned disk and waits for the task to finish", func() {
+			client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+			err := client.DeleteOrphanedDisk("some-disk-cid")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(deleted).To(Equal([]string{"/disks/some-disk-cid"}))
+		})
+
+		It("returns an error when the task fails", func() {
+			taskState = "error"
+			client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+			err := client.DeleteDeployment("some-deployment")
+			Expect(err).To(MatchError("task 7 finished with state error: some-result"))
+		})
+	})
 })
@@ -1,26 +1,88 @@
 package bosh
 
 import (
+	"bytes"
 	"io"
+	"os"
 	"os/exec"
+	"sync"
 )
 
 type Cmd struct {
+	stdout io.Writer
 	stderr io.Writer
 }
 
-func NewCmd(stderr io.Writer) Cmd {
+// NewCmd returns a Cmd that writes what bosh prints to the terminal to stdout
+// and stderr.
+func NewCmd(stdout, stderr io.Writer) Cmd {
 	return Cmd{
+		stdout: stdout,
 		stderr: stderr,
 	}
 }
 
+// Run runs bosh with args. When bosh fails, the error is a CmdError that
+// holds what this run printed.
 func (c Cmd) Run(stdout io.Writer, workingDirectory string, args []string) error {
 	command := exec.Command("bosh", args...)
 	command.Dir = workingDirectory
 
-	command.Stdout = stdout
-	command.Stderr = c.stderr
+	// The executors pass os.Stdout for output that is meant for the terminal.
+	if stdout == os.Stdout {
+		stdout = c.stdout
+	}
+
+	output := &lockedWriter{writer: bytes.NewBuffer([]byte{})}
+	command.Stdout = io.MultiWriter(stdout, output)
+	command.Stderr = io.MultiWriter(c.stderr, output)
+
+	err := command.Run()
+	if err != nil {
+		return NewCmdError(err, output.String())
+	}
+
+	return nil
+}
+
+// CmdError is the error of a bosh run that failed, along with what it
+// printed to stdout and stderr.
+type CmdError struct {
+	err    error
+	output string
+}
+
+func NewCmdError(err error, output string) CmdError {
+	return CmdError{
+		err:    err,
+		output: output,
+	}
+}
+
+func (c CmdError) Error() string {
+	return c.err.Error()
+}
+
+func (c CmdError) Output() string {
+	return c.output
+}
+
+// lockedWriter lets bosh write its stdout and stderr to the same buffer.
+type lockedWriter struct {
+	mutex  sync.Mutex
+	writer *bytes.Buffer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.writer.Write(p)
+}
+
+func (w *lockedWriter) String() string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
 
-	return command.Run()
+	return w.writer.String()
 }
@@ -53,7 +53,7 @@ var _ = Describe("Cmd", func() {
 		stdout = bytes.NewBuffer([]byte{})
 		stderr = bytes.NewBuffer([]byte{})
 
-		cmd = bosh.NewCmd(stderr)
+		cmd = bosh.NewCmd(os.Stdout, stderr)
 
 		fakeBOSHBackendServer = httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
 			switch request.URL.Path {
@@ -115,6 +115,10 @@ var _ = Describe("Cmd", func() {
 			err := cmd.Run(stdout, tempDir, []string{"create-env"})
 			Expect(err).To(MatchError("exit status 1"))
 			Expect(stderr.String()).To(ContainSubstring("failed to bosh"))
+
+			cmdErr, ok := err.(bosh.CmdError)
+			Expect(ok).To(BeTrue())
+			Expect(cmdErr.Output()).To(ContainSubstring("failed to bosh"))
 		})
 	})
 })
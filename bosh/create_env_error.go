@@ -19,3 +19,12 @@ func (b CreateEnvError) Error() string {
 func (b CreateEnvError) BOSHState() map[string]interface{} {
 	return b.boshState
 }
+
+// Output is what the failed bosh create-env printed, when it ran.
+func (b CreateEnvError) Output() string {
+	if cmdErr, ok := b.err.(CmdError); ok {
+		return cmdErr.Output()
+	}
+
+	return ""
+}
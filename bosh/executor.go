@@ -10,6 +10,7 @@ import (
 	"regexp"
 
 	"github.com/cloudfoundry/bosh-bootloader/helpers"
+	yaml "gopkg.in/yaml.v2"
 )
 
 const iamProfileOps = `
@@ -29,12 +30,62 @@ const iamProfileOps = `
   value: env_or_profile
   `
 
+const awsSessionTokenOps = `
+- type: replace
+  path: /cloud_provider/properties/aws/session_token?
+  value: ((session_token))
+`
+
+const gcpDirectorServiceAccountOps = `
+- type: remove
+  path: /instance_groups/name=bosh/properties/google/json_key
+- type: replace
+  path: /resource_pools/name=vms/cloud_properties/service_account?
+  value: ((director_service_account))
+- type: replace
+  path: /resource_pools/name=vms/cloud_properties/scopes?
+  value: [https://www.googleapis.com/auth/cloud-platform]
+`
+
+const gcpApplicationDefaultCredentialsOps = `
+- type: remove
+  path: /cloud_provider/properties/google/json_key
+`
+
 const boshDirectorEphemeralIPOps = `
 - type: replace
   path: /networks/name=default/subnets/0/cloud_properties/ephemeral_external_ip?
   value: true
 `
 
+const credhubAdminClientOps = `
+- type: replace
+  path: /instance_groups/name=bosh/jobs/name=uaa/properties/uaa/clients/credhub-admin?
+  value:
+    override: true
+    authorized-grant-types: client_credentials
+    scope: ""
+    authorities: credhub.read,credhub.write
+    access-token-validity: 3600
+    secret: ((credhub_admin_client_secret))
+- type: replace
+  path: /variables/-
+  value:
+    name: credhub_admin_client_secret
+    type: password
+`
+
+const credhubExternalIPOps = `
+- type: replace
+  path: /variables/name=credhub_tls/options/alternative_names/-
+  value: ((external_ip))
+`
+
+type opsFile struct {
+	name     string
+	contents []byte
+}
+
 type Executor struct {
 	command       command
 	tempDir       func(string, string) (string, error)
@@ -51,6 +102,20 @@ type InterpolateInput struct {
 	BOSHState             map[string]interface{}
 	Variables             string
 	OpsFile               string
+	InternalOnly          bool
+	Tags                  map[string]string
+	UAA                   bool
+	CredHub               bool
+	AWSSessionToken       bool
+
+	// GCPApplicationDefaultCredentials is set when there is no service
+	// account key, and bosh create-env runs the cpi with the application
+	// default credentials of the machine bbl runs on.
+	GCPApplicationDefaultCredentials bool
+
+	// GCPDirectorServiceAccount is set when the director authenticates with
+	// the service account attached to its VM.
+	GCPDirectorServiceAccount bool
 }
 
 type InterpolateOutput struct {
@@ -149,6 +214,16 @@ func (e Executor) JumpboxInterpolate(interpolateInput InterpolateInput) (Jumpbox
 		"-o", cpiOpsFilePath,
 	}
 
+	if interpolateInput.GCPApplicationDefaultCredentials {
+		applicationDefaultCredentialsOpsFilePath := filepath.Join(tempDir, "gcp-application-default-credentials.yml")
+		err = e.writeFile(applicationDefaultCredentialsOpsFilePath, []byte(gcpApplicationDefaultCredentialsOps), os.ModePerm)
+		if err != nil {
+			//not tested
+			return JumpboxInterpolateOutput{}, err
+		}
+		args = append(args, "-o", applicationDefaultCredentialsOpsFilePath)
+	}
+
 	buffer := bytes.NewBuffer([]byte{})
 	err = e.command.Run(buffer, tempDir, args)
 	if err != nil {
@@ -254,49 +329,138 @@ func (e Executor) DirectorInterpolate(interpolateInput InterpolateInput) (Interp
 			return InterpolateOutput{}, err
 		}
 
-		externalIPNotRecommendedOpsFilePath := filepath.Join(tempDir, "external-ip-not-recommended.yml")
-		var externalIPNotRecommendedOpsFileContents []byte
-		switch interpolateInput.IAAS {
-		case "gcp":
-			externalIPNotRecommendedOpsFileContents, err = Asset("vendor/github.com/cloudfoundry/bosh-deployment/external-ip-not-recommended.yml")
-			if err != nil {
-				//not tested
-				return InterpolateOutput{}, err
+		if interpolateInput.InternalOnly {
+			args = []string{
+				"interpolate", boshManifestPath,
+				"--var-errs",
+				"--var-errs-unused",
+				"--vars-store", variablesPath,
+				"--vars-file", deploymentVarsPath,
+				"-o", cpiOpsFilePath,
+				"-o", jumpboxUserOpsFilePath,
+			}
+		} else {
+			externalIPNotRecommendedOpsFilePath := filepath.Join(tempDir, "external-ip-not-recommended.yml")
+			var externalIPNotRecommendedOpsFileContents []byte
+			switch interpolateInput.IAAS {
+			case "gcp":
+				externalIPNotRecommendedOpsFileContents, err = Asset("vendor/github.com/cloudfoundry/bosh-deployment/external-ip-not-recommended.yml")
+				if err != nil {
+					//not tested
+					return InterpolateOutput{}, err
+				}
+			case "aws":
+				externalIPNotRecommendedOpsFileContents, err = Asset("vendor/github.com/cloudfoundry/bosh-deployment/external-ip-with-registry-not-recommended.yml")
+				if err != nil {
+					//not tested
+					return InterpolateOutput{}, err
+				}
+
+				err = e.writeFile(iamProfileFilepath, []byte(iamProfileOps), os.ModePerm)
+				if err != nil {
+					//not tested
+					return InterpolateOutput{}, err
+				}
 			}
-		case "aws":
-			externalIPNotRecommendedOpsFileContents, err = Asset("vendor/github.com/cloudfoundry/bosh-deployment/external-ip-with-registry-not-recommended.yml")
+
+			err = e.writeFile(externalIPNotRecommendedOpsFilePath, externalIPNotRecommendedOpsFileContents, os.ModePerm)
 			if err != nil {
 				//not tested
 				return InterpolateOutput{}, err
 			}
 
-			err = e.writeFile(iamProfileFilepath, []byte(iamProfileOps), os.ModePerm)
+			args = []string{
+				"interpolate", boshManifestPath,
+				"--var-errs",
+				"--var-errs-unused",
+				"--vars-store", variablesPath,
+				"--vars-file", deploymentVarsPath,
+				"-o", cpiOpsFilePath,
+				"-o", jumpboxUserOpsFilePath,
+				"-o", externalIPNotRecommendedOpsFilePath,
+			}
+
+			if interpolateInput.IAAS == "aws" {
+				args = append(args, "-o", iamProfileFilepath)
+			}
+		}
+	}
+
+	externalIP := interpolateInput.JumpboxDeploymentVars == "" && !interpolateInput.InternalOnly
+
+	var opsFiles []opsFile
+	if interpolateInput.AWSSessionToken {
+		// bosh create-env deploys the director with short-lived credentials,
+		// while the director itself uses its instance profile.
+		opsFiles = append(opsFiles, opsFile{name: "aws-session-token.yml", contents: []byte(awsSessionTokenOps)})
+	}
+
+	if interpolateInput.GCPDirectorServiceAccount {
+		opsFiles = append(opsFiles, opsFile{name: "gcp-director-service-account.yml", contents: []byte(gcpDirectorServiceAccountOps)})
+	}
+
+	if interpolateInput.GCPApplicationDefaultCredentials {
+		opsFiles = append(opsFiles, opsFile{name: "gcp-application-default-credentials.yml", contents: []byte(gcpApplicationDefaultCredentialsOps)})
+	}
+
+	if interpolateInput.UAA || interpolateInput.CredHub {
+		uaaOpsFiles := []string{"uaa.yml"}
+		if externalIP {
+			uaaOpsFiles = append(uaaOpsFiles, "external-ip-not-recommended-uaa.yml")
+		}
+
+		for _, name := range uaaOpsFiles {
+			contents, err := Asset(fmt.Sprintf("vendor/github.com/cloudfoundry/bosh-deployment/%s", name))
 			if err != nil {
 				//not tested
 				return InterpolateOutput{}, err
 			}
+			opsFiles = append(opsFiles, opsFile{name: name, contents: contents})
 		}
+	}
 
-		err = e.writeFile(externalIPNotRecommendedOpsFilePath, externalIPNotRecommendedOpsFileContents, os.ModePerm)
+	if interpolateInput.CredHub {
+		contents, err := Asset("vendor/github.com/cloudfoundry/bosh-deployment/credhub.yml")
 		if err != nil {
 			//not tested
 			return InterpolateOutput{}, err
 		}
 
-		args = []string{
-			"interpolate", boshManifestPath,
-			"--var-errs",
-			"--var-errs-unused",
-			"--vars-store", variablesPath,
-			"--vars-file", deploymentVarsPath,
-			"-o", cpiOpsFilePath,
-			"-o", jumpboxUserOpsFilePath,
-			"-o", externalIPNotRecommendedOpsFilePath,
+		opsFiles = append(opsFiles,
+			opsFile{name: "credhub.yml", contents: contents},
+			opsFile{name: "credhub-admin-client.yml", contents: []byte(credhubAdminClientOps)},
+		)
+		if externalIP {
+			opsFiles = append(opsFiles, opsFile{name: "credhub-external-ip.yml", contents: []byte(credhubExternalIPOps)})
 		}
+	}
 
-		if interpolateInput.IAAS == "aws" {
-			args = append(args, "-o", iamProfileFilepath)
+	for _, opsFile := range opsFiles {
+		opsFilePath := filepath.Join(tempDir, opsFile.name)
+		err = e.writeFile(opsFilePath, opsFile.contents, os.ModePerm)
+		if err != nil {
+			//not tested
+			return InterpolateOutput{}, err
+		}
+
+		args = append(args, "-o", opsFilePath)
+	}
+
+	if len(interpolateInput.Tags) > 0 {
+		tagsOpsFilePath := filepath.Join(tempDir, "tags.yml")
+		tagsOpsFileContents, err := yaml.Marshal(tagsOps(interpolateInput.Tags))
+		if err != nil {
+			//not tested
+			return InterpolateOutput{}, err
 		}
+
+		err = e.writeFile(tagsOpsFilePath, tagsOpsFileContents, os.ModePerm)
+		if err != nil {
+			//not tested
+			return InterpolateOutput{}, err
+		}
+
+		args = append(args, "-o", tagsOpsFilePath)
 	}
 
 	buffer := bytes.NewBuffer([]byte{})
@@ -338,6 +502,23 @@ func (e Executor) DirectorInterpolate(interpolateInput InterpolateInput) (Interp
 	}, nil
 }
 
+// tagsOps applies the user's tags to the director VM and, through the
+// director's default tags, to every VM and disk the director creates.
+func tagsOps(tags map[string]string) []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"type":  "replace",
+			"path":  "/tags?",
+			"value": tags,
+		},
+		{
+			"type":  "replace",
+			"path":  "/instance_groups/name=bosh/properties/director/tags?",
+			"value": tags,
+		},
+	}
+}
+
 func (e Executor) CreateEnv(createEnvInput CreateEnvInput) (CreateEnvOutput, error) {
 	tempDir, err := e.writePreviousFiles(createEnvInput.State, createEnvInput.Variables, createEnvInput.Manifest)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
@@ -108,6 +109,25 @@ var _ = Describe("Executor", func() {
 				Expect(interpolateOutput.Manifest).To(Equal("some-manifest"))
 				Expect(interpolateOutput.Variables).To(gomegamatchers.MatchYAML(variablesYMLContents))
 			})
+
+			Context("when the credentials have a session token", func() {
+				It("applies the session token to the cpi bosh create-env runs", func() {
+					awsInterpolateInput.AWSSessionToken = true
+
+					_, err := executor.DirectorInterpolate(awsInterpolateInput)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, _, args := cmd.RunArgsForCall(0)
+					Expect(args[len(args)-4:]).To(Equal([]string{
+						"-o", fmt.Sprintf("%s/iam-instance-profile.yml", tempDir),
+						"-o", fmt.Sprintf("%s/aws-session-token.yml", tempDir),
+					}))
+
+					sessionTokenOpsFile, err := ioutil.ReadFile(filepath.Join(tempDir, "aws-session-token.yml"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(sessionTokenOpsFile)).To(ContainSubstring("path: /cloud_provider/properties/aws/session_token?"))
+				})
+			})
 		})
 
 		Context("gcp", func() {
@@ -150,6 +170,159 @@ var _ = Describe("Executor", func() {
 				Expect(interpolateOutput.Variables).To(gomegamatchers.MatchYAML(variablesYMLContents))
 			})
 
+			Context("when the director has a service account", func() {
+				It("attaches the service account to the director instead of the key", func() {
+					gcpInterpolateInput.GCPDirectorServiceAccount = true
+
+					_, err := executor.DirectorInterpolate(gcpInterpolateInput)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, _, args := cmd.RunArgsForCall(0)
+					Expect(args[len(args)-4:]).To(Equal([]string{
+						"-o", fmt.Sprintf("%s/external-ip-not-recommended.yml", tempDir),
+						"-o", fmt.Sprintf("%s/gcp-director-service-account.yml", tempDir),
+					}))
+
+					serviceAccountOpsFile, err := ioutil.ReadFile(filepath.Join(tempDir, "gcp-director-service-account.yml"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(serviceAccountOpsFile)).To(ContainSubstring("path: /instance_groups/name=bosh/properties/google/json_key"))
+					Expect(string(serviceAccountOpsFile)).To(ContainSubstring("value: ((director_service_account))"))
+				})
+			})
+
+			Context("when there is no service account key", func() {
+				It("runs the cpi of bosh create-env with the application default credentials", func() {
+					gcpInterpolateInput.GCPDirectorServiceAccount = true
+					gcpInterpolateInput.GCPApplicationDefaultCredentials = true
+
+					_, err := executor.DirectorInterpolate(gcpInterpolateInput)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, _, args := cmd.RunArgsForCall(0)
+					Expect(args[len(args)-4:]).To(Equal([]string{
+						"-o", fmt.Sprintf("%s/gcp-director-service-account.yml", tempDir),
+						"-o", fmt.Sprintf("%s/gcp-application-default-credentials.yml", tempDir),
+					}))
+
+					applicationDefaultCredentialsOpsFile, err := ioutil.ReadFile(filepath.Join(tempDir, "gcp-application-default-credentials.yml"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(applicationDefaultCredentialsOpsFile)).To(ContainSubstring("path: /cloud_provider/properties/google/json_key"))
+				})
+
+				It("runs the cpi of the jumpbox's bosh create-env with the application default credentials", func() {
+					gcpInterpolateInput.JumpboxDeploymentVars = "internal_cidr: 10.0.0.0/24"
+					gcpInterpolateInput.GCPApplicationDefaultCredentials = true
+
+					_, err := executor.JumpboxInterpolate(gcpInterpolateInput)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, _, args := cmd.RunArgsForCall(0)
+					Expect(args[len(args)-4:]).To(Equal([]string{
+						"-o", fmt.Sprintf("%s/cpi.yml", tempDir),
+						"-o", fmt.Sprintf("%s/gcp-application-default-credentials.yml", tempDir),
+					}))
+				})
+			})
+
+			Context("when the environment is internal only", func() {
+				It("does not apply the external ip ops file", func() {
+					gcpInterpolateInput.InternalOnly = true
+
+					_, err := executor.DirectorInterpolate(gcpInterpolateInput)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, _, args := cmd.RunArgsForCall(0)
+					Expect(args).To(Equal([]string{
+						"interpolate", fmt.Sprintf("%s/bosh.yml", tempDir),
+						"--var-errs",
+						"--var-errs-unused",
+						"--vars-store", fmt.Sprintf("%s/variables.yml", tempDir),
+						"--vars-file", fmt.Sprintf("%s/deployment-vars.yml", tempDir),
+						"-o", fmt.Sprintf("%s/cpi.yml", tempDir),
+						"-o", fmt.Sprintf("%s/jumpbox-user.yml", tempDir),
+					}))
+				})
+			})
+
+			Context("when tags are provided", func() {
+				It("applies the tags to the director and its default vm tags", func() {
+					gcpInterpolateInput.Tags = map[string]string{
+						"owner": "some-owner",
+					}
+
+					_, err := executor.DirectorInterpolate(gcpInterpolateInput)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, _, args := cmd.RunArgsForCall(0)
+					Expect(args[len(args)-2:]).To(Equal([]string{"-o", fmt.Sprintf("%s/tags.yml", tempDir)}))
+
+					tagsOpsFile, err := ioutil.ReadFile(filepath.Join(tempDir, "tags.yml"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(tagsOpsFile).To(gomegamatchers.MatchYAML(`
+- type: replace
+  path: /tags?
+  value:
+    owner: some-owner
+- type: replace
+  path: /instance_groups/name=bosh/properties/director/tags?
+  value:
+    owner: some-owner
+`))
+				})
+			})
+
+			Context("when uaa is enabled", func() {
+				It("applies the uaa ops files", func() {
+					gcpInterpolateInput.UAA = true
+
+					_, err := executor.DirectorInterpolate(gcpInterpolateInput)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, _, args := cmd.RunArgsForCall(0)
+					Expect(args[len(args)-4:]).To(Equal([]string{
+						"-o", fmt.Sprintf("%s/uaa.yml", tempDir),
+						"-o", fmt.Sprintf("%s/external-ip-not-recommended-uaa.yml", tempDir),
+					}))
+				})
+
+				It("does not expose uaa on the external ip when the environment is internal only", func() {
+					gcpInterpolateInput.UAA = true
+					gcpInterpolateInput.InternalOnly = true
+
+					_, err := executor.DirectorInterpolate(gcpInterpolateInput)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, _, args := cmd.RunArgsForCall(0)
+					Expect(args[len(args)-4:]).To(Equal([]string{
+						"-o", fmt.Sprintf("%s/jumpbox-user.yml", tempDir),
+						"-o", fmt.Sprintf("%s/uaa.yml", tempDir),
+					}))
+				})
+			})
+
+			Context("when credhub is enabled", func() {
+				It("applies the uaa and credhub ops files with an admin client", func() {
+					gcpInterpolateInput.UAA = true
+					gcpInterpolateInput.CredHub = true
+
+					_, err := executor.DirectorInterpolate(gcpInterpolateInput)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, _, args := cmd.RunArgsForCall(0)
+					Expect(args[len(args)-10:]).To(Equal([]string{
+						"-o", fmt.Sprintf("%s/uaa.yml", tempDir),
+						"-o", fmt.Sprintf("%s/external-ip-not-recommended-uaa.yml", tempDir),
+						"-o", fmt.Sprintf("%s/credhub.yml", tempDir),
+						"-o", fmt.Sprintf("%s/credhub-admin-client.yml", tempDir),
+						"-o", fmt.Sprintf("%s/credhub-external-ip.yml", tempDir),
+					}))
+
+					credhubAdminClientOpsFile, err := ioutil.ReadFile(filepath.Join(tempDir, "credhub-admin-client.yml"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(credhubAdminClientOpsFile)).To(ContainSubstring("secret: ((credhub_admin_client_secret))"))
+				})
+			})
+
 			Context("when there are jumpbox deployment vars", func() {
 				It("interpolates the jumpbox and bosh manifests", func() {
 					gcpInterpolateInput.JumpboxDeploymentVars = "internal_cidr: 10.0.0.0/24"
@@ -1,6 +1,11 @@
 package bosh
 
-import "os"
+import (
+	"os"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
 
 func SetOSSetenv(f func(string, string) error) {
 	osSetenv = f
@@ -17,3 +22,19 @@ func SetOSUnsetenv(f func(string) error) {
 func ResetOSUnsetenv() {
 	osUnsetenv = os.Unsetenv
 }
+
+func SetTaskPollInterval(interval time.Duration) {
+	taskPollInterval = interval
+}
+
+func ResetTaskPollInterval() {
+	taskPollInterval = 2 * time.Second
+}
+
+func SetProxySOCKS5(f func(string, string, *proxy.Auth, proxy.Dialer) (proxy.Dialer, error)) {
+	proxySOCKS5 = f
+}
+
+func ResetProxySOCKS5() {
+	proxySOCKS5 = proxy.SOCKS5
+}
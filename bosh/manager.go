@@ -4,10 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 
 	yaml "gopkg.in/yaml.v2"
 
+	"github.com/cloudfoundry/bosh-bootloader/aws"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
@@ -19,13 +21,26 @@ var (
 const (
 	DIRECTOR_USERNAME    = "admin"
 	DIRECTOR_INTERNAL_IP = "10.0.0.6"
+
+	insufficientCapacityErrorCode = "InsufficientInstanceCapacity"
 )
 
 type Manager struct {
-	executor    executor
-	logger      logger
-	socks5Proxy socks5Proxy
-	iaasInputs  InterpolateInput
+	executor               executor
+	logger                 logger
+	socks5Proxy            socks5Proxy
+	awsCredentialsProvider awsCredentialsProvider
+
+	// directorInterpolation is the director manifest interpolation
+	// CreateJumpbox starts, so that it runs while the jumpbox is deployed.
+	directorInterpolation *directorInterpolation
+}
+
+type directorInterpolation struct {
+	input  InterpolateInput
+	done   chan struct{}
+	output InterpolateOutput
+	err    error
 }
 
 type directorVars struct {
@@ -72,11 +87,18 @@ type socks5Proxy interface {
 	Addr() string
 }
 
-func NewManager(executor executor, logger logger, socks5Proxy socks5Proxy) *Manager {
+// awsCredentialsProvider resolves the AWS credentials that are not kept in
+// the state, such as those of a profile or an assumed role.
+type awsCredentialsProvider interface {
+	GetCredentials() (aws.Credentials, error)
+}
+
+func NewManager(executor executor, logger logger, socks5Proxy socks5Proxy, awsCredentialsProvider awsCredentialsProvider) *Manager {
 	return &Manager{
-		executor:    executor,
-		logger:      logger,
-		socks5Proxy: socks5Proxy,
+		executor:               executor,
+		logger:                 logger,
+		socks5Proxy:            socks5Proxy,
+		awsCredentialsProvider: awsCredentialsProvider,
 	}
 }
 
@@ -90,19 +112,42 @@ func (m *Manager) Version() (string, error) {
 }
 
 func (m *Manager) CreateJumpbox(state storage.State, terraformOutputs map[string]interface{}) (storage.State, error) {
+	state, err := m.createJumpbox(state, terraformOutputs)
+	if err != nil {
+		m.discardDirectorInterpolate()
+	}
+
+	return state, err
+}
+
+func (m *Manager) createJumpbox(state storage.State, terraformOutputs map[string]interface{}) (storage.State, error) {
 	var err error
 	m.logger.Step("creating jumpbox")
 
-	m.iaasInputs, err = generateIAASInputs(state)
+	jumpboxInputs, err := generateIAASInputs(state)
 	if err != nil {
 		return storage.State{}, err
 	}
 
-	m.iaasInputs.JumpboxDeploymentVars, err = m.GetJumpboxDeploymentVars(state, terraformOutputs)
+	jumpboxInputs.JumpboxDeploymentVars, err = m.GetJumpboxDeploymentVars(state, terraformOutputs)
 	if err != nil {
 		return storage.State{}, err //not tested
 	}
-	interpolateOutputs, err := m.executor.JumpboxInterpolate(m.iaasInputs)
+
+	// The jumpbox keeps its own vars store, separate from the director's.
+	jumpboxInputs.Variables = state.Jumpbox.Variables
+
+	// The director manifest does not depend on the jumpbox deploy, so it is
+	// interpolated in the meantime.
+	if !state.NoDirector {
+		directorInputs, err := m.directorInterpolateInput(state, terraformOutputs)
+		if err != nil {
+			return storage.State{}, err //not tested
+		}
+		m.startDirectorInterpolate(directorInputs)
+	}
+
+	interpolateOutputs, err := m.executor.JumpboxInterpolate(jumpboxInputs)
 	if err != nil {
 		return storage.State{}, err
 	}
@@ -159,29 +204,21 @@ func (m *Manager) CreateJumpbox(state storage.State, terraformOutputs map[string
 }
 
 func (m *Manager) CreateDirector(state storage.State, terraformOutputs map[string]interface{}) (storage.State, error) {
-	var err error
 	var directorAddress string
 
 	directorAddress = terraformOutputs["director_address"].(string)
 
 	if state.Jumpbox.Enabled {
 		directorAddress = fmt.Sprintf("https://%s:25555", DIRECTOR_INTERNAL_IP)
-	} else {
-		m.iaasInputs, err = generateIAASInputs(state)
-		if err != nil {
-			return storage.State{}, err
-		}
 	}
 
-	m.logger.Step("creating bosh director")
-	m.iaasInputs.DeploymentVars, err = m.GetDeploymentVars(state, terraformOutputs)
+	directorInputs, err := m.directorInterpolateInput(state, terraformOutputs)
 	if err != nil {
-		return storage.State{}, err //not tested
+		return storage.State{}, err
 	}
 
-	m.iaasInputs.OpsFile = state.BOSH.UserOpsFile
-
-	interpolateOutputs, err := m.executor.DirectorInterpolate(m.iaasInputs)
+	m.logger.Step("creating bosh director")
+	interpolateOutputs, err := m.directorInterpolate(directorInputs)
 	if err != nil {
 		return storage.State{}, err
 	}
@@ -191,13 +228,22 @@ func (m *Manager) CreateDirector(state storage.State, terraformOutputs map[strin
 		State:     state.BOSH.State,
 		Variables: interpolateOutputs.Variables,
 	})
+
+	manifest, redactErr := m.redactAWSCredentials(state, interpolateOutputs.Manifest)
+	if redactErr != nil {
+		return storage.State{}, redactErr //not tested
+	}
+
 	switch err.(type) {
 	case CreateEnvError:
 		ceErr := err.(CreateEnvError)
 		state.BOSH = storage.BOSH{
 			Variables: interpolateOutputs.Variables,
 			State:     ceErr.BOSHState(),
-			Manifest:  interpolateOutputs.Manifest,
+			Manifest:  manifest,
+		}
+		if strings.Contains(ceErr.Output(), insufficientCapacityErrorCode) {
+			return storage.State{}, NewManagerCreateInsufficientCapacityError(state, err)
 		}
 		return storage.State{}, NewManagerCreateError(state, err)
 	case error:
@@ -219,13 +265,114 @@ func (m *Manager) CreateDirector(state storage.State, terraformOutputs map[strin
 		DirectorSSLPrivateKey:  directorVars.directorSSLPrivateKey,
 		Variables:              interpolateOutputs.Variables,
 		State:                  createEnvOutputs.State,
-		Manifest:               interpolateOutputs.Manifest,
+		Manifest:               manifest,
 	}
 
 	m.logger.Step("created bosh director")
 	return state, nil
 }
 
+// directorInterpolateInput is what the director manifest is interpolated
+// from.
+func (m *Manager) directorInterpolateInput(state storage.State, terraformOutputs map[string]interface{}) (InterpolateInput, error) {
+	input, err := generateIAASInputs(state)
+	if err != nil {
+		return InterpolateInput{}, err
+	}
+
+	if state.Jumpbox.Enabled {
+		input.JumpboxDeploymentVars, err = m.GetJumpboxDeploymentVars(state, terraformOutputs)
+		if err != nil {
+			return InterpolateInput{}, err //not tested
+		}
+	}
+
+	input.DeploymentVars, err = m.GetDeploymentVars(state, terraformOutputs)
+	if err != nil {
+		return InterpolateInput{}, err //not tested
+	}
+
+	if state.IAAS == "aws" {
+		subnet, err := getAWSDirectorSubnet(state, terraformOutputs)
+		if err != nil {
+			return InterpolateInput{}, err //not tested
+		}
+
+		input.Variables, err = m.removeCertificatesForOtherIPs(input.Variables, subnet.directorIP)
+		if err != nil {
+			return InterpolateInput{}, err
+		}
+	}
+
+	input.AWSSessionToken, err = m.awsSessionToken(state)
+	if err != nil {
+		return InterpolateInput{}, err //not tested
+	}
+
+	input.OpsFile = state.BOSH.UserOpsFile
+
+	return input, nil
+}
+
+// removeCertificatesForOtherIPs drops the certificates issued for another
+// director IP from the vars store, so that they are issued again for
+// directorIP. The director IP changes when the director moves to the subnet
+// of another availability zone.
+func (m *Manager) removeCertificatesForOtherIPs(variables, directorIP string) (string, error) {
+	names, err := certificatesNotForIP(variables, directorIP)
+	if err != nil {
+		return "", err
+	}
+
+	if len(names) == 0 {
+		return variables, nil
+	}
+
+	m.logger.Step("regenerating certificates for director IP %s: %s", directorIP, strings.Join(names, ", "))
+	return RemoveVariables(variables, names)
+}
+
+func (m *Manager) startDirectorInterpolate(input InterpolateInput) {
+	interpolation := &directorInterpolation{
+		input: input,
+		done:  make(chan struct{}),
+	}
+	m.directorInterpolation = interpolation
+
+	go func() {
+		interpolation.output, interpolation.err = m.executor.DirectorInterpolate(input)
+		close(interpolation.done)
+	}()
+}
+
+// directorInterpolate waits for the interpolation CreateJumpbox started when
+// it was started from the same input, and interpolates the manifest otherwise.
+func (m *Manager) directorInterpolate(input InterpolateInput) (InterpolateOutput, error) {
+	interpolation := m.directorInterpolation
+	m.directorInterpolation = nil
+
+	if interpolation != nil {
+		<-interpolation.done
+		if reflect.DeepEqual(interpolation.input, input) {
+			return interpolation.output, interpolation.err
+		}
+	}
+
+	return m.executor.DirectorInterpolate(input)
+}
+
+// discardDirectorInterpolate waits for the interpolation CreateJumpbox
+// started, if there is one, and drops its result, so that it does not outlive
+// a jumpbox that failed to deploy.
+func (m *Manager) discardDirectorInterpolate() {
+	interpolation := m.directorInterpolation
+	m.directorInterpolation = nil
+
+	if interpolation != nil {
+		<-interpolation.done
+	}
+}
+
 func (m *Manager) Delete(state storage.State, terraformOutputs map[string]interface{}) error {
 	iaasInputs, err := generateIAASInputs(state)
 	if err != nil {
@@ -256,6 +403,11 @@ func (m *Manager) Delete(state storage.State, terraformOutputs map[string]interf
 		return err //not tested
 	}
 
+	iaasInputs.AWSSessionToken, err = m.awsSessionToken(state)
+	if err != nil {
+		return err //not tested
+	}
+
 	iaasInputs.OpsFile = state.BOSH.UserOpsFile
 
 	interpolateOutputs, err := m.executor.DirectorInterpolate(iaasInputs)
@@ -319,7 +471,7 @@ func (m *Manager) DeleteJumpbox(state storage.State, terraformOutputs map[string
 }
 
 func (m *Manager) GetJumpboxDeploymentVars(state storage.State, terraformOutputs map[string]interface{}) (string, error) {
-	vars := strings.Join([]string{
+	lines := []string{
 		"internal_cidr: 10.0.0.0/24",
 		"internal_gw: 10.0.0.1",
 		"internal_ip: 10.0.0.5",
@@ -330,8 +482,12 @@ func (m *Manager) GetJumpboxDeploymentVars(state storage.State, terraformOutputs
 		fmt.Sprintf("subnetwork: %s", terraformOutputs["subnetwork_name"]),
 		fmt.Sprintf("tags: [%s]", terraformOutputs["bosh_open_tag_name"]),
 		fmt.Sprintf("project_id: %s", state.GCP.ProjectID),
-		fmt.Sprintf("gcp_credentials_json: '%s'", state.GCP.ServiceAccountKey),
-	}, "\n")
+	}
+	if state.GCP.UsesServiceAccountKey() {
+		lines = append(lines, fmt.Sprintf("gcp_credentials_json: '%s'", state.GCP.ServiceAccountKey))
+	}
+
+	vars := strings.Join(lines, "\n")
 
 	return strings.TrimSuffix(vars, "\n"), nil
 }
@@ -342,7 +498,7 @@ func (m *Manager) GetDeploymentVars(state storage.State, terraformOutputs map[st
 	switch state.IAAS {
 	case "gcp":
 		if state.Jumpbox.Enabled {
-			vars = strings.Join([]string{
+			vars = strings.Join(append([]string{
 				"internal_cidr: 10.0.0.0/24",
 				"internal_gw: 10.0.0.1",
 				fmt.Sprintf("internal_ip: %s", DIRECTOR_INTERNAL_IP),
@@ -352,10 +508,21 @@ func (m *Manager) GetDeploymentVars(state storage.State, terraformOutputs map[st
 				fmt.Sprintf("subnetwork: %s", terraformOutputs["subnetwork_name"]),
 				fmt.Sprintf("tags: [%s]", terraformOutputs["bosh_director_tag_name"]),
 				fmt.Sprintf("project_id: %s", state.GCP.ProjectID),
-				fmt.Sprintf("gcp_credentials_json: '%s'", state.GCP.ServiceAccountKey),
-			}, "\n")
+			}, gcpCredentialVars(state, terraformOutputs)...), "\n")
+		} else if state.GCP.InternalOnly {
+			vars = strings.Join(append([]string{
+				"internal_cidr: 10.0.0.0/24",
+				"internal_gw: 10.0.0.1",
+				fmt.Sprintf("internal_ip: %s", DIRECTOR_INTERNAL_IP),
+				fmt.Sprintf("director_name: %s", fmt.Sprintf("bosh-%s", state.EnvID)),
+				fmt.Sprintf("zone: %s", state.GCP.Zone),
+				fmt.Sprintf("network: %s", terraformOutputs["network_name"]),
+				fmt.Sprintf("subnetwork: %s", terraformOutputs["subnetwork_name"]),
+				fmt.Sprintf("tags: [%s, %s]", terraformOutputs["bosh_open_tag_name"], terraformOutputs["bosh_director_tag_name"]),
+				fmt.Sprintf("project_id: %s", state.GCP.ProjectID),
+			}, gcpCredentialVars(state, terraformOutputs)...), "\n")
 		} else {
-			vars = strings.Join([]string{
+			vars = strings.Join(append([]string{
 				"internal_cidr: 10.0.0.0/24",
 				"internal_gw: 10.0.0.1",
 				fmt.Sprintf("internal_ip: %s", DIRECTOR_INTERNAL_IP),
@@ -366,38 +533,174 @@ func (m *Manager) GetDeploymentVars(state storage.State, terraformOutputs map[st
 				fmt.Sprintf("subnetwork: %s", terraformOutputs["subnetwork_name"]),
 				fmt.Sprintf("tags: [%s, %s]", terraformOutputs["bosh_open_tag_name"], terraformOutputs["bosh_director_tag_name"]),
 				fmt.Sprintf("project_id: %s", state.GCP.ProjectID),
-				fmt.Sprintf("gcp_credentials_json: '%s'", state.GCP.ServiceAccountKey),
-			}, "\n")
+			}, gcpCredentialVars(state, terraformOutputs)...), "\n")
 		}
 	case "aws":
-		vars = strings.Join([]string{
-			"internal_cidr: 10.0.0.0/24",
-			"internal_gw: 10.0.0.1",
-			fmt.Sprintf("internal_ip: %s", DIRECTOR_INTERNAL_IP),
+		subnet, err := getAWSDirectorSubnet(state, terraformOutputs)
+		if err != nil {
+			return "", err
+		}
+
+		credentials, err := m.awsCredentials(state)
+		if err != nil {
+			return "", err
+		}
+
+		lines := []string{
+			fmt.Sprintf("internal_cidr: %s", subnet.cidr),
+			fmt.Sprintf("internal_gw: %s", subnet.gateway),
+			fmt.Sprintf("internal_ip: %s", subnet.directorIP),
 			fmt.Sprintf("director_name: %s", fmt.Sprintf("bosh-%s", state.EnvID)),
 			fmt.Sprintf("external_ip: %s", terraformOutputs["external_ip"]),
-			fmt.Sprintf("az: %s", terraformOutputs["bosh_subnet_availability_zone"]),
-			fmt.Sprintf("subnet_id: %s", terraformOutputs["bosh_subnet_id"]),
-			fmt.Sprintf("access_key_id: %s", state.AWS.AccessKeyID),
-			fmt.Sprintf("secret_access_key: %s", state.AWS.SecretAccessKey),
+			fmt.Sprintf("az: %s", subnet.az),
+			fmt.Sprintf("subnet_id: %s", subnet.id),
+			fmt.Sprintf("access_key_id: %s", credentials.AccessKeyID),
+			fmt.Sprintf("secret_access_key: %s", credentials.SecretAccessKey),
+		}
+		if credentials.SessionToken != "" {
+			lines = append(lines, fmt.Sprintf("session_token: %s", credentials.SessionToken))
+		}
+		lines = append(lines,
 			fmt.Sprintf("iam_instance_profile: %s", terraformOutputs["bosh_iam_instance_profile"]),
 			fmt.Sprintf("default_key_name: %s", state.KeyPair.Name),
 			fmt.Sprintf("default_security_groups: [%s]", terraformOutputs["bosh_security_group"]),
 			fmt.Sprintf("region: %s", state.AWS.Region),
 			fmt.Sprintf("private_key: |-\n  %s", strings.Replace(state.KeyPair.PrivateKey, "\n", "\n  ", -1)),
-		}, "\n")
+		)
+
+		vars = strings.Join(lines, "\n")
 	}
 
 	return strings.TrimSuffix(vars, "\n"), nil
 }
 
+// gcpCredentialVars are the credentials of the director and of the cpi bosh
+// create-env runs. Without a service account key the cpi uses the application
+// default credentials of the machine bbl runs on, and a director with a
+// service account uses the one attached to its VM.
+func gcpCredentialVars(state storage.State, terraformOutputs map[string]interface{}) []string {
+	var vars []string
+	if state.GCP.UsesServiceAccountKey() {
+		vars = append(vars, fmt.Sprintf("gcp_credentials_json: '%s'", state.GCP.ServiceAccountKey))
+	}
+
+	if state.GCP.DirectorServiceAccount {
+		vars = append(vars, fmt.Sprintf("director_service_account: %s", terraformOutputs["director_service_account_email"]))
+	}
+
+	return vars
+}
+
+// awsCredentials are the credentials the director is created with. They are
+// the access keys in the state, unless the state refers to credentials that
+// are resolved when bbl runs.
+func (m *Manager) awsCredentials(state storage.State) (aws.Credentials, error) {
+	if state.AWS.UsesStaticKeys() {
+		return aws.Credentials{
+			AccessKeyID:     state.AWS.AccessKeyID,
+			SecretAccessKey: state.AWS.SecretAccessKey,
+		}, nil
+	}
+
+	return m.awsCredentialsProvider.GetCredentials()
+}
+
+func (m *Manager) awsSessionToken(state storage.State) (bool, error) {
+	if state.IAAS != "aws" {
+		return false, nil
+	}
+
+	credentials, err := m.awsCredentials(state)
+	if err != nil {
+		return false, err
+	}
+
+	return credentials.SessionToken != "", nil
+}
+
+// redactAWSCredentials replaces the credentials that are resolved when bbl
+// runs with their variables in the manifest that is kept in the state.
+func (m *Manager) redactAWSCredentials(state storage.State, manifest string) (string, error) {
+	if state.IAAS != "aws" || state.AWS.UsesStaticKeys() {
+		return manifest, nil
+	}
+
+	credentials, err := m.awsCredentials(state)
+	if err != nil {
+		return "", err
+	}
+
+	for name, value := range map[string]string{
+		"access_key_id":     credentials.AccessKeyID,
+		"secret_access_key": credentials.SecretAccessKey,
+		"session_token":     credentials.SessionToken,
+	} {
+		if value != "" {
+			manifest = strings.Replace(manifest, value, fmt.Sprintf("((%s))", name), -1)
+		}
+	}
+
+	return manifest, nil
+}
+
+type awsDirectorSubnet struct {
+	az         string
+	id         string
+	cidr       string
+	gateway    string
+	directorIP string
+}
+
+func getAWSDirectorSubnet(state storage.State, terraformOutputs map[string]interface{}) (awsDirectorSubnet, error) {
+	primaryAZ := fmt.Sprintf("%s", terraformOutputs["bosh_subnet_availability_zone"])
+	if state.AWS.DirectorAZ == "" || state.AWS.DirectorAZ == primaryAZ {
+		return awsDirectorSubnet{
+			az:         primaryAZ,
+			id:         fmt.Sprintf("%s", terraformOutputs["bosh_subnet_id"]),
+			cidr:       "10.0.0.0/24",
+			gateway:    "10.0.0.1",
+			directorIP: DIRECTOR_INTERNAL_IP,
+		}, nil
+	}
+
+	subnetIDs, _ := terraformOutputs["bosh_az_subnet_id_mapping"].(map[string]interface{})
+	subnetCIDRs, _ := terraformOutputs["bosh_az_subnet_cidr_mapping"].(map[string]interface{})
+
+	subnetID, idOK := subnetIDs[state.AWS.DirectorAZ].(string)
+	subnetCIDR, cidrOK := subnetCIDRs[state.AWS.DirectorAZ].(string)
+	if !idOK || !cidrOK {
+		return awsDirectorSubnet{}, fmt.Errorf("no bosh subnet exists in availability zone %q", state.AWS.DirectorAZ)
+	}
+
+	cidrBlock, err := ParseCIDRBlock(subnetCIDR)
+	if err != nil {
+		return awsDirectorSubnet{}, err
+	}
+
+	firstIP := cidrBlock.GetFirstIP()
+	return awsDirectorSubnet{
+		az:         state.AWS.DirectorAZ,
+		id:         subnetID,
+		cidr:       subnetCIDR,
+		gateway:    firstIP.Add(1).String(),
+		directorIP: firstIP.Add(6).String(),
+	}, nil
+}
+
 func generateIAASInputs(state storage.State) (InterpolateInput, error) {
 	switch state.IAAS {
 	case "gcp", "aws":
 		return InterpolateInput{
-			IAAS:      state.IAAS,
-			BOSHState: state.BOSH.State,
-			Variables: state.BOSH.Variables,
+			IAAS:         state.IAAS,
+			BOSHState:    state.BOSH.State,
+			Variables:    state.BOSH.Variables,
+			InternalOnly: state.IAAS == "gcp" && state.GCP.InternalOnly,
+			Tags:         state.Tags,
+			UAA:          state.UAA || state.CredHub,
+			CredHub:      state.CredHub,
+
+			GCPApplicationDefaultCredentials: state.IAAS == "gcp" && !state.GCP.UsesServiceAccountKey(),
+			GCPDirectorServiceAccount:        state.IAAS == "gcp" && state.GCP.DirectorServiceAccount,
 		}, nil
 	default:
 		return InterpolateInput{}, errors.New("A valid IAAS was not provided")
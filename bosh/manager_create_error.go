@@ -3,8 +3,9 @@ package bosh
 import "github.com/cloudfoundry/bosh-bootloader/storage"
 
 type ManagerCreateError struct {
-	state storage.State
-	err   error
+	state                storage.State
+	err                  error
+	insufficientCapacity bool
 }
 
 func NewManagerCreateError(state storage.State, err error) ManagerCreateError {
@@ -14,6 +15,14 @@ func NewManagerCreateError(state storage.State, err error) ManagerCreateError {
 	}
 }
 
+func NewManagerCreateInsufficientCapacityError(state storage.State, err error) ManagerCreateError {
+	return ManagerCreateError{
+		state:                state,
+		err:                  err,
+		insufficientCapacity: true,
+	}
+}
+
 func (b ManagerCreateError) Error() string {
 	return b.err.Error()
 }
@@ -21,3 +30,7 @@ func (b ManagerCreateError) Error() string {
 func (b ManagerCreateError) State() storage.State {
 	return b.state
 }
+
+func (b ManagerCreateError) InsufficientCapacity() bool {
+	return b.insufficientCapacity
+}
@@ -1,14 +1,25 @@
 package bosh_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"math/big"
+	"net"
+	"time"
 
+	"github.com/cloudfoundry/bosh-bootloader/aws"
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 
 	"github.com/pivotal-cf-experimental/gomegamatchers"
+	yaml "gopkg.in/yaml.v2"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -30,6 +41,7 @@ var _ = Describe("Manager", func() {
 			logger           *fakes.Logger
 			socks5Proxy      *fakes.Socks5Proxy
 			boshManager      *bosh.Manager
+			awsCredentials   *fakes.AWSClientProvider
 			incomingGCPState storage.State
 			terraformOutputs map[string]interface{}
 
@@ -42,7 +54,8 @@ var _ = Describe("Manager", func() {
 			boshExecutor = &fakes.BOSHExecutor{}
 			logger = &fakes.Logger{}
 			socks5Proxy = &fakes.Socks5Proxy{}
-			boshManager = bosh.NewManager(boshExecutor, logger, socks5Proxy)
+			awsCredentials = &fakes.AWSClientProvider{}
+			boshManager = bosh.NewManager(boshExecutor, logger, socks5Proxy, awsCredentials)
 
 			bosh.SetOSSetenv(func(key, value string) error {
 				osSetenvKey = key
@@ -148,6 +161,36 @@ gcp_credentials_json: 'some-credential-json'`,
 				Expect(boshExecutor.JumpboxInterpolateCall.CallCount).To(Equal(0))
 			})
 
+			It("enables uaa and credhub when credhub was requested", func() {
+				boshExecutor.DirectorInterpolateCall.Returns.Output = bosh.InterpolateOutput{
+					Manifest:  "some-manifest",
+					Variables: variablesYAML,
+				}
+
+				incomingGCPState.CredHub = true
+				_, err := boshManager.CreateDirector(incomingGCPState, terraformOutputs)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshExecutor.DirectorInterpolateCall.Receives.InterpolateInput.UAA).To(BeTrue())
+				Expect(boshExecutor.DirectorInterpolateCall.Receives.InterpolateInput.CredHub).To(BeTrue())
+			})
+
+			It("gives the director its service account when there is no service account key", func() {
+				boshExecutor.DirectorInterpolateCall.Returns.Output = bosh.InterpolateOutput{
+					Manifest:  "some-manifest",
+					Variables: variablesYAML,
+				}
+
+				incomingGCPState.GCP.ServiceAccountKey = ""
+				incomingGCPState.GCP.ImpersonateServiceAccount = "some-account@some-project-id.iam.gserviceaccount.com"
+				incomingGCPState.GCP.DirectorServiceAccount = true
+				_, err := boshManager.CreateDirector(incomingGCPState, terraformOutputs)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshExecutor.DirectorInterpolateCall.Receives.InterpolateInput.GCPApplicationDefaultCredentials).To(BeTrue())
+				Expect(boshExecutor.DirectorInterpolateCall.Receives.InterpolateInput.GCPDirectorServiceAccount).To(BeTrue())
+			})
+
 			It("returns a state with a proper bosh state", func() {
 				boshExecutor.DirectorInterpolateCall.Returns.Output = bosh.InterpolateOutput{
 					Manifest:  "some-manifest",
@@ -272,6 +315,110 @@ private_key: |-
 					}))
 				})
 
+				Context("when the vars store has certificates issued for the director IP", func() {
+					BeforeEach(func() {
+						defaultCA := certificateForIPs()
+
+						contents, err := yaml.Marshal(yaml.MapSlice{
+							{Key: "admin_password", Value: "some-admin-password"},
+							{Key: "default_ca", Value: map[string]string{"certificate": defaultCA}},
+							{Key: "director_ssl", Value: map[string]string{"ca": defaultCA, "certificate": certificateForIPs("10.0.0.6", "203.0.113.10")}},
+							{Key: "nats_server_tls", Value: map[string]string{"ca": defaultCA, "certificate": certificateForIPs()}},
+							{Key: "uaa_ssl", Value: map[string]string{"ca": defaultCA, "certificate": certificateForIPs("10.0.0.6")}},
+						})
+						Expect(err).NotTo(HaveOccurred())
+						incomingAWSState.BOSH.Variables = string(contents)
+					})
+
+					AfterEach(func() {
+						incomingAWSState.BOSH.Variables = ""
+						incomingAWSState.AWS.DirectorAZ = ""
+					})
+
+					It("keeps them while the director stays in its subnet", func() {
+						_, err := boshManager.CreateDirector(incomingAWSState, terraformOutputs)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(boshExecutor.DirectorInterpolateCall.Receives.InterpolateInput.Variables).To(Equal(incomingAWSState.BOSH.Variables))
+					})
+
+					It("removes those for another IP when the director moves to a failover availability zone", func() {
+						incomingAWSState.AWS.DirectorAZ = "some-failover-az"
+						terraformOutputs["bosh_az_subnet_id_mapping"] = map[string]interface{}{"some-failover-az": "some-failover-subnet"}
+						terraformOutputs["bosh_az_subnet_cidr_mapping"] = map[string]interface{}{"some-failover-az": "10.0.240.0/24"}
+
+						_, err := boshManager.CreateDirector(incomingAWSState, terraformOutputs)
+						Expect(err).NotTo(HaveOccurred())
+
+						var variables map[string]interface{}
+						err = yaml.Unmarshal([]byte(boshExecutor.DirectorInterpolateCall.Receives.InterpolateInput.Variables), &variables)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(variables).To(HaveKey("admin_password"))
+						Expect(variables).To(HaveKey("default_ca"))
+						Expect(variables).To(HaveKey("nats_server_tls"))
+						Expect(variables).NotTo(HaveKey("director_ssl"))
+						Expect(variables).NotTo(HaveKey("uaa_ssl"))
+
+						Expect(logger.StepCall.Messages).To(ContainElement("regenerating certificates for director IP 10.0.240.6: director_ssl, uaa_ssl"))
+					})
+				})
+
+				Context("when the credentials are resolved from a profile", func() {
+					var profileState storage.State
+
+					BeforeEach(func() {
+						profileState = incomingAWSState
+						profileState.AWS = storage.AWS{
+							Region:  "some-region",
+							Profile: "some-profile",
+						}
+
+						awsCredentials.GetCredentialsCall.Returns.Credentials = aws.Credentials{
+							AccessKeyID:     "some-session-access-key-id",
+							SecretAccessKey: "some-session-secret-access-key",
+							SessionToken:    "some-session-token",
+						}
+
+						boshExecutor.DirectorInterpolateCall.Returns.Output.Manifest = `cloud_provider:
+  properties:
+    aws:
+      access_key_id: some-session-access-key-id
+      secret_access_key: some-session-secret-access-key
+      session_token: some-session-token`
+					})
+
+					It("creates the director with the resolved credentials and session token", func() {
+						_, err := boshManager.CreateDirector(profileState, terraformOutputs)
+						Expect(err).NotTo(HaveOccurred())
+
+						input := boshExecutor.DirectorInterpolateCall.Receives.InterpolateInput
+						Expect(input.AWSSessionToken).To(BeTrue())
+						Expect(input.DeploymentVars).To(ContainSubstring(`access_key_id: some-session-access-key-id
+secret_access_key: some-session-secret-access-key
+session_token: some-session-token
+`))
+					})
+
+					It("does not keep the resolved credentials in the manifest in the state", func() {
+						state, err := boshManager.CreateDirector(profileState, terraformOutputs)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(state.BOSH.Manifest).To(Equal(`cloud_provider:
+  properties:
+    aws:
+      access_key_id: ((access_key_id))
+      secret_access_key: ((secret_access_key))
+      session_token: ((session_token))`))
+					})
+
+					It("returns an error when the credentials cannot be resolved", func() {
+						awsCredentials.GetCredentialsCall.Returns.Error = errors.New("failed to assume role")
+
+						_, err := boshManager.CreateDirector(profileState, terraformOutputs)
+						Expect(err).To(MatchError("failed to assume role"))
+					})
+				})
+
 				It("returns a state with a proper bosh state", func() {
 					state, err := boshManager.CreateDirector(incomingAWSState, terraformOutputs)
 					Expect(err).NotTo(HaveOccurred())
@@ -342,6 +489,32 @@ private_key: |-
 					_, err := boshManager.CreateDirector(incomingAWSState, terraformOutputs)
 					Expect(err).To(MatchError(expectedError))
 				})
+
+				Context("when the create env output reports insufficient capacity", func() {
+					BeforeEach(func() {
+						cmdErr := bosh.NewCmdError(errors.New("failed to create env"), "CPI 'create_vm' method responded with error: InsufficientInstanceCapacity")
+						createEnvError := bosh.NewCreateEnvError(expectedState.BOSH.State, cmdErr)
+						boshExecutor.CreateEnvCall.Returns.Error = createEnvError
+					})
+
+					It("returns a bosh manager create error flagged as a capacity failure", func() {
+						_, err := boshManager.CreateDirector(incomingAWSState, terraformOutputs)
+						Expect(err).To(MatchError("failed to create env"))
+
+						managerCreateError, ok := err.(bosh.ManagerCreateError)
+						Expect(ok).To(BeTrue())
+						Expect(managerCreateError.InsufficientCapacity()).To(BeTrue())
+						Expect(managerCreateError.State()).To(Equal(expectedState))
+					})
+				})
+
+				It("does not flag the error as a capacity failure", func() {
+					_, err := boshManager.CreateDirector(incomingAWSState, terraformOutputs)
+
+					managerCreateError, ok := err.(bosh.ManagerCreateError)
+					Expect(ok).To(BeTrue())
+					Expect(managerCreateError.InsufficientCapacity()).To(BeFalse())
+				})
 			})
 		})
 
@@ -418,7 +591,7 @@ private_key: |-
 			boshExecutor = &fakes.BOSHExecutor{}
 			logger = &fakes.Logger{}
 			socks5Proxy = &fakes.Socks5Proxy{}
-			boshManager = bosh.NewManager(boshExecutor, logger, socks5Proxy)
+			boshManager = bosh.NewManager(boshExecutor, logger, socks5Proxy, &fakes.AWSClientProvider{})
 
 			bosh.SetOSSetenv(func(key, value string) error {
 				osSetenvKey = key
@@ -538,6 +711,15 @@ gcp_credentials_json: 'some-credential-json'`
 			}))
 		})
 
+		It("interpolates the jumpbox with its own vars store", func() {
+			incomingGCPState.BOSH.Variables = "some-director-variables"
+
+			_, err := boshManager.CreateJumpbox(incomingGCPState, terraformOutputs)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(boshExecutor.JumpboxInterpolateCall.Receives.InterpolateInput.Variables).To(Equal("jumpbox_ssh:\n  private_key: some-jumpbox-private-key"))
+		})
+
 		Context("when bosh director is created after jumpbox", func() {
 			It("generates a jumpbox and bosh manifest", func() {
 				afterJumpboxState, err := boshManager.CreateJumpbox(incomingGCPState, terraformOutputs)
@@ -556,6 +738,46 @@ gcp_credentials_json: 'some-credential-json'`
 				}))
 			})
 
+			It("interpolates the director manifest once, while the jumpbox is deployed", func() {
+				afterJumpboxState, err := boshManager.CreateJumpbox(incomingGCPState, terraformOutputs)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = boshManager.CreateDirector(afterJumpboxState, terraformOutputs)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshExecutor.DirectorInterpolateCall.CallCount).To(Equal(1))
+				Expect(boshExecutor.CreateEnvCall.Receives.Input.Manifest).To(Equal("some-manifest"))
+			})
+
+			It("interpolates the director manifest again when its inputs changed after the jumpbox was deployed", func() {
+				afterJumpboxState, err := boshManager.CreateJumpbox(incomingGCPState, terraformOutputs)
+				Expect(err).NotTo(HaveOccurred())
+
+				afterJumpboxState.BOSH.UserOpsFile = "some-ops-file"
+				_, err = boshManager.CreateDirector(afterJumpboxState, terraformOutputs)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshExecutor.DirectorInterpolateCall.CallCount).To(Equal(2))
+				Expect(boshExecutor.DirectorInterpolateCall.Receives.InterpolateInput.OpsFile).To(Equal("some-ops-file"))
+			})
+
+			It("interpolates the director manifest when the jumpbox was deployed by an earlier run", func() {
+				incomingGCPState.Jumpbox.Enabled = true
+
+				_, err := boshManager.CreateDirector(incomingGCPState, terraformOutputs)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshExecutor.DirectorInterpolateCall.Receives.InterpolateInput).To(Equal(bosh.InterpolateInput{
+					IAAS: "gcp",
+					JumpboxDeploymentVars: jumpboxDeploymentVars,
+					DeploymentVars:        deploymentVars,
+					BOSHState: map[string]interface{}{
+						"some-key": "some-value",
+					},
+					Variables: "",
+				}))
+			})
+
 			It("returns a bbl state with a proper jumpbox state", func() {
 				boshExecutor.CreateEnvCall.Returns.Output = bosh.CreateEnvOutput{
 					State: map[string]interface{}{
@@ -612,6 +834,19 @@ gcp_credentials_json: 'some-credential-json'`
 		})
 
 		Context("when an error occurs", func() {
+			It("waits for the director interpolation and discards it", func() {
+				boshExecutor.JumpboxInterpolateCall.Returns.Error = errors.New("failed to interpolate jumpbox")
+
+				_, err := boshManager.CreateJumpbox(incomingGCPState, terraformOutputs)
+				Expect(err).To(MatchError("failed to interpolate jumpbox"))
+				Expect(boshExecutor.DirectorInterpolateCall.CallCount).To(Equal(1))
+
+				incomingGCPState.Jumpbox.Enabled = true
+				_, err = boshManager.CreateDirector(incomingGCPState, terraformOutputs)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(boshExecutor.DirectorInterpolateCall.CallCount).To(Equal(2))
+			})
+
 			Context("when the jumpbox variables cannot be parsed", func() {
 				BeforeEach(func() {
 					boshExecutor.JumpboxInterpolateCall.Returns.Output.Variables = "%%%"
@@ -646,7 +881,7 @@ gcp_credentials_json: 'some-credential-json'`
 			boshExecutor = &fakes.BOSHExecutor{}
 			logger = &fakes.Logger{}
 			socks5Proxy = &fakes.Socks5Proxy{}
-			boshManager = bosh.NewManager(boshExecutor, logger, socks5Proxy)
+			boshManager = bosh.NewManager(boshExecutor, logger, socks5Proxy, &fakes.AWSClientProvider{})
 
 			vars = `jumpbox_ssh:
   private_key: some-private-key
@@ -751,7 +986,7 @@ gcp_credentials_json: 'some-credential-json'`
 			boshExecutor = &fakes.BOSHExecutor{}
 			logger = &fakes.Logger{}
 			socks5Proxy = &fakes.Socks5Proxy{}
-			boshManager = bosh.NewManager(boshExecutor, logger, socks5Proxy)
+			boshManager = bosh.NewManager(boshExecutor, logger, socks5Proxy, &fakes.AWSClientProvider{})
 
 			bosh.SetOSSetenv(func(key, value string) error {
 				osSetenvKey = key
@@ -902,17 +1137,19 @@ gcp_credentials_json: 'some-credential-json'`
 
 	Describe("GetDeploymentVars", func() {
 		var (
-			boshExecutor *fakes.BOSHExecutor
-			logger       *fakes.Logger
-			socks5Proxy  *fakes.Socks5Proxy
-			boshManager  *bosh.Manager
+			boshExecutor   *fakes.BOSHExecutor
+			logger         *fakes.Logger
+			socks5Proxy    *fakes.Socks5Proxy
+			awsCredentials *fakes.AWSClientProvider
+			boshManager    *bosh.Manager
 		)
 
 		BeforeEach(func() {
 			boshExecutor = &fakes.BOSHExecutor{}
 			logger = &fakes.Logger{}
 			socks5Proxy = &fakes.Socks5Proxy{}
-			boshManager = bosh.NewManager(boshExecutor, logger, socks5Proxy)
+			awsCredentials = &fakes.AWSClientProvider{}
+			boshManager = bosh.NewManager(boshExecutor, logger, socks5Proxy, awsCredentials)
 		})
 
 		Context("gcp", func() {
@@ -967,6 +1204,76 @@ tags: [some-jumpbox-tag, some-director-tag]
 project_id: some-project-id
 gcp_credentials_json: 'some-credential-json'`))
 			})
+
+			Context("when the environment is internal only", func() {
+				BeforeEach(func() {
+					incomingState.GCP.InternalOnly = true
+				})
+
+				It("omits the external ip", func() {
+					vars, err := boshManager.GetDeploymentVars(incomingState, map[string]interface{}{
+						"network_name":           "some-network",
+						"subnetwork_name":        "some-subnetwork",
+						"bosh_open_tag_name":     "some-jumpbox-tag",
+						"bosh_director_tag_name": "some-director-tag",
+						"internal_tag_name":      "some-internal-tag",
+						"director_address":       "some-director-address",
+					})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(vars).To(Equal(`internal_cidr: 10.0.0.0/24
+internal_gw: 10.0.0.1
+internal_ip: 10.0.0.6
+director_name: bosh-some-env-id
+zone: some-zone
+network: some-network
+subnetwork: some-subnetwork
+tags: [some-jumpbox-tag, some-director-tag]
+project_id: some-project-id
+gcp_credentials_json: 'some-credential-json'`))
+				})
+			})
+
+			Context("when the director has a service account and there is no key", func() {
+				BeforeEach(func() {
+					incomingState.GCP.ServiceAccountKey = ""
+					incomingState.GCP.ApplicationDefaultCredentials = true
+					incomingState.GCP.DirectorServiceAccount = true
+				})
+
+				It("provides the service account instead of the key", func() {
+					vars, err := boshManager.GetDeploymentVars(incomingState, map[string]interface{}{
+						"network_name":                   "some-network",
+						"subnetwork_name":                "some-subnetwork",
+						"bosh_open_tag_name":             "some-jumpbox-tag",
+						"bosh_director_tag_name":         "some-director-tag",
+						"external_ip":                    "some-external-ip",
+						"director_service_account_email": "some-director@some-project-id.iam.gserviceaccount.com",
+					})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(vars).To(Equal(`internal_cidr: 10.0.0.0/24
+internal_gw: 10.0.0.1
+internal_ip: 10.0.0.6
+director_name: bosh-some-env-id
+external_ip: some-external-ip
+zone: some-zone
+network: some-network
+subnetwork: some-subnetwork
+tags: [some-jumpbox-tag, some-director-tag]
+project_id: some-project-id
+director_service_account: some-director@some-project-id.iam.gserviceaccount.com`))
+				})
+
+				It("omits the key from the jumpbox deployment vars", func() {
+					vars, err := boshManager.GetJumpboxDeploymentVars(incomingState, map[string]interface{}{
+						"network_name":       "some-network",
+						"subnetwork_name":    "some-subnetwork",
+						"bosh_open_tag_name": "some-jumpbox-tag",
+						"external_ip":        "some-external-ip",
+					})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(vars).NotTo(ContainSubstring("gcp_credentials_json"))
+				})
+			})
 		})
 
 		Context("aws", func() {
@@ -1031,6 +1338,86 @@ private_key: |-
 				})
 			})
 
+			Context("when the state refers to an assumed role", func() {
+				BeforeEach(func() {
+					incomingState.AWS = storage.AWS{
+						Region:        "some-region",
+						AssumeRoleARN: "some-role-arn",
+					}
+					awsCredentials.GetCredentialsCall.Returns.Credentials = aws.Credentials{
+						AccessKeyID:     "some-session-access-key-id",
+						SecretAccessKey: "some-session-secret-access-key",
+						SessionToken:    "some-session-token",
+					}
+				})
+
+				It("uses the credentials of the role instead of the keys in the state", func() {
+					vars, err := boshManager.GetDeploymentVars(incomingState, map[string]interface{}{
+						"bosh_subnet_availability_zone": "some-bosh-subnet-az",
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(awsCredentials.GetCredentialsCall.CallCount).To(Equal(1))
+					Expect(vars).To(ContainSubstring(`access_key_id: some-session-access-key-id
+secret_access_key: some-session-secret-access-key
+session_token: some-session-token
+`))
+				})
+
+				It("returns an error when the credentials cannot be resolved", func() {
+					awsCredentials.GetCredentialsCall.Returns.Error = errors.New("failed to assume role")
+
+					_, err := boshManager.GetDeploymentVars(incomingState, map[string]interface{}{})
+					Expect(err).To(MatchError("failed to assume role"))
+				})
+			})
+
+			Context("when the director has been placed in a failover availability zone", func() {
+				var terraformOutputs map[string]interface{}
+
+				BeforeEach(func() {
+					incomingState.AWS.DirectorAZ = "some-failover-az"
+					terraformOutputs = map[string]interface{}{
+						"bosh_iam_instance_profile":     "some-bosh-iam-instance-profile",
+						"bosh_subnet_availability_zone": "some-bosh-subnet-az",
+						"bosh_security_group":           "some-bosh-security-group",
+						"bosh_subnet_id":                "some-bosh-subnet",
+						"external_ip":                   "some-bosh-external-ip",
+						"bosh_az_subnet_id_mapping": map[string]interface{}{
+							"some-bosh-subnet-az": "some-bosh-subnet",
+							"some-failover-az":    "some-failover-subnet",
+						},
+						"bosh_az_subnet_cidr_mapping": map[string]interface{}{
+							"some-bosh-subnet-az": "10.0.0.0/24",
+							"some-failover-az":    "10.0.240.0/24",
+						},
+					}
+				})
+
+				It("returns deployment variables for the failover subnet", func() {
+					vars, err := boshManager.GetDeploymentVars(incomingState, terraformOutputs)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(vars).To(ContainSubstring(`internal_cidr: 10.0.240.0/24
+internal_gw: 10.0.240.1
+internal_ip: 10.0.240.6
+director_name: bosh-some-env-id
+external_ip: some-bosh-external-ip
+az: some-failover-az
+subnet_id: some-failover-subnet
+`))
+				})
+
+				Context("when no bosh subnet exists in that availability zone", func() {
+					BeforeEach(func() {
+						incomingState.AWS.DirectorAZ = "some-unknown-az"
+					})
+
+					It("returns an error", func() {
+						_, err := boshManager.GetDeploymentVars(incomingState, terraformOutputs)
+						Expect(err).To(MatchError(`no bosh subnet exists in availability zone "some-unknown-az"`))
+					})
+				})
+			})
 		})
 	})
 
@@ -1046,7 +1433,7 @@ private_key: |-
 			boshExecutor = &fakes.BOSHExecutor{}
 			logger = &fakes.Logger{}
 			socks5Proxy = &fakes.Socks5Proxy{}
-			boshManager = bosh.NewManager(boshExecutor, logger, socks5Proxy)
+			boshManager = bosh.NewManager(boshExecutor, logger, socks5Proxy, &fakes.AWSClientProvider{})
 
 			boshExecutor.VersionCall.Returns.Version = "2.0.24"
 		})
@@ -1083,3 +1470,24 @@ private_key: |-
 		})
 	})
 })
+
+func certificateForIPs(ips ...string) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "some-common-name"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		IsCA:         len(ips) == 0,
+	}
+	for _, ip := range ips {
+		template.IPAddresses = append(template.IPAddresses, net.ParseIP(ip))
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
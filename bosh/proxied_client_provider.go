@@ -0,0 +1,75 @@
+package bosh
+
+import (
+	"fmt"
+
+	"golang.org/x/net/proxy"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+var proxySOCKS5 func(string, string, *proxy.Auth, proxy.Dialer) (proxy.Dialer, error) = proxy.SOCKS5
+
+// ProxiedClientProvider returns clients for the director in a bbl state.
+// When the director is behind a jumpbox, the client talks to it through a
+// socks5 proxy over ssh to the jumpbox.
+type ProxiedClientProvider struct {
+	logger           logger
+	clientProvider   clientProvider
+	socks5Proxy      socks5Proxy
+	terraformManager terraformManager
+	sshKeyGetter     sshKeyGetter
+}
+
+type clientProvider interface {
+	Client(directorAddress, directorUsername, directorPassword string) Client
+}
+
+type terraformManager interface {
+	GetOutputs(storage.State) (map[string]interface{}, error)
+}
+
+type sshKeyGetter interface {
+	Get(storage.State) (string, error)
+}
+
+func NewProxiedClientProvider(logger logger, clientProvider clientProvider, socks5Proxy socks5Proxy,
+	terraformManager terraformManager, sshKeyGetter sshKeyGetter) ProxiedClientProvider {
+	return ProxiedClientProvider{
+		logger:           logger,
+		clientProvider:   clientProvider,
+		socks5Proxy:      socks5Proxy,
+		terraformManager: terraformManager,
+		sshKeyGetter:     sshKeyGetter,
+	}
+}
+
+func (p ProxiedClientProvider) Client(state storage.State) (Client, error) {
+	boshClient := p.clientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword)
+
+	if state.Jumpbox.Enabled {
+		privateKey, err := p.sshKeyGetter.Get(state)
+		if err != nil {
+			return nil, err
+		}
+		terraformOutputs, err := p.terraformManager.GetOutputs(state)
+		if err != nil {
+			return nil, err
+		}
+		jumpboxURL := fmt.Sprintf("%s:%d", terraformOutputs["external_ip"], 22)
+
+		p.logger.Step("starting socks5 proxy")
+		err = p.socks5Proxy.Start(privateKey, jumpboxURL)
+		if err != nil {
+			return nil, err
+		}
+
+		socks5Client, err := proxySOCKS5("tcp", p.socks5Proxy.Addr(), nil, proxy.Direct)
+		if err != nil {
+			return nil, err
+		}
+		boshClient.ConfigureHTTPClient(socks5Client)
+	}
+
+	return boshClient, nil
+}
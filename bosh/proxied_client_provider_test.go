@@ -0,0 +1,153 @@
+package bosh_test
+
+import (
+	"errors"
+
+	"golang.org/x/net/proxy"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProxiedClientProvider", func() {
+	var (
+		logger           *fakes.Logger
+		clientProvider   *fakes.BOSHClientProvider
+		boshClient       *fakes.BOSHClient
+		socks5Proxy      *fakes.Socks5Proxy
+		terraformManager *fakes.TerraformManager
+		sshKeyGetter     *fakes.SSHKeyGetter
+
+		proxiedClientProvider bosh.ProxiedClientProvider
+
+		state storage.State
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		boshClient = &fakes.BOSHClient{}
+		clientProvider = &fakes.BOSHClientProvider{}
+		socks5Proxy = &fakes.Socks5Proxy{}
+		terraformManager = &fakes.TerraformManager{}
+		sshKeyGetter = &fakes.SSHKeyGetter{}
+
+		clientProvider.ClientCall.Returns.Client = boshClient
+
+		state = storage.State{
+			BOSH: storage.BOSH{
+				DirectorAddress:  "some-director-address",
+				DirectorUsername: "some-director-username",
+				DirectorPassword: "some-director-password",
+			},
+		}
+
+		proxiedClientProvider = bosh.NewProxiedClientProvider(logger, clientProvider, socks5Proxy, terraformManager, sshKeyGetter)
+	})
+
+	Describe("Client", func() {
+		It("returns a client for the director", func() {
+			client, err := proxiedClientProvider.Client(state)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client).To(Equal(boshClient))
+
+			Expect(clientProvider.ClientCall.Receives.DirectorAddress).To(Equal("some-director-address"))
+			Expect(clientProvider.ClientCall.Receives.DirectorUsername).To(Equal("some-director-username"))
+			Expect(clientProvider.ClientCall.Receives.DirectorPassword).To(Equal("some-director-password"))
+
+			Expect(socks5Proxy.StartCall.CallCount).To(Equal(0))
+			Expect(boshClient.ConfigureHTTPClientCall.CallCount).To(Equal(0))
+		})
+
+		Context("when a jumpbox exists", func() {
+			var (
+				socks5Network string
+				socks5Addr    string
+				socks5Auth    *proxy.Auth
+				socks5Forward proxy.Dialer
+				socks5Client  *fakes.Socks5Client
+			)
+
+			BeforeEach(func() {
+				state.Jumpbox.Enabled = true
+				terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+					"external_ip": "some-external-url",
+				}
+				sshKeyGetter.GetCall.Returns.PrivateKey = "some-private-key"
+
+				socks5Client = &fakes.Socks5Client{}
+				bosh.SetProxySOCKS5(func(network, addr string, auth *proxy.Auth, forward proxy.Dialer) (proxy.Dialer, error) {
+					socks5Network = network
+					socks5Addr = addr
+					socks5Auth = auth
+					socks5Forward = forward
+
+					return socks5Client, nil
+				})
+			})
+
+			AfterEach(func() {
+				bosh.ResetProxySOCKS5()
+			})
+
+			It("starts a socks5 proxy", func() {
+				_, err := proxiedClientProvider.Client(state)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sshKeyGetter.GetCall.Receives.State).To(Equal(state))
+				Expect(terraformManager.GetOutputsCall.Receives.BBLState).To(Equal(state))
+
+				Expect(socks5Proxy.StartCall.CallCount).To(Equal(1))
+				Expect(socks5Proxy.StartCall.Receives.JumpboxPrivateKey).To(Equal("some-private-key"))
+				Expect(socks5Proxy.StartCall.Receives.JumpboxExternalURL).To(Equal("some-external-url:22"))
+				Expect(logger.StepCall.Messages).To(Equal([]string{"starting socks5 proxy"}))
+			})
+
+			It("configures the bosh client", func() {
+				socks5Proxy.AddrCall.Returns.Addr = "some-socks-proxy-addr"
+				_, err := proxiedClientProvider.Client(state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshClient.ConfigureHTTPClientCall.CallCount).To(Equal(1))
+				Expect(boshClient.ConfigureHTTPClientCall.Receives.Socks5Client).To(Equal(socks5Client))
+
+				Expect(socks5Proxy.AddrCall.CallCount).To(Equal(1))
+
+				Expect(socks5Network).To(Equal("tcp"))
+				Expect(socks5Addr).To(Equal("some-socks-proxy-addr"))
+				Expect(socks5Auth).To(BeNil())
+				Expect(socks5Forward).To(Equal(proxy.Direct))
+			})
+
+			Context("failure cases", func() {
+				It("returns an error when sshKeyGetter.Get fails", func() {
+					sshKeyGetter.GetCall.Returns.Error = errors.New("failed to get jumpbox ssh key")
+					_, err := proxiedClientProvider.Client(state)
+					Expect(err).To(MatchError("failed to get jumpbox ssh key"))
+				})
+
+				It("returns an error when terraformManager.GetOutputs fails", func() {
+					terraformManager.GetOutputsCall.Returns.Error = errors.New("failed to get terraform outputs")
+					_, err := proxiedClientProvider.Client(state)
+					Expect(err).To(MatchError("failed to get terraform outputs"))
+				})
+
+				It("returns an error when the socks5Proxy fails to start", func() {
+					socks5Proxy.StartCall.Returns.Error = errors.New("failed to start socks5 proxy")
+					_, err := proxiedClientProvider.Client(state)
+					Expect(err).To(MatchError("failed to start socks5 proxy"))
+				})
+
+				It("returns an error when it cannot create a socks5 proxy client", func() {
+					bosh.SetProxySOCKS5(func(network, addr string, auth *proxy.Auth, forward proxy.Dialer) (proxy.Dialer, error) {
+						return nil, errors.New("failed to create socks5 proxy client")
+					})
+					_, err := proxiedClientProvider.Client(state)
+					Expect(err).To(MatchError("failed to create socks5 proxy client"))
+				})
+			})
+		})
+	})
+})
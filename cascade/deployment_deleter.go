@@ -0,0 +1,85 @@
+package cascade
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+type DeploymentDeleter struct {
+	logger             logger
+	boshClientProvider boshClientProvider
+}
+
+type logger interface {
+	Step(string, ...interface{})
+}
+
+type boshClientProvider interface {
+	Client(storage.State) (bosh.Client, error)
+}
+
+func NewDeploymentDeleter(logger logger, boshClientProvider boshClientProvider) DeploymentDeleter {
+	return DeploymentDeleter{
+		logger:             logger,
+		boshClientProvider: boshClientProvider,
+	}
+}
+
+// Deployments returns the names of the deployments on the director.
+func (d DeploymentDeleter) Deployments(state storage.State) ([]string, error) {
+	boshClient, err := d.boshClientProvider.Client(state)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments, err := boshClient.Deployments()
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, deployment := range deployments {
+		names = append(names, deployment.Name)
+	}
+
+	return names, nil
+}
+
+// Delete deletes every deployment on the director and then the disks those
+// deployments left orphaned.
+func (d DeploymentDeleter) Delete(state storage.State) error {
+	boshClient, err := d.boshClientProvider.Client(state)
+	if err != nil {
+		return err
+	}
+
+	deployments, err := boshClient.Deployments()
+	if err != nil {
+		return err
+	}
+
+	for _, deployment := range deployments {
+		d.logger.Step("deleting deployment %s", deployment.Name)
+		err = boshClient.DeleteDeployment(deployment.Name)
+		if err != nil {
+			return fmt.Errorf("failed to delete deployment %s: %s", deployment.Name, err)
+		}
+	}
+
+	disks, err := boshClient.OrphanedDisks()
+	if err != nil {
+		return err
+	}
+
+	for _, disk := range disks {
+		d.logger.Step("deleting orphaned disk %s", disk.DiskCID)
+		err = boshClient.DeleteOrphanedDisk(disk.DiskCID)
+		if err != nil {
+			return fmt.Errorf("failed to delete orphaned disk %s: %s", disk.DiskCID, err)
+		}
+	}
+
+	return nil
+}
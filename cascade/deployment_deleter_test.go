@@ -0,0 +1,112 @@
+package cascade_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/cascade"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeploymentDeleter", func() {
+	var (
+		logger             *fakes.Logger
+		boshClientProvider *fakes.ProxiedBOSHClientProvider
+		boshClient         *fakes.BOSHClient
+		deleter            cascade.DeploymentDeleter
+
+		incomingState storage.State
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		boshClient = &fakes.BOSHClient{}
+		boshClientProvider = &fakes.ProxiedBOSHClientProvider{}
+
+		boshClientProvider.ClientCall.Returns.Client = boshClient
+		boshClient.DeploymentsCall.Returns.Deployments = []bosh.Deployment{
+			{Name: "some-deployment"},
+			{Name: "other-deployment"},
+		}
+		boshClient.OrphanedDisksCall.Returns.OrphanedDisks = []bosh.OrphanedDisk{
+			{DiskCID: "some-disk-cid", DeploymentName: "some-deployment"},
+		}
+
+		incomingState = storage.State{
+			BOSH: storage.BOSH{
+				DirectorAddress:  "some-director-address",
+				DirectorUsername: "some-director-username",
+				DirectorPassword: "some-director-password",
+			},
+		}
+
+		deleter = cascade.NewDeploymentDeleter(logger, boshClientProvider)
+	})
+
+	Describe("Deployments", func() {
+		It("returns the names of the deployments on the director", func() {
+			deployments, err := deleter.Deployments(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(deployments).To(Equal([]string{"some-deployment", "other-deployment"}))
+			Expect(boshClientProvider.ClientCall.Receives.State).To(Equal(incomingState))
+		})
+
+		It("returns an error when the deployments cannot be listed", func() {
+			boshClient.DeploymentsCall.Returns.Error = errors.New("failed to list deployments")
+
+			_, err := deleter.Deployments(incomingState)
+			Expect(err).To(MatchError("failed to list deployments"))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes every deployment and then the orphaned disks", func() {
+			err := deleter.Delete(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(boshClient.DeleteDeploymentCall.Receives.Names).To(Equal([]string{"some-deployment", "other-deployment"}))
+			Expect(boshClient.DeleteOrphanedDiskCall.Receives.DiskCIDs).To(Equal([]string{"some-disk-cid"}))
+			Expect(logger.StepCall.Messages).To(Equal([]string{
+				"deleting deployment some-deployment",
+				"deleting deployment other-deployment",
+				"deleting orphaned disk some-disk-cid",
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the bosh client cannot be created", func() {
+				boshClientProvider.ClientCall.Returns.Error = errors.New("failed to start socks5 proxy")
+
+				err := deleter.Delete(incomingState)
+				Expect(err).To(MatchError("failed to start socks5 proxy"))
+			})
+
+			It("returns an error when a deployment cannot be deleted", func() {
+				boshClient.DeleteDeploymentCall.Returns.Error = errors.New("task failed")
+
+				err := deleter.Delete(incomingState)
+				Expect(err).To(MatchError("failed to delete deployment some-deployment: task failed"))
+				Expect(boshClient.OrphanedDisksCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the orphaned disks cannot be listed", func() {
+				boshClient.OrphanedDisksCall.Returns.Error = errors.New("failed to list disks")
+
+				err := deleter.Delete(incomingState)
+				Expect(err).To(MatchError("failed to list disks"))
+			})
+
+			It("returns an error when an orphaned disk cannot be deleted", func() {
+				boshClient.DeleteOrphanedDiskCall.Returns.Error = errors.New("task failed")
+
+				err := deleter.Delete(incomingState)
+				Expect(err).To(MatchError("failed to delete orphaned disk some-disk-cid: task failed"))
+			})
+		})
+	})
+})
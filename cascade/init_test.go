@@ -0,0 +1,13 @@
+package cascade_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCascade(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "cascade")
+}
@@ -0,0 +1,131 @@
+package cloudconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContext = 3
+
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// diff returns a unified diff of two documents, line by line, or an empty
+// string when they are identical.
+func diff(from, to string) string {
+	if from == to {
+		return ""
+	}
+
+	lines := diffLines(splitLines(from), splitLines(to))
+
+	// fromLines and toLines hold the 1-based line number each diff line
+	// starts at in the respective document.
+	fromLines := make([]int, len(lines))
+	toLines := make([]int, len(lines))
+	fromLine, toLine := 1, 1
+	var changes []int
+	for i, line := range lines {
+		fromLines[i], toLines[i] = fromLine, toLine
+		if line.kind != '+' {
+			fromLine++
+		}
+		if line.kind != '-' {
+			toLine++
+		}
+		if line.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+
+	var out []string
+	for len(changes) > 0 {
+		last := 0
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*diffContext {
+			last++
+		}
+
+		start := changes[0] - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := changes[last] + diffContext
+		if end > len(lines)-1 {
+			end = len(lines) - 1
+		}
+
+		var fromCount, toCount int
+		var body []string
+		for _, line := range lines[start : end+1] {
+			if line.kind != '+' {
+				fromCount++
+			}
+			if line.kind != '-' {
+				toCount++
+			}
+			body = append(body, fmt.Sprintf("%c %s", line.kind, line.text))
+		}
+
+		out = append(out, fmt.Sprintf("@@ -%d,%d +%d,%d @@", fromLines[start], fromCount, toLines[start], toCount))
+		out = append(out, body...)
+
+		changes = changes[last+1:]
+	}
+
+	return strings.Join(out, "\n")
+}
+
+func diffLines(from, to []string) []diffLine {
+	lengths := make([][]int, len(from)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(to)+1)
+	}
+
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			lines = append(lines, diffLine{kind: ' ', text: from[i]})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			lines = append(lines, diffLine{kind: '-', text: from[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{kind: '+', text: to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		lines = append(lines, diffLine{kind: '-', text: from[i]})
+	}
+	for ; j < len(to); j++ {
+		lines = append(lines, diffLine{kind: '+', text: to[j]})
+	}
+
+	return lines
+}
+
+func splitLines(document string) []string {
+	document = strings.TrimSuffix(document, "\n")
+	if document == "" {
+		return nil
+	}
+
+	return strings.Split(document, "\n")
+}
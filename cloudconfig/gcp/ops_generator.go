@@ -61,9 +61,14 @@ type lb struct {
 }
 
 type lbCloudProperties struct {
-	BackendService string   `yaml:"backend_service,omitempty"`
-	TargetPool     string   `yaml:"target_pool,omitempty"`
-	Tags           []string `yaml:",omitempty"`
+	BackendService interface{} `yaml:"backend_service,omitempty"`
+	TargetPool     string      `yaml:"target_pool,omitempty"`
+	Tags           []string    `yaml:",omitempty"`
+}
+
+type internalBackendService struct {
+	Name   string
+	Scheme string
 }
 
 var marshal func(interface{}) ([]byte, error) = yaml.Marshal
@@ -127,6 +132,7 @@ func (o *OpsGenerator) generateGCPOps(state storage.State) ([]op, error) {
 			terraformOutputs["network_name"].(string),
 			terraformOutputs["subnetwork_name"].(string),
 			terraformOutputs["internal_tag_name"].(string),
+			!state.GCP.InternalOnly,
 		)
 		if err != nil {
 			return []op{}, err
@@ -147,6 +153,12 @@ func (o *OpsGenerator) generateGCPOps(state storage.State) ([]op, error) {
 		Type:    "manual",
 	}))
 
+	if state.GCP.InternalOnly {
+		ops = append(ops, createOp("replace", "/vm_extensions/name=internet-required/cloud_properties/ephemeral_external_ip", false))
+
+		return append(ops, generateInternalLBOps(state, terraformOutputs)...), nil
+	}
+
 	if state.LB.Type == "concourse" {
 		ops = append(ops, createOp("replace", "/vm_extensions/-", lb{
 			Name: "lb",
@@ -193,7 +205,56 @@ func (o *OpsGenerator) generateGCPOps(state storage.State) ([]op, error) {
 	return ops, nil
 }
 
-func generateNetworkSubnet(az, cidr, networkName, subnetworkName, internalTag string) (networkSubnet, error) {
+func generateInternalLBOps(state storage.State, terraformOutputs map[string]interface{}) []op {
+	var ops []op
+
+	switch state.LB.Type {
+	case "concourse":
+		ops = append(ops, createOp("replace", "/vm_extensions/-", lb{
+			Name: "lb",
+			CloudProperties: lbCloudProperties{
+				BackendService: internalBackendService{
+					Name:   terraformOutputs["concourse_backend_service"].(string),
+					Scheme: "INTERNAL",
+				},
+			},
+		}))
+	case "cf":
+		ops = append(ops, createOp("replace", "/vm_extensions/-", lb{
+			Name: "cf-router-network-properties",
+			CloudProperties: lbCloudProperties{
+				BackendService: internalBackendService{
+					Name:   terraformOutputs["router_backend_service"].(string),
+					Scheme: "INTERNAL",
+				},
+				Tags: []string{
+					terraformOutputs["router_backend_service"].(string),
+				},
+			},
+		}))
+
+		ops = append(ops, createOp("replace", "/vm_extensions/-", lb{
+			Name: "diego-ssh-proxy-network-properties",
+			CloudProperties: lbCloudProperties{
+				BackendService: internalBackendService{
+					Name:   terraformOutputs["ssh_proxy_backend_service"].(string),
+					Scheme: "INTERNAL",
+				},
+				Tags: []string{
+					terraformOutputs["ssh_proxy_backend_service"].(string),
+				},
+			},
+		}))
+
+		ops = append(ops, createOp("replace", "/vm_extensions/-", lb{
+			Name: "cf-tcp-router-network-properties",
+		}))
+	}
+
+	return ops
+}
+
+func generateNetworkSubnet(az, cidr, networkName, subnetworkName, internalTag string, ephemeralExternalIP bool) (networkSubnet, error) {
 	parsedCidr, err := bosh.ParseCIDRBlock(cidr)
 	if err != nil {
 		return networkSubnet{}, err
@@ -218,7 +279,7 @@ func generateNetworkSubnet(az, cidr, networkName, subnetworkName, internalTag st
 			fmt.Sprintf("%s-%s", firstStatic, lastStatic),
 		},
 		CloudProperties: subnetCloudProperties{
-			EphemeralExternalIP: true,
+			EphemeralExternalIP: ephemeralExternalIP,
 			NetworkName:         networkName,
 			SubnetworkName:      subnetworkName,
 			Tags:                []string{internalTag},
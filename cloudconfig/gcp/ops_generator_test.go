@@ -101,6 +101,58 @@ var _ = Describe("GCPOpsGenerator", func() {
 				}),
 		)
 
+		Context("when the environment is internal only", func() {
+			BeforeEach(func() {
+				incomingState.GCP.InternalOnly = true
+
+				var err error
+				expectedOpsFile, err = ioutil.ReadFile(filepath.Join("fixtures", "gcp-internal-ops.yml"))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an ops file without ephemeral external ips", func() {
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(opsYAML).To(gomegamatchers.MatchYAML(expectedOpsFile))
+			})
+
+			DescribeTable("returns an ops file with vm extensions for the internal lb",
+				func(lbType string, lbOutputs map[string]interface{}) {
+					incomingState.LB.Type = lbType
+
+					expectedLBOpsFile, err := ioutil.ReadFile(filepath.Join("fixtures", fmt.Sprintf("gcp-internal-%s-lb-ops.yml", lbType)))
+					Expect(err).NotTo(HaveOccurred())
+
+					expectedOps := strings.Join([]string{string(expectedOpsFile), string(expectedLBOpsFile)}, "\n")
+
+					terraformManager.GetOutputsCall.Returns.Outputs = lbOutputs
+
+					opsYAML, err := opsGenerator.Generate(incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(opsYAML).To(gomegamatchers.MatchYAML(expectedOps))
+				},
+				Entry("cf load balancer exists", "cf",
+					map[string]interface{}{
+						"network_name":              "some-network-name",
+						"subnetwork_name":           "some-subnetwork-name",
+						"bosh_open_tag_name":        "some-bosh-tag",
+						"internal_tag_name":         "some-internal-tag",
+						"router_backend_service":    "router-backend-service",
+						"ssh_proxy_backend_service": "ssh-proxy-backend-service",
+					}),
+				Entry("concourse load balancer exists", "concourse",
+					map[string]interface{}{
+						"network_name":              "some-network-name",
+						"subnetwork_name":           "some-subnetwork-name",
+						"bosh_open_tag_name":        "some-bosh-tag",
+						"internal_tag_name":         "some-internal-tag",
+						"concourse_backend_service": "concourse-backend-service",
+					}),
+			)
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when terraform output provider fails to retrieve", func() {
 				terraformManager.GetOutputsCall.Returns.Error = errors.New("failed to output")
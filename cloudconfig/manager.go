@@ -1,41 +1,41 @@
 package cloudconfig
 
 import (
-	"bytes"
 	"fmt"
-	"io"
-	"io/ioutil"
-	"os"
-	"path/filepath"
+	"reflect"
 
-	"golang.org/x/net/proxy"
+	yaml "gopkg.in/yaml.v2"
 
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/opsfile"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
-var (
-	tempDir     func(string, string) (string, error)                                  = ioutil.TempDir
-	writeFile   func(string, []byte, os.FileMode) error                               = ioutil.WriteFile
-	proxySOCKS5 func(string, string, *proxy.Auth, proxy.Dialer) (proxy.Dialer, error) = proxy.SOCKS5
+const (
+	cloudConfigType        = "cloud"
+	runtimeConfigType      = "runtime"
+	cpiConfigType          = "cpi"
+	cloudConfigName        = "bbl"
+	defaultCloudConfigName = "default"
+
+	dnsRuntimeConfigAsset = "vendor/github.com/cloudfoundry/bosh-deployment/runtime-configs/dns.yml"
 )
 
+var cpiTypes = map[string]string{
+	"aws": "aws",
+	"gcp": "google",
+}
+
 type Manager struct {
 	logger             logger
-	command            command
 	opsGenerator       opsGenerator
 	boshClientProvider boshClientProvider
-	socks5Proxy        socks5Proxy
 	terraformManager   terraformManager
-	sshKeyGetter       sshKeyGetter
 }
 
 type logger interface {
 	Step(string, ...interface{})
-}
-
-type command interface {
-	Run(stdout io.Writer, workingDirectory string, args []string) error
+	Println(string)
 }
 
 type opsGenerator interface {
@@ -43,108 +43,271 @@ type opsGenerator interface {
 }
 
 type boshClientProvider interface {
-	Client(directorAddress, directorUsername, directorPassword string) bosh.Client
-}
-
-type socks5Proxy interface {
-	Start(string, string) error
-	Addr() string
+	Client(storage.State) (bosh.Client, error)
 }
 
 type terraformManager interface {
 	GetOutputs(storage.State) (map[string]interface{}, error)
 }
 
-type sshKeyGetter interface {
-	Get(storage.State) (string, error)
-}
-
-func NewManager(logger logger, cmd command, opsGenerator opsGenerator, boshClientProvider boshClientProvider,
-	socks5Proxy socks5Proxy, terraformManager terraformManager, sshKeyGetter sshKeyGetter) Manager {
+func NewManager(logger logger, opsGenerator opsGenerator, boshClientProvider boshClientProvider,
+	terraformManager terraformManager) Manager {
 	return Manager{
 		logger:             logger,
-		command:            cmd,
 		opsGenerator:       opsGenerator,
 		boshClientProvider: boshClientProvider,
-		socks5Proxy:        socks5Proxy,
 		terraformManager:   terraformManager,
-		sshKeyGetter:       sshKeyGetter,
 	}
 }
 
 func (m Manager) Generate(state storage.State) (string, error) {
-	buf := bytes.NewBuffer([]byte{})
-	workingDir, err := tempDir("", "")
+	ops, err := m.opsGenerator.Generate(state)
 	if err != nil {
 		return "", err
 	}
 
-	err = writeFile(filepath.Join(workingDir, "cloud-config.yml"), []byte(BaseCloudConfig), os.ModePerm)
+	cloudConfig, err := opsfile.Apply([]byte(BaseCloudConfig), []byte(ops))
 	if err != nil {
 		return "", err
 	}
 
-	ops, err := m.opsGenerator.Generate(state)
-	if err != nil {
-		return "", err
+	if state.CPIConfig {
+		cloudConfig, err = addCPIToAZs(cloudConfig, state.IAAS)
+		if err != nil {
+			return "", err
+		}
 	}
 
-	err = writeFile(filepath.Join(workingDir, "ops.yml"), []byte(ops), os.ModePerm)
+	if state.CloudConfigOpsFile != "" {
+		cloudConfig, err = opsfile.Apply(cloudConfig, []byte(state.CloudConfigOpsFile))
+		if err != nil {
+			return "", fmt.Errorf("error applying cloud-config ops-file: %s", err)
+		}
+	}
+
+	return string(cloudConfig), nil
+}
+
+// GenerateRuntimeConfig returns the BOSH DNS runtime config from the vendored
+// bosh-deployment.
+func (m Manager) GenerateRuntimeConfig(state storage.State) (string, error) {
+	runtimeConfig, err := bosh.Asset(dnsRuntimeConfigAsset)
 	if err != nil {
 		return "", err
 	}
 
-	args := []string{
-		"interpolate", fmt.Sprintf("%s/cloud-config.yml", workingDir),
-		"-o", fmt.Sprintf("%s/ops.yml", workingDir),
+	return string(runtimeConfig), nil
+}
+
+// GenerateCPIConfig returns a cpi config with a single CPI, named after the
+// IaaS, using the same credentials the director was deployed with.
+func (m Manager) GenerateCPIConfig(state storage.State) (string, error) {
+	var properties map[string]interface{}
+
+	switch state.IAAS {
+	case "aws":
+		terraformOutputs, err := m.terraformManager.GetOutputs(state)
+		if err != nil {
+			return "", err
+		}
+
+		properties = map[string]interface{}{
+			"region":                  state.AWS.Region,
+			"default_key_name":        state.KeyPair.Name,
+			"default_security_groups": []interface{}{terraformOutputs["bosh_security_group"]},
+		}
+
+		// Without static keys the director authenticates with its
+		// instance profile, so the cpi does too.
+		if state.AWS.UsesStaticKeys() {
+			properties["access_key_id"] = state.AWS.AccessKeyID
+			properties["secret_access_key"] = state.AWS.SecretAccessKey
+		} else {
+			properties["credentials_source"] = "env_or_profile"
+		}
+	case "gcp":
+		properties = map[string]interface{}{
+			"project": state.GCP.ProjectID,
+		}
+
+		// A director with a service account gives it to the cpi too.
+		if state.GCP.UsesServiceAccountKey() && !state.GCP.DirectorServiceAccount {
+			properties["json_key"] = state.GCP.ServiceAccountKey
+		}
+	default:
+		return "", fmt.Errorf("cpi config is not supported for iaas %q", state.IAAS)
 	}
 
-	err = m.command.Run(buf, workingDir, args)
+	cpiConfig, err := yaml.Marshal(map[string]interface{}{
+		"cpis": []interface{}{
+			map[string]interface{}{
+				"name":       state.IAAS,
+				"type":       cpiTypes[state.IAAS],
+				"properties": properties,
+			},
+		},
+	})
 	if err != nil {
 		return "", err
 	}
 
-	return buf.String(), nil
+	return string(cpiConfig), nil
 }
 
 func (m Manager) Update(state storage.State) error {
-	boshClient := m.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword)
+	boshClient, err := m.boshClientProvider.Client(state)
+	if err != nil {
+		return err
+	}
 
-	if state.Jumpbox.Enabled {
-		privateKey, err := m.sshKeyGetter.Get(state)
+	if state.CPIConfig {
+		m.logger.Step("generating cpi config")
+		cpiConfig, err := m.GenerateCPIConfig(state)
 		if err != nil {
 			return err
 		}
-		terraformOutputs, err := m.terraformManager.GetOutputs(state)
+
+		m.logger.Step("applying cpi config")
+		err = boshClient.UpdateConfig(cpiConfigType, cloudConfigName, []byte(cpiConfig))
 		if err != nil {
 			return err
 		}
-		jumpboxURL := fmt.Sprintf("%s:%d", terraformOutputs["external_ip"], 22)
+	}
+
+	m.logger.Step("generating cloud config")
+	cloudConfig, err := m.Generate(state)
+	if err != nil {
+		return err
+	}
 
-		m.logger.Step("starting socks5 proxy")
-		err = m.socks5Proxy.Start(privateKey, jumpboxURL)
+	err = m.removeDefaultCloudConfig(boshClient, cloudConfig)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Step("applying cloud config")
+	err = boshClient.UpdateConfig(cloudConfigType, cloudConfigName, []byte(cloudConfig))
+	if err != nil {
+		return err
+	}
+
+	if !state.NoRuntimeConfig {
+		runtimeConfig, err := m.GenerateRuntimeConfig(state)
 		if err != nil {
 			return err
 		}
 
-		socks5Client, err := proxySOCKS5("tcp", m.socks5Proxy.Addr(), nil, proxy.Direct)
+		m.logger.Step("applying runtime config")
+		err = boshClient.UpdateConfig(runtimeConfigType, cloudConfigName, []byte(runtimeConfig))
 		if err != nil {
 			return err
 		}
-		boshClient.ConfigureHTTPClient(socks5Client)
 	}
 
-	m.logger.Step("generating cloud config")
+	return nil
+}
+
+// Diff returns the changes Update would make to the cloud config currently
+// deployed on the director, or an empty string when there are none.
+func (m Manager) Diff(state storage.State) (string, error) {
+	boshClient, err := m.boshClientProvider.Client(state)
+	if err != nil {
+		return "", err
+	}
+
 	cloudConfig, err := m.Generate(state)
+	if err != nil {
+		return "", err
+	}
+
+	current, err := boshClient.LatestConfig(cloudConfigType, cloudConfigName)
+	if err != nil {
+		return "", err
+	}
+
+	return diff(normalizeYAML(current.Content), cloudConfig), nil
+}
+
+// removeDefaultCloudConfig deletes the unnamed cloud config written by
+// earlier versions of bbl the first time the named config is applied, so the
+// director does not see duplicate networks, azs and vm types. A default
+// cloud config that differs from ours may belong to other tooling, so it is
+// kept and the user is warned instead.
+func (m Manager) removeDefaultCloudConfig(boshClient bosh.Client, cloudConfig string) error {
+	current, err := boshClient.LatestConfig(cloudConfigType, cloudConfigName)
 	if err != nil {
 		return err
 	}
 
-	m.logger.Step("applying cloud config")
-	err = boshClient.UpdateCloudConfig([]byte(cloudConfig))
+	if current.ID != "" {
+		return nil
+	}
+
+	defaultConfig, err := boshClient.LatestConfig(cloudConfigType, defaultCloudConfigName)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if defaultConfig.Content == "" {
+		return nil
+	}
+
+	if !sameYAML(defaultConfig.Content, cloudConfig) {
+		m.logger.Println("warning: the director has a default cloud config that bbl did not generate, so it is left in place. If the bbl cloud config replaces it, remove it with `bosh delete-config --type cloud --name default`.")
+		return nil
+	}
+
+	m.logger.Step("removing default cloud config applied by a previous version of bbl")
+	return boshClient.DeleteConfig(cloudConfigType, defaultCloudConfigName)
+}
+
+// addCPIToAZs points every az at the CPI from the cpi config. Once a cpi
+// config is uploaded the director requires each az to name its CPI.
+func addCPIToAZs(cloudConfig []byte, cpiName string) ([]byte, error) {
+	var document map[interface{}]interface{}
+	err := yaml.Unmarshal(cloudConfig, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	azs, _ := document["azs"].([]interface{})
+	for _, az := range azs {
+		if az, ok := az.(map[interface{}]interface{}); ok {
+			az["cpi"] = cpiName
+		}
+	}
+
+	return yaml.Marshal(document)
+}
+
+// normalizeYAML re-marshals a document so that formatting differences from
+// however it was uploaded do not show up in a diff.
+func normalizeYAML(document string) string {
+	if document == "" {
+		return ""
+	}
+
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(document), &value); err != nil {
+		return document
+	}
+
+	normalized, err := yaml.Marshal(value)
+	if err != nil {
+		return document
+	}
+
+	return string(normalized)
+}
+
+func sameYAML(a, b string) bool {
+	var aValue, bValue interface{}
+	if err := yaml.Unmarshal([]byte(a), &aValue); err != nil {
+		return false
+	}
+	if err := yaml.Unmarshal([]byte(b), &bValue); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(aValue, bValue)
 }
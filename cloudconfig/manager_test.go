@@ -3,16 +3,14 @@ package cloudconfig_test
 import (
 	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
-	"os"
 	"strings"
 
-	"golang.org/x/net/proxy"
-
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
+	"github.com/pivotal-cf-experimental/gomegamatchers"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -21,46 +19,25 @@ import (
 var _ = Describe("Manager", func() {
 	var (
 		logger             *fakes.Logger
-		cmd                *fakes.BOSHCommand
 		opsGenerator       *fakes.CloudConfigOpsGenerator
-		boshClientProvider *fakes.BOSHClientProvider
+		boshClientProvider *fakes.ProxiedBOSHClientProvider
 		boshClient         *fakes.BOSHClient
-		socks5Proxy        *fakes.Socks5Proxy
 		terraformManager   *fakes.TerraformManager
-		sshKeyGetter       *fakes.SSHKeyGetter
 		manager            cloudconfig.Manager
 
-		tempDir       string
-		incomingState storage.State
-
-		baseCloudConfig []byte
+		incomingState       storage.State
+		expectedCloudConfig string
 	)
 
 	BeforeEach(func() {
 		logger = &fakes.Logger{}
-		cmd = &fakes.BOSHCommand{}
 		opsGenerator = &fakes.CloudConfigOpsGenerator{}
 		boshClient = &fakes.BOSHClient{}
-		boshClientProvider = &fakes.BOSHClientProvider{}
-		socks5Proxy = &fakes.Socks5Proxy{}
+		boshClientProvider = &fakes.ProxiedBOSHClientProvider{}
 		terraformManager = &fakes.TerraformManager{}
-		sshKeyGetter = &fakes.SSHKeyGetter{}
 
 		boshClientProvider.ClientCall.Returns.Client = boshClient
 
-		var err error
-		tempDir, err = ioutil.TempDir("", "")
-		Expect(err).NotTo(HaveOccurred())
-
-		cloudconfig.SetTempDir(func(string, string) (string, error) {
-			return tempDir, nil
-		})
-
-		cmd.RunStub = func(stdout io.Writer, workingDirectory string, args []string) error {
-			stdout.Write([]byte("some-cloud-config"))
-			return nil
-		}
-
 		incomingState = storage.State{
 			IAAS: "gcp",
 			BOSH: storage.BOSH{
@@ -70,84 +47,79 @@ var _ = Describe("Manager", func() {
 			},
 		}
 
-		opsGenerator.GenerateCall.Returns.OpsYAML = "some-ops"
+		opsGenerator.GenerateCall.Returns.OpsYAML = `
+- type: replace
+  path: /compilation/network
+  value: some-network
+`
 
-		baseCloudConfig, err = ioutil.ReadFile("fixtures/base-cloud-config.yml")
+		baseCloudConfig, err := ioutil.ReadFile("fixtures/base-cloud-config.yml")
 		Expect(err).NotTo(HaveOccurred())
+		expectedCloudConfig = strings.Replace(string(baseCloudConfig), "network: private", "network: some-network", 1)
 
-		manager = cloudconfig.NewManager(logger, cmd, opsGenerator, boshClientProvider, socks5Proxy, terraformManager, sshKeyGetter)
-	})
-
-	AfterEach(func() {
-		cloudconfig.ResetTempDir()
+		manager = cloudconfig.NewManager(logger, opsGenerator, boshClientProvider, terraformManager)
 	})
 
 	Describe("Generate", func() {
-		It("returns a cloud config yaml provided a valid bbl state", func() {
-			expectedArgs := []string{
-				"interpolate", fmt.Sprintf("%s/cloud-config.yml", tempDir),
-				"-o", fmt.Sprintf("%s/ops.yml", tempDir),
-			}
-
+		It("returns the base cloud config with the generated ops applied", func() {
 			cloudConfigYAML, err := manager.Generate(incomingState)
 			Expect(err).NotTo(HaveOccurred())
 
-			cloudConfig, err := ioutil.ReadFile(fmt.Sprintf("%s/cloud-config.yml", tempDir))
-			Expect(err).NotTo(HaveOccurred())
-			Expect(cloudConfig).To(Equal(baseCloudConfig))
-
 			Expect(opsGenerator.GenerateCall.Receives.State).To(Equal(incomingState))
+			Expect(cloudConfigYAML).To(gomegamatchers.MatchYAML(expectedCloudConfig))
+		})
 
-			ops, err := ioutil.ReadFile(fmt.Sprintf("%s/ops.yml", tempDir))
-			Expect(err).NotTo(HaveOccurred())
-			Expect(string(ops)).To(Equal("some-ops"))
-
-			Expect(cmd.RunCallCount()).To(Equal(1))
-			_, workingDirectory, args := cmd.RunArgsForCall(0)
-			Expect(workingDirectory).To(Equal(tempDir))
-			Expect(args).To(Equal(expectedArgs))
+		Context("when the cpi config is opted in to", func() {
+			It("points every az at the cpi", func() {
+				incomingState.CPIConfig = true
+				opsGenerator.GenerateCall.Returns.OpsYAML = `
+- type: replace
+  path: /azs/-
+  value:
+    name: z1
+    cloud_properties:
+      zone: some-zone
+`
+
+				cloudConfigYAML, err := manager.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
 
-			Expect(cloudConfigYAML).To(Equal("some-cloud-config"))
+				Expect(cloudConfigYAML).To(ContainSubstring(`azs:
+- cloud_properties:
+    zone: some-zone
+  cpi: gcp
+  name: z1`))
+			})
 		})
 
-		Context("failure cases", func() {
-			Context("when temp dir fails", func() {
-				BeforeEach(func() {
-					cloudconfig.SetTempDir(func(string, string) (string, error) {
-						return "", errors.New("failed to create temp dir")
-					})
-				})
+		Context("when the user provided a cloud-config ops file", func() {
+			BeforeEach(func() {
+				incomingState.CloudConfigOpsFile = `
+- type: replace
+  path: /compilation/workers
+  value: 10
+`
+			})
 
-				AfterEach(func() {
-					cloudconfig.ResetTempDir()
-				})
+			It("applies the user ops after the generated ops", func() {
+				cloudConfigYAML, err := manager.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
 
-				It("returns an error", func() {
-					_, err := manager.Generate(storage.State{})
-					Expect(err).To(MatchError("failed to create temp dir"))
-				})
+				Expect(cloudConfigYAML).To(gomegamatchers.MatchYAML(strings.Replace(expectedCloudConfig, "workers: 6", "workers: 10", 1)))
 			})
 
-			Context("when write file fails to write cloud-config.yml", func() {
-				BeforeEach(func() {
-					cloudconfig.SetWriteFile(func(filename string, body []byte, mode os.FileMode) error {
-						if strings.Contains(filename, "cloud-config.yml") {
-							return errors.New("failed to write file")
-						}
-						return nil
-					})
-				})
-
-				AfterEach(func() {
-					cloudconfig.ResetWriteFile()
-				})
+			It("returns an error when the user ops cannot be applied", func() {
+				incomingState.CloudConfigOpsFile = `
+- type: remove
+  path: /missing-key
+`
 
-				It("returns an error", func() {
-					_, err := manager.Generate(storage.State{})
-					Expect(err).To(MatchError("failed to write file"))
-				})
+				_, err := manager.Generate(incomingState)
+				Expect(err).To(MatchError(`error applying cloud-config ops-file: Error applying operation 0 (remove /missing-key): Expected to find a map key "missing-key"`))
 			})
+		})
 
+		Context("failure cases", func() {
 			Context("when ops generator fails to generate", func() {
 				BeforeEach(func() {
 					opsGenerator.GenerateCall.Returns.Error = errors.New("failed to generate")
@@ -159,35 +131,202 @@ var _ = Describe("Manager", func() {
 				})
 			})
 
-			Context("when write file fails to write ops.yml", func() {
+			Context("when the ops cannot be applied", func() {
 				BeforeEach(func() {
-					cloudconfig.SetWriteFile(func(filename string, body []byte, mode os.FileMode) error {
-						if strings.Contains(filename, "ops.yml") {
-							return errors.New("failed to write file")
-						}
-						return nil
-					})
-				})
-
-				AfterEach(func() {
-					cloudconfig.ResetWriteFile()
+					opsGenerator.GenerateCall.Returns.OpsYAML = `
+- type: remove
+  path: /missing-key
+`
 				})
 
 				It("returns an error", func() {
 					_, err := manager.Generate(storage.State{})
-					Expect(err).To(MatchError("failed to write file"))
+					Expect(err).To(MatchError(`Error applying operation 0 (remove /missing-key): Expected to find a map key "missing-key"`))
 				})
 			})
+		})
+	})
 
-			Context("when command fails to run", func() {
-				BeforeEach(func() {
-					cmd.RunReturns(errors.New("failed to run"))
-				})
+	Describe("GenerateRuntimeConfig", func() {
+		It("returns the bosh dns runtime config from bosh-deployment", func() {
+			runtimeConfig, err := manager.GenerateRuntimeConfig(incomingState)
+			Expect(err).NotTo(HaveOccurred())
 
-				It("returns an error", func() {
-					_, err := manager.Generate(storage.State{})
-					Expect(err).To(MatchError("failed to run"))
-				})
+			Expect(runtimeConfig).To(ContainSubstring("url: https://bosh.io/d/github.com/cloudfoundry/dns-release"))
+			Expect(runtimeConfig).To(ContainSubstring("addons:"))
+		})
+	})
+
+	Describe("GenerateCPIConfig", func() {
+		It("returns a google cpi config for gcp", func() {
+			incomingState.GCP = storage.GCP{
+				ProjectID:         "some-project-id",
+				ServiceAccountKey: `{"some": "key"}`,
+			}
+
+			cpiConfig, err := manager.GenerateCPIConfig(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cpiConfig).To(gomegamatchers.MatchYAML(`
+cpis:
+- name: gcp
+  type: google
+  properties:
+    project: some-project-id
+    json_key: '{"some": "key"}'
+`))
+		})
+
+		It("leaves the key out of the google cpi config when the director has a service account", func() {
+			incomingState.GCP = storage.GCP{
+				ProjectID:                     "some-project-id",
+				ApplicationDefaultCredentials: true,
+				DirectorServiceAccount:        true,
+			}
+
+			cpiConfig, err := manager.GenerateCPIConfig(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cpiConfig).To(gomegamatchers.MatchYAML(`
+cpis:
+- name: gcp
+  type: google
+  properties:
+    project: some-project-id
+`))
+		})
+
+		It("returns an aws cpi config for aws", func() {
+			incomingState.IAAS = "aws"
+			incomingState.AWS = storage.AWS{
+				AccessKeyID:     "some-access-key-id",
+				SecretAccessKey: "some-secret-access-key",
+				Region:          "some-region",
+			}
+			incomingState.KeyPair.Name = "some-key-name"
+			terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+				"bosh_security_group": "some-security-group",
+			}
+
+			cpiConfig, err := manager.GenerateCPIConfig(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(terraformManager.GetOutputsCall.Receives.BBLState).To(Equal(incomingState))
+			Expect(cpiConfig).To(gomegamatchers.MatchYAML(`
+cpis:
+- name: aws
+  type: aws
+  properties:
+    access_key_id: some-access-key-id
+    secret_access_key: some-secret-access-key
+    region: some-region
+    default_key_name: some-key-name
+    default_security_groups: [some-security-group]
+`))
+		})
+
+		It("uses the director's instance profile in the aws cpi config when there are no static keys", func() {
+			incomingState.IAAS = "aws"
+			incomingState.AWS = storage.AWS{
+				AssumeRoleARN: "some-role-arn",
+				Region:        "some-region",
+			}
+			incomingState.KeyPair.Name = "some-key-name"
+			terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+				"bosh_security_group": "some-security-group",
+			}
+
+			cpiConfig, err := manager.GenerateCPIConfig(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cpiConfig).To(gomegamatchers.MatchYAML(`
+cpis:
+- name: aws
+  type: aws
+  properties:
+    credentials_source: env_or_profile
+    region: some-region
+    default_key_name: some-key-name
+    default_security_groups: [some-security-group]
+`))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the terraform outputs cannot be retrieved", func() {
+				incomingState.IAAS = "aws"
+				terraformManager.GetOutputsCall.Returns.Error = errors.New("failed to get terraform outputs")
+
+				_, err := manager.GenerateCPIConfig(incomingState)
+				Expect(err).To(MatchError("failed to get terraform outputs"))
+			})
+
+			It("returns an error when the iaas is not supported", func() {
+				incomingState.IAAS = "some-iaas"
+
+				_, err := manager.GenerateCPIConfig(incomingState)
+				Expect(err).To(MatchError(`cpi config is not supported for iaas "some-iaas"`))
+			})
+		})
+	})
+
+	Describe("Diff", func() {
+		It("returns the changes to the bbl cloud config on the director", func() {
+			boshClient.LatestConfigCall.Returns.Configs = map[string]bosh.Config{
+				"bbl": {ID: "some-id", Content: strings.Replace(expectedCloudConfig, "network: some-network", "network: some-old-network", 1)},
+			}
+
+			diff, err := manager.Diff(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(boshClient.LatestConfigCall.Receives.Types).To(Equal([]string{"cloud"}))
+			Expect(boshClient.LatestConfigCall.Receives.Names).To(Equal([]string{"bbl"}))
+			Expect(diff).To(Equal(`@@ -1,7 +1,7 @@
+  azs: []
+  compilation:
+    az: z1
+-   network: some-old-network
++   network: some-network
+    reuse_compilation_vms: true
+    vm_extensions:
+    - 100GB_ephemeral_disk`))
+		})
+
+		It("returns every line as added when the director has no bbl cloud config", func() {
+			cloudConfigYAML, err := manager.Generate(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+			lineCount := strings.Count(strings.TrimSuffix(cloudConfigYAML, "\n"), "\n") + 1
+
+			diff, err := manager.Diff(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(diff).To(HavePrefix(fmt.Sprintf("@@ -1,0 +1,%d @@\n+ ", lineCount)))
+			Expect(strings.Count(diff, "\n+ ")).To(Equal(lineCount))
+		})
+
+		It("returns an empty diff when nothing has changed", func() {
+			cloudConfigYAML, err := manager.Generate(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			boshClient.LatestConfigCall.Returns.Configs = map[string]bosh.Config{
+				"bbl": {ID: "some-id", Content: cloudConfigYAML},
+			}
+
+			diff, err := manager.Diff(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diff).To(BeEmpty())
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the cloud config fails to generate", func() {
+				opsGenerator.GenerateCall.Returns.Error = errors.New("failed to generate")
+				_, err := manager.Diff(incomingState)
+				Expect(err).To(MatchError("failed to generate"))
+			})
+
+			It("returns an error when the latest config cannot be fetched", func() {
+				boshClient.LatestConfigCall.Returns.Error = errors.New("failed to get config")
+				_, err := manager.Diff(incomingState)
+				Expect(err).To(MatchError("failed to get config"))
 			})
 		})
 	})
@@ -200,138 +339,148 @@ var _ = Describe("Manager", func() {
 				Expect(logger.StepCall.Messages).To(Equal([]string{
 					"generating cloud config",
 					"applying cloud config",
+					"applying runtime config",
 				}))
 			})
 
 			It("updates the bosh director with a cloud config provided a valid bbl state", func() {
+				incomingState.NoRuntimeConfig = true
+
 				err := manager.Update(incomingState)
 				Expect(err).NotTo(HaveOccurred())
 
-				Expect(boshClientProvider.ClientCall.Receives.DirectorAddress).To(Equal("some-director-address"))
-				Expect(boshClientProvider.ClientCall.Receives.DirectorUsername).To(Equal("some-director-username"))
-				Expect(boshClientProvider.ClientCall.Receives.DirectorPassword).To(Equal("some-director-password"))
+				Expect(boshClientProvider.ClientCall.Receives.State).To(Equal(incomingState))
 
-				Expect(boshClient.UpdateCloudConfigCall.Receives.Yaml).To(Equal([]byte("some-cloud-config")))
+				Expect(boshClient.UpdateConfigCall.Receives.Type).To(Equal("cloud"))
+				Expect(boshClient.UpdateConfigCall.Receives.Name).To(Equal("bbl"))
+				Expect(boshClient.UpdateConfigCall.Receives.Content).To(gomegamatchers.MatchYAML(expectedCloudConfig))
 			})
 
-			Context("failure cases", func() {
-				Context("when manager generate's command fails to run", func() {
-					BeforeEach(func() {
-						cmd.RunReturns(errors.New("failed to run"))
-					})
+			Context("when the bbl cloud config has not been applied before", func() {
+				It("removes the default cloud config applied by a previous version of bbl", func() {
+					boshClient.LatestConfigCall.Returns.Configs = map[string]bosh.Config{
+						"default": {ID: "some-id", Content: expectedCloudConfig},
+					}
 
-					It("returns an error", func() {
-						err := manager.Update(storage.State{})
-						Expect(err).To(MatchError("failed to run"))
-					})
+					err := manager.Update(incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(boshClient.LatestConfigCall.Receives.Names).To(Equal([]string{"bbl", "default"}))
+					Expect(boshClient.DeleteConfigCall.CallCount).To(Equal(1))
+					Expect(boshClient.DeleteConfigCall.Receives.Type).To(Equal("cloud"))
+					Expect(boshClient.DeleteConfigCall.Receives.Name).To(Equal("default"))
+					Expect(logger.StepCall.Messages).To(ContainElement("removing default cloud config applied by a previous version of bbl"))
 				})
 
-				Context("when bosh client fails to update cloud config", func() {
-					BeforeEach(func() {
-						boshClient.UpdateCloudConfigCall.Returns.Error = errors.New("failed to update")
-					})
+				It("keeps a default cloud config that bbl did not generate and warns about it", func() {
+					boshClient.LatestConfigCall.Returns.Configs = map[string]bosh.Config{
+						"default": {ID: "some-id", Content: "some-other-cloud-config: {}"},
+					}
 
-					It("returns an error", func() {
-						err := manager.Update(storage.State{})
-						Expect(err).To(MatchError("failed to update"))
-					})
+					err := manager.Update(incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(boshClient.DeleteConfigCall.CallCount).To(Equal(0))
+					Expect(logger.PrintlnCall.Receives.Message).To(ContainSubstring("warning: the director has a default cloud config that bbl did not generate"))
+					Expect(boshClient.UpdateConfigCall.Receives.Name).To(Equal("bbl"))
 				})
-			})
-		})
 
-		Context("when a jumpbox exists", func() {
-			var (
-				socks5Network string
-				socks5Addr    string
-				socks5Auth    *proxy.Auth
-				socks5Forward proxy.Dialer
-				socks5Client  *fakes.Socks5Client
-			)
+				It("does nothing when there is no default cloud config", func() {
+					err := manager.Update(incomingState)
+					Expect(err).NotTo(HaveOccurred())
 
-			BeforeEach(func() {
-				incomingState.Jumpbox.Enabled = true
-				terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
-					"external_ip": "some-external-url",
-				}
-				sshKeyGetter.GetCall.Returns.PrivateKey = "some-private-key"
-
-				socks5Client = &fakes.Socks5Client{}
-				cloudconfig.SetProxySOCKS5(func(network, addr string, auth *proxy.Auth, forward proxy.Dialer) (proxy.Dialer, error) {
-					socks5Network = network
-					socks5Addr = addr
-					socks5Auth = auth
-					socks5Forward = forward
-
-					return socks5Client, nil
+					Expect(boshClient.DeleteConfigCall.CallCount).To(Equal(0))
+					Expect(logger.PrintlnCall.CallCount).To(Equal(0))
 				})
 			})
 
-			AfterEach(func() {
-				cloudconfig.ResetProxySOCKS5()
-			})
-
-			It("logs steps taken", func() {
+			It("uploads the bosh dns runtime config", func() {
 				err := manager.Update(incomingState)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(logger.StepCall.Messages).To(Equal([]string{
-					"starting socks5 proxy",
-					"generating cloud config",
-					"applying cloud config",
-				}))
+
+				Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(2))
+				Expect(boshClient.UpdateConfigCall.Receives.Type).To(Equal("runtime"))
+				Expect(boshClient.UpdateConfigCall.Receives.Name).To(Equal("bbl"))
+				Expect(string(boshClient.UpdateConfigCall.Receives.Content)).To(ContainSubstring("name: dns"))
 			})
 
-			It("starts a socks5 proxy", func() {
-				err := manager.Update(incomingState)
-				Expect(err).NotTo(HaveOccurred())
-				Expect(sshKeyGetter.GetCall.Receives.State).To(Equal(incomingState))
-				Expect(terraformManager.GetOutputsCall.Receives.BBLState).To(Equal(incomingState))
+			Context("when the runtime config is opted out of", func() {
+				It("only uploads the cloud config", func() {
+					incomingState.NoRuntimeConfig = true
 
-				Expect(socks5Proxy.StartCall.CallCount).To(Equal(1))
-				Expect(socks5Proxy.StartCall.Receives.JumpboxPrivateKey).To(Equal("some-private-key"))
-				Expect(socks5Proxy.StartCall.Receives.JumpboxExternalURL).To(Equal("some-external-url:22"))
+					err := manager.Update(incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(1))
+					Expect(boshClient.UpdateConfigCall.Receives.Type).To(Equal("cloud"))
+				})
 			})
 
-			It("configures the bosh client", func() {
-				socks5Proxy.AddrCall.Returns.Addr = "some-socks-proxy-addr"
-				err := manager.Update(incomingState)
-				Expect(err).NotTo(HaveOccurred())
+			Context("when the cpi config is opted in to", func() {
+				It("uploads the cpi config before the cloud config", func() {
+					incomingState.CPIConfig = true
+					incomingState.NoRuntimeConfig = true
 
-				Expect(boshClient.ConfigureHTTPClientCall.CallCount).To(Equal(1))
-				Expect(boshClient.ConfigureHTTPClientCall.Receives.Socks5Client).To(Equal(socks5Client))
+					err := manager.Update(incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(2))
+					Expect(logger.StepCall.Messages).To(Equal([]string{
+						"generating cpi config",
+						"applying cpi config",
+						"generating cloud config",
+						"applying cloud config",
+					}))
+				})
+			})
 
-				Expect(socks5Proxy.AddrCall.CallCount).To(Equal(1))
+			Context("when the bbl cloud config has been applied before", func() {
+				It("does not look at the default cloud config", func() {
+					boshClient.LatestConfigCall.Returns.Configs = map[string]bosh.Config{
+						"bbl":     {ID: "some-id", Content: expectedCloudConfig},
+						"default": {ID: "some-other-id", Content: expectedCloudConfig},
+					}
 
-				Expect(socks5Network).To(Equal("tcp"))
-				Expect(socks5Addr).To(Equal("some-socks-proxy-addr"))
-				Expect(socks5Auth).To(BeNil())
-				Expect(socks5Forward).To(Equal(proxy.Direct))
+					err := manager.Update(incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(boshClient.LatestConfigCall.Receives.Names).To(Equal([]string{"bbl"}))
+					Expect(boshClient.DeleteConfigCall.CallCount).To(Equal(0))
+				})
 			})
 
 			Context("failure cases", func() {
-				It("returns an error when sshKeyGetter.Get fails", func() {
-					sshKeyGetter.GetCall.Returns.Error = errors.New("failed to get jumpbox ssh key")
-					err := manager.Update(incomingState)
-					Expect(err).To(MatchError("failed to get jumpbox ssh key"))
-				})
+				Context("when the cloud config fails to generate", func() {
+					BeforeEach(func() {
+						opsGenerator.GenerateCall.Returns.Error = errors.New("failed to generate")
+					})
 
-				It("returns an error when terraformManager.GetOutputs fails", func() {
-					terraformManager.GetOutputsCall.Returns.Error = errors.New("failed to get terraform outputs")
-					err := manager.Update(incomingState)
-					Expect(err).To(MatchError("failed to get terraform outputs"))
+					It("returns an error", func() {
+						err := manager.Update(storage.State{})
+						Expect(err).To(MatchError("failed to generate"))
+					})
 				})
 
-				It("returns an error when the socks5Proxy fails to start", func() {
-					socks5Proxy.StartCall.Returns.Error = errors.New("failed to start socks5 proxy")
-					err := manager.Update(incomingState)
-					Expect(err).To(MatchError("failed to start socks5 proxy"))
+				Context("when bosh client fails to update cloud config", func() {
+					BeforeEach(func() {
+						boshClient.UpdateConfigCall.Returns.Error = errors.New("failed to update")
+					})
+
+					It("returns an error", func() {
+						err := manager.Update(storage.State{})
+						Expect(err).To(MatchError("failed to update"))
+					})
 				})
 
-				It("returns an error when it cannot create a socks5 proxy client", func() {
-					cloudconfig.SetProxySOCKS5(func(network, addr string, auth *proxy.Auth, forward proxy.Dialer) (proxy.Dialer, error) {
-						return nil, errors.New("failed to create socks5 proxy client")
+				Context("when the bosh client cannot be created", func() {
+					BeforeEach(func() {
+						boshClientProvider.ClientCall.Returns.Error = errors.New("failed to start socks5 proxy")
+					})
+
+					It("returns an error", func() {
+						err := manager.Update(incomingState)
+						Expect(err).To(MatchError("failed to start socks5 proxy"))
 					})
-					err := manager.Update(incomingState)
-					Expect(err).To(MatchError("failed to create socks5 proxy client"))
 				})
 			})
 		})
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	AuditCommand = "audit"
+
+	auditDateFormat = "2006-01-02"
+)
+
+type auditLog interface {
+	Entries() ([]storage.AuditEntry, error)
+}
+
+type Audit struct {
+	logger   logger
+	auditLog auditLog
+}
+
+type auditConfig struct {
+	command   string
+	user      string
+	since     string
+	printJSON bool
+}
+
+func NewAudit(logger logger, auditLog auditLog) Audit {
+	return Audit{
+		logger:   logger,
+		auditLog: auditLog,
+	}
+}
+
+// CheckFastFails does not validate the state, so that the audit log of a
+// destroyed environment can still be read.
+func (a Audit) CheckFastFails(subcommandFlags []string, state storage.State) error {
+	config, err := a.parseFlags(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	_, err = parseAuditSince(config.since)
+	return err
+}
+
+func (a Audit) Execute(subcommandFlags []string, state storage.State) error {
+	config, err := a.parseFlags(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	since, err := parseAuditSince(config.since)
+	if err != nil {
+		return err
+	}
+
+	entries, err := a.auditLog.Entries()
+	if err != nil {
+		return err
+	}
+
+	matching := []storage.AuditEntry{}
+	for _, entry := range entries {
+		switch {
+		case config.command != "" && entry.Command != config.command:
+		case config.user != "" && entry.User != config.user:
+		case entry.StartedAt.Before(since):
+		default:
+			matching = append(matching, entry)
+		}
+	}
+
+	if config.printJSON {
+		output, err := json.Marshal(matching)
+		if err != nil {
+			return err //not tested
+		}
+		a.logger.Println(string(output))
+		return nil
+	}
+
+	table := bytes.NewBuffer([]byte{})
+	writer := tabwriter.NewWriter(table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "STARTED\tCOMMAND\tUSER\tHOST\tDURATION\tOUTCOME")
+	for _, entry := range matching {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.StartedAt.Format(time.RFC3339),
+			strings.Join(append([]string{entry.Command}, entry.Flags...), " "),
+			entry.User,
+			entry.Host,
+			entry.FinishedAt.Sub(entry.StartedAt).Round(time.Second),
+			entry.Outcome,
+		)
+	}
+	writer.Flush()
+	a.logger.Println(strings.TrimSuffix(table.String(), "\n"))
+
+	return nil
+}
+
+func (a Audit) Flags() flags.Flags {
+	return a.flags(&auditConfig{})
+}
+
+func (Audit) flags(config *auditConfig) flags.Flags {
+	auditFlags := flags.New("audit")
+	auditFlags.String(&config.command, "command", "")
+	auditFlags.String(&config.user, "user", "")
+	auditFlags.String(&config.since, "since", "")
+	auditFlags.Bool(&config.printJSON, "", "json", false)
+
+	return auditFlags
+}
+
+func (a Audit) parseFlags(subcommandFlags []string) (auditConfig, error) {
+	var config auditConfig
+	err := a.flags(&config).Parse(subcommandFlags)
+	if err != nil {
+		return auditConfig{}, err
+	}
+
+	return config, nil
+}
+
+func parseAuditSince(since string) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+
+	for _, format := range []string{time.RFC3339, auditDateFormat} {
+		t, err := time.Parse(format, since)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("--since must be a date like %s or a time like %s", auditDateFormat, time.RFC3339)
+}
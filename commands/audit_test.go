@@ -0,0 +1,119 @@
+package commands_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Audit", func() {
+	var (
+		logger   *fakes.Logger
+		auditLog *fakes.AuditLog
+		audit    commands.Audit
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		auditLog = &fakes.AuditLog{}
+
+		startedAt := time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+		auditLog.EntriesCall.Returns.Entries = []storage.AuditEntry{
+			{
+				Command:    "up",
+				Flags:      []string{"--iaas", "gcp"},
+				User:       "some-user",
+				Host:       "some-host",
+				StartedAt:  startedAt,
+				FinishedAt: startedAt.Add(90 * time.Second),
+				Outcome:    "success",
+			},
+			{
+				Command:    "rotate",
+				User:       "other-user",
+				Host:       "other-host",
+				StartedAt:  startedAt.Add(48 * time.Hour),
+				FinishedAt: startedAt.Add(48*time.Hour + 10*time.Second),
+				Outcome:    "failure",
+				Error:      "some-error",
+			},
+		}
+
+		audit = commands.NewAudit(logger, auditLog)
+	})
+
+	Describe("CheckFastFails", func() {
+		It("does not require an environment", func() {
+			err := audit.CheckFastFails([]string{}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error when --since is not a date", func() {
+			err := audit.CheckFastFails([]string{"--since", "yesterday"}, storage.State{})
+			Expect(err).To(MatchError("--since must be a date like 2006-01-02 or a time like 2006-01-02T15:04:05Z07:00"))
+		})
+	})
+
+	Describe("Execute", func() {
+		It("prints a table of the recorded commands", func() {
+			err := audit.Execute([]string{}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Receives.Message).To(Equal(
+				"STARTED               COMMAND        USER        HOST        DURATION  OUTCOME\n" +
+					"2017-08-01T12:00:00Z  up --iaas gcp  some-user   some-host   1m30s     success\n" +
+					"2017-08-03T12:00:00Z  rotate         other-user  other-host  10s       failure",
+			))
+		})
+
+		It("filters the entries by command, user and start time", func() {
+			err := audit.Execute([]string{"--command", "rotate", "--user", "other-user", "--since", "2017-08-02"}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Receives.Message).To(ContainSubstring("rotate"))
+			Expect(logger.PrintlnCall.Receives.Message).NotTo(ContainSubstring("up --iaas gcp"))
+
+			err = audit.Execute([]string{"--user", "some-user", "--since", "2017-08-02T00:00:00Z"}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Receives.Message).To(Equal("STARTED  COMMAND  USER  HOST  DURATION  OUTCOME"))
+		})
+
+		It("prints the entries as json when --json is passed", func() {
+			err := audit.Execute([]string{"--json", "--command", "rotate"}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Receives.Message).To(MatchJSON(`[{
+				"command": "rotate",
+				"flags": null,
+				"user": "other-user",
+				"host": "other-host",
+				"bblVersion": "",
+				"startedAt": "2017-08-03T12:00:00Z",
+				"finishedAt": "2017-08-03T12:00:10Z",
+				"outcome": "failure",
+				"error": "some-error"
+			}]`))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the audit log cannot be read", func() {
+				auditLog.EntriesCall.Returns.Error = errors.New("failed to read")
+
+				err := audit.Execute([]string{}, storage.State{})
+				Expect(err).To(MatchError("failed to read"))
+			})
+
+			It("returns an error when the flags cannot be parsed", func() {
+				err := audit.Execute([]string{"--unknown-flag"}, storage.State{})
+				Expect(err).To(MatchError("flag provided but not defined: -unknown-flag"))
+			})
+		})
+	})
+})
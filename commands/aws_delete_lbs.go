@@ -17,6 +17,7 @@ type AWSDeleteLBs struct {
 
 type deleteLBsConfig struct {
 	skipIfMissing bool
+	confirmEnvID  string
 }
 
 func NewAWSDeleteLBs(credentialValidator credentialValidator,
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws"
@@ -50,12 +51,19 @@ type configProvider interface {
 type cloudConfigManager interface {
 	Update(state storage.State) error
 	Generate(state storage.State) (string, error)
+	Diff(state storage.State) (string, error)
+	GenerateRuntimeConfig(state storage.State) (string, error)
+	GenerateCPIConfig(state storage.State) (string, error)
 }
 
 type brokenEnvironmentValidator interface {
 	Validate(state storage.State) error
 }
 
+type natAMIResolver interface {
+	Resolve() (string, error)
+}
+
 type AWSUp struct {
 	credentialValidator        credentialValidator
 	keyPairManager             keyPairManager
@@ -66,17 +74,32 @@ type AWSUp struct {
 	envIDManager               envIDManager
 	terraformManager           terraformApplier
 	brokenEnvironmentValidator brokenEnvironmentValidator
+	natAMIResolver             natAMIResolver
+	stemcellUploader           stemcellUploader
+	logger                     logger
+	envIDConfirmer             envIDConfirmer
+	bblVersion                 string
 }
 
 type AWSUpConfig struct {
 	AccessKeyID     string
 	SecretAccessKey string
+	Profile         string
+	CredentialChain bool
+	AssumeRoleARN   string
+	ExternalID      string
+	SessionName     string
 	Region          string
 	OpsFilePath     string
 	BOSHAZ          string
+	BOSHAZs         []string
+	NATType         string
+	NATAMI          string
 	Name            string
 	NoDirector      bool
 	Terraform       bool
+	FromStep        string
+	ConfirmEnvID    string
 }
 
 func NewAWSUp(
@@ -84,7 +107,9 @@ func NewAWSUp(
 	boshManager boshManager,
 	cloudConfigManager cloudConfigManager,
 	stateStore stateStore, configProvider configProvider, envIDManager envIDManager,
-	terraformManager terraformApplier, brokenEnvironmentValidator brokenEnvironmentValidator) AWSUp {
+	terraformManager terraformApplier, brokenEnvironmentValidator brokenEnvironmentValidator,
+	natAMIResolver natAMIResolver, stemcellUploader stemcellUploader, logger logger, envIDConfirmer envIDConfirmer,
+	bblVersion string) AWSUp {
 
 	return AWSUp{
 		credentialValidator:        credentialValidator,
@@ -96,6 +121,11 @@ func NewAWSUp(
 		envIDManager:               envIDManager,
 		terraformManager:           terraformManager,
 		brokenEnvironmentValidator: brokenEnvironmentValidator,
+		natAMIResolver:             natAMIResolver,
+		stemcellUploader:           stemcellUploader,
+		logger:                     logger,
+		envIDConfirmer:             envIDConfirmer,
+		bblVersion:                 bblVersion,
 	}
 }
 
@@ -105,6 +135,11 @@ func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
 	if u.awsCredentialsPresent(config) {
 		state.AWS.AccessKeyID = config.AccessKeyID
 		state.AWS.SecretAccessKey = config.SecretAccessKey
+		state.AWS.Profile = config.Profile
+		state.AWS.CredentialChain = config.CredentialChain
+		state.AWS.AssumeRoleARN = config.AssumeRoleARN
+		state.AWS.ExternalID = config.ExternalID
+		state.AWS.SessionName = config.SessionName
 		state.AWS.Region = config.Region
 		if err := u.stateStore.Set(state); err != nil {
 			return err
@@ -112,6 +147,11 @@ func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
 		u.configProvider.SetConfig(aws.Config{
 			AccessKeyID:     config.AccessKeyID,
 			SecretAccessKey: config.SecretAccessKey,
+			Profile:         config.Profile,
+			CredentialChain: config.CredentialChain,
+			AssumeRoleARN:   config.AssumeRoleARN,
+			ExternalID:      config.ExternalID,
+			SessionName:     config.SessionName,
 			Region:          config.Region,
 		})
 	} else if u.awsCredentialsNotPresent(config) {
@@ -136,16 +176,95 @@ func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
 		return err
 	}
 
-	state, err = u.envIDManager.Sync(state, config.Name)
+	journal := newUpJournal(u.logger, u.bblVersion, config.FromStep, state)
+
+	envIDHash, err := journal.hash(envIDInput(config.Name, state))
 	if err != nil {
 		return err
 	}
+	syncEnvID := !journal.skip(EnvIDStep, envIDHash)
 
-	if err := u.stateStore.Set(state); err != nil {
-		return err
+	syncKeyPair := syncEnvID
+	if !syncKeyPair {
+		keyPairHash, err := journal.hash(state.EnvID)
+		if err != nil {
+			return err
+		}
+		syncKeyPair = !journal.skip(KeyPairStep, keyPairHash)
+	}
+
+	switch {
+	case syncEnvID:
+		state = journal.start(state, EnvIDStep)
+	case syncKeyPair:
+		state = journal.start(state, KeyPairStep)
+	}
+
+	state.Stack.BOSHAZ = config.BOSHAZ
+	if config.NATType != "" {
+		state.AWS.NATType = config.NATType
+	}
+
+	if config.NATAMI != "" {
+		state.AWS.NATAMI = config.NATAMI
 	}
 
-	state, err = u.keyPairManager.Sync(state)
+	if len(config.BOSHAZs) > 0 {
+		state.AWS.BOSHAZs = config.BOSHAZs
+	}
+
+	resolveNATAMI := state.AWS.NATAMI == "" && (state.AWS.NATType == "" || state.AWS.NATType == "instance")
+
+	// The keypair is named after the env id, so it is synced once the env
+	// id is, while the NAT AMI is looked up at the same time.
+	var (
+		envIDState   storage.State
+		keyPairState storage.State
+		natAMI       string
+	)
+	steps := []upGraphStep{}
+	if syncEnvID {
+		steps = append(steps, upGraphStep{
+			name: EnvIDStep,
+			run: func() error {
+				var err error
+				envIDState, err = u.envIDManager.Sync(state, config.Name)
+				return err
+			},
+		})
+	}
+	if syncKeyPair {
+		var after []string
+		if syncEnvID {
+			after = []string{EnvIDStep}
+		}
+		steps = append(steps, upGraphStep{
+			name:  KeyPairStep,
+			after: after,
+			run: func() error {
+				keyPairInput := state
+				if syncEnvID {
+					keyPairInput.EnvID = envIDState.EnvID
+				}
+
+				var err error
+				keyPairState, err = u.keyPairManager.Sync(keyPairInput)
+				return err
+			},
+		})
+	}
+	if resolveNATAMI {
+		steps = append(steps, upGraphStep{
+			name: "nat ami",
+			run: func() error {
+				var err error
+				natAMI, err = u.natAMIResolver.Resolve()
+				return err
+			},
+		})
+	}
+
+	err = runUpGraph(u.logger, upWorkers, steps)
 	switch err := err.(type) {
 	case keypair.ManagerError:
 		updatedBBLState := err.BBLState()
@@ -162,14 +281,50 @@ func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
 		return err
 	}
 
+	if syncEnvID {
+		state.EnvID = envIDState.EnvID
+
+		inputHash, err := journal.hash(state.EnvID)
+		if err != nil {
+			return err
+		}
+		state = journal.complete(state, EnvIDStep, inputHash)
+	}
+
+	if syncKeyPair {
+		state.KeyPair = keyPairState.KeyPair
+
+		inputHash, err := journal.hash(state.EnvID)
+		if err != nil {
+			return err
+		}
+		state = journal.complete(state, KeyPairStep, inputHash)
+	}
+
+	if resolveNATAMI {
+		state.AWS.NATAMI = natAMI
+	}
+
 	if err := u.stateStore.Set(state); err != nil {
 		return err
 	}
 
-	state.Stack.BOSHAZ = config.BOSHAZ
-	state, err = u.terraformManager.Apply(state)
+	inputHash, err := journal.hash(terraformInputs(state))
 	if err != nil {
-		return handleTerraformError(err, u.stateStore)
+		return err
+	}
+	if !journal.skip(TerraformStep, inputHash) {
+		err = u.envIDConfirmer.Confirm(state, config.ConfirmEnvID, "apply terraform to it")
+		if err != nil {
+			return err
+		}
+
+		state = journal.start(state, TerraformStep)
+		state, err = u.terraformManager.Apply(state)
+		if err != nil {
+			return handleTerraformError(err, u.stateStore)
+		}
+		state = journal.complete(state, TerraformStep, inputHash)
 	}
 
 	err = u.stateStore.Set(state)
@@ -192,34 +347,113 @@ func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
 		}
 		state.BOSH.UserOpsFile = string(opsFile)
 
-		state, err = u.boshManager.CreateDirector(state, terraformOutputs)
-		switch err.(type) {
-		case bosh.ManagerCreateError:
-			bcErr := err.(bosh.ManagerCreateError)
-			if setErr := u.stateStore.Set(bcErr.State()); setErr != nil {
-				errorList := helpers.Errors{}
-				errorList.Add(err)
-				errorList.Add(setErr)
-				return errorList
-			}
-			return err
-		case error:
+		inputHash, err = journal.hash(terraformOutputs, directorInputs(state))
+		if err != nil {
 			return err
 		}
+		if !journal.skip(DirectorStep, inputHash) {
+			state = journal.start(state, DirectorStep)
+			state, err = u.createDirector(state, terraformOutputs)
+			switch err.(type) {
+			case bosh.ManagerCreateError:
+				bcErr := err.(bosh.ManagerCreateError)
+				if setErr := u.stateStore.Set(bcErr.State()); setErr != nil {
+					errorList := helpers.Errors{}
+					errorList.Add(err)
+					errorList.Add(setErr)
+					return errorList
+				}
+				return err
+			case error:
+				return err
+			}
+			state = journal.complete(state, DirectorStep, inputHash)
 
-		err = u.stateStore.Set(state)
-		if err != nil {
-			return err
+			err = u.stateStore.Set(state)
+			if err != nil {
+				return err
+			}
 		}
 
-		err = u.cloudConfigManager.Update(state)
+		inputHash, err = journal.hash(terraformOutputs, cloudConfigInputs(state))
 		if err != nil {
 			return err
 		}
+		if !journal.skip(CloudConfigStep, inputHash) {
+			state = journal.start(state, CloudConfigStep)
+			err = u.cloudConfigManager.Update(state)
+			if err != nil {
+				return err
+			}
+			state = journal.complete(state, CloudConfigStep, inputHash)
+
+			err = u.stateStore.Set(state)
+			if err != nil {
+				return err
+			}
+		}
+
+		if state.UploadStemcell {
+			err = u.stemcellUploader.Upload(state)
+			if err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
+// createDirector retries director creation in the next bosh availability zone
+// when AWS reports insufficient capacity, as long as no persistent disk has
+// been created yet. A director with a disk has to be moved explicitly.
+func (u AWSUp) createDirector(state storage.State, terraformOutputs map[string]interface{}) (storage.State, error) {
+	primaryAZ, _ := terraformOutputs["bosh_subnet_availability_zone"].(string)
+	triedAZs := map[string]bool{}
+
+	for {
+		directorAZ := state.AWS.DirectorAZ
+		if directorAZ == "" {
+			directorAZ = primaryAZ
+		}
+		triedAZs[directorAZ] = true
+
+		newState, err := u.boshManager.CreateDirector(state, terraformOutputs)
+		bcErr, ok := err.(bosh.ManagerCreateError)
+		if !ok || !bcErr.InsufficientCapacity() {
+			return newState, err
+		}
+
+		failedState := bcErr.State()
+		if diskID, _ := failedState.BOSH.State["current_disk_id"].(string); diskID != "" {
+			return storage.State{}, bosh.NewManagerCreateError(failedState,
+				fmt.Errorf("%s\nThe director has a persistent disk, run `bbl move-director --az <az>` to move it to another availability zone.", err))
+		}
+
+		nextAZ := ""
+		for _, az := range append([]string{primaryAZ}, failedState.AWS.BOSHAZs...) {
+			if !triedAZs[az] {
+				nextAZ = az
+				break
+			}
+		}
+
+		if nextAZ == "" {
+			return storage.State{}, err
+		}
+
+		failedState.AWS.DirectorAZ = nextAZ
+		if nextAZ == primaryAZ {
+			failedState.AWS.DirectorAZ = ""
+		}
+
+		if err := u.stateStore.Set(failedState); err != nil {
+			return storage.State{}, err
+		}
+
+		state = failedState
+	}
+}
+
 func (u AWSUp) checkForFastFails(state storage.State, config AWSUpConfig) error {
 	err := u.brokenEnvironmentValidator.Validate(state)
 	if err != nil {
@@ -230,22 +464,32 @@ func (u AWSUp) checkForFastFails(state storage.State, config AWSUpConfig) error
 		return errors.New("The --aws-bosh-az cannot be changed for existing environments.")
 	}
 
+	switch config.NATType {
+	case "", "instance", "gateway", "gateway-per-az":
+	default:
+		return fmt.Errorf("%q is an invalid nat type, supported values are: [instance, gateway, gateway-per-az]", config.NATType)
+	}
+
 	return nil
 }
 
 func (AWSUp) awsCredentialsPresent(config AWSUpConfig) bool {
-	return config.AccessKeyID != "" && config.SecretAccessKey != "" && config.Region != ""
+	if config.Region == "" {
+		return false
+	}
+
+	return (config.AccessKeyID != "" && config.SecretAccessKey != "") || awsCredentialSourcePresent(config)
 }
 
 func (AWSUp) awsCredentialsNotPresent(config AWSUpConfig) bool {
-	return config.AccessKeyID == "" && config.SecretAccessKey == "" && config.Region == ""
+	return config.AccessKeyID == "" && config.SecretAccessKey == "" && config.Region == "" && !awsCredentialSourcePresent(config)
 }
 
 func (AWSUp) awsMissingCredentials(config AWSUpConfig) error {
 	switch {
-	case config.AccessKeyID == "":
+	case config.AccessKeyID == "" && !awsCredentialSourcePresent(config):
 		return errors.New("AWS access key ID must be provided")
-	case config.SecretAccessKey == "":
+	case config.SecretAccessKey == "" && !awsCredentialSourcePresent(config):
 		return errors.New("AWS secret access key must be provided")
 	case config.Region == "":
 		return errors.New("AWS region must be provided")
@@ -253,3 +497,10 @@ func (AWSUp) awsMissingCredentials(config AWSUpConfig) error {
 
 	return nil
 }
+
+// awsCredentialSourcePresent is true when the credentials come from a
+// profile, the default credential chain or an assumed role rather than from
+// access keys alone.
+func awsCredentialSourcePresent(config AWSUpConfig) bool {
+	return config.Profile != "" || config.CredentialChain || config.AssumeRoleARN != ""
+}
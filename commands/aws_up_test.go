@@ -29,9 +29,15 @@ var _ = Describe("AWSUp", func() {
 			stateStore                 *fakes.StateStore
 			awsClientProvider          *fakes.AWSClientProvider
 			envIDManager               *fakes.EnvIDManager
+			natAMIResolver             *fakes.NATAMIResolver
+			stemcellUploader           *fakes.StemcellUploader
+			envIDConfirmer             *fakes.EnvIDConfirmer
+			logger                     *fakes.Logger
 		)
 
 		BeforeEach(func() {
+			logger = &fakes.Logger{}
+
 			keyPairManager = &fakes.KeyPairManager{}
 			keyPairManager.SyncCall.Returns.State = storage.State{
 				KeyPair: storage.KeyPair{
@@ -90,10 +96,17 @@ var _ = Describe("AWSUp", func() {
 
 			brokenEnvironmentValidator = &fakes.BrokenEnvironmentValidator{}
 
+			natAMIResolver = &fakes.NATAMIResolver{}
+
+			stemcellUploader = &fakes.StemcellUploader{}
+
+			envIDConfirmer = &fakes.EnvIDConfirmer{}
+
 			command = commands.NewAWSUp(
 				credentialValidator, keyPairManager, boshManager,
 				cloudConfigManager, stateStore, awsClientProvider,
 				envIDManager, terraformManager, brokenEnvironmentValidator,
+				natAMIResolver, stemcellUploader, logger, envIDConfirmer, "some-bbl-version",
 			)
 		})
 
@@ -162,7 +175,7 @@ var _ = Describe("AWSUp", func() {
 			Expect(awsClientProvider.SetConfigCall.CallCount).To(Equal(0))
 			Expect(credentialValidator.ValidateCall.CallCount).To(Equal(1))
 
-			Expect(keyPairManager.SyncCall.Receives.State).To(Equal(storage.State{
+			Expect(withoutUpJournal(keyPairManager.SyncCall.Receives.State)).To(Equal(storage.State{
 				IAAS: "aws",
 				AWS: storage.AWS{
 					Region:          "some-aws-region",
@@ -173,7 +186,7 @@ var _ = Describe("AWSUp", func() {
 			}))
 
 			Expect(stateStore.SetCall.CallCount).To(Equal(4))
-			actualState := stateStore.SetCall.Receives[3].State
+			actualState := stateStore.SetCall.Receives[2].State
 			Expect(actualState.KeyPair).To(Equal(storage.KeyPair{
 				Name:       "keypair-bbl-lake-time-stamp",
 				PublicKey:  "some-public-key",
@@ -195,7 +208,7 @@ var _ = Describe("AWSUp", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(terraformManager.ApplyCall.CallCount).To(Equal(1))
-			Expect(terraformManager.ApplyCall.Receives.BBLState).To(Equal(storage.State{
+			Expect(withoutUpJournal(terraformManager.ApplyCall.Receives.BBLState)).To(Equal(storage.State{
 				IAAS: "aws",
 				AWS: storage.AWS{
 					Region:          "some-aws-region",
@@ -211,7 +224,7 @@ var _ = Describe("AWSUp", func() {
 			}))
 
 			Expect(stateStore.SetCall.CallCount).To(Equal(4))
-			Expect(stateStore.SetCall.Receives[2].State).To(Equal(storage.State{
+			Expect(withoutUpJournal(stateStore.SetCall.Receives[1].State)).To(Equal(storage.State{
 				IAAS: "aws",
 				AWS: storage.AWS{
 					Region:          "some-aws-region",
@@ -247,8 +260,8 @@ var _ = Describe("AWSUp", func() {
 					err := command.Execute(commands.AWSUpConfig{}, storage.State{})
 					Expect(err).To(MatchError("cannot apply"))
 
-					Expect(stateStore.SetCall.CallCount).To(Equal(3))
-					Expect(stateStore.SetCall.Receives[2].State).To(Equal(storage.State{
+					Expect(stateStore.SetCall.CallCount).To(Equal(2))
+					Expect(stateStore.SetCall.Receives[1].State).To(Equal(storage.State{
 						TFState: "some-partial-tf-state",
 					}))
 				})
@@ -270,7 +283,6 @@ var _ = Describe("AWSUp", func() {
 							TFState: "some-partial-tf-state",
 						}
 						stateStore.SetCall.Returns = []fakes.SetCallReturn{
-							{},
 							{},
 							{errors.New("failed to set bbl state")},
 						}
@@ -340,7 +352,7 @@ var _ = Describe("AWSUp", func() {
 					Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(0))
 					Expect(terraformManager.ApplyCall.CallCount).To(Equal(1))
 					Expect(keyPairManager.SyncCall.CallCount).To(Equal(1))
-					Expect(stateStore.SetCall.CallCount).To(Equal(4))
+					Expect(stateStore.SetCall.CallCount).To(Equal(3))
 				})
 			})
 
@@ -382,8 +394,8 @@ var _ = Describe("AWSUp", func() {
 			err := command.Execute(commands.AWSUpConfig{}, incomingState)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(terraformManager.GetOutputsCall.Receives.BBLState).To(Equal(incomingState))
-			Expect(boshManager.CreateDirectorCall.Receives.State).To(Equal(incomingState))
+			Expect(withoutUpJournal(terraformManager.GetOutputsCall.Receives.BBLState)).To(Equal(incomingState))
+			Expect(withoutUpJournal(boshManager.CreateDirectorCall.Receives.State)).To(Equal(incomingState))
 		})
 
 		Context("when ops file are passed in via --ops-file flag", func() {
@@ -441,11 +453,250 @@ var _ = Describe("AWSUp", func() {
 			})
 		})
 
+		Context("when bosh failover azs are provided via --aws-bosh-azs flag", func() {
+			It("passes the bosh failover azs to terraform", func() {
+				err := command.Execute(commands.AWSUpConfig{
+					BOSHAZs: []string{"some-failover-az", "other-failover-az"},
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.AWS.BOSHAZs).To(Equal([]string{"some-failover-az", "other-failover-az"}))
+			})
+
+			It("keeps the bosh failover azs from the state when the flag is omitted", func() {
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					AWS: storage.AWS{
+						BOSHAZs: []string{"some-failover-az"},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.AWS.BOSHAZs).To(Equal([]string{"some-failover-az"}))
+			})
+		})
+
+		Describe("director availability zone failover", func() {
+			var (
+				directorAZs    []string
+				diskID         string
+				alwaysFailures bool
+			)
+
+			BeforeEach(func() {
+				directorAZs = []string{}
+				diskID = ""
+				alwaysFailures = false
+
+				terraformManager.ApplyCall.Returns.BBLState.AWS.BOSHAZs = []string{"some-failover-az", "other-failover-az"}
+				terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+					"bosh_subnet_availability_zone": "some-primary-az",
+				}
+
+				boshManager.CreateDirectorCall.Stub = func(state storage.State, terraformOutputs map[string]interface{}) (storage.State, error) {
+					directorAZs = append(directorAZs, state.AWS.DirectorAZ)
+					if len(directorAZs) == 1 || alwaysFailures {
+						state.BOSH.State = map[string]interface{}{
+							"current_disk_id": diskID,
+						}
+						return storage.State{}, bosh.NewManagerCreateInsufficientCapacityError(state, errors.New("InsufficientInstanceCapacity"))
+					}
+
+					state.BOSH.DirectorName = "some-director-name"
+					return state, nil
+				}
+			})
+
+			It("retries director creation in the next bosh availability zone", func() {
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(directorAZs).To(Equal([]string{"", "some-failover-az"}))
+				Expect(stateStore.SetCall.Receives[3].State.AWS.DirectorAZ).To(Equal("some-failover-az"))
+				Expect(cloudConfigManager.UpdateCall.Receives.State.AWS.DirectorAZ).To(Equal("some-failover-az"))
+			})
+
+			Context("when every bosh availability zone lacks capacity", func() {
+				It("returns the error after trying each of them", func() {
+					alwaysFailures = true
+
+					err := command.Execute(commands.AWSUpConfig{}, storage.State{})
+					Expect(err).To(MatchError("InsufficientInstanceCapacity"))
+
+					Expect(directorAZs).To(Equal([]string{"", "some-failover-az", "other-failover-az"}))
+				})
+			})
+
+			Context("when the director already has a persistent disk", func() {
+				It("does not retry and suggests moving the director", func() {
+					diskID = "some-disk-id"
+
+					err := command.Execute(commands.AWSUpConfig{}, storage.State{})
+					Expect(err).To(MatchError("InsufficientInstanceCapacity\nThe director has a persistent disk, run `bbl move-director --az <az>` to move it to another availability zone."))
+
+					Expect(directorAZs).To(Equal([]string{""}))
+					Expect(stateStore.SetCall.Receives[2].State.BOSH.State).To(Equal(map[string]interface{}{
+						"current_disk_id": "some-disk-id",
+					}))
+				})
+			})
+		})
+
+		Context("when a nat type is provided via --aws-nat-type flag", func() {
+			It("passes the nat type to terraform", func() {
+				err := command.Execute(commands.AWSUpConfig{
+					AccessKeyID:     "some-aws-access-key-id",
+					SecretAccessKey: "some-aws-secret-access-key",
+					Region:          "some-aws-region",
+					NATType:         "gateway-per-az",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.AWS.NATType).To(Equal("gateway-per-az"))
+			})
+
+			It("keeps the nat type from the state when the flag is omitted", func() {
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					AWS: storage.AWS{
+						NATType: "gateway",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.AWS.NATType).To(Equal("gateway"))
+			})
+
+			Context("when the nat type is invalid", func() {
+				It("returns an error", func() {
+					err := command.Execute(commands.AWSUpConfig{
+						NATType: "some-bad-nat-type",
+					}, storage.State{})
+					Expect(err).To(MatchError(`"some-bad-nat-type" is an invalid nat type, supported values are: [instance, gateway, gateway-per-az]`))
+				})
+			})
+		})
+
+		Describe("nat ami", func() {
+			It("resolves the latest nat ami and passes it to terraform", func() {
+				natAMIResolver.ResolveCall.Returns.AMI = "some-resolved-ami"
+
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(natAMIResolver.ResolveCall.CallCount).To(Equal(1))
+				Expect(terraformManager.ApplyCall.Receives.BBLState.AWS.NATAMI).To(Equal("some-resolved-ami"))
+			})
+
+			It("uses the nat ami provided via --aws-nat-ami", func() {
+				err := command.Execute(commands.AWSUpConfig{
+					NATAMI: "some-override-ami",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(natAMIResolver.ResolveCall.CallCount).To(Equal(0))
+				Expect(terraformManager.ApplyCall.Receives.BBLState.AWS.NATAMI).To(Equal("some-override-ami"))
+			})
+
+			It("reuses the nat ami recorded in the state", func() {
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					AWS: storage.AWS{
+						NATAMI: "some-recorded-ami",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(natAMIResolver.ResolveCall.CallCount).To(Equal(0))
+				Expect(terraformManager.ApplyCall.Receives.BBLState.AWS.NATAMI).To(Equal("some-recorded-ami"))
+			})
+
+			It("does not resolve a nat ami when a nat gateway is used", func() {
+				err := command.Execute(commands.AWSUpConfig{
+					NATType: "gateway",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(natAMIResolver.ResolveCall.CallCount).To(Equal(0))
+			})
+
+			Context("when the nat ami cannot be resolved", func() {
+				It("returns an error", func() {
+					natAMIResolver.ResolveCall.Returns.Error = errors.New("failed to resolve nat ami")
+
+					err := command.Execute(commands.AWSUpConfig{}, storage.State{})
+					Expect(err).To(MatchError("failed to resolve nat ami"))
+				})
+			})
+		})
+
+		Describe("up journal", func() {
+			var initialState storage.State
+
+			BeforeEach(func() {
+				initialState = storage.State{AWS: terraformManager.ApplyCall.Returns.BBLState.AWS}
+
+				directorState := terraformManager.ApplyCall.Returns.BBLState
+				directorState.BOSH = boshManager.CreateDirectorCall.Returns.State.BOSH
+				boshManager.CreateDirectorCall.Returns.State = directorState
+			})
+
+			It("skips the steps whose inputs have not changed on a re-run", func() {
+				err := command.Execute(commands.AWSUpConfig{}, initialState)
+				Expect(err).NotTo(HaveOccurred())
+
+				lastSavedState := stateStore.SetCall.Receives[len(stateStore.SetCall.Receives)-1].State
+				err = command.Execute(commands.AWSUpConfig{}, lastSavedState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(envIDManager.SyncCall.CallCount).To(Equal(1))
+				Expect(keyPairManager.SyncCall.CallCount).To(Equal(1))
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(1))
+				Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(1))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(1))
+			})
+
+			It("re-runs every step from --from-step", func() {
+				err := command.Execute(commands.AWSUpConfig{}, initialState)
+				Expect(err).NotTo(HaveOccurred())
+
+				lastSavedState := stateStore.SetCall.Receives[len(stateStore.SetCall.Receives)-1].State
+				err = command.Execute(commands.AWSUpConfig{FromStep: "terraform"}, lastSavedState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(keyPairManager.SyncCall.CallCount).To(Equal(1))
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(2))
+				Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(2))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(2))
+			})
+
+			It("confirms the env id of a protected environment before applying terraform", func() {
+				err := command.Execute(commands.AWSUpConfig{ConfirmEnvID: "bbl-lake-time-stamp"}, initialState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(envIDConfirmer.ConfirmCall.CallCount).To(Equal(1))
+				Expect(envIDConfirmer.ConfirmCall.Receives.ConfirmEnvID).To(Equal("bbl-lake-time-stamp"))
+				Expect(envIDConfirmer.ConfirmCall.Receives.Action).To(Equal("apply terraform to it"))
+
+				lastSavedState := stateStore.SetCall.Receives[len(stateStore.SetCall.Receives)-1].State
+				err = command.Execute(commands.AWSUpConfig{}, lastSavedState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(envIDConfirmer.ConfirmCall.CallCount).To(Equal(1))
+			})
+
+			It("does not apply terraform when the env id is not confirmed", func() {
+				envIDConfirmer.ConfirmCall.Returns.Error = errors.New("not confirmed")
+
+				err := command.Execute(commands.AWSUpConfig{}, initialState)
+				Expect(err).To(MatchError("not confirmed"))
+
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(0))
+			})
+		})
+
 		Describe("cloud config", func() {
 			It("updates the bosh director with a cloud config provided an up-to-date state", func() {
 				err := command.Execute(commands.AWSUpConfig{}, storage.State{})
 				Expect(err).NotTo(HaveOccurred())
-				Expect(cloudConfigManager.UpdateCall.Receives.State).To(Equal(storage.State{
+				Expect(withoutUpJournal(cloudConfigManager.UpdateCall.Receives.State)).To(Equal(storage.State{
 					EnvID: "bbl-lake-time-stamp",
 					IAAS:  "aws",
 					KeyPair: storage.KeyPair{
@@ -477,6 +728,32 @@ var _ = Describe("AWSUp", func() {
 			})
 		})
 
+		Describe("stemcell", func() {
+			It("does not upload a stemcell unless opted in to", func() {
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stemcellUploader.UploadCall.CallCount).To(Equal(0))
+			})
+
+			It("uploads the stemcell after updating the cloud config when opted in to", func() {
+				terraformManager.ApplyCall.Returns.BBLState.UploadStemcell = true
+
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stemcellUploader.UploadCall.CallCount).To(Equal(1))
+				Expect(withoutUpJournal(stemcellUploader.UploadCall.Receives.State)).To(Equal(withoutUpJournal(cloudConfigManager.UpdateCall.Receives.State)))
+			})
+
+			It("returns an error when the stemcell fails to upload", func() {
+				terraformManager.ApplyCall.Returns.BBLState.UploadStemcell = true
+				stemcellUploader.UploadCall.Returns.Error = errors.New("failed to upload stemcell")
+
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{})
+				Expect(err).To(MatchError("failed to upload stemcell"))
+			})
+		})
+
 		Describe("reentrant", func() {
 			Context("when the key pair fails to sync", func() {
 				It("saves the keypair name and returns an error", func() {
@@ -488,13 +765,13 @@ var _ = Describe("AWSUp", func() {
 
 					err := command.Execute(commands.AWSUpConfig{}, storage.State{})
 					Expect(err).To(MatchError("error syncing key pair"))
-					Expect(stateStore.SetCall.CallCount).To(Equal(2))
-					Expect(stateStore.SetCall.Receives[1].State.KeyPair.Name).To(Equal("keypair-bbl-lake-time-stamp"))
+					Expect(stateStore.SetCall.CallCount).To(Equal(1))
+					Expect(stateStore.SetCall.Receives[0].State.KeyPair.Name).To(Equal("keypair-bbl-lake-time-stamp"))
 				})
 
 				Context("when it can't save the state", func() {
 					It("returns an error", func() {
-						stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {errors.New("failed to set")}}
+						stateStore.SetCall.Returns = []fakes.SetCallReturn{{errors.New("failed to set")}}
 						keyPairManager.SyncCall.Returns.Error = keypair.NewManagerError(storage.State{
 							KeyPair: storage.KeyPair{
 								Name: "keypair-bbl-lake-time-stamp",
@@ -503,8 +780,8 @@ var _ = Describe("AWSUp", func() {
 
 						err := command.Execute(commands.AWSUpConfig{}, storage.State{})
 						Expect(err).To(MatchError("the following errors occurred:\nerror syncing key pair,\nfailed to set"))
-						Expect(stateStore.SetCall.CallCount).To(Equal(2))
-						Expect(stateStore.SetCall.Receives[1].State.KeyPair.Name).To(Equal("keypair-bbl-lake-time-stamp"))
+						Expect(stateStore.SetCall.CallCount).To(Equal(1))
+						Expect(stateStore.SetCall.Receives[0].State.KeyPair.Name).To(Equal("keypair-bbl-lake-time-stamp"))
 					})
 				})
 			})
@@ -517,7 +794,7 @@ var _ = Describe("AWSUp", func() {
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(stateStore.SetCall.CallCount).To(Equal(4))
-					Expect(stateStore.SetCall.Receives[3].State.IAAS).To(Equal("aws"))
+					Expect(stateStore.SetCall.Receives[2].State.IAAS).To(Equal("aws"))
 				})
 			})
 
@@ -562,6 +839,38 @@ var _ = Describe("AWSUp", func() {
 						}))
 					})
 
+					It("replaces the access keys with a profile and an assumed role", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							Profile:       "some-profile",
+							AssumeRoleARN: "some-role-arn",
+							ExternalID:    "some-external-id",
+							SessionName:   "some-session-name",
+							Region:        "some-aws-region",
+						}, storage.State{
+							AWS: storage.AWS{
+								AccessKeyID:     "old-aws-access-key-id",
+								SecretAccessKey: "old-aws-secret-access-key",
+								Region:          "old-aws-region",
+							},
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(stateStore.SetCall.Receives[1].State.AWS).To(Equal(storage.AWS{
+							Profile:       "some-profile",
+							AssumeRoleARN: "some-role-arn",
+							ExternalID:    "some-external-id",
+							SessionName:   "some-session-name",
+							Region:        "some-aws-region",
+						}))
+						Expect(awsClientProvider.SetConfigCall.Receives.Config).To(Equal(aws.Config{
+							Profile:       "some-profile",
+							AssumeRoleARN: "some-role-arn",
+							ExternalID:    "some-external-id",
+							SessionName:   "some-session-name",
+							Region:        "some-aws-region",
+						}))
+					})
+
 					It("does not override the credentials when they're not passed in", func() {
 						err := command.Execute(commands.AWSUpConfig{}, storage.State{
 							AWS: storage.AWS{
@@ -572,7 +881,7 @@ var _ = Describe("AWSUp", func() {
 						})
 						Expect(err).NotTo(HaveOccurred())
 
-						Expect(stateStore.SetCall.Receives[1].State.AWS).To(Equal(storage.AWS{
+						Expect(stateStore.SetCall.Receives[0].State.AWS).To(Equal(storage.AWS{
 							AccessKeyID:     "aws-access-key-id",
 							SecretAccessKey: "aws-secret-access-key",
 							Region:          "aws-region",
@@ -690,6 +999,11 @@ var _ = Describe("AWSUp", func() {
 				Expect(err).To(MatchError("failed to set state"))
 			})
 
+			It("returns an error when the region is not provided with the default credential chain", func() {
+				err := command.Execute(commands.AWSUpConfig{CredentialChain: true}, storage.State{})
+				Expect(err).To(MatchError("AWS region must be provided"))
+			})
+
 			It("returns an error when only some of the AWS parameters are provided", func() {
 				err := command.Execute(commands.AWSUpConfig{AccessKeyID: "some-key-id", Region: "some-region"}, storage.State{})
 				Expect(err).To(MatchError("AWS secret access key must be provided"))
@@ -724,18 +1038,25 @@ var _ = Describe("AWSUp", func() {
 				It("returns the error and saves the state", func() {
 					err := command.Execute(commands.AWSUpConfig{}, incomingState)
 					Expect(err).To(MatchError("failed to create"))
-					Expect(stateStore.SetCall.CallCount).To(Equal(4))
-					Expect(stateStore.SetCall.Receives[3].State.BOSH.State).To(Equal(expectedBOSHState))
+					Expect(stateStore.SetCall.CallCount).To(Equal(3))
+					Expect(stateStore.SetCall.Receives[2].State.BOSH.State).To(Equal(expectedBOSHState))
 				})
 
 				It("returns a compound error when it fails to save the state", func() {
-					stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {}, {}, {errors.New("state failed to be set")}}
+					stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {}, {errors.New("state failed to be set")}}
 					err := command.Execute(commands.AWSUpConfig{}, incomingState)
 					Expect(err).To(MatchError("the following errors occurred:\nfailed to create,\nstate failed to be set"))
-					Expect(stateStore.SetCall.CallCount).To(Equal(4))
-					Expect(stateStore.SetCall.Receives[3].State.BOSH.State).To(Equal(expectedBOSHState))
+					Expect(stateStore.SetCall.CallCount).To(Equal(3))
+					Expect(stateStore.SetCall.Receives[2].State.BOSH.State).To(Equal(expectedBOSHState))
 				})
 			})
 		})
 	})
 })
+
+// withoutUpJournal drops the journal from a state, for comparing states
+// with the ones returned by the fakes.
+func withoutUpJournal(state storage.State) storage.State {
+	state.UpJournal = nil
+	return state
+}
@@ -1,6 +1,12 @@
 package commands
 
-import "github.com/cloudfoundry/bosh-bootloader/storage"
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
 
 const (
 	CloudConfigCommand = "cloud-config"
@@ -30,6 +36,27 @@ func (c CloudConfig) CheckFastFails(subcommandFlags []string, state storage.Stat
 }
 
 func (c CloudConfig) Execute(args []string, state storage.State) error {
+	var showDiff bool
+	err := c.flags(&showDiff).Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if showDiff {
+		diff, err := c.cloudConfigManager.Diff(state)
+		if err != nil {
+			return err
+		}
+
+		if diff == "" {
+			c.logger.Println("no changes to cloud config")
+			return nil
+		}
+
+		c.logger.Println(diff)
+		return nil
+	}
+
 	contents, err := c.cloudConfigManager.Generate(state)
 	if err != nil {
 		return err
@@ -37,3 +64,30 @@ func (c CloudConfig) Execute(args []string, state storage.State) error {
 	c.logger.Println(string(contents))
 	return nil
 }
+
+func (c CloudConfig) Flags() flags.Flags {
+	var showDiff bool
+	return c.flags(&showDiff)
+}
+
+func (CloudConfig) flags(showDiff *bool) flags.Flags {
+	cloudConfigFlags := flags.New("cloud-config")
+	cloudConfigFlags.Bool(showDiff, "", "diff", false)
+
+	return cloudConfigFlags
+}
+
+func readCloudConfigOpsFile(path string, state storage.State) (storage.State, error) {
+	if path == "" {
+		return state, nil
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return storage.State{}, fmt.Errorf("error reading cloud-config ops-file contents: %v", err)
+	}
+
+	state.CloudConfigOpsFile = string(contents)
+
+	return state, nil
+}
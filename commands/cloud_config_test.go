@@ -55,7 +55,36 @@ var _ = Describe("CloudConfig", func() {
 			Expect(logger.PrintlnCall.Messages).To(ContainElement("some-cloud-config"))
 		})
 
+		Context("when --diff is provided", func() {
+			It("prints the changes to the deployed cloud configuration", func() {
+				cloudConfigManager.DiffCall.Returns.Diff = "some-diff"
+
+				err := cloudConfig.Execute([]string{"--diff"}, state)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cloudConfigManager.DiffCall.Receives.State).To(Equal(state))
+				Expect(cloudConfigManager.GenerateCall.CallCount).To(Equal(0))
+				Expect(logger.PrintlnCall.Messages).To(ContainElement("some-diff"))
+			})
+
+			It("prints a message when there are no changes", func() {
+				err := cloudConfig.Execute([]string{"--diff"}, state)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(logger.PrintlnCall.Messages).To(ContainElement("no changes to cloud config"))
+			})
+
+			It("returns an error when the cloud config manager fails to diff", func() {
+				cloudConfigManager.DiffCall.Returns.Error = errors.New("failed to diff cloud configuration")
+				err := cloudConfig.Execute([]string{"--diff"}, state)
+				Expect(err).To(MatchError("failed to diff cloud configuration"))
+			})
+		})
+
 		Context("failure cases", func() {
+			It("returns an error when invalid flags are provided", func() {
+				err := cloudConfig.Execute([]string{"--invalid-flag"}, state)
+				Expect(err).To(MatchError(ContainSubstring("flag provided but not defined")))
+			})
+
 			It("returns an error when the cloud config manager fails to generate", func() {
 				cloudConfigManager.GenerateCall.Returns.Error = errors.New("failed to generate cloud configuration")
 				err := cloudConfig.Execute([]string{}, state)
@@ -16,21 +16,43 @@ var _ = Describe("Commands Usage", func() {
 				usageText := upCmd.Usage()
 				Expect(usageText).To(Equal(`Deploys BOSH director on an IAAS
 
-  --iaas                     IAAS to deploy your BOSH director onto. Valid options: "gcp", "aws" (Defaults to environment variable BBL_IAAS)
-  [--name]                   Name to assign to your BOSH director (optional, will be randomly generated)
-  [--ops-file]               Path to BOSH ops file (optional)
-  [--jumpbox]                Deploy your BOSH director behind a jumpbox (supported when iaas="gcp")
-  [--no-director]            Skips creating BOSH environment
-
-  --aws-access-key-id        AWS Access Key ID to use (Defaults to environment variable BBL_AWS_ACCESS_KEY_ID)
-  --aws-secret-access-key    AWS Secret Access Key to use (Defaults to environment variable BBL_AWS_SECRET_ACCESS_KEY)
-  --aws-region               AWS Region to use (Defaults to environment variable BBL_AWS_REGION)
-  [--aws-bosh-az]            AWS Availability Zone to use for BOSH director (Defaults to environment variable BBL_AWS_BOSH_AZ)
-
-  --gcp-service-account-key  GCP Service Access Key to use (Defaults to environment variable BBL_GCP_SERVICE_ACCOUNT_KEY)
-  --gcp-project-id           GCP Project ID to use (Defaults to environment variable BBL_GCP_PROJECT_ID)
-  --gcp-zone                 GCP Zone to use for BOSH director (Defaults to environment variable BBL_GCP_ZONE)
-  --gcp-region               GCP Region to use (Defaults to environment variable BBL_GCP_REGION)`))
+  --iaas                                   IAAS to deploy your BOSH director onto. Valid options: "gcp", "aws" (Defaults to environment variable BBL_IAAS)
+  [--config]                               Path to a bbl.yml describing the environment, flags and environment variables take precedence (Defaults to bbl.yml in the state directory)
+  [--name]                                 Name to assign to your BOSH director (optional, will be randomly generated)
+  [--ops-file]                             Path to BOSH ops file (optional)
+  [--cloud-config-ops-file]                Path to an ops file applied to the generated cloud-config (optional)
+  [--no-runtime-config]                    Do not upload the BOSH DNS runtime-config to the director (optional)
+  [--cpi-config]                           Upload a cpi-config for the IaaS and reference it from the cloud-config azs (optional)
+  [--upload-stemcell]                      Upload the IaaS light stemcell once the director is created (optional)
+  [--uaa]                                  Deploy UAA on the director and use it for director users (optional)
+  [--credhub]                              Deploy CredHub, and the UAA it relies on, on the director (optional)
+  [--jumpbox]                              Deploy your BOSH director behind a jumpbox (supported when iaas="gcp")
+  [--tag]                                  Tag every IaaS resource and BOSH-created VM with key=value (repeatable)
+  [--no-director]                          Skips creating BOSH environment
+  [--from-step]                            Run every step of up from this one, even if its inputs have not changed. Valid options: "env-id", "keypair", "terraform", "jumpbox", "director", "cloud-config" (optional)
+  [--confirm-env-id]                       Env id of a protected environment, confirming that terraform may be applied to it (optional)
+
+  --aws-access-key-id                      AWS Access Key ID to use (Defaults to environment variable BBL_AWS_ACCESS_KEY_ID)
+  --aws-secret-access-key                  AWS Secret Access Key to use (Defaults to environment variable BBL_AWS_SECRET_ACCESS_KEY)
+  --aws-region                             AWS Region to use (Defaults to environment variable BBL_AWS_REGION)
+  [--aws-profile]                          AWS shared credentials profile to use instead of access keys (Defaults to environment variable BBL_AWS_PROFILE)
+  [--aws-credential-chain]                 Use the default AWS credential chain instead of access keys (optional)
+  [--aws-assume-role-arn]                  ARN of an AWS role to assume with the credentials (Defaults to environment variable BBL_AWS_ASSUME_ROLE_ARN)
+  [--aws-external-id]                      External ID to assume the AWS role with (Defaults to environment variable BBL_AWS_EXTERNAL_ID)
+  [--aws-session-name]                     Session name to assume the AWS role with (Defaults to environment variable BBL_AWS_SESSION_NAME)
+  [--aws-bosh-az]                          AWS Availability Zone to use for BOSH director (Defaults to environment variable BBL_AWS_BOSH_AZ)
+  [--aws-bosh-azs]                         Comma-separated AWS Availability Zones the BOSH director can fail over to (Defaults to environment variable BBL_AWS_BOSH_AZS)
+  [--aws-nat-type]                         AWS NAT for internal subnet egress. Valid options: "instance", "gateway", "gateway-per-az" (Defaults to environment variable BBL_AWS_NAT_TYPE)
+  [--aws-nat-ami]                          AWS AMI to use for the NAT instance (Defaults to environment variable BBL_AWS_NAT_AMI, otherwise the latest Amazon NAT AMI)
+
+  --gcp-service-account-key                GCP Service Access Key to use (Defaults to environment variable BBL_GCP_SERVICE_ACCOUNT_KEY)
+  [--gcp-application-default-credentials]  Use application default credentials instead of a service account key (optional)
+  [--gcp-impersonate-service-account]      Service account to impersonate with the credentials (Defaults to environment variable BBL_GCP_IMPERSONATE_SERVICE_ACCOUNT)
+  [--gcp-director-service-account]         Create a dedicated service account for the director and attach it to its VM (optional, always used without a service account key)
+  --gcp-project-id                         GCP Project ID to use (Defaults to environment variable BBL_GCP_PROJECT_ID)
+  --gcp-zone                               GCP Zone to use for BOSH director (Defaults to environment variable BBL_GCP_ZONE)
+  --gcp-region                             GCP Region to use (Defaults to environment variable BBL_GCP_REGION)
+  [--gcp-internal-only]                    Create the environment without public IPs, using Cloud NAT for egress. The director is then only reachable from inside the network, so bbl must run there too (supported when iaas="gcp")`))
 			})
 		})
 	})
@@ -42,12 +64,14 @@ var _ = Describe("Commands Usage", func() {
 				usageText := command.Usage()
 				Expect(usageText).To(Equal(`Attaches load balancer(s) with a certificate, key, and optional chain
 
-  --type              Load balancer(s) type. Valid options: "concourse" or "cf"
-  [--cert]            Path to SSL certificate (conditionally required; refer to table below)
-  [--key]             Path to SSL certificate key (conditionally required; refer to table below)
-  [--chain]           Path to SSL certificate chain (optional; applicable if --cert/--key are required; refer to table below)
-  [--domain]          Creates a nameserver with a zone for given domain (supported when type="cf")
-  [--skip-if-exists]  Skips creating load balancer(s) if it is already attached (optional)
+  --type                     Load balancer(s) type. Valid options: "concourse" or "cf"
+  [--cert]                   Path to SSL certificate (conditionally required; refer to table below)
+  [--key]                    Path to SSL certificate key (conditionally required; refer to table below)
+  [--chain]                  Path to SSL certificate chain (optional; applicable if --cert/--key are required; refer to table below)
+  [--domain]                 Creates a nameserver with a zone for given domain (supported when type="cf")
+  [--skip-if-exists]         Skips creating load balancer(s) if it is already attached (optional)
+  [--cloud-config-ops-file]  Path to an ops file applied to the generated cloud-config (optional)
+  [--config]                 Path to a bbl.yml describing the load balancer(s), flags take precedence (Defaults to bbl.yml in the state directory)
 
   --cert/--key requirements:
   ------------------------------
@@ -61,6 +85,44 @@ var _ = Describe("Commands Usage", func() {
 		})
 	})
 
+	Describe("Move Director", func() {
+		Describe("Usage", func() {
+			It("returns string describing usage", func() {
+				command := commands.MoveDirector{}
+				usageText := command.Usage()
+				Expect(usageText).To(Equal(`Recreates the BOSH director in another availability zone, keeping its persistent disk (supported when iaas="aws")
+
+  --az  AWS Availability Zone to move the BOSH director to`))
+			})
+		})
+	})
+
+	Describe("Rotate", func() {
+		Describe("Usage", func() {
+			It("returns string describing usage", func() {
+				command := commands.Rotate{}
+				usageText := command.Usage()
+				Expect(usageText).To(Equal(`Rotates the keypair for BOSH, or the selected credentials
+
+  [--director-credentials]  Regenerates the director passwords and the jumpbox user ssh key on the director (optional)
+  [--jumpbox-credentials]   Regenerates the jumpbox passwords and the jumpbox user ssh key on the jumpbox (optional)`))
+			})
+		})
+	})
+
+	Describe("Rotate Certs", func() {
+		Describe("Usage", func() {
+			It("returns string describing usage", func() {
+				command := commands.RotateCerts{}
+				usageText := command.Usage()
+				Expect(usageText).To(Equal(`Regenerates the BOSH director certificates and redeploys the director
+
+  [--cert]   Name of a certificate variable to rotate, may be repeated. A CA is only rotated when named, together with the certificates it signed (defaults to every certificate that is not a CA, optional)
+  [--check]  Prints the expiry date of every certificate without rotating (optional)`))
+			})
+		})
+	})
+
 	Describe("Update LBs", func() {
 		Describe("Usage", func() {
 			It("returns string describing usage", func() {
@@ -68,11 +130,12 @@ var _ = Describe("Commands Usage", func() {
 				usageText := command.Usage()
 				Expect(usageText).To(Equal(`Updates load balancer(s) with the supplied certificate, key, and optional chain
 
-  --cert               Path to SSL certificate
-  --key                Path to SSL certificate key
-  [--chain]            Path to SSL certificate chain (optional)
-  [--domain]           Updates domain in the nameserver zone (supported when type="cf", optional)
-  [--skip-if-missing]  Skips updating load balancer(s) if it is not attached (optional)`))
+  --cert                     Path to SSL certificate
+  --key                      Path to SSL certificate key
+  [--chain]                  Path to SSL certificate chain (optional)
+  [--domain]                 Updates domain in the nameserver zone (supported when type="cf", optional)
+  [--skip-if-missing]        Skips updating load balancer(s) if it is not attached (optional)
+  [--cloud-config-ops-file]  Path to an ops file applied to the generated cloud-config (optional)`))
 			})
 		})
 	})
@@ -84,7 +147,8 @@ var _ = Describe("Commands Usage", func() {
 				usageText := command.Usage()
 				Expect(usageText).To(Equal(`Deletes load balancer(s)
 
-  [--skip-if-missing]  Skips deleting load balancer(s) if it is not attached (optional)`))
+  [--skip-if-missing]  Skips deleting load balancer(s) if it is not attached (optional)
+  [--confirm-env-id]   Env id of a protected environment, confirming that its load balancer(s) may be deleted (optional)`))
 			})
 		})
 	})
@@ -96,8 +160,10 @@ var _ = Describe("Commands Usage", func() {
 				usageText := command.Usage()
 				Expect(usageText).To(Equal(`Tears down BOSH director infrastructure
 
-  [--no-confirm]       Do not ask for confirmation (optional)
-  [--skip-if-missing]  Gracefully exit if there is no state file (optional)`))
+  [--no-confirm]       Do not ask for confirmation, unless the environment is protected (optional)
+  [--skip-if-missing]  Gracefully exit if there is no state file (optional)
+  [--cascade]          Delete all deployments, orphaned disks, and IaaS resources tagged for the director first (optional)
+  [--confirm-env-id]   Env id of a protected environment, confirming that it may be destroyed (optional)`))
 			})
 		})
 	})
@@ -122,13 +188,38 @@ var _ = Describe("Commands Usage", func() {
 		Entry("director-password", newStateQuery("director password"), "Prints BOSH director password"),
 		Entry("director-username", newStateQuery("director username"), "Prints BOSH director username"),
 		Entry("director-ca-cert", newStateQuery("director ca cert"), "Prints BOSH director CA certificate"),
+		Entry("credhub-server", newStateQuery("credhub server"), "Prints CredHub server address"),
+		Entry("credhub-secret", newStateQuery("credhub secret"), "Prints CredHub admin client secret"),
+		Entry("credhub-ca-cert", newStateQuery("credhub ca cert"), "Prints CredHub and UAA CA certificates"),
+		Entry("uaa-admin-secret", newStateQuery("uaa admin secret"), "Prints UAA admin client secret"),
 		Entry("env-id", newStateQuery("environment id"), "Prints environment ID"),
 		Entry("ssh-key", commands.SSHKey{}, "Prints SSH private key for the jumpbox user. This can be used to ssh to the director/use the director as a gateway host."),
 		Entry("print-env", commands.PrintEnv{}, "Prints required BOSH environment variables"),
 		Entry("latest-error", commands.LatestError{}, "Prints the output from the latest call to terraform"),
 		Entry("bosh-deployment-vars", commands.BOSHDeploymentVars{}, "Prints required variables for BOSH deployment"),
 		Entry("version", commands.Version{}, "Prints version"),
-		Entry("cloud-config", commands.CloudConfig{}, "Prints suggested cloud configuration for BOSH environment"),
+		Entry("cloud-config", commands.CloudConfig{}, `Prints suggested cloud configuration for BOSH environment
+
+  [--diff]  Prints the changes that would be made to the "bbl" cloud config on the director (optional)`),
+		Entry("upload-stemcell", commands.UploadStemcell{}, "Uploads the light stemcell for the IaaS to the BOSH director, unless it is already present"),
+		Entry("runtime-config", commands.RuntimeConfig{}, "Prints the BOSH DNS runtime configuration uploaded to the director"),
+		Entry("cpi-config", commands.CPIConfig{}, "Prints the cpi configuration for the director's IaaS"),
+		Entry("status", commands.Status{}, `Prints health checks for the environment and exits non-zero if any fail
+
+  [--json]  Prints the checks as JSON (optional)`),
+		Entry("audit", commands.Audit{}, `Prints the audit log of the bbl commands run against the environment
+
+  [--command]  Only prints runs of the given command (optional)
+  [--user]     Only prints runs by the given user (optional)
+  [--since]    Only prints runs started at or after the given date or RFC 3339 time (optional)
+  [--json]     Prints the full entries, including flags, versions and step durations, as JSON (optional)`),
+		Entry("completion", commands.Completion{}, `Prints a script that completes bbl commands and flags in the given shell
+
+  <shell>  Shell to complete in. Valid options: "bash", "zsh", "fish"`),
+		Entry("protect", commands.Protect{}, "Protects the environment, so that destroy, delete-lbs and up refuse to change it unless its env id is confirmed"),
+		Entry("unprotect", commands.Unprotect{}, `Removes the protection from the environment
+
+  [--confirm-env-id]  Env id of the environment, confirming that it may be unprotected (optional)`),
 	)
 })
 
@@ -0,0 +1,305 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	CompletionCommand = "completion"
+
+	bashCompletionTemplate = `# bash completion for bbl, generated by "bbl completion bash"
+
+_bbl() {
+	local cur prev command candidates i
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+	command=""
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		case "${COMP_WORDS[i]}" in
+		{{join "|" .GlobalValueFlags}})
+			((i++))
+			;;
+		-*)
+			;;
+		*)
+			command="${COMP_WORDS[i]}"
+			break
+			;;
+		esac
+	done
+
+	case "${command}" in
+{{- range .Commands}}
+	"{{.Name}}")
+{{- if .ValueFlags}}
+		case "${prev}" in
+{{- range .ValueFlags}}
+		--{{.Name}})
+{{- if .Values}}
+			COMPREPLY=($(compgen -W "{{join " " .Values}}" -- "${cur}"))
+{{- else}}
+			COMPREPLY=($(compgen -f -- "${cur}"))
+{{- end}}
+			return
+			;;
+{{- end}}
+		esac
+{{- end}}
+		candidates="{{join " " .Candidates}}"
+		;;
+{{- end}}
+	esac
+
+	COMPREPLY=($(compgen -W "${candidates}" -- "${cur}"))
+}
+
+complete -F _bbl bbl
+`
+
+	zshCompletionTemplate = `# zsh completion for bbl, generated by "bbl completion zsh"
+
+_bbl() {
+	local prev command i
+	local -a candidates
+	prev="${words[CURRENT-1]}"
+
+	command=""
+	for ((i = 2; i < CURRENT; i++)); do
+		case "${words[i]}" in
+		{{join "|" .GlobalValueFlags}})
+			((i++))
+			;;
+		-*)
+			;;
+		*)
+			command="${words[i]}"
+			break
+			;;
+		esac
+	done
+
+	case "${command}" in
+{{- range .Commands}}
+	"{{.Name}}")
+{{- if .ValueFlags}}
+		case "${prev}" in
+{{- range .ValueFlags}}
+		--{{.Name}})
+{{- if .Values}}
+			compadd -- {{join " " .Values}}
+{{- else}}
+			_files
+{{- end}}
+			return
+			;;
+{{- end}}
+		esac
+{{- end}}
+		candidates=({{join " " .Candidates}})
+		;;
+{{- end}}
+	esac
+
+	compadd -- "${candidates[@]}"
+}
+
+compdef _bbl bbl
+`
+
+	fishCompletionTemplate = `# fish completion for bbl, generated by "bbl completion fish"
+
+function __bbl_command
+	set -l tokens (commandline -opc)
+	set -e tokens[1]
+	set -l skip 0
+	for token in $tokens
+		if test $skip -eq 1
+			set skip 0
+			continue
+		end
+		switch $token
+			case {{join " " .GlobalValueFlags}}
+				set skip 1
+			case '-*'
+			case '*'
+				echo $token
+				return
+		end
+	end
+end
+
+function __bbl_no_command
+	set -l command (__bbl_command)
+	test -z "$command"
+end
+
+function __bbl_using_command
+	set -l command (__bbl_command)
+	test "$command" = "$argv[1]"
+end
+
+complete -c bbl -f
+{{- range .Commands}}
+{{- $condition := "__bbl_no_command"}}
+{{- if .Name}}{{$condition = printf "'__bbl_using_command %s'" .Name}}{{end}}
+{{- range .Flags}}
+complete -c bbl -n {{$condition}}{{if .Short}} -s {{.Short}}{{end}} -l {{.Name}}
+{{- if .Values}} -x -a '{{join " " .Values}}'{{else if not .Bool}} -r -F{{end}}
+{{- end}}
+{{- if .Arguments}}
+complete -c bbl -n {{$condition}} -a '{{join " " .Arguments}}'
+{{- end}}
+{{- end}}
+`
+)
+
+var completionTemplates = map[string]string{
+	"bash": bashCompletionTemplate,
+	"zsh":  zshCompletionTemplate,
+	"fish": fishCompletionTemplate,
+}
+
+// CompletionShells are the shells bbl completion generates scripts for.
+var CompletionShells = []string{"bash", "zsh", "fish"}
+
+// flagDefiner is implemented by the commands that take flags, so that
+// their flags can be completed.
+type flagDefiner interface {
+	Flags() flags.Flags
+}
+
+// Completion prints a shell completion script for the commands and their
+// flags. The command set is read when the script is generated, so it may be
+// given before every command has been added to it.
+type Completion struct {
+	logger      logger
+	globalFlags flags.Flags
+	commands    map[string]Command
+}
+
+type completionScript struct {
+	GlobalValueFlags []string
+	Commands         []completionCommand
+}
+
+// completionCommand is a command whose flags and arguments the script
+// completes. The command named "" stands for bbl itself, before a command
+// is given.
+type completionCommand struct {
+	Name       string
+	Flags      []flags.Definition
+	Arguments  []string
+	ValueFlags []flags.Definition
+	Candidates []string
+}
+
+func NewCompletion(logger logger, globalFlags flags.Flags, commands map[string]Command) Completion {
+	return Completion{
+		logger:      logger,
+		globalFlags: globalFlags,
+		commands:    commands,
+	}
+}
+
+func (c Completion) CheckFastFails(subcommandFlags []string, state storage.State) error {
+	_, err := completionTemplate(subcommandFlags)
+	return err
+}
+
+func (c Completion) Execute(subcommandFlags []string, state storage.State) error {
+	text, err := completionTemplate(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("completion").Funcs(template.FuncMap{
+		"join": func(separator string, values []string) string { return strings.Join(values, separator) },
+	}).Parse(text)
+	if err != nil {
+		return err //not tested
+	}
+
+	var script bytes.Buffer
+	err = tmpl.Execute(&script, c.script())
+	if err != nil {
+		return err //not tested
+	}
+
+	c.logger.Println(strings.TrimSuffix(script.String(), "\n"))
+	return nil
+}
+
+func (c Completion) script() completionScript {
+	names := []string{}
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	globalDefinitions := c.globalFlags.Definitions()
+	globalValueFlags := []string{}
+	for _, definition := range globalDefinitions {
+		if !definition.Bool {
+			globalValueFlags = append(globalValueFlags, "--"+definition.Name)
+		}
+	}
+
+	commands := []completionCommand{newCompletionCommand("", globalDefinitions, names)}
+	for _, name := range names {
+		var definitions []flags.Definition
+		if definer, ok := c.commands[name].(flagDefiner); ok {
+			definitions = definer.Flags().Definitions()
+		}
+
+		var arguments []string
+		switch name {
+		case HelpCommand:
+			arguments = names
+		case CompletionCommand:
+			arguments = CompletionShells
+		}
+
+		commands = append(commands, newCompletionCommand(name, definitions, arguments))
+	}
+
+	return completionScript{
+		GlobalValueFlags: globalValueFlags,
+		Commands:         commands,
+	}
+}
+
+func newCompletionCommand(name string, definitions []flags.Definition, arguments []string) completionCommand {
+	command := completionCommand{
+		Name:      name,
+		Flags:     definitions,
+		Arguments: arguments,
+	}
+
+	for _, definition := range definitions {
+		if !definition.Bool {
+			command.ValueFlags = append(command.ValueFlags, definition)
+		}
+		command.Candidates = append(command.Candidates, "--"+definition.Name)
+	}
+	command.Candidates = append(command.Candidates, arguments...)
+
+	return command
+}
+
+func completionTemplate(subcommandFlags []string) (string, error) {
+	if len(subcommandFlags) == 1 {
+		if text, ok := completionTemplates[subcommandFlags[0]]; ok {
+			return text, nil
+		}
+	}
+
+	return "", fmt.Errorf("bbl completion requires a shell, supported values are: [%s]", strings.Join(CompletionShells, ", "))
+}
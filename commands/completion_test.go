@@ -0,0 +1,130 @@
+package commands_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Completion", func() {
+	var (
+		logger     *fakes.Logger
+		completion commands.Completion
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+
+		var stateDir, logFormat string
+		var debug bool
+		globalFlags := flags.New("global")
+		globalFlags.String(&stateDir, "state-dir", "")
+		globalFlags.String(&logFormat, "log-format", "text")
+		globalFlags.Values("log-format", "text", "json")
+		globalFlags.Bool(&debug, "d", "debug", false)
+
+		commandSet := map[string]commands.Command{
+			"help":       commands.Usage{},
+			"status":     commands.Status{},
+			"create-lbs": commands.CreateLBs{},
+		}
+		completion = commands.NewCompletion(logger, globalFlags, commandSet)
+		commandSet["completion"] = completion
+	})
+
+	Describe("CheckFastFails", func() {
+		DescribeTable("accepts the supported shells", func(shell string) {
+			err := completion.CheckFastFails([]string{shell}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+		},
+			Entry("bash", "bash"),
+			Entry("zsh", "zsh"),
+			Entry("fish", "fish"),
+		)
+
+		DescribeTable("returns an error without a supported shell", func(args []string) {
+			err := completion.CheckFastFails(args, storage.State{})
+			Expect(err).To(MatchError("bbl completion requires a shell, supported values are: [bash, zsh, fish]"))
+		},
+			Entry("no shell", []string{}),
+			Entry("an unknown shell", []string{"powershell"}),
+			Entry("more than one shell", []string{"bash", "zsh"}),
+		)
+	})
+
+	Describe("Execute", func() {
+		It("prints a bash script completing the commands, flags and flag values", func() {
+			err := completion.Execute([]string{"bash"}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			script := logger.PrintlnCall.Receives.Message
+			Expect(script).To(HavePrefix(`# bash completion for bbl, generated by "bbl completion bash"`))
+			Expect(script).To(ContainSubstring(`		--log-format|--state-dir)
+			((i++))`))
+			Expect(script).To(ContainSubstring(`	"")
+		case "${prev}" in
+		--log-format)
+			COMPREPLY=($(compgen -W "text json" -- "${cur}"))
+			return
+			;;
+		--state-dir)
+			COMPREPLY=($(compgen -f -- "${cur}"))
+			return
+			;;
+		esac
+		candidates="--debug --log-format --state-dir completion create-lbs help status"
+		;;`))
+			Expect(script).To(ContainSubstring(`		--type)
+			COMPREPLY=($(compgen -W "cf concourse" -- "${cur}"))`))
+			Expect(script).To(ContainSubstring(`	"completion")
+		candidates="bash zsh fish"
+		;;`))
+			Expect(script).To(ContainSubstring(`	"help")
+		candidates="completion create-lbs help status"
+		;;`))
+			Expect(script).To(ContainSubstring(`	"status")
+		candidates="--json"
+		;;`))
+			Expect(script).To(HaveSuffix("complete -F _bbl bbl"))
+		})
+
+		It("prints a zsh script", func() {
+			err := completion.Execute([]string{"zsh"}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			script := logger.PrintlnCall.Receives.Message
+			Expect(script).To(HavePrefix(`# zsh completion for bbl, generated by "bbl completion zsh"`))
+			Expect(script).To(ContainSubstring(`		--type)
+			compadd -- cf concourse
+			return`))
+			Expect(script).To(ContainSubstring(`		--cert)
+			_files
+			return`))
+			Expect(script).To(HaveSuffix("compdef _bbl bbl"))
+		})
+
+		It("prints a fish script", func() {
+			err := completion.Execute([]string{"fish"}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			script := logger.PrintlnCall.Receives.Message
+			Expect(script).To(HavePrefix(`# fish completion for bbl, generated by "bbl completion fish"`))
+			Expect(script).To(ContainSubstring(`
+complete -c bbl -n __bbl_no_command -s d -l debug
+complete -c bbl -n __bbl_no_command -l log-format -x -a 'text json'
+complete -c bbl -n __bbl_no_command -l state-dir -r -F
+complete -c bbl -n __bbl_no_command -a 'completion create-lbs help status'
+complete -c bbl -n '__bbl_using_command completion' -a 'bash zsh fish'
+complete -c bbl -n '__bbl_using_command create-lbs' -l cert -r -F
+`))
+			Expect(script).To(ContainSubstring(`complete -c bbl -n '__bbl_using_command create-lbs' -l skip-if-exists
+complete -c bbl -n '__bbl_using_command create-lbs' -l type -x -a 'cf concourse'
+`))
+		})
+	})
+})
@@ -0,0 +1,39 @@
+package commands
+
+import "github.com/cloudfoundry/bosh-bootloader/storage"
+
+const (
+	CPIConfigCommand = "cpi-config"
+)
+
+type CPIConfig struct {
+	logger             logger
+	stateValidator     stateValidator
+	cloudConfigManager cloudConfigManager
+}
+
+func NewCPIConfig(logger logger, stateValidator stateValidator, cloudConfigManager cloudConfigManager) CPIConfig {
+	return CPIConfig{
+		logger:             logger,
+		stateValidator:     stateValidator,
+		cloudConfigManager: cloudConfigManager,
+	}
+}
+
+func (c CPIConfig) CheckFastFails(subcommandFlags []string, state storage.State) error {
+	err := c.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c CPIConfig) Execute(args []string, state storage.State) error {
+	contents, err := c.cloudConfigManager.GenerateCPIConfig(state)
+	if err != nil {
+		return err
+	}
+	c.logger.Println(contents)
+	return nil
+}
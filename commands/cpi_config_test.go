@@ -0,0 +1,61 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CPIConfig", func() {
+	var (
+		logger             *fakes.Logger
+		stateValidator     *fakes.StateValidator
+		cpiConfig          commands.CPIConfig
+		state              storage.State
+		cloudConfigManager *fakes.CloudConfigManager
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		stateValidator = &fakes.StateValidator{}
+		cloudConfigManager = &fakes.CloudConfigManager{}
+
+		cloudConfigManager.GenerateCPIConfigCall.Returns.CPIConfig = "some-cpi-config"
+
+		state = storage.State{
+			IAAS: "gcp",
+		}
+
+		cpiConfig = commands.NewCPIConfig(logger, stateValidator, cloudConfigManager)
+	})
+
+	Describe("CheckFastFails", func() {
+		It("returns an error when the state validator fails", func() {
+			stateValidator.ValidateCall.Returns.Error = errors.New("failed to validate state")
+			err := cpiConfig.CheckFastFails([]string{}, storage.State{})
+			Expect(err).To(MatchError("failed to validate state"))
+		})
+	})
+
+	Describe("Execute", func() {
+		It("prints the cpi configuration for the bbl environment", func() {
+			err := cpiConfig.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cloudConfigManager.GenerateCPIConfigCall.CallCount).To(Equal(1))
+			Expect(cloudConfigManager.GenerateCPIConfigCall.Receives.State).To(Equal(state))
+			Expect(logger.PrintlnCall.Messages).To(ContainElement("some-cpi-config"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the cloud config manager fails to generate", func() {
+				cloudConfigManager.GenerateCPIConfigCall.Returns.Error = errors.New("failed to generate cpi configuration")
+				err := cpiConfig.Execute([]string{}, state)
+				Expect(err).To(MatchError("failed to generate cpi configuration"))
+			})
+		})
+	})
+})
@@ -13,15 +13,18 @@ type CreateLBs struct {
 	stateValidator       stateValidator
 	certificateValidator certificateValidator
 	boshManager          boshManager
+	configFileLoader     configFileLoader
 }
 
 type lbConfig struct {
-	lbType       string
-	certPath     string
-	keyPath      string
-	chainPath    string
-	domain       string
-	skipIfExists bool
+	lbType             string
+	certPath           string
+	keyPath            string
+	chainPath          string
+	domain             string
+	skipIfExists       bool
+	cloudConfigOpsFile string
+	configFile         string
 }
 
 type gcpCreateLBs interface {
@@ -36,18 +39,19 @@ type certificateValidator interface {
 	Validate(command, certPath, keyPath, chainPath string) error
 }
 
-func NewCreateLBs(awsCreateLBs awsCreateLBs, gcpCreateLBs gcpCreateLBs, stateValidator stateValidator, certificateValidator certificateValidator, boshManager boshManager) CreateLBs {
+func NewCreateLBs(awsCreateLBs awsCreateLBs, gcpCreateLBs gcpCreateLBs, stateValidator stateValidator, certificateValidator certificateValidator, boshManager boshManager, configFileLoader configFileLoader) CreateLBs {
 	return CreateLBs{
 		awsCreateLBs:         awsCreateLBs,
 		gcpCreateLBs:         gcpCreateLBs,
 		stateValidator:       stateValidator,
 		certificateValidator: certificateValidator,
 		boshManager:          boshManager,
+		configFileLoader:     configFileLoader,
 	}
 }
 
 func (c CreateLBs) CheckFastFails(subcommandFlags []string, state storage.State) error {
-	config, err := parseFlags(subcommandFlags)
+	config, err := c.parseFlags(subcommandFlags)
 	if err != nil {
 		return err
 	}
@@ -74,7 +78,12 @@ func (c CreateLBs) CheckFastFails(subcommandFlags []string, state storage.State)
 }
 
 func (c CreateLBs) Execute(args []string, state storage.State) error {
-	config, err := parseFlags(args)
+	config, err := c.parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	state, err = readCloudConfigOpsFile(config.cloudConfigOpsFile, state)
 	if err != nil {
 		return err
 	}
@@ -106,20 +115,45 @@ func (c CreateLBs) Execute(args []string, state storage.State) error {
 	return nil
 }
 
-func parseFlags(subcommandFlags []string) (lbConfig, error) {
+func (c CreateLBs) Flags() flags.Flags {
+	return createLBsFlags(&lbConfig{}, storage.ConfigFile{})
+}
+
+// createLBsFlags builds the create-lbs flags. A flag that is not given
+// defaults to the config file.
+func createLBsFlags(config *lbConfig, configFile storage.ConfigFile) flags.Flags {
 	lbFlags := flags.New("create-lbs")
 
-	config := lbConfig{}
-	lbFlags.String(&config.lbType, "type", "")
-	lbFlags.String(&config.certPath, "cert", "")
-	lbFlags.String(&config.keyPath, "key", "")
-	lbFlags.String(&config.chainPath, "chain", "")
-	lbFlags.String(&config.domain, "domain", "")
+	lbFlags.String(&config.configFile, "config", "")
+	lbFlags.String(&config.lbType, "type", configFile.LB.Type)
+	lbFlags.Values("type", "cf", "concourse")
+	lbFlags.String(&config.certPath, "cert", configFile.LB.Cert)
+	lbFlags.String(&config.keyPath, "key", configFile.LB.Key)
+	lbFlags.String(&config.chainPath, "chain", configFile.LB.Chain)
+	lbFlags.String(&config.domain, "domain", configFile.LB.Domain)
 	lbFlags.Bool(&config.skipIfExists, "skip-if-exists", "", false)
+	lbFlags.String(&config.cloudConfigOpsFile, "cloud-config-ops-file", configFile.CloudConfigOpsFile)
 
-	if err := lbFlags.Parse(subcommandFlags); err != nil {
+	return lbFlags
+}
+
+// parseFlags parses the flags once to find the config file, and again with
+// the config file values as defaults.
+func (c CreateLBs) parseFlags(subcommandFlags []string) (lbConfig, error) {
+	config := lbConfig{}
+	if err := createLBsFlags(&config, storage.ConfigFile{}).Parse(subcommandFlags); err != nil {
 		return config, err
 	}
 
+	configFile, err := c.configFileLoader.Load(config.configFile)
+	if err != nil {
+		return lbConfig{}, err
+	}
+
+	config = lbConfig{}
+	if err := createLBsFlags(&config, configFile).Parse(subcommandFlags); err != nil {
+		return config, err //not tested
+	}
+
 	return config, nil
 }
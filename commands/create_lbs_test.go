@@ -2,6 +2,7 @@ package commands_test
 
 import (
 	"errors"
+	"io/ioutil"
 
 	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
@@ -19,6 +20,7 @@ var _ = Describe("create-lbs", func() {
 		stateValidator       *fakes.StateValidator
 		certificateValidator *fakes.CertificateValidator
 		boshManager          *fakes.BOSHManager
+		configFileLoader     *fakes.ConfigFileLoader
 	)
 
 	BeforeEach(func() {
@@ -28,8 +30,9 @@ var _ = Describe("create-lbs", func() {
 		certificateValidator = &fakes.CertificateValidator{}
 		boshManager = &fakes.BOSHManager{}
 		boshManager.VersionCall.Returns.Version = "2.0.24"
+		configFileLoader = &fakes.ConfigFileLoader{}
 
-		command = commands.NewCreateLBs(awsCreateLBs, gcpCreateLBs, stateValidator, certificateValidator, boshManager)
+		command = commands.NewCreateLBs(awsCreateLBs, gcpCreateLBs, stateValidator, certificateValidator, boshManager, configFileLoader)
 	})
 
 	Describe("CheckFastFails", func() {
@@ -157,6 +160,86 @@ var _ = Describe("create-lbs", func() {
 			}))
 		})
 
+		Context("when a config file is provided", func() {
+			BeforeEach(func() {
+				configFileLoader.LoadCall.Returns.ConfigFile = storage.ConfigFile{
+					LB: storage.ConfigFileLB{
+						Type:   "cf",
+						Cert:   "cert-from-config",
+						Key:    "key-from-config",
+						Chain:  "chain-from-config",
+						Domain: "domain-from-config",
+					},
+					Path: "some-bbl.yml",
+				}
+			})
+
+			It("uses the values from the config file unless they are given as flags", func() {
+				err := command.Execute([]string{
+					"--config", "some-bbl.yml",
+					"--domain", "domain-from-args",
+				}, storage.State{
+					IAAS: "aws",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(configFileLoader.LoadCall.Receives.Path).To(Equal("some-bbl.yml"))
+				Expect(awsCreateLBs.ExecuteCall.Receives.Config).To(Equal(commands.AWSCreateLBsConfig{
+					LBType:    "cf",
+					CertPath:  "cert-from-config",
+					KeyPath:   "key-from-config",
+					ChainPath: "chain-from-config",
+					Domain:    "domain-from-args",
+				}))
+			})
+
+			It("validates the certificate from the config file", func() {
+				err := command.CheckFastFails([]string{"--config", "some-bbl.yml"}, storage.State{IAAS: "aws"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateValidator.ValidateCall.Receives.CertificatePath).To(Equal("cert-from-config"))
+				Expect(certificateValidator.ValidateCall.Receives.KeyPath).To(Equal("key-from-config"))
+				Expect(certificateValidator.ValidateCall.Receives.ChainPath).To(Equal("chain-from-config"))
+			})
+
+			It("returns an error when the config file cannot be loaded", func() {
+				configFileLoader.LoadCall.Returns.Error = errors.New("failed to load config file")
+
+				err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError("failed to load config file"))
+			})
+		})
+
+		Context("when a cloud-config ops file is provided", func() {
+			It("saves the ops file contents to the state", func() {
+				opsFile, err := ioutil.TempFile("", "cloud-config-ops")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = opsFile.WriteString("some-cloud-config-ops")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = command.Execute([]string{
+					"--type", "concourse",
+					"--cloud-config-ops-file", opsFile.Name(),
+				}, storage.State{
+					IAAS: "gcp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpCreateLBs.ExecuteCall.Receives.State.CloudConfigOpsFile).To(Equal("some-cloud-config-ops"))
+			})
+
+			It("returns an error when the ops file cannot be read", func() {
+				err := command.Execute([]string{
+					"--type", "concourse",
+					"--cloud-config-ops-file", "/some/missing/file",
+				}, storage.State{
+					IAAS: "gcp",
+				})
+				Expect(err).To(MatchError("error reading cloud-config ops-file contents: open /some/missing/file: no such file or directory"))
+			})
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when an invalid command line flag is supplied", func() {
 				err := command.Execute([]string{"--invalid-flag"}, storage.State{})
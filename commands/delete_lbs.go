@@ -13,6 +13,7 @@ type DeleteLBs struct {
 	logger         logger
 	stateValidator stateValidator
 	boshManager    boshManager
+	envIDConfirmer envIDConfirmer
 }
 
 type gcpDeleteLBs interface {
@@ -24,13 +25,14 @@ type awsDeleteLBs interface {
 }
 
 func NewDeleteLBs(gcpDeleteLBs gcpDeleteLBs, awsDeleteLBs awsDeleteLBs,
-	logger logger, stateValidator stateValidator, boshManager boshManager) DeleteLBs {
+	logger logger, stateValidator stateValidator, boshManager boshManager, envIDConfirmer envIDConfirmer) DeleteLBs {
 	return DeleteLBs{
 		gcpDeleteLBs:   gcpDeleteLBs,
 		awsDeleteLBs:   awsDeleteLBs,
 		logger:         logger,
 		stateValidator: stateValidator,
 		boshManager:    boshManager,
+		envIDConfirmer: envIDConfirmer,
 	}
 }
 
@@ -61,6 +63,11 @@ func (d DeleteLBs) Execute(subcommandFlags []string, state storage.State) error
 		return nil
 	}
 
+	err = d.envIDConfirmer.Confirm(state, config.confirmEnvID, "delete its load balancers")
+	if err != nil {
+		return err
+	}
+
 	switch state.IAAS {
 	case "gcp":
 		return d.gcpDeleteLBs.Execute(state)
@@ -73,13 +80,21 @@ func (d DeleteLBs) Execute(subcommandFlags []string, state storage.State) error
 	return nil
 }
 
-func (DeleteLBs) parseFlags(subcommandFlags []string) (deleteLBsConfig, error) {
-	lbFlags := flags.New("delete-lbs")
+func (d DeleteLBs) Flags() flags.Flags {
+	return d.flags(&deleteLBsConfig{})
+}
 
-	config := deleteLBsConfig{}
+func (DeleteLBs) flags(config *deleteLBsConfig) flags.Flags {
+	lbFlags := flags.New("delete-lbs")
 	lbFlags.Bool(&config.skipIfMissing, "skip-if-missing", "", false)
+	lbFlags.String(&config.confirmEnvID, "confirm-env-id", "")
 
-	err := lbFlags.Parse(subcommandFlags)
+	return lbFlags
+}
+
+func (d DeleteLBs) parseFlags(subcommandFlags []string) (deleteLBsConfig, error) {
+	config := deleteLBsConfig{}
+	err := d.flags(&config).Parse(subcommandFlags)
 	if err != nil {
 		return config, err
 	}
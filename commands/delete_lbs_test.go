@@ -21,6 +21,7 @@ var _ = Describe("DeleteLBs", func() {
 		stateValidator *fakes.StateValidator
 		logger         *fakes.Logger
 		boshManager    *fakes.BOSHManager
+		envIDConfirmer *fakes.EnvIDConfirmer
 	)
 
 	BeforeEach(func() {
@@ -31,7 +32,9 @@ var _ = Describe("DeleteLBs", func() {
 		boshManager = &fakes.BOSHManager{}
 		boshManager.VersionCall.Returns.Version = "2.0.24"
 
-		command = commands.NewDeleteLBs(gcpDeleteLBs, awsDeleteLBs, logger, stateValidator, boshManager)
+		envIDConfirmer = &fakes.EnvIDConfirmer{}
+
+		command = commands.NewDeleteLBs(gcpDeleteLBs, awsDeleteLBs, logger, stateValidator, boshManager, envIDConfirmer)
 	})
 
 	Describe("CheckFastFails", func() {
@@ -164,6 +167,29 @@ var _ = Describe("DeleteLBs", func() {
 			)
 		})
 
+		Context("when the environment is protected", func() {
+			It("confirms the env id before deleting the lbs", func() {
+				state := storage.State{IAAS: "gcp", EnvID: "some-env-id", Protected: true}
+				err := command.Execute([]string{"--confirm-env-id", "some-env-id"}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(envIDConfirmer.ConfirmCall.CallCount).To(Equal(1))
+				Expect(envIDConfirmer.ConfirmCall.Receives.State).To(Equal(state))
+				Expect(envIDConfirmer.ConfirmCall.Receives.ConfirmEnvID).To(Equal("some-env-id"))
+				Expect(envIDConfirmer.ConfirmCall.Receives.Action).To(Equal("delete its load balancers"))
+				Expect(gcpDeleteLBs.ExecuteCall.CallCount).To(Equal(1))
+			})
+
+			It("does not delete the lbs when the env id is not confirmed", func() {
+				envIDConfirmer.ConfirmCall.Returns.Error = errors.New("not confirmed")
+
+				err := command.Execute([]string{}, storage.State{IAAS: "aws", Protected: true})
+				Expect(err).To(MatchError("not confirmed"))
+
+				Expect(awsDeleteLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when an unknown flag is provided", func() {
 				err := command.Execute([]string{"--unknown-flag"}, storage.State{})
@@ -33,11 +33,27 @@ type Destroy struct {
 	stateValidator          stateValidator
 	terraformManager        terraformDestroyer
 	networkInstancesChecker networkInstancesChecker
+	cascadeDeleter          cascadeDeleter
+	awsOrphanSweeper        orphanSweeper
+	gcpOrphanSweeper        orphanSweeper
+	envIDConfirmer          envIDConfirmer
 }
 
 type destroyConfig struct {
 	NoConfirm     bool
 	SkipIfMissing bool
+	Cascade       bool
+	ConfirmEnvID  string
+}
+
+type cascadeDeleter interface {
+	Deployments(storage.State) ([]string, error)
+	Delete(storage.State) error
+}
+
+type orphanSweeper interface {
+	Orphans(directorName string) ([]string, error)
+	DeleteOrphans(directorName string) error
 }
 
 type awsKeyPairDeleter interface {
@@ -72,7 +88,8 @@ func NewDestroy(credentialValidator credentialValidator, logger logger, stdin io
 	boshManager boshManager, vpcStatusChecker vpcStatusChecker, stackManager stackManager,
 	infrastructureManager infrastructureManager, awsKeyPairDeleter awsKeyPairDeleter,
 	gcpKeyPairDeleter gcpKeyPairDeleter, certificateDeleter certificateDeleter, stateStore stateStore, stateValidator stateValidator,
-	terraformManager terraformDestroyer, networkInstancesChecker networkInstancesChecker) Destroy {
+	terraformManager terraformDestroyer, networkInstancesChecker networkInstancesChecker, cascadeDeleter cascadeDeleter,
+	awsOrphanSweeper orphanSweeper, gcpOrphanSweeper orphanSweeper, envIDConfirmer envIDConfirmer) Destroy {
 	return Destroy{
 		credentialValidator:     credentialValidator,
 		logger:                  logger,
@@ -88,6 +105,10 @@ func NewDestroy(credentialValidator credentialValidator, logger logger, stdin io
 		stateValidator:          stateValidator,
 		terraformManager:        terraformManager,
 		networkInstancesChecker: networkInstancesChecker,
+		cascadeDeleter:          cascadeDeleter,
+		awsOrphanSweeper:        awsOrphanSweeper,
+		gcpOrphanSweeper:        gcpOrphanSweeper,
+		envIDConfirmer:          envIDConfirmer,
 	}
 }
 
@@ -126,6 +147,12 @@ func (d Destroy) CheckFastFails(subcommandFlags []string, state storage.State) e
 		return err
 	}
 
+	// --cascade deletes the VMs that would otherwise make it unsafe to
+	// delete the network.
+	if config.Cascade {
+		return nil
+	}
+
 	var terraformOutputs map[string]interface{}
 	if state.IAAS == "gcp" {
 		terraformOutputs, err = d.terraformManager.GetOutputs(state)
@@ -187,7 +214,21 @@ func (d Destroy) Execute(subcommandFlags []string, state storage.State) error {
 		return nil
 	}
 
-	if !config.NoConfirm {
+	if config.Cascade {
+		err = d.printCascadeSummary(state)
+		if err != nil {
+			return err
+		}
+	}
+
+	// --no-confirm does not skip the confirmation of a protected
+	// environment.
+	if state.Protected {
+		err = d.envIDConfirmer.Confirm(state, config.ConfirmEnvID, "destroy it")
+		if err != nil {
+			return err
+		}
+	} else if !config.NoConfirm {
 		d.logger.Prompt(fmt.Sprintf("Are you sure you want to delete infrastructure for %q? This operation cannot be undone!", state.EnvID))
 
 		var proceed string
@@ -210,6 +251,13 @@ func (d Destroy) Execute(subcommandFlags []string, state storage.State) error {
 		return err
 	}
 
+	if config.Cascade {
+		err = d.deleteCascade(state)
+		if err != nil {
+			return err
+		}
+	}
+
 	terraformOutputs, err := d.terraformManager.GetOutputs(state)
 	if err != nil {
 		return err
@@ -298,14 +346,23 @@ func (d Destroy) Execute(subcommandFlags []string, state storage.State) error {
 	return nil
 }
 
-func (d Destroy) parseFlags(subcommandFlags []string) (destroyConfig, error) {
-	destroyFlags := flags.New("destroy")
+func (d Destroy) Flags() flags.Flags {
+	return d.flags(&destroyConfig{})
+}
 
-	config := destroyConfig{}
+func (Destroy) flags(config *destroyConfig) flags.Flags {
+	destroyFlags := flags.New("destroy")
 	destroyFlags.Bool(&config.NoConfirm, "n", "no-confirm", false)
 	destroyFlags.Bool(&config.SkipIfMissing, "", "skip-if-missing", false)
+	destroyFlags.Bool(&config.Cascade, "", "cascade", false)
+	destroyFlags.String(&config.ConfirmEnvID, "confirm-env-id", "")
+
+	return destroyFlags
+}
 
-	err := destroyFlags.Parse(subcommandFlags)
+func (d Destroy) parseFlags(subcommandFlags []string) (destroyConfig, error) {
+	config := destroyConfig{}
+	err := d.flags(&config).Parse(subcommandFlags)
 	if err != nil {
 		return config, err
 	}
@@ -313,6 +370,79 @@ func (d Destroy) parseFlags(subcommandFlags []string) (destroyConfig, error) {
 	return config, nil
 }
 
+// printCascadeSummary lists the deployments on the director and the IaaS
+// resources tagged for it, all of which --cascade deletes before the
+// infrastructure is destroyed.
+func (d Destroy) printCascadeSummary(state storage.State) error {
+	resources := []string{}
+
+	if directorExists(state) {
+		deployments, err := d.cascadeDeleter.Deployments(state)
+		if err != nil {
+			return err
+		}
+		for _, deployment := range deployments {
+			resources = append(resources, fmt.Sprintf("deployment %s", deployment))
+		}
+	}
+
+	if sweeper := d.orphanSweeper(state); sweeper != nil {
+		orphans, err := sweeper.Orphans(directorName(state))
+		if err != nil {
+			return err
+		}
+		resources = append(resources, orphans...)
+	}
+
+	if len(resources) == 0 {
+		d.logger.Println("no deployments or orphaned resources to delete")
+		return nil
+	}
+
+	d.logger.Println(fmt.Sprintf("--cascade will also delete:\n  %s", strings.Join(resources, "\n  ")))
+	return nil
+}
+
+func (d Destroy) deleteCascade(state storage.State) error {
+	if directorExists(state) {
+		err := d.cascadeDeleter.Delete(state)
+		if err != nil {
+			return err
+		}
+	}
+
+	if sweeper := d.orphanSweeper(state); sweeper != nil {
+		err := sweeper.DeleteOrphans(directorName(state))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d Destroy) orphanSweeper(state storage.State) orphanSweeper {
+	switch state.IAAS {
+	case "aws":
+		return d.awsOrphanSweeper
+	case "gcp":
+		return d.gcpOrphanSweeper
+	}
+	return nil
+}
+
+func directorExists(state storage.State) bool {
+	return !state.NoDirector && !state.BOSH.IsEmpty()
+}
+
+// directorName is the name the CPI tags the director's VMs and disks with.
+func directorName(state storage.State) string {
+	if state.BOSH.DirectorName != "" {
+		return state.BOSH.DirectorName
+	}
+	return fmt.Sprintf("bosh-%s", state.EnvID)
+}
+
 func (d Destroy) deleteBOSH(state storage.State, stack cloudformation.Stack, terraformOutputs map[string]interface{}) (storage.State, error) {
 	emptyBOSH := storage.BOSH{}
 	if reflect.DeepEqual(state.BOSH, emptyBOSH) {
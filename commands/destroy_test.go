@@ -35,6 +35,10 @@ var _ = Describe("Destroy", func() {
 		terraformManager        *fakes.TerraformManager
 		terraformManagerError   *fakes.TerraformManagerError
 		networkInstancesChecker *fakes.NetworkInstancesChecker
+		cascadeDeleter          *fakes.CascadeDeleter
+		awsOrphanSweeper        *fakes.OrphanSweeper
+		gcpOrphanSweeper        *fakes.OrphanSweeper
+		envIDConfirmer          *fakes.EnvIDConfirmer
 		stdin                   *bytes.Buffer
 	)
 
@@ -56,11 +60,16 @@ var _ = Describe("Destroy", func() {
 		terraformManager = &fakes.TerraformManager{}
 		terraformManagerError = &fakes.TerraformManagerError{}
 		networkInstancesChecker = &fakes.NetworkInstancesChecker{}
+		cascadeDeleter = &fakes.CascadeDeleter{}
+		awsOrphanSweeper = &fakes.OrphanSweeper{}
+		gcpOrphanSweeper = &fakes.OrphanSweeper{}
+		envIDConfirmer = &fakes.EnvIDConfirmer{}
 
 		destroy = commands.NewDestroy(credentialValidator, logger, stdin, boshManager,
 			vpcStatusChecker, stackManager, infrastructureManager,
 			awsKeyPairDeleter, gcpKeyPairDeleter, certificateDeleter, stateStore,
-			stateValidator, terraformManager, networkInstancesChecker)
+			stateValidator, terraformManager, networkInstancesChecker, cascadeDeleter,
+			awsOrphanSweeper, gcpOrphanSweeper, envIDConfirmer)
 	})
 
 	Describe("CheckFastFails", func() {
@@ -113,6 +122,17 @@ var _ = Describe("Destroy", func() {
 			Expect(err).To(MatchError("state validator failed"))
 		})
 
+		It("does not check for vms in the network when --cascade is supplied", func() {
+			networkInstancesChecker.ValidateSafeToDeleteCall.Returns.Error = errors.New("instances exist")
+			terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+				"network_name": "some-network-name",
+			}
+
+			err := destroy.CheckFastFails([]string{"--cascade"}, storage.State{IAAS: "gcp"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networkInstancesChecker.ValidateSafeToDeleteCall.CallCount).To(Equal(0))
+		})
+
 		It("returns an error when credential validator fails", func() {
 			credentialValidator.ValidateCall.Returns.Error = errors.New("credentials validator failed")
 
@@ -322,6 +342,91 @@ var _ = Describe("Destroy", func() {
 	})
 
 	Describe("Execute", func() {
+		Context("when the --cascade flag is supplied", func() {
+			var state storage.State
+
+			BeforeEach(func() {
+				state = storage.State{
+					IAAS:  "gcp",
+					EnvID: "some-env-id",
+					BOSH: storage.BOSH{
+						DirectorName: "bosh-some-env-id",
+					},
+				}
+				cascadeDeleter.DeploymentsCall.Returns.Deployments = []string{"cf", "concourse"}
+				gcpOrphanSweeper.OrphansCall.Returns.Orphans = []string{"instance vm-1 (us-east1-b)"}
+			})
+
+			It("summarises what will be deleted before asking for confirmation", func() {
+				stdin.Write([]byte("no\n"))
+
+				err := destroy.Execute([]string{"--cascade"}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PrintlnCall.Messages).To(ContainElement("--cascade will also delete:\n  deployment cf\n  deployment concourse\n  instance vm-1 (us-east1-b)"))
+				Expect(gcpOrphanSweeper.OrphansCall.Receives.DirectorName).To(Equal("bosh-some-env-id"))
+				Expect(cascadeDeleter.DeleteCall.CallCount).To(Equal(0))
+				Expect(gcpOrphanSweeper.DeleteOrphansCall.CallCount).To(Equal(0))
+				Expect(boshManager.DeleteCall.CallCount).To(Equal(0))
+			})
+
+			It("deletes the deployments and orphaned resources before the director", func() {
+				err := destroy.Execute([]string{"--cascade", "--no-confirm"}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cascadeDeleter.DeleteCall.Receives.State).To(Equal(state))
+				Expect(gcpOrphanSweeper.DeleteOrphansCall.Receives.DirectorName).To(Equal("bosh-some-env-id"))
+				Expect(awsOrphanSweeper.DeleteOrphansCall.CallCount).To(Equal(0))
+				Expect(boshManager.DeleteCall.CallCount).To(Equal(1))
+				Expect(terraformManager.DestroyCall.CallCount).To(Equal(1))
+			})
+
+			It("sweeps resources tagged for the env id when there is no director", func() {
+				state.IAAS = "aws"
+				state.BOSH = storage.BOSH{}
+
+				err := destroy.Execute([]string{"--cascade", "--no-confirm"}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cascadeDeleter.DeploymentsCall.CallCount).To(Equal(0))
+				Expect(cascadeDeleter.DeleteCall.CallCount).To(Equal(0))
+				Expect(awsOrphanSweeper.DeleteOrphansCall.Receives.DirectorName).To(Equal("bosh-some-env-id"))
+			})
+
+			It("says when there is nothing extra to delete", func() {
+				cascadeDeleter.DeploymentsCall.Returns.Deployments = []string{}
+				gcpOrphanSweeper.OrphansCall.Returns.Orphans = []string{}
+
+				err := destroy.Execute([]string{"--cascade", "--no-confirm"}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PrintlnCall.Messages).To(ContainElement("no deployments or orphaned resources to delete"))
+			})
+
+			It("returns an error when the deployments cannot be listed", func() {
+				cascadeDeleter.DeploymentsCall.Returns.Error = errors.New("failed to list deployments")
+
+				err := destroy.Execute([]string{"--cascade", "--no-confirm"}, state)
+				Expect(err).To(MatchError("failed to list deployments"))
+			})
+
+			It("returns an error and keeps the director when a deployment fails to delete", func() {
+				cascadeDeleter.DeleteCall.Returns.Error = errors.New("failed to delete deployment cf: boom")
+
+				err := destroy.Execute([]string{"--cascade", "--no-confirm"}, state)
+				Expect(err).To(MatchError("failed to delete deployment cf: boom"))
+				Expect(boshManager.DeleteCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the orphaned resources fail to delete", func() {
+				gcpOrphanSweeper.DeleteOrphansCall.Returns.Error = errors.New("failed to delete disk")
+
+				err := destroy.Execute([]string{"--cascade", "--no-confirm"}, state)
+				Expect(err).To(MatchError("failed to delete disk"))
+				Expect(boshManager.DeleteCall.CallCount).To(Equal(0))
+			})
+		})
+
 		It("returns when there is no state and --skip-if-missing flag is provided", func() {
 			err := destroy.Execute([]string{"--skip-if-missing"}, storage.State{})
 
@@ -377,6 +482,43 @@ var _ = Describe("Destroy", func() {
 			)
 		})
 
+		Context("when the environment is protected", func() {
+			var state storage.State
+
+			BeforeEach(func() {
+				state = storage.State{
+					BOSH: storage.BOSH{
+						DirectorName: "some-director",
+					},
+					EnvID:     "some-lake",
+					Protected: true,
+				}
+			})
+
+			It("confirms the env id instead of prompting, even with --no-confirm", func() {
+				err := destroy.Execute([]string{"--no-confirm", "--confirm-env-id", "some-lake"}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PromptCall.CallCount).To(Equal(0))
+				Expect(envIDConfirmer.ConfirmCall.CallCount).To(Equal(1))
+				Expect(envIDConfirmer.ConfirmCall.Receives.State).To(Equal(state))
+				Expect(envIDConfirmer.ConfirmCall.Receives.ConfirmEnvID).To(Equal("some-lake"))
+				Expect(envIDConfirmer.ConfirmCall.Receives.Action).To(Equal("destroy it"))
+				Expect(boshManager.DeleteCall.CallCount).To(Equal(1))
+			})
+
+			It("does not destroy anything when the env id is not confirmed", func() {
+				envIDConfirmer.ConfirmCall.Returns.Error = errors.New("not confirmed")
+
+				err := destroy.Execute([]string{"--no-confirm"}, state)
+				Expect(err).To(MatchError("not confirmed"))
+
+				Expect(boshManager.DeleteCall.CallCount).To(Equal(0))
+				Expect(terraformManager.DestroyCall.CallCount).To(Equal(0))
+				Expect(stateStore.SetCall.CallCount).To(Equal(0))
+			})
+		})
+
 		It("invokes bosh delete", func() {
 			stdin.Write([]byte("yes\n"))
 			state := storage.State{
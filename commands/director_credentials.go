@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const credhubAdminClient = "credhub-admin"
+
+type directorCredentials struct {
+	credhubServer        string
+	credhubClient        string
+	credhubSecret        string
+	credhubCACert        string
+	uaaAdminClientSecret string
+}
+
+// getDirectorCredentials reads the CredHub and UAA credentials generated into
+// the director's variables. Credentials for components that were not
+// deployed are left empty.
+func getDirectorCredentials(state storage.State) (directorCredentials, error) {
+	var variables struct {
+		CredHubAdminClientSecret string `yaml:"credhub_admin_client_secret"`
+		UAAAdminClientSecret     string `yaml:"uaa_admin_client_secret"`
+		CredHubTLS               struct {
+			CA string `yaml:"ca"`
+		} `yaml:"credhub_tls"`
+		UAASSL struct {
+			CA string `yaml:"ca"`
+		} `yaml:"uaa_ssl"`
+	}
+
+	err := yaml.Unmarshal([]byte(state.BOSH.Variables), &variables)
+	if err != nil {
+		return directorCredentials{}, fmt.Errorf("error unmarshalling director variables: %v", err)
+	}
+
+	var credentials directorCredentials
+
+	if state.UAA || state.CredHub {
+		credentials.uaaAdminClientSecret = variables.UAAAdminClientSecret
+	}
+
+	if state.CredHub {
+		directorURL, err := url.Parse(state.BOSH.DirectorAddress)
+		if err != nil {
+			return directorCredentials{}, err
+		}
+
+		credentials.credhubServer = fmt.Sprintf("https://%s:8844", directorURL.Hostname())
+		credentials.credhubClient = credhubAdminClient
+		credentials.credhubSecret = variables.CredHubAdminClientSecret
+
+		// CredHub authenticates through UAA, so the CLI has to trust both.
+		credentials.credhubCACert = strings.TrimSpace(variables.CredHubTLS.CA) + "\n" + strings.TrimSpace(variables.UAASSL.CA)
+	}
+
+	return credentials, nil
+}
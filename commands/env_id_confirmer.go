@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+type envIDConfirmer interface {
+	Confirm(state storage.State, confirmEnvID, action string) error
+}
+
+// EnvIDConfirmer guards the operations that change a protected environment.
+// They only go ahead once the env id is typed back, or passed with
+// --confirm-env-id, so that scripts passing --no-confirm cannot run them by
+// accident.
+type EnvIDConfirmer struct {
+	logger logger
+	stdin  io.Reader
+}
+
+func NewEnvIDConfirmer(logger logger, stdin io.Reader) EnvIDConfirmer {
+	return EnvIDConfirmer{
+		logger: logger,
+		stdin:  stdin,
+	}
+}
+
+func (c EnvIDConfirmer) Confirm(state storage.State, confirmEnvID, action string) error {
+	if !state.Protected {
+		return nil
+	}
+
+	if confirmEnvID == "" {
+		c.logger.Printf("%q is protected. Type its env id to %s: ", state.EnvID, action)
+		fmt.Fscanln(c.stdin, &confirmEnvID)
+	}
+
+	if confirmEnvID != state.EnvID {
+		return fmt.Errorf("%q is protected, type its env id or pass --confirm-env-id=%s to %s", state.EnvID, state.EnvID, action)
+	}
+
+	return nil
+}
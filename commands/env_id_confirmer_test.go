@@ -0,0 +1,71 @@
+package commands_test
+
+import (
+	"bytes"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EnvIDConfirmer", func() {
+	var (
+		logger    *fakes.Logger
+		stdin     *bytes.Buffer
+		confirmer commands.EnvIDConfirmer
+		state     storage.State
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		stdin = bytes.NewBuffer([]byte{})
+		confirmer = commands.NewEnvIDConfirmer(logger, stdin)
+
+		state = storage.State{
+			EnvID:     "some-env-id",
+			Protected: true,
+		}
+	})
+
+	It("does not ask to confirm an environment that is not protected", func() {
+		err := confirmer.Confirm(storage.State{EnvID: "some-env-id"}, "", "destroy it")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(logger.PrintfCall.CallCount).To(Equal(0))
+	})
+
+	It("accepts the env id passed with --confirm-env-id", func() {
+		err := confirmer.Confirm(state, "some-env-id", "destroy it")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(logger.PrintfCall.CallCount).To(Equal(0))
+	})
+
+	It("accepts the env id typed back", func() {
+		stdin.Write([]byte("some-env-id\n"))
+
+		err := confirmer.Confirm(state, "", "destroy it")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(logger.PrintfCall.Messages).To(Equal([]string{`"some-env-id" is protected. Type its env id to destroy it: `}))
+	})
+
+	Context("failure cases", func() {
+		It("refuses when a different env id is typed back", func() {
+			stdin.Write([]byte("yes\n"))
+
+			err := confirmer.Confirm(state, "", "destroy it")
+			Expect(err).To(MatchError(`"some-env-id" is protected, type its env id or pass --confirm-env-id=some-env-id to destroy it`))
+		})
+
+		It("refuses when a different env id is passed with --confirm-env-id", func() {
+			err := confirmer.Confirm(state, "other-env-id", "destroy it")
+			Expect(err).To(MatchError(`"some-env-id" is protected, type its env id or pass --confirm-env-id=some-env-id to destroy it`))
+
+			Expect(logger.PrintfCall.CallCount).To(Equal(0))
+		})
+	})
+})
@@ -17,3 +17,17 @@ func SetUnmarshal(f func([]byte, interface{}) error) {
 func ResetUnmarshal() {
 	unmarshal = yaml.Unmarshal
 }
+
+type UpGraphStep struct {
+	Name  string
+	After []string
+	Run   func() error
+}
+
+func RunUpGraph(logger logger, workers int, steps []UpGraphStep) error {
+	graphSteps := []upGraphStep{}
+	for _, step := range steps {
+		graphSteps = append(graphSteps, upGraphStep{name: step.Name, after: step.After, run: step.Run})
+	}
+	return runUpGraph(logger, workers, graphSteps)
+}
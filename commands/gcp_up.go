@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/gcp"
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/cloudfoundry/bosh-bootloader/helpers"
@@ -32,6 +33,9 @@ type GCPUp struct {
 	terraformManager             terraformApplier
 	envIDManager                 envIDManager
 	gcpAvailabilityZoneRetriever gcpAvailabilityZoneRetriever
+	stemcellUploader             stemcellUploader
+	envIDConfirmer               envIDConfirmer
+	bblVersion                   string
 }
 
 type GCPUpConfig struct {
@@ -43,6 +47,13 @@ type GCPUpConfig struct {
 	Name              string
 	NoDirector        bool
 	Jumpbox           bool
+	InternalOnly      bool
+	FromStep          string
+
+	ApplicationDefaultCredentials bool
+	ImpersonateServiceAccount     string
+	DirectorServiceAccount        bool
+	ConfirmEnvID                  string
 }
 
 type gcpKeyPairCreator interface {
@@ -54,7 +65,7 @@ type keyPairUpdater interface {
 }
 
 type gcpProvider interface {
-	SetConfig(serviceAccountKey, projectID, region, zone string) error
+	SetConfig(config gcp.Config) error
 }
 
 type terraformManagerError interface {
@@ -89,6 +100,9 @@ type NewGCPUpArgs struct {
 	EnvIDManager                 envIDManager
 	CloudConfigManager           cloudConfigManager
 	GCPAvailabilityZoneRetriever gcpAvailabilityZoneRetriever
+	StemcellUploader             stemcellUploader
+	EnvIDConfirmer               envIDConfirmer
+	BBLVersion                   string
 }
 
 func NewGCPUp(args NewGCPUpArgs) GCPUp {
@@ -102,6 +116,9 @@ func NewGCPUp(args NewGCPUpArgs) GCPUp {
 		logger:                       args.Logger,
 		envIDManager:                 args.EnvIDManager,
 		gcpAvailabilityZoneRetriever: args.GCPAvailabilityZoneRetriever,
+		stemcellUploader:             args.StemcellUploader,
+		envIDConfirmer:               args.EnvIDConfirmer,
+		bblVersion:                   args.BBLVersion,
 	}
 }
 
@@ -127,7 +144,7 @@ func (u GCPUp) Execute(upConfig GCPUpConfig, state storage.State) error {
 		return err
 	}
 
-	if err := fastFailConflictingGCPState(gcpDetails, state.GCP); err != nil {
+	if err := fastFailConflictingGCPState(gcpDetails, state.GCP, state.Jumpbox.Enabled); err != nil {
 		return err
 	}
 
@@ -145,41 +162,115 @@ func (u GCPUp) Execute(upConfig GCPUpConfig, state storage.State) error {
 		return err
 	}
 
-	if err := u.gcpProvider.SetConfig(state.GCP.ServiceAccountKey, state.GCP.ProjectID, state.GCP.Region, state.GCP.Zone); err != nil {
+	if err := u.gcpProvider.SetConfig(gcp.Config{
+		ServiceAccountKey:             state.GCP.ServiceAccountKey,
+		ProjectID:                     state.GCP.ProjectID,
+		Region:                        state.GCP.Region,
+		Zone:                          state.GCP.Zone,
+		ApplicationDefaultCredentials: state.GCP.ApplicationDefaultCredentials,
+		ImpersonateServiceAccount:     state.GCP.ImpersonateServiceAccount,
+	}); err != nil {
 		return err
 	}
 
-	state, err = u.envIDManager.Sync(state, upConfig.Name)
+	journal := newUpJournal(u.logger, u.bblVersion, upConfig.FromStep, state)
+
+	envIDHash, err := journal.hash(envIDInput(upConfig.Name, state))
 	if err != nil {
 		return err
 	}
+	syncEnvID := !journal.skip(EnvIDStep, envIDHash)
+	syncKeyPair := syncEnvID || !journal.skip(KeyPairStep, envIDHash)
 
-	if err := u.stateStore.Set(state); err != nil {
-		return err
+	switch {
+	case syncEnvID:
+		state = journal.start(state, EnvIDStep)
+	case syncKeyPair:
+		state = journal.start(state, KeyPairStep)
 	}
 
-	state, err = u.keyPairManager.Sync(state)
-	if err != nil {
-		return err
+	// The env id, the keypair and the availability zones only read the
+	// state, so they are looked up at the same time and merged afterwards.
+	var (
+		envIDState   storage.State
+		keyPairState storage.State
+		zones        []string
+	)
+	steps := []upGraphStep{{
+		name: "availability zones",
+		run: func() error {
+			var err error
+			zones, err = u.gcpAvailabilityZoneRetriever.Get(state.GCP.Region)
+			return err
+		},
+	}}
+	if syncEnvID {
+		steps = append(steps, upGraphStep{
+			name: EnvIDStep,
+			run: func() error {
+				var err error
+				envIDState, err = u.envIDManager.Sync(state, upConfig.Name)
+				return err
+			},
+		})
+	}
+	if syncKeyPair {
+		steps = append(steps, upGraphStep{
+			name: KeyPairStep,
+			run: func() error {
+				var err error
+				keyPairState, err = u.keyPairManager.Sync(state)
+				return err
+			},
+		})
 	}
 
-	if err := u.stateStore.Set(state); err != nil {
+	if err := runUpGraph(u.logger, upWorkers, steps); err != nil {
 		return err
 	}
 
-	state.GCP.Zones, err = u.gcpAvailabilityZoneRetriever.Get(state.GCP.Region)
-	if err != nil {
+	if syncEnvID {
+		state.EnvID = envIDState.EnvID
+
+		inputHash, err := journal.hash(state.EnvID)
+		if err != nil {
+			return err
+		}
+		state = journal.complete(state, EnvIDStep, inputHash)
+	}
+
+	if syncKeyPair {
+		state.KeyPair = keyPairState.KeyPair
+
+		inputHash, err := journal.hash(state.EnvID)
+		if err != nil {
+			return err
+		}
+		state = journal.complete(state, KeyPairStep, inputHash)
+	}
+
+	state.GCP.Zones = zones
+
+	if err := u.stateStore.Set(state); err != nil {
 		return err
 	}
 
-	err = u.stateStore.Set(state)
+	inputHash, err := journal.hash(terraformInputs(state))
 	if err != nil {
 		return err
 	}
+	if !journal.skip(TerraformStep, inputHash) {
+		err = u.envIDConfirmer.Confirm(state, upConfig.ConfirmEnvID, "apply terraform to it")
+		if err != nil {
+			return err
+		}
 
-	state, err = u.terraformManager.Apply(state)
-	if err != nil {
-		return handleTerraformError(err, u.stateStore)
+		state = journal.start(state, TerraformStep)
+		state, err = u.terraformManager.Apply(state)
+		if err != nil {
+			return handleTerraformError(err, u.stateStore)
+		}
+		state = journal.complete(state, TerraformStep, inputHash)
 	}
 
 	err = u.stateStore.Set(state)
@@ -196,36 +287,77 @@ func (u GCPUp) Execute(upConfig GCPUpConfig, state storage.State) error {
 		state.BOSH.UserOpsFile = string(opsFileContents)
 
 		if upConfig.Jumpbox {
-			state, err = u.boshManager.CreateJumpbox(state, terraformOutputs)
+			inputHash, err = journal.hash(terraformOutputs)
 			if err != nil {
 				return err
 			}
-		}
-
-		state, err = u.boshManager.CreateDirector(state, terraformOutputs)
-		switch err.(type) {
-		case bosh.ManagerCreateError:
-			bcErr := err.(bosh.ManagerCreateError)
-			if setErr := u.stateStore.Set(bcErr.State()); setErr != nil {
-				errorList := helpers.Errors{}
-				errorList.Add(err)
-				errorList.Add(setErr)
-				return errorList
+			if !journal.skip(JumpboxStep, inputHash) {
+				state = journal.start(state, JumpboxStep)
+				state, err = u.boshManager.CreateJumpbox(state, terraformOutputs)
+				if err != nil {
+					return err
+				}
+				state = journal.complete(state, JumpboxStep, inputHash)
+
+				err = u.stateStore.Set(state)
+				if err != nil {
+					return err
+				}
 			}
-			return err
-		case error:
-			return err
 		}
 
-		err = u.stateStore.Set(state)
+		inputHash, err = journal.hash(terraformOutputs, directorInputs(state))
 		if err != nil {
 			return err
 		}
+		if !journal.skip(DirectorStep, inputHash) {
+			state = journal.start(state, DirectorStep)
+			state, err = u.boshManager.CreateDirector(state, terraformOutputs)
+			switch err.(type) {
+			case bosh.ManagerCreateError:
+				bcErr := err.(bosh.ManagerCreateError)
+				if setErr := u.stateStore.Set(bcErr.State()); setErr != nil {
+					errorList := helpers.Errors{}
+					errorList.Add(err)
+					errorList.Add(setErr)
+					return errorList
+				}
+				return err
+			case error:
+				return err
+			}
+			state = journal.complete(state, DirectorStep, inputHash)
+
+			err = u.stateStore.Set(state)
+			if err != nil {
+				return err
+			}
+		}
 
-		err := u.cloudConfigManager.Update(state)
+		inputHash, err = journal.hash(terraformOutputs, cloudConfigInputs(state))
 		if err != nil {
 			return err
 		}
+		if !journal.skip(CloudConfigStep, inputHash) {
+			state = journal.start(state, CloudConfigStep)
+			err = u.cloudConfigManager.Update(state)
+			if err != nil {
+				return err
+			}
+			state = journal.complete(state, CloudConfigStep, inputHash)
+
+			err = u.stateStore.Set(state)
+			if err != nil {
+				return err
+			}
+		}
+
+		if state.UploadStemcell {
+			err = u.stemcellUploader.Upload(state)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -233,7 +365,7 @@ func (u GCPUp) Execute(upConfig GCPUpConfig, state storage.State) error {
 
 func (u GCPUp) validateState(state storage.State) error {
 	switch {
-	case state.GCP.ServiceAccountKey == "":
+	case state.GCP.ServiceAccountKey == "" && state.GCP.UsesServiceAccountKey():
 		return errors.New("GCP service account key must be provided")
 	case state.GCP.ProjectID == "":
 		return errors.New("GCP project ID must be provided")
@@ -247,6 +379,10 @@ func (u GCPUp) validateState(state storage.State) error {
 }
 
 func parseUpConfig(upConfig GCPUpConfig, store storage.GCP) (storage.GCP, error) {
+	if upConfig.ServiceAccountKey != "" && upConfig.ApplicationDefaultCredentials {
+		return storage.GCP{}, errors.New("--gcp-service-account-key and --gcp-application-default-credentials cannot be used together")
+	}
+
 	var serviceAccountKey string
 	if upConfig.ServiceAccountKey != "" {
 		var err error
@@ -259,6 +395,14 @@ func parseUpConfig(upConfig GCPUpConfig, store storage.GCP) (storage.GCP, error)
 	gcpState := store
 	if serviceAccountKey != "" {
 		gcpState.ServiceAccountKey = serviceAccountKey
+		gcpState.ApplicationDefaultCredentials = false
+	}
+	if upConfig.ApplicationDefaultCredentials {
+		gcpState.ServiceAccountKey = ""
+		gcpState.ApplicationDefaultCredentials = true
+	}
+	if upConfig.ImpersonateServiceAccount != "" {
+		gcpState.ImpersonateServiceAccount = upConfig.ImpersonateServiceAccount
 	}
 	if upConfig.ProjectID != "" {
 		gcpState.ProjectID = upConfig.ProjectID
@@ -269,11 +413,20 @@ func parseUpConfig(upConfig GCPUpConfig, store storage.GCP) (storage.GCP, error)
 	if upConfig.Region != "" {
 		gcpState.Region = upConfig.Region
 	}
+	if upConfig.InternalOnly {
+		gcpState.InternalOnly = true
+	}
+
+	// The director cannot use credentials bbl does not keep, so it gets a
+	// service account of its own instead.
+	if upConfig.DirectorServiceAccount || !gcpState.UsesServiceAccountKey() {
+		gcpState.DirectorServiceAccount = true
+	}
 
 	return gcpState, nil
 }
 
-func fastFailConflictingGCPState(configGCP storage.GCP, stateGCP storage.GCP) error {
+func fastFailConflictingGCPState(configGCP storage.GCP, stateGCP storage.GCP, jumpbox bool) error {
 	if stateGCP.Region != "" && stateGCP.Region != configGCP.Region {
 		return errors.New(fmt.Sprintf("The region cannot be changed for an existing environment. The current region is %s.", stateGCP.Region))
 	}
@@ -286,6 +439,14 @@ func fastFailConflictingGCPState(configGCP storage.GCP, stateGCP storage.GCP) er
 		return errors.New(fmt.Sprintf("The project id cannot be changed for an existing environment. The current project id is %s.", stateGCP.ProjectID))
 	}
 
+	if stateGCP.Region != "" && stateGCP.InternalOnly != configGCP.InternalOnly {
+		return errors.New("Internal only mode cannot be enabled for an existing environment.")
+	}
+
+	if configGCP.InternalOnly && jumpbox {
+		return errors.New("Internal only mode cannot be used with a jumpbox.")
+	}
+
 	return nil
 }
 
@@ -40,6 +40,8 @@ var _ = Describe("GCPUp", func() {
 		logger                *fakes.Logger
 		terraformManagerError *fakes.TerraformManagerError
 		gcpZones              *fakes.Zones
+		stemcellUploader      *fakes.StemcellUploader
+		envIDConfirmer        *fakes.EnvIDConfirmer
 
 		serviceAccountKeyPath string
 		serviceAccountKey     string
@@ -132,6 +134,9 @@ var _ = Describe("GCPUp", func() {
 		boshManager.CreateJumpboxCall.Returns.State = expectedBOSHState
 		gcpZones.GetCall.Returns.Zones = expectedAvailabilityZones
 
+		stemcellUploader = &fakes.StemcellUploader{}
+		envIDConfirmer = &fakes.EnvIDConfirmer{}
+
 		gcpUp = commands.NewGCPUp(commands.NewGCPUpArgs{
 			StateStore:                   stateStore,
 			KeyPairManager:               keyPairManager,
@@ -142,6 +147,9 @@ var _ = Describe("GCPUp", func() {
 			EnvIDManager:                 envIDManager,
 			CloudConfigManager:           cloudConfigManager,
 			GCPAvailabilityZoneRetriever: gcpZones,
+			StemcellUploader:             stemcellUploader,
+			EnvIDConfirmer:               envIDConfirmer,
+			BBLVersion:                   "some-bbl-version",
 		})
 
 		body, err := ioutil.ReadFile("fixtures/terraform_template_no_lb.tf")
@@ -187,14 +195,9 @@ var _ = Describe("GCPUp", func() {
 				Expect(envIDManager.SyncCall.Receives.Name).To(BeEmpty())
 			})
 
-			By("saving the resulting state with the env ID", func() {
-				Expect(stateStore.SetCall.CallCount).To(BeNumerically(">=", 1))
-				Expect(stateStore.SetCall.Receives[0].State).To(Equal(expectedEnvIDState))
-			})
-
 			By("syncing the keypair", func() {
 				Expect(keyPairManager.SyncCall.CallCount).To(Equal(1))
-				Expect(keyPairManager.SyncCall.Receives.State).To(Equal(storage.State{
+				Expect(withoutUpJournal(keyPairManager.SyncCall.Receives.State)).To(Equal(storage.State{
 					IAAS:  "gcp",
 					EnvID: "some-env-id",
 					GCP: storage.GCP{
@@ -206,52 +209,247 @@ var _ = Describe("GCPUp", func() {
 				}))
 			})
 
-			By("saving the key pair to the state", func() {
-				Expect(stateStore.SetCall.CallCount).To(BeNumerically(">=", 2))
-				Expect(stateStore.SetCall.Receives[1].State).To(Equal(expectedKeyPairState))
-			})
-
 			By("getting gcp availability zones", func() {
 				Expect(gcpZones.GetCall.CallCount).To(Equal(1))
 				Expect(gcpZones.GetCall.Receives.Region).To(Equal("some-region"))
 			})
 
-			By("saving gcp zones to the state", func() {
-				Expect(stateStore.SetCall.CallCount).To(BeNumerically(">=", 3))
-				Expect(stateStore.SetCall.Receives[2].State).To(Equal(expectedZonesState))
+			By("saving the env ID, key pair and gcp zones to the state", func() {
+				Expect(stateStore.SetCall.CallCount).To(BeNumerically(">=", 1))
+				Expect(withoutUpJournal(stateStore.SetCall.Receives[0].State)).To(Equal(expectedZonesState))
 			})
 
 			By("creating gcp resources via terraform", func() {
 				Expect(terraformManager.ApplyCall.CallCount).To(Equal(1))
-				Expect(terraformManager.ApplyCall.Receives.BBLState).To(Equal(expectedZonesState))
+				Expect(withoutUpJournal(terraformManager.ApplyCall.Receives.BBLState)).To(Equal(expectedZonesState))
 			})
 
 			By("saving the terraform state to the state", func() {
-				Expect(stateStore.SetCall.CallCount).To(BeNumerically(">=", 4))
-				Expect(stateStore.SetCall.Receives[3].State).To(Equal(expectedTerraformState))
+				Expect(stateStore.SetCall.CallCount).To(BeNumerically(">=", 2))
+				Expect(withoutUpJournal(stateStore.SetCall.Receives[1].State)).To(Equal(expectedTerraformState))
 			})
 
 			By("getting the terraform outputs", func() {
 				Expect(terraformManager.GetOutputsCall.CallCount).To(Equal(1))
-				Expect(terraformManager.GetOutputsCall.Receives.BBLState).To(Equal(expectedTerraformState))
+				Expect(withoutUpJournal(terraformManager.GetOutputsCall.Receives.BBLState)).To(Equal(expectedTerraformState))
 			})
 
 			By("creating a bosh", func() {
 				Expect(err).NotTo(HaveOccurred())
-				Expect(boshManager.CreateDirectorCall.Receives.State).To(Equal(expectedTerraformState))
+				Expect(withoutUpJournal(boshManager.CreateDirectorCall.Receives.State)).To(Equal(expectedTerraformState))
 			})
 
 			By("saving the bosh state to the state", func() {
-				Expect(stateStore.SetCall.CallCount).To(BeNumerically(">=", 5))
-				Expect(stateStore.SetCall.Receives[4].State).To(Equal(expectedBOSHState))
+				Expect(stateStore.SetCall.CallCount).To(BeNumerically(">=", 3))
+				Expect(withoutUpJournal(stateStore.SetCall.Receives[2].State)).To(Equal(expectedBOSHState))
 			})
 
 			By("updating the cloud config", func() {
 				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(1))
-				Expect(cloudConfigManager.UpdateCall.Receives.State).To(Equal(expectedBOSHState))
+				Expect(withoutUpJournal(cloudConfigManager.UpdateCall.Receives.State)).To(Equal(expectedBOSHState))
+			})
+		})
+
+		It("logs how long syncing the env ID, key pair and zones took", func() {
+			err := gcpUp.Execute(commands.GCPUpConfig{
+				ServiceAccountKey: serviceAccountKeyPath,
+				ProjectID:         "some-project-id",
+				Zone:              "some-zone",
+				Region:            "some-region",
+			}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.StepCall.Messages).To(ContainElement(MatchRegexp("^env-id finished in ")))
+			Expect(logger.StepCall.Messages).To(ContainElement(MatchRegexp("^keypair finished in ")))
+			Expect(logger.StepCall.Messages).To(ContainElement(MatchRegexp("^availability zones finished in ")))
+		})
+
+		Describe("up journal", func() {
+			var (
+				upConfig     commands.GCPUpConfig
+				initialState storage.State
+			)
+
+			BeforeEach(func() {
+				upConfig = commands.GCPUpConfig{
+					ServiceAccountKey: serviceAccountKeyPath,
+					ProjectID:         "some-project-id",
+					Zone:              "some-zone",
+					Region:            "some-region",
+				}
+				initialState = storage.State{IAAS: "gcp"}
+			})
+
+			lastSavedState := func() storage.State {
+				return stateStore.SetCall.Receives[len(stateStore.SetCall.Receives)-1].State
+			}
+
+			stepNames := func(state storage.State) []string {
+				names := []string{}
+				for _, step := range state.UpJournal {
+					names = append(names, step.Name)
+				}
+				return names
+			}
+
+			It("confirms the env id of a protected environment only when terraform is applied", func() {
+				upConfig.ConfirmEnvID = "some-env-id"
+				err := gcpUp.Execute(upConfig, initialState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(envIDConfirmer.ConfirmCall.CallCount).To(Equal(1))
+				Expect(envIDConfirmer.ConfirmCall.Receives.State.TFState).To(BeEmpty())
+				Expect(envIDConfirmer.ConfirmCall.Receives.ConfirmEnvID).To(Equal("some-env-id"))
+				Expect(envIDConfirmer.ConfirmCall.Receives.Action).To(Equal("apply terraform to it"))
+
+				err = gcpUp.Execute(upConfig, lastSavedState())
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(envIDConfirmer.ConfirmCall.CallCount).To(Equal(1))
+			})
+
+			It("does not apply terraform when the env id is not confirmed", func() {
+				envIDConfirmer.ConfirmCall.Returns.Error = errors.New("not confirmed")
+
+				err := gcpUp.Execute(upConfig, initialState)
+				Expect(err).To(MatchError("not confirmed"))
+
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(0))
+			})
+
+			It("records every completed step in the state", func() {
+				err := gcpUp.Execute(upConfig, initialState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stepNames(lastSavedState())).To(Equal([]string{"env-id", "keypair", "terraform", "director", "cloud-config"}))
+				for _, step := range lastSavedState().UpJournal {
+					Expect(step.InputHash).To(HaveLen(64))
+				}
+			})
+
+			It("skips the steps whose inputs have not changed on a re-run", func() {
+				err := gcpUp.Execute(upConfig, initialState)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = gcpUp.Execute(upConfig, lastSavedState())
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(envIDManager.SyncCall.CallCount).To(Equal(1))
+				Expect(keyPairManager.SyncCall.CallCount).To(Equal(1))
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(1))
+				Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(1))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(1))
+				Expect(logger.StepCall.Messages).To(ContainElement("skipping terraform, its inputs have not changed"))
+			})
+
+			It("re-runs a step whose inputs changed and every step after it", func() {
+				err := gcpUp.Execute(upConfig, initialState)
+				Expect(err).NotTo(HaveOccurred())
+
+				state := lastSavedState()
+				state.LB = storage.LB{Type: "concourse"}
+				err = gcpUp.Execute(upConfig, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(keyPairManager.SyncCall.CallCount).To(Equal(1))
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(2))
+				Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(2))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(2))
+			})
+
+			It("re-runs every step from --from-step", func() {
+				err := gcpUp.Execute(upConfig, initialState)
+				Expect(err).NotTo(HaveOccurred())
+
+				upConfig.FromStep = "director"
+				err = gcpUp.Execute(upConfig, lastSavedState())
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(1))
+				Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(2))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(2))
+			})
+
+			It("re-runs every step after bbl is upgraded", func() {
+				err := gcpUp.Execute(upConfig, initialState)
+				Expect(err).NotTo(HaveOccurred())
+
+				upgradedGCPUp := commands.NewGCPUp(commands.NewGCPUpArgs{
+					StateStore:                   stateStore,
+					KeyPairManager:               keyPairManager,
+					GCPProvider:                  gcpClientProvider,
+					TerraformManager:             terraformManager,
+					BoshManager:                  boshManager,
+					Logger:                       logger,
+					EnvIDManager:                 envIDManager,
+					CloudConfigManager:           cloudConfigManager,
+					GCPAvailabilityZoneRetriever: gcpZones,
+					StemcellUploader:             stemcellUploader,
+					EnvIDConfirmer:               envIDConfirmer,
+					BBLVersion:                   "some-newer-bbl-version",
+				})
+				err = upgradedGCPUp.Execute(upConfig, lastSavedState())
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(envIDManager.SyncCall.CallCount).To(Equal(2))
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(2))
+				Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(2))
+			})
+
+			It("forgets a step and the steps after it before running it, so a failure is retried", func() {
+				err := gcpUp.Execute(upConfig, initialState)
+				Expect(err).NotTo(HaveOccurred())
+
+				upConfig.FromStep = "director"
+				err = gcpUp.Execute(upConfig, lastSavedState())
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stepNames(boshManager.CreateDirectorCall.Receives.State)).To(Equal([]string{"env-id", "keypair", "terraform"}))
+			})
+		})
+
+		Context("when stemcell upload is opted in to", func() {
+			BeforeEach(func() {
+				terraformManager.ApplyCall.Returns.BBLState.UploadStemcell = true
+			})
+
+			It("uploads the stemcell after updating the cloud config", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey: serviceAccountKeyPath,
+					ProjectID:         "some-project-id",
+					Zone:              "some-zone",
+					Region:            "us-west1",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stemcellUploader.UploadCall.CallCount).To(Equal(1))
+				Expect(stemcellUploader.UploadCall.Receives.State.BOSH.DirectorAddress).To(Equal(cloudConfigManager.UpdateCall.Receives.State.BOSH.DirectorAddress))
+			})
+
+			It("returns an error when the stemcell fails to upload", func() {
+				stemcellUploader.UploadCall.Returns.Error = errors.New("failed to upload stemcell")
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey: serviceAccountKeyPath,
+					ProjectID:         "some-project-id",
+					Zone:              "some-zone",
+					Region:            "us-west1",
+				}, storage.State{})
+				Expect(err).To(MatchError("failed to upload stemcell"))
 			})
 		})
 
+		It("does not upload a stemcell unless opted in to", func() {
+			err := gcpUp.Execute(commands.GCPUpConfig{
+				ServiceAccountKey: serviceAccountKeyPath,
+				ProjectID:         "some-project-id",
+				Zone:              "some-zone",
+				Region:            "us-west1",
+			}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stemcellUploader.UploadCall.CallCount).To(Equal(0))
+		})
+
 		Context("when the serviceAccountKey is passed as a JSON string", func() {
 			It("sets the serviceAccountKey", func() {
 				err := gcpUp.Execute(commands.GCPUpConfig{
@@ -328,8 +526,8 @@ var _ = Describe("GCPUp", func() {
 				Expect(boshManager.CreateJumpboxCall.CallCount).To(Equal(0))
 				Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(0))
 				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(0))
-				Expect(stateStore.SetCall.CallCount).To(Equal(4))
-				Expect(stateStore.SetCall.Receives[3].State.NoDirector).To(Equal(true))
+				Expect(stateStore.SetCall.CallCount).To(Equal(2))
+				Expect(stateStore.SetCall.Receives[1].State.NoDirector).To(Equal(true))
 			})
 
 			Context("when re-bbling up an environment with no director", func() {
@@ -348,8 +546,8 @@ var _ = Describe("GCPUp", func() {
 					Expect(boshManager.CreateJumpboxCall.CallCount).To(Equal(0))
 					Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(0))
 					Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(0))
-					Expect(stateStore.SetCall.CallCount).To(Equal(4))
-					Expect(stateStore.SetCall.Receives[3].State.NoDirector).To(Equal(true))
+					Expect(stateStore.SetCall.CallCount).To(Equal(2))
+					Expect(stateStore.SetCall.Receives[1].State.NoDirector).To(Equal(true))
 				})
 			})
 		})
@@ -379,6 +577,70 @@ var _ = Describe("GCPUp", func() {
 			})
 		})
 
+		Context("when the internal only flag is provided", func() {
+			It("saves internal only mode to the state", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey: serviceAccountKeyPath,
+					ProjectID:         "some-project-id",
+					Zone:              "some-zone",
+					Region:            "us-west1",
+					InternalOnly:      true,
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stateStore.SetCall.Receives[0].State.GCP.InternalOnly).To(BeTrue())
+				Expect(terraformManager.ApplyCall.Receives.BBLState.GCP.InternalOnly).To(BeTrue())
+			})
+		})
+
+		Context("when application default credentials are used", func() {
+			It("does not require a service account key and gives the director its own service account", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ApplicationDefaultCredentials: true,
+					ImpersonateServiceAccount:     "bbl@some-project-id.iam.gserviceaccount.com",
+					ProjectID:                     "some-project-id",
+					Zone:                          "some-zone",
+					Region:                        "us-west1",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				gcpState := stateStore.SetCall.Receives[0].State.GCP
+				Expect(gcpState.ServiceAccountKey).To(BeEmpty())
+				Expect(gcpState.ApplicationDefaultCredentials).To(BeTrue())
+				Expect(gcpState.ImpersonateServiceAccount).To(Equal("bbl@some-project-id.iam.gserviceaccount.com"))
+				Expect(gcpState.DirectorServiceAccount).To(BeTrue())
+				Expect(terraformManager.ApplyCall.Receives.BBLState.GCP.DirectorServiceAccount).To(BeTrue())
+			})
+
+			It("returns an error when a service account key is also provided", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey:             serviceAccountKeyPath,
+					ApplicationDefaultCredentials: true,
+					ProjectID:                     "some-project-id",
+					Zone:                          "some-zone",
+					Region:                        "us-west1",
+				}, storage.State{})
+				Expect(err).To(MatchError("--gcp-service-account-key and --gcp-application-default-credentials cannot be used together"))
+				Expect(stateStore.SetCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when the director service account flag is provided", func() {
+			It("saves the director service account to the state", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey:      serviceAccountKeyPath,
+					ProjectID:              "some-project-id",
+					Zone:                   "some-zone",
+					Region:                 "us-west1",
+					DirectorServiceAccount: true,
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stateStore.SetCall.Receives[0].State.GCP.ServiceAccountKey).To(Equal(serviceAccountKey))
+				Expect(stateStore.SetCall.Receives[0].State.GCP.DirectorServiceAccount).To(BeTrue())
+			})
+		})
+
 		Context("reentrance", func() {
 			var (
 				updatedServiceAccountKey     string
@@ -428,8 +690,8 @@ var _ = Describe("GCPUp", func() {
 				}, storage.State{})
 				Expect(err).To(MatchError("terraform manager failed"))
 
-				Expect(stateStore.SetCall.CallCount).To(Equal(3))
-				Expect(stateStore.SetCall.Receives[2].State).To(Equal(expectedZonesState))
+				Expect(stateStore.SetCall.CallCount).To(Equal(1))
+				Expect(withoutUpJournal(stateStore.SetCall.Receives[0].State)).To(Equal(expectedZonesState))
 			})
 
 			It("calls terraform manager with previous state", func() {
@@ -447,7 +709,7 @@ var _ = Describe("GCPUp", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(terraformManager.ApplyCall.CallCount).To(Equal(1))
-				Expect(terraformManager.ApplyCall.Receives.BBLState).To(Equal(expectedZonesState))
+				Expect(withoutUpJournal(terraformManager.ApplyCall.Receives.BBLState)).To(Equal(expectedZonesState))
 			})
 		})
 
@@ -551,6 +813,36 @@ var _ = Describe("GCPUp", func() {
 					})
 					Expect(err).To(MatchError("The project id cannot be changed for an existing environment. The current project id is some-project-id."))
 				})
+
+				It("returns an error when internal only mode is enabled for an existing environment", func() {
+					err := gcpUp.Execute(commands.GCPUpConfig{
+						ServiceAccountKey: serviceAccountKeyPath,
+						ProjectID:         "some-project-id",
+						Zone:              "some-zone",
+						Region:            "us-west1",
+						InternalOnly:      true,
+					}, storage.State{
+						GCP: storage.GCP{
+							ServiceAccountKey: serviceAccountKey,
+							ProjectID:         "some-project-id",
+							Zone:              "some-zone",
+							Region:            "us-west1",
+						},
+					})
+					Expect(err).To(MatchError("Internal only mode cannot be enabled for an existing environment."))
+				})
+
+				It("returns an error when internal only mode is combined with a jumpbox", func() {
+					err := gcpUp.Execute(commands.GCPUpConfig{
+						ServiceAccountKey: serviceAccountKeyPath,
+						ProjectID:         "some-project-id",
+						Zone:              "some-zone",
+						Region:            "us-west1",
+						InternalOnly:      true,
+						Jumpbox:           true,
+					}, storage.State{})
+					Expect(err).To(MatchError("Internal only mode cannot be used with a jumpbox."))
+				})
 			})
 
 			Context("when a bbl environment exists with a bosh director", func() {
@@ -637,7 +929,7 @@ var _ = Describe("GCPUp", func() {
 				Expect(err).To(MatchError("environment already exists"))
 			})
 
-			It("returns an error when state store fails to set after syncing env id", func() {
+			It("returns an error when state store fails to set after syncing the env id, keypair and zones", func() {
 				stateStore.SetCall.Returns = []fakes.SetCallReturn{{Error: errors.New("set call failed")}}
 				err := gcpUp.Execute(commands.GCPUpConfig{
 					ServiceAccountKey: serviceAccountKeyPath,
@@ -660,18 +952,6 @@ var _ = Describe("GCPUp", func() {
 				Expect(err).To(MatchError("keypair sync failed"))
 			})
 
-			It("returns an error when the state fails to be set after updating keypair", func() {
-				stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {errors.New("state failed to be set")}}
-
-				err := gcpUp.Execute(commands.GCPUpConfig{
-					ServiceAccountKey: serviceAccountKeyPath,
-					ProjectID:         "some-project-id",
-					Zone:              "some-zone",
-					Region:            "us-west1",
-				}, storage.State{})
-				Expect(err).To(MatchError("state failed to be set"))
-			})
-
 			It("returns an error when GCP AZs cannot be retrieved", func() {
 				gcpZones.GetCall.Returns.Error = errors.New("can't get gcp availability zones")
 
@@ -684,18 +964,6 @@ var _ = Describe("GCPUp", func() {
 				Expect(err).To(MatchError("can't get gcp availability zones"))
 			})
 
-			It("returns an error when the state fails to be set after retrieving GCP zones", func() {
-				stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {errors.New("state failed to be set")}}
-
-				err := gcpUp.Execute(commands.GCPUpConfig{
-					ServiceAccountKey: serviceAccountKeyPath,
-					ProjectID:         "some-project-id",
-					Zone:              "some-zone",
-					Region:            "us-west1",
-				}, storage.State{})
-				Expect(err).To(MatchError("state failed to be set"))
-			})
-
 			Context("terraform manager error handling", func() {
 				BeforeEach(func() {
 					terraformManagerError.ErrorCall.Returns = "failed to apply"
@@ -719,8 +987,8 @@ var _ = Describe("GCPUp", func() {
 					})
 
 					Expect(err).To(MatchError("failed to apply"))
-					Expect(stateStore.SetCall.CallCount).To(Equal(4))
-					Expect(stateStore.SetCall.Receives[3].State.TFState).To(Equal("some-updated-tf-state"))
+					Expect(stateStore.SetCall.CallCount).To(Equal(2))
+					Expect(stateStore.SetCall.Receives[1].State.TFState).To(Equal("some-updated-tf-state"))
 				})
 
 				It("returns an error when the applier fails and we cannot retrieve the updated bbl state", func() {
@@ -739,7 +1007,7 @@ var _ = Describe("GCPUp", func() {
 					})
 
 					Expect(err).To(MatchError("the following errors occurred:\nfailed to apply,\nsome-bbl-state-error"))
-					Expect(stateStore.SetCall.CallCount).To(Equal(3))
+					Expect(stateStore.SetCall.CallCount).To(Equal(1))
 				})
 
 				It("returns an error if applier fails with non terraform manager apply error", func() {
@@ -771,17 +1039,17 @@ var _ = Describe("GCPUp", func() {
 
 					terraformManager.ApplyCall.Returns.Error = terraformManagerError
 
-					stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {}, {}, {errors.New("state failed to be set")}}
+					stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {errors.New("state failed to be set")}}
 					err := gcpUp.Execute(commands.GCPUpConfig{}, incomingState)
 
 					Expect(err).To(MatchError("the following errors occurred:\nfailed to apply,\nstate failed to be set"))
-					Expect(stateStore.SetCall.CallCount).To(Equal(4))
-					Expect(stateStore.SetCall.Receives[3].State.TFState).To(Equal("some-updated-tf-state"))
+					Expect(stateStore.SetCall.CallCount).To(Equal(2))
+					Expect(stateStore.SetCall.Receives[1].State.TFState).To(Equal("some-updated-tf-state"))
 				})
 			})
 
 			It("returns an error when the state fails to be set after applying terraform", func() {
-				stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {}, {}, {errors.New("state failed to be set")}}
+				stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {errors.New("state failed to be set")}}
 
 				err := gcpUp.Execute(commands.GCPUpConfig{
 					ServiceAccountKey: serviceAccountKeyPath,
@@ -837,12 +1105,12 @@ var _ = Describe("GCPUp", func() {
 					It("returns the error and saves the state", func() {
 						err := gcpUp.Execute(commands.GCPUpConfig{}, incomingState)
 						Expect(err).To(MatchError("failed to create"))
-						Expect(stateStore.SetCall.CallCount).To(Equal(5))
-						Expect(stateStore.SetCall.Receives[4].State.BOSH.State).To(Equal(expectedBOSHState))
+						Expect(stateStore.SetCall.CallCount).To(Equal(3))
+						Expect(stateStore.SetCall.Receives[2].State.BOSH.State).To(Equal(expectedBOSHState))
 					})
 
 					It("returns a compound error when it fails to save the state", func() {
-						stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {}, {}, {}, {errors.New("state failed to be set")}}
+						stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {}, {errors.New("state failed to be set")}}
 
 						err := gcpUp.Execute(commands.GCPUpConfig{
 							ServiceAccountKey: serviceAccountKeyPath,
@@ -851,8 +1119,8 @@ var _ = Describe("GCPUp", func() {
 							Region:            "us-west1",
 						}, storage.State{})
 						Expect(err).To(MatchError("the following errors occurred:\nfailed to create,\nstate failed to be set"))
-						Expect(stateStore.SetCall.CallCount).To(Equal(5))
-						Expect(stateStore.SetCall.Receives[4].State.BOSH.State).To(Equal(expectedBOSHState))
+						Expect(stateStore.SetCall.CallCount).To(Equal(3))
+						Expect(stateStore.SetCall.Receives[2].State.BOSH.State).To(Equal(expectedBOSHState))
 					})
 				})
 
@@ -870,7 +1138,7 @@ var _ = Describe("GCPUp", func() {
 			})
 
 			It("returns an error when the state fails to be set after deploying bosh", func() {
-				stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {}, {}, {errors.New("state failed to be set")}}
+				stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {}, {errors.New("state failed to be set")}}
 
 				err := gcpUp.Execute(commands.GCPUpConfig{
 					ServiceAccountKey: serviceAccountKeyPath,
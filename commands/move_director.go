@@ -0,0 +1,243 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/helpers"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	MoveDirectorCommand = "move-director"
+)
+
+type volumeMigrator interface {
+	Migrate(instanceID, volumeID, availabilityZone string) (string, error)
+}
+
+type MoveDirector struct {
+	logger             logger
+	stateStore         stateStore
+	stateValidator     stateValidator
+	terraformManager   terraformApplier
+	boshManager        boshManager
+	cloudConfigManager cloudConfigManager
+	volumeMigrator     volumeMigrator
+}
+
+type moveDirectorConfig struct {
+	az string
+}
+
+func NewMoveDirector(logger logger, stateStore stateStore, stateValidator stateValidator, terraformManager terraformApplier,
+	boshManager boshManager, cloudConfigManager cloudConfigManager, volumeMigrator volumeMigrator) MoveDirector {
+	return MoveDirector{
+		logger:             logger,
+		stateStore:         stateStore,
+		stateValidator:     stateValidator,
+		terraformManager:   terraformManager,
+		boshManager:        boshManager,
+		cloudConfigManager: cloudConfigManager,
+		volumeMigrator:     volumeMigrator,
+	}
+}
+
+func (m MoveDirector) CheckFastFails(subcommandFlags []string, state storage.State) error {
+	err := m.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	if state.IAAS != "aws" {
+		return errors.New("move-director is only supported on aws")
+	}
+
+	if state.NoDirector || state.BOSH.IsEmpty() {
+		return errors.New("There is no director to move.")
+	}
+
+	config, err := m.parseFlags(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	if config.az == "" {
+		return errors.New("--az is required")
+	}
+
+	return fastFailBOSHVersion(m.boshManager)
+}
+
+func (m MoveDirector) Execute(subcommandFlags []string, state storage.State) error {
+	config, err := m.parseFlags(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	terraformOutputs, err := m.terraformManager.GetOutputs(state)
+	if err != nil {
+		return err
+	}
+
+	primaryAZ, _ := terraformOutputs["bosh_subnet_availability_zone"].(string)
+	currentAZ := state.AWS.DirectorAZ
+	if currentAZ == "" {
+		currentAZ = primaryAZ
+	}
+
+	if config.az == currentAZ {
+		return fmt.Errorf("The director is already in availability zone %q.", config.az)
+	}
+
+	if config.az != primaryAZ && !containsString(state.AWS.BOSHAZs, config.az) {
+		state.AWS.BOSHAZs = append(state.AWS.BOSHAZs, config.az)
+
+		state, err = m.terraformManager.Apply(state)
+		if err != nil {
+			return handleTerraformError(err, m.stateStore)
+		}
+
+		err = m.stateStore.Set(state)
+		if err != nil {
+			return err
+		}
+
+		terraformOutputs, err = m.terraformManager.GetOutputs(state)
+		if err != nil {
+			return err
+		}
+	}
+
+	boshState := copyBOSHState(state.BOSH.State)
+	diskCID := currentDiskCID(boshState)
+	if diskCID != "" {
+		vmCID, _ := boshState["current_vm_cid"].(string)
+
+		m.logger.Step("moving director persistent disk to %s", config.az)
+		newDiskCID, err := m.volumeMigrator.Migrate(vmCID, diskCID, config.az)
+		if err != nil {
+			return err
+		}
+
+		setCurrentDiskCID(boshState, newDiskCID)
+
+		// The old VM is gone, so create-env has to create a new one and attach the
+		// copied disk to it.
+		boshState["current_vm_cid"] = ""
+		boshState["current_manifest_sha"] = ""
+	}
+
+	state.BOSH.State = boshState
+	state.AWS.DirectorAZ = config.az
+	if config.az == primaryAZ {
+		state.AWS.DirectorAZ = ""
+	}
+
+	err = m.stateStore.Set(state)
+	if err != nil {
+		return err
+	}
+
+	state, err = m.boshManager.CreateDirector(state, terraformOutputs)
+	switch err.(type) {
+	case bosh.ManagerCreateError:
+		bcErr := err.(bosh.ManagerCreateError)
+		if setErr := m.stateStore.Set(bcErr.State()); setErr != nil {
+			errorList := helpers.Errors{}
+			errorList.Add(err)
+			errorList.Add(setErr)
+			return errorList
+		}
+		return err
+	case error:
+		return err
+	}
+
+	err = m.stateStore.Set(state)
+	if err != nil {
+		return err
+	}
+
+	return m.cloudConfigManager.Update(state)
+}
+
+func (m MoveDirector) Flags() flags.Flags {
+	return m.flags(&moveDirectorConfig{})
+}
+
+func (MoveDirector) flags(config *moveDirectorConfig) flags.Flags {
+	moveDirectorFlags := flags.New("move-director")
+	moveDirectorFlags.String(&config.az, "az", "")
+
+	return moveDirectorFlags
+}
+
+func (m MoveDirector) parseFlags(subcommandFlags []string) (moveDirectorConfig, error) {
+	config := moveDirectorConfig{}
+	err := m.flags(&config).Parse(subcommandFlags)
+	if err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func copyBOSHState(boshState map[string]interface{}) map[string]interface{} {
+	copied := map[string]interface{}{}
+	for key, value := range boshState {
+		copied[key] = value
+	}
+
+	return copied
+}
+
+func currentDiskCID(boshState map[string]interface{}) string {
+	diskID, _ := boshState["current_disk_id"].(string)
+	if diskID == "" {
+		return ""
+	}
+
+	disks, _ := boshState["disks"].([]interface{})
+	for _, disk := range disks {
+		disk, _ := disk.(map[string]interface{})
+		if disk["id"] == diskID {
+			cid, _ := disk["cid"].(string)
+			return cid
+		}
+	}
+
+	return ""
+}
+
+func setCurrentDiskCID(boshState map[string]interface{}, cid string) {
+	diskID := boshState["current_disk_id"]
+
+	disks, _ := boshState["disks"].([]interface{})
+	movedDisks := []interface{}{}
+	for _, disk := range disks {
+		disk, _ := disk.(map[string]interface{})
+		movedDisk := map[string]interface{}{}
+		for key, value := range disk {
+			movedDisk[key] = value
+		}
+		if movedDisk["id"] == diskID {
+			movedDisk["cid"] = cid
+		}
+		movedDisks = append(movedDisks, movedDisk)
+	}
+
+	boshState["disks"] = movedDisks
+}
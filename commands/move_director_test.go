@@ -0,0 +1,236 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MoveDirector", func() {
+	var (
+		logger             *fakes.Logger
+		stateStore         *fakes.StateStore
+		stateValidator     *fakes.StateValidator
+		terraformManager   *fakes.TerraformManager
+		boshManager        *fakes.BOSHManager
+		cloudConfigManager *fakes.CloudConfigManager
+		volumeMigrator     *fakes.VolumeMigrator
+
+		command commands.MoveDirector
+
+		incomingState storage.State
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		stateStore = &fakes.StateStore{}
+		stateValidator = &fakes.StateValidator{}
+		terraformManager = &fakes.TerraformManager{}
+		boshManager = &fakes.BOSHManager{}
+		boshManager.VersionCall.Returns.Version = "2.0.24"
+		cloudConfigManager = &fakes.CloudConfigManager{}
+		volumeMigrator = &fakes.VolumeMigrator{}
+		volumeMigrator.MigrateCall.Returns.VolumeID = "vol-new"
+
+		terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+			"bosh_subnet_availability_zone": "some-primary-az",
+		}
+
+		incomingState = storage.State{
+			IAAS: "aws",
+			AWS: storage.AWS{
+				BOSHAZs: []string{"some-failover-az"},
+			},
+			BOSH: storage.BOSH{
+				DirectorName: "some-director-name",
+				State: map[string]interface{}{
+					"current_vm_cid":       "i-director",
+					"current_disk_id":      "some-disk-id",
+					"current_manifest_sha": "some-sha",
+					"disks": []interface{}{
+						map[string]interface{}{"id": "other-disk-id", "cid": "vol-other"},
+						map[string]interface{}{"id": "some-disk-id", "cid": "vol-old"},
+					},
+				},
+			},
+		}
+
+		command = commands.NewMoveDirector(logger, stateStore, stateValidator, terraformManager, boshManager, cloudConfigManager, volumeMigrator)
+	})
+
+	Describe("CheckFastFails", func() {
+		It("returns an error when state validator fails", func() {
+			stateValidator.ValidateCall.Returns.Error = errors.New("state validator failed")
+			err := command.CheckFastFails([]string{"--az", "some-failover-az"}, incomingState)
+			Expect(err).To(MatchError("state validator failed"))
+		})
+
+		It("returns an error when the iaas is not aws", func() {
+			incomingState.IAAS = "gcp"
+			err := command.CheckFastFails([]string{"--az", "some-failover-az"}, incomingState)
+			Expect(err).To(MatchError("move-director is only supported on aws"))
+		})
+
+		It("returns an error when there is no director", func() {
+			incomingState.BOSH = storage.BOSH{}
+			err := command.CheckFastFails([]string{"--az", "some-failover-az"}, incomingState)
+			Expect(err).To(MatchError("There is no director to move."))
+		})
+
+		It("returns an error when the az is missing", func() {
+			err := command.CheckFastFails([]string{}, incomingState)
+			Expect(err).To(MatchError("--az is required"))
+		})
+
+		It("returns an error when the bosh version is too old", func() {
+			boshManager.VersionCall.Returns.Version = "1.9.0"
+			err := command.CheckFastFails([]string{"--az", "some-failover-az"}, incomingState)
+			Expect(err).To(MatchError("BOSH version must be at least v2.0.24"))
+		})
+	})
+
+	Describe("Execute", func() {
+		It("moves the persistent disk and recreates the director in the availability zone", func() {
+			boshManager.CreateDirectorCall.Returns.State = storage.State{
+				BOSH: storage.BOSH{
+					DirectorName: "some-director-name",
+					State: map[string]interface{}{
+						"new-key": "new-value",
+					},
+				},
+			}
+
+			err := command.Execute([]string{"--az", "some-failover-az"}, incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(terraformManager.ApplyCall.CallCount).To(Equal(0))
+
+			Expect(volumeMigrator.MigrateCall.Receives.InstanceID).To(Equal("i-director"))
+			Expect(volumeMigrator.MigrateCall.Receives.VolumeID).To(Equal("vol-old"))
+			Expect(volumeMigrator.MigrateCall.Receives.AvailabilityZone).To(Equal("some-failover-az"))
+
+			Expect(boshManager.CreateDirectorCall.Receives.State.AWS.DirectorAZ).To(Equal("some-failover-az"))
+			Expect(boshManager.CreateDirectorCall.Receives.State.BOSH.State).To(Equal(map[string]interface{}{
+				"current_vm_cid":       "",
+				"current_disk_id":      "some-disk-id",
+				"current_manifest_sha": "",
+				"disks": []interface{}{
+					map[string]interface{}{"id": "other-disk-id", "cid": "vol-other"},
+					map[string]interface{}{"id": "some-disk-id", "cid": "vol-new"},
+				},
+			}))
+
+			Expect(incomingState.BOSH.State["current_vm_cid"]).To(Equal("i-director"))
+
+			Expect(stateStore.SetCall.CallCount).To(Equal(2))
+			Expect(stateStore.SetCall.Receives[0].State.BOSH.State["current_vm_cid"]).To(Equal(""))
+			Expect(stateStore.SetCall.Receives[1].State.BOSH.State).To(Equal(map[string]interface{}{
+				"new-key": "new-value",
+			}))
+			Expect(stateStore.SetCall.Receives[1].State.AWS.DirectorAZ).To(Equal("some-failover-az"))
+
+			Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(1))
+		})
+
+		Context("when the availability zone does not have a bosh subnet yet", func() {
+			It("creates the subnet before moving the director", func() {
+				terraformManager.ApplyCall.Returns.BBLState = storage.State{
+					IAAS: "aws",
+					AWS: storage.AWS{
+						BOSHAZs: []string{"some-failover-az", "other-failover-az"},
+					},
+					BOSH: incomingState.BOSH,
+				}
+
+				err := command.Execute([]string{"--az", "other-failover-az"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(1))
+				Expect(terraformManager.ApplyCall.Receives.BBLState.AWS.BOSHAZs).To(Equal([]string{"some-failover-az", "other-failover-az"}))
+				Expect(terraformManager.GetOutputsCall.CallCount).To(Equal(2))
+
+				Expect(stateStore.SetCall.Receives[0].State.AWS.BOSHAZs).To(Equal([]string{"some-failover-az", "other-failover-az"}))
+				Expect(boshManager.CreateDirectorCall.Receives.State.AWS.DirectorAZ).To(Equal("other-failover-az"))
+			})
+		})
+
+		Context("when the director moves back to the primary availability zone", func() {
+			It("clears the director availability zone", func() {
+				incomingState.AWS.DirectorAZ = "some-failover-az"
+
+				err := command.Execute([]string{"--az", "some-primary-az"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(0))
+				Expect(volumeMigrator.MigrateCall.Receives.AvailabilityZone).To(Equal("some-primary-az"))
+				Expect(boshManager.CreateDirectorCall.Receives.State.AWS.DirectorAZ).To(Equal(""))
+			})
+		})
+
+		Context("when the director does not have a persistent disk", func() {
+			It("recreates the director without moving a disk", func() {
+				incomingState.BOSH.State = map[string]interface{}{
+					"current_vm_cid": "i-director",
+				}
+
+				err := command.Execute([]string{"--az", "some-failover-az"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(volumeMigrator.MigrateCall.CallCount).To(Equal(0))
+				Expect(boshManager.CreateDirectorCall.Receives.State.BOSH.State).To(Equal(map[string]interface{}{
+					"current_vm_cid": "i-director",
+				}))
+			})
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the director is already in the availability zone", func() {
+				err := command.Execute([]string{"--az", "some-primary-az"}, incomingState)
+				Expect(err).To(MatchError(`The director is already in availability zone "some-primary-az".`))
+				Expect(volumeMigrator.MigrateCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the outputs cannot be retrieved", func() {
+				terraformManager.GetOutputsCall.Returns.Error = errors.New("failed to get outputs")
+				err := command.Execute([]string{"--az", "some-failover-az"}, incomingState)
+				Expect(err).To(MatchError("failed to get outputs"))
+			})
+
+			It("returns an error when terraform fails to create the subnet", func() {
+				terraformManager.ApplyCall.Returns.Error = errors.New("failed to apply")
+				err := command.Execute([]string{"--az", "other-failover-az"}, incomingState)
+				Expect(err).To(MatchError("failed to apply"))
+				Expect(volumeMigrator.MigrateCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the disk cannot be moved", func() {
+				volumeMigrator.MigrateCall.Returns.Error = errors.New("failed to migrate")
+				err := command.Execute([]string{"--az", "some-failover-az"}, incomingState)
+				Expect(err).To(MatchError("failed to migrate"))
+				Expect(stateStore.SetCall.CallCount).To(Equal(0))
+			})
+
+			It("saves the state when the director cannot be created", func() {
+				errState := incomingState
+				errState.BOSH.State = map[string]interface{}{
+					"partial": "bosh-state",
+				}
+				boshManager.CreateDirectorCall.Returns.Error = bosh.NewManagerCreateError(errState, errors.New("failed to create"))
+
+				err := command.Execute([]string{"--az", "some-failover-az"}, incomingState)
+				Expect(err).To(MatchError("failed to create"))
+				Expect(stateStore.SetCall.CallCount).To(Equal(2))
+				Expect(stateStore.SetCall.Receives[1].State.BOSH.State).To(Equal(map[string]interface{}{
+					"partial": "bosh-state",
+				}))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(0))
+			})
+		})
+	})
+})
@@ -59,6 +59,22 @@ func (p PrintEnv) Execute(args []string, state storage.State) error {
 	p.logger.Println(fmt.Sprintf("export BOSH_ENVIRONMENT=%s", state.BOSH.DirectorAddress))
 	p.logger.Println(fmt.Sprintf("export BOSH_CA_CERT='%s'", state.BOSH.DirectorSSLCA))
 
+	if state.UAA || state.CredHub {
+		credentials, err := getDirectorCredentials(state)
+		if err != nil {
+			return err
+		}
+
+		p.logger.Println(fmt.Sprintf("export UAA_ADMIN_CLIENT_SECRET=%s", credentials.uaaAdminClientSecret))
+
+		if state.CredHub {
+			p.logger.Println(fmt.Sprintf("export CREDHUB_SERVER=%s", credentials.credhubServer))
+			p.logger.Println(fmt.Sprintf("export CREDHUB_CLIENT=%s", credentials.credhubClient))
+			p.logger.Println(fmt.Sprintf("export CREDHUB_SECRET=%s", credentials.credhubSecret))
+			p.logger.Println(fmt.Sprintf("export CREDHUB_CA_CERT='%s'", credentials.credhubCACert))
+		}
+	}
+
 	if state.Jumpbox.Enabled {
 		portNumber, err := p.getPort()
 		if err != nil {
@@ -59,6 +59,8 @@ var _ = Describe("PrintEnv", func() {
 			Expect(logger.PrintlnCall.Messages).NotTo(ContainElement(MatchRegexp("export BOSH_ALL_PROXY=")))
 			Expect(logger.PrintlnCall.Messages).NotTo(ContainElement(MatchRegexp("export BOSH_GW_PRIVATE_KEY=")))
 			Expect(logger.PrintlnCall.Messages).NotTo(ContainElement(MatchRegexp("ssh -f -N -D")))
+			Expect(logger.PrintlnCall.Messages).NotTo(ContainElement(MatchRegexp("export CREDHUB_")))
+			Expect(logger.PrintlnCall.Messages).NotTo(ContainElement(MatchRegexp("export UAA_")))
 		})
 
 		Context("when a jumpbox exists", func() {
@@ -112,6 +114,50 @@ jumpbox_ssh:
 			})
 		})
 
+		Context("when credhub was deployed", func() {
+			BeforeEach(func() {
+				state.CredHub = true
+				state.UAA = true
+				state.BOSH.DirectorAddress = "https://10.0.0.6:25555"
+				state.BOSH.Variables = `
+credhub_admin_client_secret: some-credhub-secret
+uaa_admin_client_secret: some-uaa-secret
+credhub_tls:
+  ca: some-credhub-ca
+uaa_ssl:
+  ca: some-uaa-ca
+`
+			})
+
+			It("prints the credhub and uaa environment variables", func() {
+				err := printEnv.Execute([]string{}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PrintlnCall.Messages).To(ContainElement("export CREDHUB_SERVER=https://10.0.0.6:8844"))
+				Expect(logger.PrintlnCall.Messages).To(ContainElement("export CREDHUB_CLIENT=credhub-admin"))
+				Expect(logger.PrintlnCall.Messages).To(ContainElement("export CREDHUB_SECRET=some-credhub-secret"))
+				Expect(logger.PrintlnCall.Messages).To(ContainElement("export CREDHUB_CA_CERT='some-credhub-ca\nsome-uaa-ca'"))
+				Expect(logger.PrintlnCall.Messages).To(ContainElement("export UAA_ADMIN_CLIENT_SECRET=some-uaa-secret"))
+			})
+
+			It("prints only the uaa environment variables when only uaa was deployed", func() {
+				state.CredHub = false
+
+				err := printEnv.Execute([]string{}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PrintlnCall.Messages).To(ContainElement("export UAA_ADMIN_CLIENT_SECRET=some-uaa-secret"))
+				Expect(logger.PrintlnCall.Messages).NotTo(ContainElement(MatchRegexp("export CREDHUB_")))
+			})
+
+			It("returns an error when the director variables yaml is invalid", func() {
+				state.BOSH.Variables = "%%%"
+
+				err := printEnv.Execute([]string{}, state)
+				Expect(err).To(MatchError("error unmarshalling director variables: yaml: could not find expected directive name"))
+			})
+		})
+
 		Context("when there is no director", func() {
 			BeforeEach(func() {
 				terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
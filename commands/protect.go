@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	ProtectCommand   = "protect"
+	UnprotectCommand = "unprotect"
+)
+
+type Protect struct {
+	logger         logger
+	stateStore     stateStore
+	stateValidator stateValidator
+}
+
+type Unprotect struct {
+	logger         logger
+	stateStore     stateStore
+	stateValidator stateValidator
+	envIDConfirmer envIDConfirmer
+}
+
+func NewProtect(logger logger, stateStore stateStore, stateValidator stateValidator) Protect {
+	return Protect{
+		logger:         logger,
+		stateStore:     stateStore,
+		stateValidator: stateValidator,
+	}
+}
+
+func NewUnprotect(logger logger, stateStore stateStore, stateValidator stateValidator, envIDConfirmer envIDConfirmer) Unprotect {
+	return Unprotect{
+		logger:         logger,
+		stateStore:     stateStore,
+		stateValidator: stateValidator,
+		envIDConfirmer: envIDConfirmer,
+	}
+}
+
+func (p Protect) CheckFastFails(subcommandFlags []string, state storage.State) error {
+	return p.stateValidator.Validate()
+}
+
+func (p Protect) Execute(subcommandFlags []string, state storage.State) error {
+	state.Protected = true
+
+	err := p.stateStore.Set(state)
+	if err != nil {
+		return err
+	}
+
+	p.logger.Step("protected %s", state.EnvID)
+	return nil
+}
+
+func (u Unprotect) CheckFastFails(subcommandFlags []string, state storage.State) error {
+	_, err := u.parseFlags(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	return u.stateValidator.Validate()
+}
+
+func (u Unprotect) Execute(subcommandFlags []string, state storage.State) error {
+	confirmEnvID, err := u.parseFlags(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	err = u.envIDConfirmer.Confirm(state, confirmEnvID, "unprotect it")
+	if err != nil {
+		return err
+	}
+
+	state.Protected = false
+
+	err = u.stateStore.Set(state)
+	if err != nil {
+		return err
+	}
+
+	u.logger.Step("unprotected %s", state.EnvID)
+	return nil
+}
+
+func (u Unprotect) Flags() flags.Flags {
+	var confirmEnvID string
+	return u.flags(&confirmEnvID)
+}
+
+func (Unprotect) flags(confirmEnvID *string) flags.Flags {
+	unprotectFlags := flags.New("unprotect")
+	unprotectFlags.String(confirmEnvID, "confirm-env-id", "")
+
+	return unprotectFlags
+}
+
+func (u Unprotect) parseFlags(subcommandFlags []string) (string, error) {
+	var confirmEnvID string
+	err := u.flags(&confirmEnvID).Parse(subcommandFlags)
+	if err != nil {
+		return "", err
+	}
+
+	return confirmEnvID, nil
+}
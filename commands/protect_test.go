@@ -0,0 +1,116 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Protect", func() {
+	var (
+		logger         *fakes.Logger
+		stateStore     *fakes.StateStore
+		stateValidator *fakes.StateValidator
+		envIDConfirmer *fakes.EnvIDConfirmer
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		stateStore = &fakes.StateStore{}
+		stateValidator = &fakes.StateValidator{}
+		envIDConfirmer = &fakes.EnvIDConfirmer{}
+	})
+
+	Describe("protect", func() {
+		var command commands.Protect
+
+		BeforeEach(func() {
+			command = commands.NewProtect(logger, stateStore, stateValidator)
+		})
+
+		Describe("CheckFastFails", func() {
+			It("returns an error when the state is not valid", func() {
+				stateValidator.ValidateCall.Returns.Error = errors.New("bbl-state.json not found")
+
+				err := command.CheckFastFails([]string{}, storage.State{})
+				Expect(err).To(MatchError("bbl-state.json not found"))
+			})
+		})
+
+		Describe("Execute", func() {
+			It("marks the environment as protected in the state", func() {
+				err := command.Execute([]string{}, storage.State{EnvID: "some-env-id"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stateStore.SetCall.CallCount).To(Equal(1))
+				Expect(stateStore.SetCall.Receives[0].State).To(Equal(storage.State{
+					EnvID:     "some-env-id",
+					Protected: true,
+				}))
+				Expect(logger.StepCall.Messages).To(Equal([]string{"protected some-env-id"}))
+			})
+
+			It("returns an error when the state cannot be set", func() {
+				stateStore.SetCall.Returns = []fakes.SetCallReturn{{Error: errors.New("failed to set state")}}
+
+				err := command.Execute([]string{}, storage.State{EnvID: "some-env-id"})
+				Expect(err).To(MatchError("failed to set state"))
+			})
+		})
+	})
+
+	Describe("unprotect", func() {
+		var (
+			command commands.Unprotect
+			state   storage.State
+		)
+
+		BeforeEach(func() {
+			command = commands.NewUnprotect(logger, stateStore, stateValidator, envIDConfirmer)
+			state = storage.State{EnvID: "some-env-id", Protected: true}
+		})
+
+		Describe("CheckFastFails", func() {
+			It("returns an error when the state is not valid", func() {
+				stateValidator.ValidateCall.Returns.Error = errors.New("bbl-state.json not found")
+
+				err := command.CheckFastFails([]string{}, storage.State{})
+				Expect(err).To(MatchError("bbl-state.json not found"))
+			})
+
+			It("returns an error when the flags cannot be parsed", func() {
+				err := command.CheckFastFails([]string{"--unknown-flag"}, state)
+				Expect(err).To(MatchError("flag provided but not defined: -unknown-flag"))
+			})
+		})
+
+		Describe("Execute", func() {
+			It("confirms the env id and removes the protection", func() {
+				err := command.Execute([]string{"--confirm-env-id", "some-env-id"}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(envIDConfirmer.ConfirmCall.CallCount).To(Equal(1))
+				Expect(envIDConfirmer.ConfirmCall.Receives.State).To(Equal(state))
+				Expect(envIDConfirmer.ConfirmCall.Receives.ConfirmEnvID).To(Equal("some-env-id"))
+				Expect(envIDConfirmer.ConfirmCall.Receives.Action).To(Equal("unprotect it"))
+
+				Expect(stateStore.SetCall.Receives[0].State).To(Equal(storage.State{EnvID: "some-env-id"}))
+				Expect(logger.StepCall.Messages).To(Equal([]string{"unprotected some-env-id"}))
+			})
+
+			It("keeps the protection when the env id is not confirmed", func() {
+				envIDConfirmer.ConfirmCall.Returns.Error = errors.New("not confirmed")
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError("not confirmed"))
+
+				Expect(stateStore.SetCall.CallCount).To(Equal(0))
+			})
+		})
+	})
+})
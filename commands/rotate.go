@@ -1,11 +1,37 @@
 package commands
 
-import "github.com/cloudfoundry/bosh-bootloader/storage"
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
 
 const (
 	RotateCommand = "rotate"
 )
 
+// directorCredentialVariables and jumpboxCredentialVariables are the vars
+// store entries that bosh-deployment and jumpbox-deployment generate again
+// when they are missing.
+var (
+	directorCredentialVariables = []string{
+		"admin_password",
+		"jumpbox_ssh",
+		"nats_password",
+		"blobstore_director_password",
+		"blobstore_agent_password",
+		"hm_password",
+		"mbus_bootstrap_password",
+	}
+
+	jumpboxCredentialVariables = []string{
+		"jumpbox_ssh",
+		"mbus_bootstrap_password",
+	}
+)
+
 type Rotate struct {
 	stateStore     stateStore
 	keyPairManager keyPairManager
@@ -14,6 +40,11 @@ type Rotate struct {
 	stateValidator stateValidator
 }
 
+type rotateConfig struct {
+	directorCredentials bool
+	jumpboxCredentials  bool
+}
+
 func NewRotate(stateStore stateStore, keyPairManager keyPairManager, terraform terraformOutputter, boshManager boshManager, stateValidator stateValidator) Rotate {
 	return Rotate{
 		stateStore:     stateStore,
@@ -30,11 +61,33 @@ func (r Rotate) CheckFastFails(subcommandFlags []string, state storage.State) er
 		return err
 	}
 
+	config, err := r.parseFlags(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	if config.directorCredentials && (state.NoDirector || state.BOSH.IsEmpty()) {
+		return errors.New("There is no director to rotate credentials on.")
+	}
+
+	if config.jumpboxCredentials && !state.Jumpbox.Enabled {
+		return errors.New("There is no jumpbox to rotate credentials on.")
+	}
+
 	return nil
 }
 
 func (r Rotate) Execute(args []string, state storage.State) error {
-	state, err := r.keyPairManager.Rotate(state)
+	config, err := r.parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	if config.directorCredentials || config.jumpboxCredentials {
+		return r.rotateCredentials(state, config)
+	}
+
+	state, err = r.keyPairManager.Rotate(state)
 	if err != nil {
 		return err
 	}
@@ -63,3 +116,79 @@ func (r Rotate) Execute(args []string, state storage.State) error {
 
 	return nil
 }
+
+// rotateCredentials removes the selected credentials from the vars stores
+// and redeploys, so create-env generates new ones and the derived state
+// fields are updated.
+func (r Rotate) rotateCredentials(state storage.State, config rotateConfig) error {
+	var err error
+
+	if config.jumpboxCredentials {
+		state.Jumpbox.Variables, err = bosh.RemoveVariables(state.Jumpbox.Variables, jumpboxCredentialVariables)
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.directorCredentials {
+		state.BOSH.Variables, err = bosh.RemoveVariables(state.BOSH.Variables, directorCredentialVariables)
+		if err != nil {
+			return err
+		}
+	}
+
+	terraformOutputs, err := r.terraform.GetOutputs(state)
+	if err != nil {
+		return err
+	}
+
+	hasDirector := !state.NoDirector && !state.BOSH.IsEmpty()
+
+	if state.Jumpbox.Enabled {
+		state, err = r.boshManager.CreateJumpbox(state, terraformOutputs)
+		if err != nil {
+			return err
+		}
+
+		err = r.stateStore.Set(state)
+		if err != nil {
+			return err
+		}
+	}
+
+	if hasDirector {
+		state, err = r.boshManager.CreateDirector(state, terraformOutputs)
+		if err != nil {
+			return err
+		}
+
+		err = r.stateStore.Set(state)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r Rotate) Flags() flags.Flags {
+	return r.flags(&rotateConfig{})
+}
+
+func (Rotate) flags(config *rotateConfig) flags.Flags {
+	rotateFlags := flags.New("rotate")
+	rotateFlags.Bool(&config.directorCredentials, "", "director-credentials", false)
+	rotateFlags.Bool(&config.jumpboxCredentials, "", "jumpbox-credentials", false)
+
+	return rotateFlags
+}
+
+func (r Rotate) parseFlags(subcommandFlags []string) (rotateConfig, error) {
+	config := rotateConfig{}
+	err := r.flags(&config).Parse(subcommandFlags)
+	if err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
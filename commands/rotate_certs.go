@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/helpers"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	RotateCertsCommand = "rotate-certs"
+)
+
+type RotateCerts struct {
+	logger           logger
+	stateStore       stateStore
+	stateValidator   stateValidator
+	terraformManager terraformOutputter
+	boshManager      boshManager
+}
+
+type rotateCertsConfig struct {
+	certs []string
+	check bool
+}
+
+func NewRotateCerts(logger logger, stateStore stateStore, stateValidator stateValidator, terraformManager terraformOutputter,
+	boshManager boshManager) RotateCerts {
+	return RotateCerts{
+		logger:           logger,
+		stateStore:       stateStore,
+		stateValidator:   stateValidator,
+		terraformManager: terraformManager,
+		boshManager:      boshManager,
+	}
+}
+
+func (r RotateCerts) CheckFastFails(subcommandFlags []string, state storage.State) error {
+	err := r.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	if state.NoDirector || state.BOSH.IsEmpty() {
+		return errors.New("There is no director to rotate certificates on.")
+	}
+
+	_, err = r.parseFlags(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r RotateCerts) Execute(subcommandFlags []string, state storage.State) error {
+	config, err := r.parseFlags(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	certificates, err := bosh.ParseCertificates(state.BOSH.Variables)
+	if err != nil {
+		return err
+	}
+
+	if config.check {
+		r.printExpiries(certificates)
+		return nil
+	}
+
+	names, err := certificatesToRotate(certificates, config.certs)
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		return errors.New("There are no certificates to rotate.")
+	}
+
+	r.logger.Step("rotating certificates: %s", strings.Join(names, ", "))
+	state.BOSH.Variables, err = bosh.RemoveVariables(state.BOSH.Variables, names)
+	if err != nil {
+		return err
+	}
+
+	terraformOutputs, err := r.terraformManager.GetOutputs(state)
+	if err != nil {
+		return err
+	}
+
+	state, err = r.boshManager.CreateDirector(state, terraformOutputs)
+	switch err.(type) {
+	case bosh.ManagerCreateError:
+		bcErr := err.(bosh.ManagerCreateError)
+		if setErr := r.stateStore.Set(bcErr.State()); setErr != nil {
+			errorList := helpers.Errors{}
+			errorList.Add(err)
+			errorList.Add(setErr)
+			return errorList
+		}
+		return err
+	case error:
+		return err
+	}
+
+	err = r.stateStore.Set(state)
+	if err != nil {
+		return err
+	}
+
+	r.logger.Step("rotated certificates")
+	return nil
+}
+
+func (r RotateCerts) printExpiries(certificates []bosh.Certificate) {
+	table := bytes.NewBuffer([]byte{})
+	writer := tabwriter.NewWriter(table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "CERTIFICATE\tCA\tEXPIRES")
+	for _, certificate := range certificates {
+		fmt.Fprintf(writer, "%s\t%t\t%s\n", certificate.Name, certificate.IsCA, certificate.NotAfter.UTC().Format("2006-01-02"))
+	}
+	writer.Flush()
+	r.logger.Println(strings.TrimSuffix(table.String(), "\n"))
+}
+
+func (r RotateCerts) Flags() flags.Flags {
+	return r.flags(&rotateCertsConfig{})
+}
+
+func (RotateCerts) flags(config *rotateCertsConfig) flags.Flags {
+	rotateCertsFlags := flags.New("rotate-certs")
+	rotateCertsFlags.StringSlice(&config.certs, "cert")
+	rotateCertsFlags.Bool(&config.check, "", "check", false)
+
+	return rotateCertsFlags
+}
+
+func (r RotateCerts) parseFlags(subcommandFlags []string) (rotateCertsConfig, error) {
+	config := rotateCertsConfig{}
+	err := r.flags(&config).Parse(subcommandFlags)
+	if err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+// certificatesToRotate returns the requested certificates, or every
+// certificate that is not a CA when none were requested, since agents on VMs
+// the director already deployed would stop trusting a new CA. Rotating a CA
+// also rotates every certificate it signed, since they would no longer be
+// trusted.
+func certificatesToRotate(certificates []bosh.Certificate, requested []string) ([]string, error) {
+	known := map[string]bool{}
+	for _, certificate := range certificates {
+		known[certificate.Name] = true
+	}
+
+	for _, name := range requested {
+		if !known[name] {
+			return nil, fmt.Errorf("%q is not a certificate in the director vars store", name)
+		}
+	}
+
+	selected := map[string]bool{}
+	for _, certificate := range certificates {
+		if len(requested) == 0 {
+			selected[certificate.Name] = !certificate.IsCA
+		} else {
+			selected[certificate.Name] = containsString(requested, certificate.Name)
+		}
+	}
+
+	names := []string{}
+	for _, certificate := range certificates {
+		if selected[certificate.Name] || (len(requested) > 0 && selected[certificate.CAName]) {
+			names = append(names, certificate.Name)
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,186 @@
+package commands_test
+
+import (
+	"errors"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+	"github.com/cloudfoundry/bosh-bootloader/testhelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RotateCerts", func() {
+	var (
+		logger           *fakes.Logger
+		stateStore       *fakes.StateStore
+		stateValidator   *fakes.StateValidator
+		terraformManager *fakes.TerraformManager
+		boshManager      *fakes.BOSHManager
+
+		command commands.RotateCerts
+
+		incomingState storage.State
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		stateStore = &fakes.StateStore{}
+		stateValidator = &fakes.StateValidator{}
+		terraformManager = &fakes.TerraformManager{}
+		boshManager = &fakes.BOSHManager{}
+
+		variables, err := yaml.Marshal(yaml.MapSlice{
+			{Key: "admin_password", Value: "some-admin-password"},
+			{Key: "default_ca", Value: map[string]string{
+				"ca":          testhelpers.BBL_CHAIN,
+				"certificate": testhelpers.BBL_CHAIN,
+			}},
+			{Key: "director_ssl", Value: map[string]string{
+				"ca":          testhelpers.BBL_CHAIN,
+				"certificate": testhelpers.BBL_CERT,
+			}},
+			{Key: "consul_ssl", Value: map[string]string{
+				"ca":          testhelpers.OTHER_BBL_CHAIN,
+				"certificate": testhelpers.OTHER_BBL_CERT,
+			}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		incomingState = storage.State{
+			IAAS: "gcp",
+			BOSH: storage.BOSH{
+				DirectorName: "some-director-name",
+				Variables:    string(variables),
+			},
+		}
+
+		terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+			"director_address": "some-director-address",
+		}
+		boshManager.CreateDirectorCall.Returns.State = storage.State{
+			BOSH: storage.BOSH{
+				DirectorName:  "some-director-name",
+				DirectorSSLCA: "some-new-ca",
+			},
+		}
+
+		command = commands.NewRotateCerts(logger, stateStore, stateValidator, terraformManager, boshManager)
+	})
+
+	remainingVariables := func(state storage.State) []string {
+		var vars yaml.MapSlice
+		Expect(yaml.Unmarshal([]byte(state.BOSH.Variables), &vars)).To(Succeed())
+
+		names := []string{}
+		for _, variable := range vars {
+			names = append(names, variable.Key.(string))
+		}
+		return names
+	}
+
+	Describe("CheckFastFails", func() {
+		It("returns an error when the state validator fails", func() {
+			stateValidator.ValidateCall.Returns.Error = errors.New("state validator failed")
+			err := command.CheckFastFails([]string{}, incomingState)
+			Expect(err).To(MatchError("state validator failed"))
+		})
+
+		It("returns an error when there is no director", func() {
+			incomingState.NoDirector = true
+			err := command.CheckFastFails([]string{}, incomingState)
+			Expect(err).To(MatchError("There is no director to rotate certificates on."))
+		})
+
+		It("returns an error when the flags cannot be parsed", func() {
+			err := command.CheckFastFails([]string{"--unknown-flag"}, incomingState)
+			Expect(err).To(MatchError("flag provided but not defined: -unknown-flag"))
+		})
+	})
+
+	Describe("Execute", func() {
+		It("removes every certificate that is not a CA from the vars store and redeploys the director", func() {
+			err := command.Execute([]string{}, incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(1))
+			Expect(remainingVariables(boshManager.CreateDirectorCall.Receives.State)).To(Equal([]string{"admin_password", "default_ca"}))
+			Expect(terraformManager.GetOutputsCall.Receives.BBLState).To(Equal(boshManager.CreateDirectorCall.Receives.State))
+			Expect(boshManager.CreateJumpboxCall.CallCount).To(Equal(0))
+
+			Expect(stateStore.SetCall.CallCount).To(Equal(1))
+			Expect(stateStore.SetCall.Receives[0].State.BOSH.DirectorSSLCA).To(Equal("some-new-ca"))
+
+			Expect(logger.StepCall.Messages).To(Equal([]string{
+				"rotating certificates: director_ssl, consul_ssl",
+				"rotated certificates",
+			}))
+		})
+
+		It("rotates only the chosen certificates", func() {
+			err := command.Execute([]string{"--cert", "consul_ssl"}, incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(remainingVariables(boshManager.CreateDirectorCall.Receives.State)).To(Equal([]string{"admin_password", "default_ca", "director_ssl"}))
+		})
+
+		It("rotates a CA, and the certificates it signed, only when it is chosen", func() {
+			err := command.Execute([]string{"--cert", "default_ca"}, incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(remainingVariables(boshManager.CreateDirectorCall.Receives.State)).To(Equal([]string{"admin_password", "consul_ssl"}))
+		})
+
+		It("does not recreate the jumpbox when there is one", func() {
+			incomingState.Jumpbox.Enabled = true
+
+			err := command.Execute([]string{}, incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(boshManager.CreateJumpboxCall.CallCount).To(Equal(0))
+			Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(1))
+		})
+
+		Context("when --check is provided", func() {
+			It("prints the expiry of every certificate without rotating", func() {
+				err := command.Execute([]string{"--check"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PrintlnCall.Receives.Message).To(Equal(`CERTIFICATE   CA     EXPIRES
+default_ca    true   2026-05-04
+director_ssl  false  2018-05-26
+consul_ssl    false  2018-06-08`))
+				Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(0))
+				Expect(stateStore.SetCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when a chosen certificate is not in the vars store", func() {
+				err := command.Execute([]string{"--cert", "some-cert"}, incomingState)
+				Expect(err).To(MatchError(`"some-cert" is not a certificate in the director vars store`))
+			})
+
+			It("returns an error when the terraform outputs cannot be read", func() {
+				terraformManager.GetOutputsCall.Returns.Error = errors.New("failed to get outputs")
+
+				err := command.Execute([]string{}, incomingState)
+				Expect(err).To(MatchError("failed to get outputs"))
+			})
+
+			It("saves the partial state when the director fails to deploy", func() {
+				errState := storage.State{EnvID: "some-env-id"}
+				boshManager.CreateDirectorCall.Returns.Error = bosh.NewManagerCreateError(errState, errors.New("failed to create"))
+
+				err := command.Execute([]string{}, incomingState)
+				Expect(err).To(MatchError(bosh.NewManagerCreateError(errState, errors.New("failed to create"))))
+				Expect(stateStore.SetCall.Receives[0].State).To(Equal(errState))
+			})
+		})
+	})
+})
@@ -40,6 +40,21 @@ var _ = Describe("Rotate", func() {
 			err := command.CheckFastFails([]string{}, incomingState)
 			Expect(err).To(MatchError("state validator failed"))
 		})
+
+		It("returns an error when director credentials are requested without a director", func() {
+			err := command.CheckFastFails([]string{"--director-credentials"}, storage.State{NoDirector: true})
+			Expect(err).To(MatchError("There is no director to rotate credentials on."))
+		})
+
+		It("returns an error when jumpbox credentials are requested without a jumpbox", func() {
+			err := command.CheckFastFails([]string{"--jumpbox-credentials"}, storage.State{})
+			Expect(err).To(MatchError("There is no jumpbox to rotate credentials on."))
+		})
+
+		It("returns an error when the flags cannot be parsed", func() {
+			err := command.CheckFastFails([]string{"--unknown-flag"}, storage.State{})
+			Expect(err).To(MatchError("flag provided but not defined: -unknown-flag"))
+		})
 	})
 
 	Describe("Execute", func() {
@@ -136,6 +151,60 @@ var _ = Describe("Rotate", func() {
 			})
 		})
 
+		Context("when credentials are selected", func() {
+			BeforeEach(func() {
+				incomingState = storage.State{
+					Jumpbox: storage.Jumpbox{
+						Enabled:   true,
+						Variables: "jumpbox_ssh:\n  private_key: some-jumpbox-key\nmbus_bootstrap_password: some-password\nsome_other_variable: some-value\n",
+					},
+					BOSH: storage.BOSH{
+						DirectorPassword: "some-director-password",
+						Variables:        "admin_password: some-admin-password\nnats_password: some-nats-password\ndefault_ca: some-ca\n",
+					},
+				}
+
+				boshManager.CreateJumpboxCall.Returns.State = incomingState
+			})
+
+			It("removes the director credentials from the vars store and redeploys", func() {
+				err := command.Execute([]string{"--director-credentials"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(keyPairManager.RotateCall.CallCount).To(Equal(0))
+				Expect(boshManager.CreateJumpboxCall.Receives.State.Jumpbox.Variables).To(Equal(incomingState.Jumpbox.Variables))
+				Expect(boshManager.CreateJumpboxCall.Receives.State.BOSH.Variables).To(Equal("default_ca: some-ca\n"))
+				Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(1))
+
+				Expect(stateStore.SetCall.CallCount).To(Equal(2))
+				Expect(stateStore.SetCall.Receives[1].State.BOSH.DirectorName).To(Equal("some-director-name"))
+			})
+
+			It("removes the jumpbox credentials from the vars store and redeploys", func() {
+				err := command.Execute([]string{"--jumpbox-credentials"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshManager.CreateJumpboxCall.Receives.State.Jumpbox.Variables).To(Equal("some_other_variable: some-value\n"))
+				Expect(boshManager.CreateJumpboxCall.Receives.State.BOSH.Variables).To(Equal(incomingState.BOSH.Variables))
+				Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(1))
+			})
+
+			It("returns an error when the vars store is not valid yaml", func() {
+				incomingState.BOSH.Variables = "%%%"
+
+				err := command.Execute([]string{"--director-credentials"}, incomingState)
+				Expect(err).To(MatchError(ContainSubstring("yaml")))
+			})
+
+			It("returns an error when the jumpbox fails to deploy", func() {
+				boshManager.CreateJumpboxCall.Returns.Error = errors.New("failed to create jumpbox")
+
+				err := command.Execute([]string{"--jumpbox-credentials"}, incomingState)
+				Expect(err).To(MatchError("failed to create jumpbox"))
+				Expect(boshManager.CreateDirectorCall.CallCount).To(Equal(0))
+			})
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when key pair manager rotate fails", func() {
 				keyPairManager.RotateCall.Returns.Error = errors.New("failed to rotate")
@@ -0,0 +1,39 @@
+package commands
+
+import "github.com/cloudfoundry/bosh-bootloader/storage"
+
+const (
+	RuntimeConfigCommand = "runtime-config"
+)
+
+type RuntimeConfig struct {
+	logger             logger
+	stateValidator     stateValidator
+	cloudConfigManager cloudConfigManager
+}
+
+func NewRuntimeConfig(logger logger, stateValidator stateValidator, cloudConfigManager cloudConfigManager) RuntimeConfig {
+	return RuntimeConfig{
+		logger:             logger,
+		stateValidator:     stateValidator,
+		cloudConfigManager: cloudConfigManager,
+	}
+}
+
+func (r RuntimeConfig) CheckFastFails(subcommandFlags []string, state storage.State) error {
+	err := r.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r RuntimeConfig) Execute(args []string, state storage.State) error {
+	contents, err := r.cloudConfigManager.GenerateRuntimeConfig(state)
+	if err != nil {
+		return err
+	}
+	r.logger.Println(contents)
+	return nil
+}
@@ -0,0 +1,61 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RuntimeConfig", func() {
+	var (
+		logger             *fakes.Logger
+		stateValidator     *fakes.StateValidator
+		runtimeConfig      commands.RuntimeConfig
+		state              storage.State
+		cloudConfigManager *fakes.CloudConfigManager
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		stateValidator = &fakes.StateValidator{}
+		cloudConfigManager = &fakes.CloudConfigManager{}
+
+		cloudConfigManager.GenerateRuntimeConfigCall.Returns.RuntimeConfig = "some-runtime-config"
+
+		state = storage.State{
+			IAAS: "gcp",
+		}
+
+		runtimeConfig = commands.NewRuntimeConfig(logger, stateValidator, cloudConfigManager)
+	})
+
+	Describe("CheckFastFails", func() {
+		It("returns an error when the state validator fails", func() {
+			stateValidator.ValidateCall.Returns.Error = errors.New("failed to validate state")
+			err := runtimeConfig.CheckFastFails([]string{}, storage.State{})
+			Expect(err).To(MatchError("failed to validate state"))
+		})
+	})
+
+	Describe("Execute", func() {
+		It("prints the runtime configuration for the bbl environment", func() {
+			err := runtimeConfig.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cloudConfigManager.GenerateRuntimeConfigCall.CallCount).To(Equal(1))
+			Expect(cloudConfigManager.GenerateRuntimeConfigCall.Receives.State).To(Equal(state))
+			Expect(logger.PrintlnCall.Messages).To(ContainElement("some-runtime-config"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the cloud config manager fails to generate", func() {
+				cloudConfigManager.GenerateRuntimeConfigCall.Returns.Error = errors.New("failed to generate runtime configuration")
+				err := runtimeConfig.Execute([]string{}, state)
+				Expect(err).To(MatchError("failed to generate runtime configuration"))
+			})
+		})
+	})
+})
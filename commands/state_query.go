@@ -14,6 +14,10 @@ const (
 	DirectorPasswordCommand = "director-password"
 	DirectorAddressCommand  = "director-address"
 	DirectorCACertCommand   = "director-ca-cert"
+	CredHubServerCommand    = "credhub-server"
+	CredHubSecretCommand    = "credhub-secret"
+	CredHubCACertCommand    = "credhub-ca-cert"
+	UAAAdminSecretCommand   = "uaa-admin-secret"
 
 	EnvIDPropertyName            = "environment id"
 	JumpboxAddressPropertyName   = "jumpbox address"
@@ -21,6 +25,10 @@ const (
 	DirectorPasswordPropertyName = "director password"
 	DirectorAddressPropertyName  = "director address"
 	DirectorCACertPropertyName   = "director ca cert"
+	CredHubServerPropertyName    = "credhub server"
+	CredHubSecretPropertyName    = "credhub secret"
+	CredHubCACertPropertyName    = "credhub ca cert"
+	UAAAdminSecretPropertyName   = "uaa admin secret"
 )
 
 type StateQuery struct {
@@ -81,6 +89,22 @@ func (s StateQuery) Execute(subcommandFlags []string, state storage.State) error
 		propertyValue = state.BOSH.DirectorSSLCA
 	case EnvIDPropertyName:
 		propertyValue = state.EnvID
+	case CredHubServerPropertyName, CredHubSecretPropertyName, CredHubCACertPropertyName, UAAAdminSecretPropertyName:
+		credentials, err := getDirectorCredentials(state)
+		if err != nil {
+			return err
+		}
+
+		switch s.propertyName {
+		case CredHubServerPropertyName:
+			propertyValue = credentials.credhubServer
+		case CredHubSecretPropertyName:
+			propertyValue = credentials.credhubSecret
+		case CredHubCACertPropertyName:
+			propertyValue = credentials.credhubCACert
+		case UAAAdminSecretPropertyName:
+			propertyValue = credentials.uaaAdminClientSecret
+		}
 	}
 
 	if propertyValue == "" {
@@ -60,6 +60,8 @@ var _ = Describe("StateQuery", func() {
 				Entry("director-username", "director username"),
 				Entry("director-password", "director password"),
 				Entry("director-ssl-ca", "director ca cert"),
+				Entry("credhub-secret", "credhub secret"),
+				Entry("uaa-admin-secret", "uaa admin secret"),
 			)
 		})
 	})
@@ -117,6 +119,51 @@ var _ = Describe("StateQuery", func() {
 			)
 		})
 
+		Context("bbl deployed credhub on the director", func() {
+			var state storage.State
+
+			BeforeEach(func() {
+				state = storage.State{
+					UAA:     true,
+					CredHub: true,
+					BOSH: storage.BOSH{
+						DirectorAddress: "https://10.0.0.6:25555",
+						Variables: `
+credhub_admin_client_secret: some-credhub-secret
+uaa_admin_client_secret: some-uaa-secret
+credhub_tls:
+  ca: some-credhub-ca
+uaa_ssl:
+  ca: some-uaa-ca
+`,
+					},
+				}
+			})
+
+			DescribeTable("prints out the credhub and uaa information",
+				func(propertyName, expectedOutput string) {
+					command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, propertyName)
+
+					err := command.Execute([]string{}, state)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeLogger.PrintlnCall.Receives.Message).To(Equal(expectedOutput))
+				},
+				Entry("credhub-server", "credhub server", "https://10.0.0.6:8844"),
+				Entry("credhub-secret", "credhub secret", "some-credhub-secret"),
+				Entry("credhub-ca-cert", "credhub ca cert", "some-credhub-ca\nsome-uaa-ca"),
+				Entry("uaa-admin-secret", "uaa admin secret", "some-uaa-secret"),
+			)
+
+			It("returns an error when credhub was not deployed", func() {
+				state.CredHub = false
+				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, "credhub secret")
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError("Could not retrieve credhub secret, please make sure you are targeting the proper state dir."))
+			})
+		})
+
 		Context("bbl does not manage the bosh director", func() {
 			var state storage.State
 
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/status"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	StatusCommand = "status"
+)
+
+type statusChecker interface {
+	Check(state storage.State) []status.Result
+}
+
+type Status struct {
+	logger         logger
+	stateValidator stateValidator
+	statusChecker  statusChecker
+}
+
+func NewStatus(logger logger, stateValidator stateValidator, statusChecker statusChecker) Status {
+	return Status{
+		logger:         logger,
+		stateValidator: stateValidator,
+		statusChecker:  statusChecker,
+	}
+}
+
+func (s Status) CheckFastFails(subcommandFlags []string, state storage.State) error {
+	err := s.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Status) Execute(args []string, state storage.State) error {
+	var printJSON bool
+	err := s.flags(&printJSON).Parse(args)
+	if err != nil {
+		return err
+	}
+
+	results := s.statusChecker.Check(state)
+
+	if printJSON {
+		output, err := json.Marshal(results)
+		if err != nil {
+			// not tested
+			return err
+		}
+		s.logger.Println(string(output))
+	} else {
+		table := bytes.NewBuffer([]byte{})
+		writer := tabwriter.NewWriter(table, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "CHECK\tSTATUS\tDETAIL")
+		for _, result := range results {
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", result.Name, result.Status, result.Detail)
+		}
+		writer.Flush()
+		s.logger.Println(strings.TrimSuffix(table.String(), "\n"))
+	}
+
+	for _, result := range results {
+		if result.Status == status.Fail {
+			return errors.New("one or more status checks failed")
+		}
+	}
+
+	return nil
+}
+
+func (s Status) Flags() flags.Flags {
+	var printJSON bool
+	return s.flags(&printJSON)
+}
+
+func (Status) flags(printJSON *bool) flags.Flags {
+	statusFlags := flags.New("status")
+	statusFlags.Bool(printJSON, "", "json", false)
+
+	return statusFlags
+}
@@ -0,0 +1,84 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/status"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Status", func() {
+	var (
+		logger         *fakes.Logger
+		stateValidator *fakes.StateValidator
+		statusChecker  *fakes.StatusChecker
+		command        commands.Status
+		state          storage.State
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		stateValidator = &fakes.StateValidator{}
+		statusChecker = &fakes.StatusChecker{}
+
+		state = storage.State{
+			IAAS: "gcp",
+		}
+
+		statusChecker.CheckCall.Returns.Results = []status.Result{
+			{Name: "terraform", Status: status.Pass, Detail: "infrastructure matches the terraform state"},
+			{Name: "jumpbox", Status: status.Skip, Detail: "no jumpbox"},
+		}
+
+		command = commands.NewStatus(logger, stateValidator, statusChecker)
+	})
+
+	Describe("CheckFastFails", func() {
+		It("returns an error when the state validator fails", func() {
+			stateValidator.ValidateCall.Returns.Error = errors.New("failed to validate state")
+			err := command.CheckFastFails([]string{}, state)
+			Expect(err).To(MatchError("failed to validate state"))
+		})
+	})
+
+	Describe("Execute", func() {
+		It("prints a table of the status checks", func() {
+			err := command.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(statusChecker.CheckCall.Receives.State).To(Equal(state))
+			Expect(logger.PrintlnCall.Receives.Message).To(Equal(`CHECK      STATUS  DETAIL
+terraform  pass    infrastructure matches the terraform state
+jumpbox    skip    no jumpbox`))
+		})
+
+		It("prints the status checks as json when --json is provided", func() {
+			err := command.Execute([]string{"--json"}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Receives.Message).To(MatchJSON(`[
+				{"name": "terraform", "status": "pass", "detail": "infrastructure matches the terraform state"},
+				{"name": "jumpbox", "status": "skip", "detail": "no jumpbox"}
+			]`))
+		})
+
+		It("returns an error after printing when a check fails", func() {
+			statusChecker.CheckCall.Returns.Results = append(statusChecker.CheckCall.Returns.Results,
+				status.Result{Name: "director", Status: status.Fail, Detail: "failed to get info"})
+
+			err := command.Execute([]string{}, state)
+			Expect(err).To(MatchError("one or more status checks failed"))
+			Expect(logger.PrintlnCall.Receives.Message).To(ContainSubstring("director   fail    failed to get info"))
+		})
+
+		It("returns an error when an unknown flag is provided", func() {
+			err := command.Execute([]string{"--some-flag"}, state)
+			Expect(err).To(MatchError("flag provided but not defined: -some-flag"))
+		})
+	})
+})
@@ -3,16 +3,28 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
 
 	"github.com/cloudfoundry/bosh-bootloader/flags"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
+var (
+	gcpLabelKeyRegexp   = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
+	gcpLabelValueRegexp = regexp.MustCompile(`^[a-z0-9_-]{0,63}$`)
+)
+
 type Up struct {
-	awsUp       awsUp
-	gcpUp       gcpUp
-	envGetter   envGetter
-	boshManager boshManager
+	awsUp            awsUp
+	gcpUp            gcpUp
+	envGetter        envGetter
+	boshManager      boshManager
+	configFileLoader configFileLoader
+	stateGetter      stateGetter
+	createLBs        Command
+	updateLBs        Command
 }
 
 type awsUp interface {
@@ -27,33 +39,68 @@ type envGetter interface {
 	Get(name string) string
 }
 
+type configFileLoader interface {
+	Load(path string) (storage.ConfigFile, error)
+}
+
+type stateGetter interface {
+	Get() (storage.State, error)
+}
+
 type upConfig struct {
-	awsAccessKeyID       string
-	awsSecretAccessKey   string
-	awsRegion            string
-	awsBOSHAZ            string
-	gcpServiceAccountKey string
-	gcpProjectID         string
-	gcpZone              string
-	gcpRegion            string
-	iaas                 string
-	name                 string
-	opsFile              string
-	noDirector           bool
-	jumpbox              bool
-}
-
-func NewUp(awsUp awsUp, gcpUp gcpUp, envGetter envGetter, boshManager boshManager) Up {
+	awsAccessKeyID                   string
+	awsSecretAccessKey               string
+	awsProfile                       string
+	awsCredentialChain               bool
+	awsAssumeRoleARN                 string
+	awsExternalID                    string
+	awsSessionName                   string
+	awsRegion                        string
+	awsBOSHAZ                        string
+	awsBOSHAZs                       string
+	awsNATType                       string
+	awsNATAMI                        string
+	gcpServiceAccountKey             string
+	gcpProjectID                     string
+	gcpZone                          string
+	gcpRegion                        string
+	iaas                             string
+	name                             string
+	opsFile                          string
+	noDirector                       bool
+	jumpbox                          bool
+	gcpInternalOnly                  bool
+	gcpApplicationDefaultCredentials bool
+	gcpImpersonateServiceAccount     string
+	gcpDirectorServiceAccount        bool
+	tags                             []string
+	cloudConfigOpsFile               string
+	noRuntimeConfig                  bool
+	cpiConfig                        bool
+	uploadStemcell                   bool
+	uaa                              bool
+	credhub                          bool
+	fromStep                         string
+	confirmEnvID                     string
+	configFile                       string
+}
+
+func NewUp(awsUp awsUp, gcpUp gcpUp, envGetter envGetter, boshManager boshManager,
+	configFileLoader configFileLoader, stateGetter stateGetter, createLBs Command, updateLBs Command) Up {
 	return Up{
-		awsUp:       awsUp,
-		gcpUp:       gcpUp,
-		envGetter:   envGetter,
-		boshManager: boshManager,
+		awsUp:            awsUp,
+		gcpUp:            gcpUp,
+		envGetter:        envGetter,
+		boshManager:      boshManager,
+		configFileLoader: configFileLoader,
+		stateGetter:      stateGetter,
+		createLBs:        createLBs,
+		updateLBs:        updateLBs,
 	}
 }
 
 func (u Up) CheckFastFails(args []string, state storage.State) error {
-	config, err := u.parseArgs(args)
+	config, _, err := u.parseArgs(args, state)
 	if err != nil {
 		return err
 	}
@@ -65,6 +112,11 @@ func (u Up) CheckFastFails(args []string, state storage.State) error {
 		}
 	}
 
+	err = validateUpStep(config.fromStep)
+	if err != nil {
+		return err
+	}
+
 	if state.IAAS == "" && config.iaas == "" {
 		return errors.New("--iaas [gcp, aws] must be provided or BBL_IAAS must be set")
 	}
@@ -77,13 +129,38 @@ func (u Up) CheckFastFails(args []string, state storage.State) error {
 		return fmt.Errorf("The director name cannot be changed for an existing environment. Current name is %s.", state.EnvID)
 	}
 
+	tags, err := parseTags(config.tags)
+	if err != nil {
+		return err
+	}
+
+	iaas := state.IAAS
+	if iaas == "" {
+		iaas = config.iaas
+	}
+
+	switch iaas {
+	case "aws":
+		for key := range tags {
+			if strings.HasPrefix(key, "aws:") {
+				return fmt.Errorf("Tag %q uses the reserved \"aws:\" prefix.", key)
+			}
+		}
+	case "gcp":
+		for key, value := range tags {
+			if !gcpLabelKeyRegexp.MatchString(key) || !gcpLabelValueRegexp.MatchString(value) {
+				return fmt.Errorf("Tag %q is not a valid GCP label. Keys and values may only contain lowercase letters, numbers, dashes and underscores.", fmt.Sprintf("%s=%s", key, value))
+			}
+		}
+	}
+
 	return nil
 }
 
 func (u Up) Execute(args []string, state storage.State) error {
 	var desiredIAAS string
 
-	config, err := u.parseArgs(args)
+	config, configFile, err := u.parseArgs(args, state)
 	if err != nil {
 		return err
 	}
@@ -94,27 +171,85 @@ func (u Up) Execute(args []string, state storage.State) error {
 		desiredIAAS = config.iaas
 	}
 
+	tags, err := parseTags(config.tags)
+	if err != nil {
+		return err
+	}
+
+	state, err = readCloudConfigOpsFile(config.cloudConfigOpsFile, state)
+	if err != nil {
+		return err
+	}
+
+	if config.noRuntimeConfig {
+		state.NoRuntimeConfig = true
+	}
+
+	if config.cpiConfig {
+		state.CPIConfig = true
+	}
+
+	if config.uploadStemcell {
+		state.UploadStemcell = true
+	}
+
+	if config.uaa {
+		state.UAA = true
+	}
+
+	if config.credhub {
+		state.UAA = true
+		state.CredHub = true
+	}
+
+	if len(tags) > 0 {
+		mergedTags := map[string]string{}
+		for key, value := range state.Tags {
+			mergedTags[key] = value
+		}
+		for key, value := range tags {
+			mergedTags[key] = value
+		}
+		state.Tags = mergedTags
+	}
+
 	switch desiredIAAS {
 	case "aws":
 		err = u.awsUp.Execute(AWSUpConfig{
 			AccessKeyID:     config.awsAccessKeyID,
 			SecretAccessKey: config.awsSecretAccessKey,
+			Profile:         config.awsProfile,
+			CredentialChain: config.awsCredentialChain,
+			AssumeRoleARN:   config.awsAssumeRoleARN,
+			ExternalID:      config.awsExternalID,
+			SessionName:     config.awsSessionName,
 			Region:          config.awsRegion,
 			BOSHAZ:          config.awsBOSHAZ,
+			BOSHAZs:         splitList(config.awsBOSHAZs),
+			NATType:         config.awsNATType,
+			NATAMI:          config.awsNATAMI,
 			OpsFilePath:     config.opsFile,
 			Name:            config.name,
 			NoDirector:      config.noDirector,
+			FromStep:        config.fromStep,
+			ConfirmEnvID:    config.confirmEnvID,
 		}, state)
 	case "gcp":
 		err = u.gcpUp.Execute(GCPUpConfig{
-			ServiceAccountKey: config.gcpServiceAccountKey,
-			ProjectID:         config.gcpProjectID,
-			Zone:              config.gcpZone,
-			Region:            config.gcpRegion,
-			OpsFilePath:       config.opsFile,
-			Name:              config.name,
-			NoDirector:        config.noDirector,
-			Jumpbox:           config.jumpbox,
+			ServiceAccountKey:             config.gcpServiceAccountKey,
+			ProjectID:                     config.gcpProjectID,
+			Zone:                          config.gcpZone,
+			Region:                        config.gcpRegion,
+			OpsFilePath:                   config.opsFile,
+			Name:                          config.name,
+			NoDirector:                    config.noDirector,
+			Jumpbox:                       config.jumpbox,
+			InternalOnly:                  config.gcpInternalOnly,
+			ApplicationDefaultCredentials: config.gcpApplicationDefaultCredentials,
+			ImpersonateServiceAccount:     config.gcpImpersonateServiceAccount,
+			DirectorServiceAccount:        config.gcpDirectorServiceAccount,
+			FromStep:                      config.fromStep,
+			ConfirmEnvID:                  config.confirmEnvID,
 		}, state)
 	default:
 		return fmt.Errorf("%q is an invalid iaas type, supported values are: [gcp, aws]", desiredIAAS)
@@ -124,35 +259,232 @@ func (u Up) Execute(args []string, state storage.State) error {
 		return err
 	}
 
+	if configFile.Path != "" && configFile.LB.Type != "" {
+		return u.createConfiguredLBs(configFile)
+	}
+
 	return nil
 }
 
-func (u Up) parseArgs(args []string) (upConfig, error) {
-	var config upConfig
+// createConfiguredLBs converges the environment to the load balancers
+// described in the config file. They are created when the environment has
+// none, and updated when their certificate or domain changed. A load balancer
+// of another type is not replaced.
+func (u Up) createConfiguredLBs(configFile storage.ConfigFile) error {
+	state, err := u.stateGetter.Get()
+	if err != nil {
+		return err
+	}
+
+	currentLBType := state.LB.Type
+	if !lbExists(currentLBType) {
+		currentLBType = state.Stack.LBType
+	}
+
+	if !lbExists(currentLBType) {
+		return runLBCommand(u.createLBs, []string{"--config", configFile.Path}, state)
+	}
+
+	if currentLBType != configFile.LB.Type {
+		return fmt.Errorf("%s describes a %s load balancer, but the environment has a %s load balancer. Run `bbl delete-lbs` and then `bbl up` to replace it.",
+			configFile.Path, configFile.LB.Type, currentLBType)
+	}
+
+	lbArgs, changed, err := lbUpdateArgs(configFile.LB, state.LB)
+	if err != nil {
+		return err
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return runLBCommand(u.updateLBs, lbArgs, state)
+}
+
+// lbUpdateArgs returns the update-lbs args for the certificate and domain in
+// the config file, and whether they differ from the ones in the state.
+func lbUpdateArgs(configLB storage.ConfigFileLB, stateLB storage.LB) ([]string, bool, error) {
+	var (
+		args    []string
+		changed bool
+	)
+
+	for _, file := range []struct {
+		flag    string
+		path    string
+		current string
+	}{
+		{"--cert", configLB.Cert, stateLB.Cert},
+		{"--key", configLB.Key, stateLB.Key},
+		{"--chain", configLB.Chain, stateLB.Chain},
+	} {
+		if file.path == "" {
+			continue
+		}
+
+		contents, err := ioutil.ReadFile(file.path)
+		if err != nil {
+			return nil, false, err
+		}
+
+		args = append(args, file.flag, file.path)
+		changed = changed || string(contents) != file.current
+	}
+
+	if configLB.Domain != "" {
+		args = append(args, "--domain", configLB.Domain)
+		changed = changed || configLB.Domain != stateLB.Domain
+	}
+
+	return args, changed, nil
+}
+
+func runLBCommand(command Command, args []string, state storage.State) error {
+	err := command.CheckFastFails(args, state)
+	if err != nil {
+		return err
+	}
 
+	return command.Execute(args, state)
+}
+
+func (u Up) Flags() flags.Flags {
+	return u.flags(&upConfig{}, storage.ConfigFile{})
+}
+
+// flags builds the up flags. A flag that is not given defaults to its
+// environment variable and then to the config file.
+func (u Up) flags(config *upConfig, configFile storage.ConfigFile) flags.Flags {
 	upFlags := flags.New("up")
 
-	upFlags.String(&config.iaas, "iaas", u.envGetter.Get("BBL_IAAS"))
+	upFlags.String(&config.configFile, "config", "")
+
+	upFlags.String(&config.iaas, "iaas", u.envOrConfig("BBL_IAAS", configFile.IAAS))
+	upFlags.Values("iaas", "aws", "gcp")
 
 	upFlags.String(&config.awsAccessKeyID, "aws-access-key-id", u.envGetter.Get("BBL_AWS_ACCESS_KEY_ID"))
 	upFlags.String(&config.awsSecretAccessKey, "aws-secret-access-key", u.envGetter.Get("BBL_AWS_SECRET_ACCESS_KEY"))
-	upFlags.String(&config.awsRegion, "aws-region", u.envGetter.Get("BBL_AWS_REGION"))
+	upFlags.String(&config.awsProfile, "aws-profile", u.envOrConfig("BBL_AWS_PROFILE", configFile.AWS.Profile))
+	upFlags.Bool(&config.awsCredentialChain, "", "aws-credential-chain", configFile.AWS.CredentialChain)
+	upFlags.String(&config.awsAssumeRoleARN, "aws-assume-role-arn", u.envOrConfig("BBL_AWS_ASSUME_ROLE_ARN", configFile.AWS.AssumeRoleARN))
+	upFlags.String(&config.awsExternalID, "aws-external-id", u.envOrConfig("BBL_AWS_EXTERNAL_ID", configFile.AWS.ExternalID))
+	upFlags.String(&config.awsSessionName, "aws-session-name", u.envOrConfig("BBL_AWS_SESSION_NAME", configFile.AWS.SessionName))
+	upFlags.String(&config.awsRegion, "aws-region", u.envOrConfig("BBL_AWS_REGION", configFile.AWS.Region))
 	upFlags.String(&config.awsBOSHAZ, "aws-bosh-az", u.envGetter.Get("BBL_AWS_BOSH_AZ"))
+	upFlags.String(&config.awsBOSHAZs, "aws-bosh-azs", u.envGetter.Get("BBL_AWS_BOSH_AZS"))
+	upFlags.String(&config.awsNATType, "aws-nat-type", u.envGetter.Get("BBL_AWS_NAT_TYPE"))
+	upFlags.Values("aws-nat-type", "instance", "gateway", "gateway-per-az")
+	upFlags.String(&config.awsNATAMI, "aws-nat-ami", u.envGetter.Get("BBL_AWS_NAT_AMI"))
 
 	upFlags.String(&config.gcpServiceAccountKey, "gcp-service-account-key", u.envGetter.Get("BBL_GCP_SERVICE_ACCOUNT_KEY"))
-	upFlags.String(&config.gcpProjectID, "gcp-project-id", u.envGetter.Get("BBL_GCP_PROJECT_ID"))
-	upFlags.String(&config.gcpZone, "gcp-zone", u.envGetter.Get("BBL_GCP_ZONE"))
-	upFlags.String(&config.gcpRegion, "gcp-region", u.envGetter.Get("BBL_GCP_REGION"))
+	upFlags.String(&config.gcpProjectID, "gcp-project-id", u.envOrConfig("BBL_GCP_PROJECT_ID", configFile.GCP.ProjectID))
+	upFlags.String(&config.gcpZone, "gcp-zone", u.envOrConfig("BBL_GCP_ZONE", configFile.GCP.Zone))
+	upFlags.String(&config.gcpRegion, "gcp-region", u.envOrConfig("BBL_GCP_REGION", configFile.GCP.Region))
+	upFlags.Bool(&config.gcpInternalOnly, "", "gcp-internal-only", false)
+	upFlags.Bool(&config.gcpApplicationDefaultCredentials, "", "gcp-application-default-credentials", configFile.GCP.ApplicationDefaultCredentials)
+	upFlags.String(&config.gcpImpersonateServiceAccount, "gcp-impersonate-service-account", u.envOrConfig("BBL_GCP_IMPERSONATE_SERVICE_ACCOUNT", configFile.GCP.ImpersonateServiceAccount))
+	upFlags.Bool(&config.gcpDirectorServiceAccount, "", "gcp-director-service-account", configFile.GCP.DirectorServiceAccount)
 
-	upFlags.String(&config.name, "name", "")
-	upFlags.String(&config.opsFile, "ops-file", "")
+	upFlags.String(&config.name, "name", configFile.Name)
+	upFlags.String(&config.opsFile, "ops-file", configFile.OpsFile)
+	upFlags.String(&config.cloudConfigOpsFile, "cloud-config-ops-file", configFile.CloudConfigOpsFile)
 	upFlags.Bool(&config.noDirector, "", "no-director", false)
-	upFlags.Bool(&config.jumpbox, "", "jumpbox", false)
+	upFlags.Bool(&config.jumpbox, "", "jumpbox", configFile.Jumpbox)
+	upFlags.StringSlice(&config.tags, "tag")
+	upFlags.Bool(&config.noRuntimeConfig, "", "no-runtime-config", false)
+	upFlags.Bool(&config.cpiConfig, "", "cpi-config", false)
+	upFlags.Bool(&config.uploadStemcell, "", "upload-stemcell", false)
+	upFlags.Bool(&config.uaa, "", "uaa", false)
+	upFlags.Bool(&config.credhub, "", "credhub", false)
+	upFlags.String(&config.fromStep, "from-step", "")
+	upFlags.Values("from-step", UpSteps...)
+	upFlags.String(&config.confirmEnvID, "confirm-env-id", "")
+
+	return upFlags
+}
+
+func (u Up) envOrConfig(name, configValue string) string {
+	if value := u.envGetter.Get(name); value != "" {
+		return value
+	}
+
+	return configValue
+}
+
+// parseArgs parses the args once to find the config file, and again with
+// the config file values as defaults. Credentials in the config file are
+// only resolved for the environment's iaas, when the state does not have
+// them yet and neither a flag nor an environment variable gives them.
+func (u Up) parseArgs(args []string, state storage.State) (upConfig, storage.ConfigFile, error) {
+	var config upConfig
+	err := u.flags(&config, storage.ConfigFile{}).Parse(args)
+	if err != nil {
+		return upConfig{}, storage.ConfigFile{}, err
+	}
 
-	err := upFlags.Parse(args)
+	configFile, err := u.configFileLoader.Load(config.configFile)
 	if err != nil {
-		return upConfig{}, err
+		return upConfig{}, storage.ConfigFile{}, err
+	}
+
+	config = upConfig{}
+	err = u.flags(&config, configFile).Parse(args)
+	if err != nil {
+		return upConfig{}, storage.ConfigFile{}, err //not tested
+	}
+
+	iaas := state.IAAS
+	if iaas == "" {
+		iaas = config.iaas
+	}
+
+	secrets := []struct {
+		value  *string
+		name   string
+		secret storage.Secret
+		needed bool
+	}{
+		{&config.awsAccessKeyID, "aws.accessKeyID", configFile.AWS.AccessKeyID, iaas == "aws" && state.AWS.AccessKeyID == ""},
+		{&config.awsSecretAccessKey, "aws.secretAccessKey", configFile.AWS.SecretAccessKey, iaas == "aws" && state.AWS.SecretAccessKey == ""},
+		{&config.gcpServiceAccountKey, "gcp.serviceAccountKey", configFile.GCP.ServiceAccountKey, iaas == "gcp" && state.GCP.ServiceAccountKey == ""},
+	}
+	for _, s := range secrets {
+		if !s.needed || *s.value != "" {
+			continue
+		}
+
+		*s.value, err = s.secret.Resolve(s.name)
+		if err != nil {
+			return upConfig{}, storage.ConfigFile{}, fmt.Errorf("config file %s: %s", configFile.Path, err)
+		}
+	}
+
+	return config, configFile, nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func parseTags(tags []string) (map[string]string, error) {
+	parsedTags := map[string]string{}
+	for _, tag := range tags {
+		parts := strings.SplitN(tag, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return map[string]string{}, fmt.Errorf("Tag %q must be in the form key=value.", tag)
+		}
+
+		parsedTags[parts[0]] = parts[1]
 	}
 
-	return config, nil
+	return parsedTags, nil
 }
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// upWorkers bounds how many steps of bbl up run at the same time.
+const upWorkers = 4
+
+// upGraphStep is a unit of bbl up work that can run once every step named in
+// after has finished.
+type upGraphStep struct {
+	name  string
+	after []string
+	run   func() error
+}
+
+type upGraphResult struct {
+	name     string
+	err      error
+	duration time.Duration
+}
+
+// runUpGraph runs steps concurrently, at most workers at a time, starting each
+// one once the steps it comes after have finished. When a step fails no more
+// steps are started, and the error of the first failure is returned once the
+// running steps have finished.
+func runUpGraph(logger logger, workers int, steps []upGraphStep) error {
+	names := map[string]bool{}
+	for _, step := range steps {
+		names[step.name] = true
+	}
+	for _, step := range steps {
+		for _, dependency := range step.after {
+			if !names[dependency] {
+				return fmt.Errorf("step %q comes after unknown step %q", step.name, dependency)
+			}
+		}
+	}
+
+	finished := map[string]bool{}
+	started := map[string]bool{}
+	results := make(chan upGraphResult, len(steps))
+
+	var firstErr error
+	running := 0
+	for {
+		if firstErr == nil {
+			for _, step := range steps {
+				if running == workers {
+					break
+				}
+				if started[step.name] || !upGraphReady(step, finished) {
+					continue
+				}
+
+				started[step.name] = true
+				running++
+				go func(step upGraphStep) {
+					start := time.Now()
+					err := step.run()
+					results <- upGraphResult{name: step.name, err: err, duration: time.Since(start)}
+				}(step)
+			}
+		}
+
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		finished[result.name] = true
+		if result.err != nil {
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			continue
+		}
+
+		logger.Step("%s finished in %s", result.name, result.duration.Round(time.Millisecond))
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	if len(finished) != len(steps) {
+		return errors.New("steps of up depend on each other in a cycle")
+	}
+
+	return nil
+}
+
+func upGraphReady(step upGraphStep, finished map[string]bool) bool {
+	for _, dependency := range step.after {
+		if !finished[dependency] {
+			return false
+		}
+	}
+	return true
+}
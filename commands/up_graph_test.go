@@ -0,0 +1,127 @@
+package commands_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RunUpGraph", func() {
+	var (
+		logger *fakes.Logger
+
+		mutex sync.Mutex
+		order []string
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		order = []string{}
+	})
+
+	record := func(name string) func() error {
+		return func() error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	It("runs each step after the steps it comes after", func() {
+		err := commands.RunUpGraph(logger, 4, []commands.UpGraphStep{
+			{Name: "c", After: []string{"a", "b"}, Run: record("c")},
+			{Name: "b", After: []string{"a"}, Run: record("b")},
+			{Name: "a", Run: record("a")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(order).To(Equal([]string{"a", "b", "c"}))
+	})
+
+	It("runs independent steps at the same time", func() {
+		aStarted := make(chan struct{})
+		bStarted := make(chan struct{})
+
+		done := make(chan error)
+		go func() {
+			done <- commands.RunUpGraph(logger, 2, []commands.UpGraphStep{
+				{Name: "a", Run: func() error { close(aStarted); <-bStarted; return nil }},
+				{Name: "b", Run: func() error { close(bStarted); <-aStarted; return nil }},
+			})
+		}()
+
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("runs no more steps at a time than there are workers", func() {
+		running := 0
+		maxRunning := 0
+		step := func() error {
+			mutex.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mutex.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mutex.Lock()
+			running--
+			mutex.Unlock()
+			return nil
+		}
+
+		err := commands.RunUpGraph(logger, 2, []commands.UpGraphStep{
+			{Name: "a", Run: step},
+			{Name: "b", Run: step},
+			{Name: "c", Run: step},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(maxRunning).To(Equal(2))
+	})
+
+	It("logs how long each step took", func() {
+		err := commands.RunUpGraph(logger, 4, []commands.UpGraphStep{
+			{Name: "a", Run: record("a")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(logger.StepCall.Messages).To(ConsistOf(MatchRegexp(`^a finished in \d+(\.\d+)?[mµn]?s$`)))
+	})
+
+	Context("failure cases", func() {
+		It("returns the error of a failed step and does not start the steps after it", func() {
+			err := commands.RunUpGraph(logger, 4, []commands.UpGraphStep{
+				{Name: "a", Run: func() error { return errors.New("failed to run a") }},
+				{Name: "b", After: []string{"a"}, Run: record("b")},
+			})
+			Expect(err).To(MatchError("failed to run a"))
+
+			Expect(order).To(BeEmpty())
+		})
+
+		It("returns an error when a step comes after an unknown step", func() {
+			err := commands.RunUpGraph(logger, 4, []commands.UpGraphStep{
+				{Name: "a", After: []string{"unknown"}, Run: record("a")},
+			})
+			Expect(err).To(MatchError(`step "a" comes after unknown step "unknown"`))
+		})
+
+		It("returns an error when steps come after each other", func() {
+			err := commands.RunUpGraph(logger, 4, []commands.UpGraphStep{
+				{Name: "a", After: []string{"b"}, Run: record("a")},
+				{Name: "b", After: []string{"a"}, Run: record("b")},
+			})
+			Expect(err).To(MatchError("steps of up depend on each other in a cycle"))
+		})
+	})
+})
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	EnvIDStep       = "env-id"
+	KeyPairStep     = "keypair"
+	TerraformStep   = "terraform"
+	JumpboxStep     = "jumpbox"
+	DirectorStep    = "director"
+	CloudConfigStep = "cloud-config"
+)
+
+// UpSteps are the steps of bbl up, in the order they run.
+var UpSteps = []string{EnvIDStep, KeyPairStep, TerraformStep, JumpboxStep, DirectorStep, CloudConfigStep}
+
+// upJournal decides which steps of bbl up can be skipped because they
+// completed in an earlier run with the same inputs. The bbl version is part
+// of every input hash, so upgrading bbl runs every step again.
+//
+// The journal is kept here rather than read back from the state each step
+// returns, so that it survives steps that build a new state.
+type upJournal struct {
+	logger     logger
+	bblVersion string
+	fromStep   string
+	steps      []storage.UpStep
+}
+
+func newUpJournal(logger logger, bblVersion, fromStep string, state storage.State) *upJournal {
+	return &upJournal{
+		logger:     logger,
+		bblVersion: bblVersion,
+		fromStep:   fromStep,
+		steps:      state.UpJournal,
+	}
+}
+
+func validateUpStep(step string) error {
+	if step == "" || upStepIndex(step) != -1 {
+		return nil
+	}
+
+	return fmt.Errorf("%q is not a step of up, supported values are: [%s]", step, strings.Join(UpSteps, ", "))
+}
+
+// hash returns the hash of a step's inputs.
+func (j *upJournal) hash(inputs ...interface{}) (string, error) {
+	contents, err := json.Marshal(append([]interface{}{j.bblVersion}, inputs...))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(contents)), nil
+}
+
+// skip reports whether the step completed in an earlier run with the same
+// inputs, and was not forced to run again with --from-step.
+func (j *upJournal) skip(step, inputHash string) bool {
+	if j.fromStep != "" && upStepIndex(step) >= upStepIndex(j.fromStep) {
+		return false
+	}
+
+	for _, completed := range j.steps {
+		if completed.Name == step && completed.InputHash == inputHash {
+			j.logger.Step("skipping %s, its inputs have not changed", step)
+			return true
+		}
+	}
+
+	return false
+}
+
+// start forgets the step and every step after it, so that a step which
+// fails part way is not skipped by the next run, and the steps that depend
+// on it run again.
+func (j *upJournal) start(state storage.State, step string) storage.State {
+	var steps []storage.UpStep
+	for _, completed := range j.steps {
+		if upStepIndex(completed.Name) < upStepIndex(step) {
+			steps = append(steps, completed)
+		}
+	}
+	j.steps = steps
+
+	state.UpJournal = j.steps
+	return state
+}
+
+func (j *upJournal) complete(state storage.State, step, inputHash string) storage.State {
+	j.start(state, step)
+	j.steps = append(j.steps, storage.UpStep{
+		Name:      step,
+		InputHash: inputHash,
+	})
+
+	state.UpJournal = j.steps
+	return state
+}
+
+// envIDInput is the env id the env-id step should end up with, which is the
+// one recorded once the step completes.
+func envIDInput(name string, state storage.State) string {
+	if name != "" {
+		return name
+	}
+	return state.EnvID
+}
+
+// terraformInputs is the part of the state the terraform templates and
+// variables are generated from. Credentials are left out so that rotating
+// them does not re-apply terraform.
+func terraformInputs(state storage.State) storage.State {
+	inputs := storage.State{
+		IAAS:                       state.IAAS,
+		NoDirector:                 state.NoDirector,
+		MigratedFromCloudFormation: state.MigratedFromCloudFormation,
+		AWS:                        state.AWS,
+		GCP:                        state.GCP,
+		KeyPair:                    storage.KeyPair{Name: state.KeyPair.Name, PublicKey: state.KeyPair.PublicKey},
+		Jumpbox:                    storage.Jumpbox{Enabled: state.Jumpbox.Enabled},
+		Stack:                      state.Stack,
+		EnvID:                      state.EnvID,
+		LB:                         state.LB,
+		Tags:                       state.Tags,
+	}
+	inputs.AWS.AccessKeyID = ""
+	inputs.AWS.SecretAccessKey = ""
+	inputs.AWS.Profile = ""
+	inputs.AWS.CredentialChain = false
+	inputs.AWS.AssumeRoleARN = ""
+	inputs.AWS.ExternalID = ""
+	inputs.AWS.SessionName = ""
+	inputs.AWS.DirectorAZ = ""
+	inputs.GCP.ServiceAccountKey = ""
+	inputs.GCP.ApplicationDefaultCredentials = false
+	inputs.GCP.ImpersonateServiceAccount = ""
+
+	return inputs
+}
+
+// directorInputs is the part of the state, besides the terraform outputs,
+// that the director is deployed from.
+func directorInputs(state storage.State) storage.State {
+	return storage.State{
+		Jumpbox: storage.Jumpbox{Enabled: state.Jumpbox.Enabled},
+		BOSH:    storage.BOSH{UserOpsFile: state.BOSH.UserOpsFile},
+		UAA:     state.UAA,
+		CredHub: state.CredHub,
+	}
+}
+
+// cloudConfigInputs is the part of the state, besides the terraform outputs,
+// that the cloud config and runtime configs are generated from.
+func cloudConfigInputs(state storage.State) storage.State {
+	return storage.State{
+		IAAS:               state.IAAS,
+		LB:                 storage.LB{Type: state.LB.Type},
+		CloudConfigOpsFile: state.CloudConfigOpsFile,
+		NoRuntimeConfig:    state.NoRuntimeConfig,
+		CPIConfig:          state.CPIConfig,
+	}
+}
+
+func upStepIndex(step string) int {
+	for i, upStep := range UpSteps {
+		if upStep == step {
+			return i
+		}
+	}
+
+	return -1
+}
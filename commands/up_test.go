@@ -3,6 +3,9 @@ package commands_test
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/commands"
@@ -22,6 +25,10 @@ var _ = Describe("Up", func() {
 		fakeGCPUp       *fakes.GCPUp
 		fakeEnvGetter   *fakes.EnvGetter
 		fakeBOSHManager *fakes.BOSHManager
+		fakeConfigFile  *fakes.ConfigFileLoader
+		fakeStateStore  *fakes.StateStore
+		fakeCreateLBs   *fakes.Command
+		fakeUpdateLBs   *fakes.Command
 		state           storage.State
 	)
 
@@ -31,8 +38,12 @@ var _ = Describe("Up", func() {
 		fakeEnvGetter = &fakes.EnvGetter{}
 		fakeBOSHManager = &fakes.BOSHManager{}
 		fakeBOSHManager.VersionCall.Returns.Version = "2.0.24"
+		fakeConfigFile = &fakes.ConfigFileLoader{}
+		fakeStateStore = &fakes.StateStore{}
+		fakeCreateLBs = &fakes.Command{}
+		fakeUpdateLBs = &fakes.Command{}
 
-		command = commands.NewUp(fakeAWSUp, fakeGCPUp, fakeEnvGetter, fakeBOSHManager)
+		command = commands.NewUp(fakeAWSUp, fakeGCPUp, fakeEnvGetter, fakeBOSHManager, fakeConfigFile, fakeStateStore, fakeCreateLBs, fakeUpdateLBs)
 	})
 
 	Describe("CheckFastFails", func() {
@@ -141,9 +152,194 @@ var _ = Describe("Up", func() {
 				})
 			})
 		})
+
+		Context("when tags are provided", func() {
+			It("returns an error when a tag is not in the form key=value", func() {
+				err := command.CheckFastFails([]string{
+					"--iaas", "aws",
+					"--tag", "some-tag",
+				}, storage.State{})
+				Expect(err).To(MatchError(`Tag "some-tag" must be in the form key=value.`))
+			})
+
+			It("returns an error when an aws tag uses the reserved prefix", func() {
+				err := command.CheckFastFails([]string{
+					"--iaas", "aws",
+					"--tag", "aws:owner=some-owner",
+				}, storage.State{})
+				Expect(err).To(MatchError(`Tag "aws:owner" uses the reserved "aws:" prefix.`))
+			})
+
+			It("returns an error when a gcp tag is not a valid label", func() {
+				err := command.CheckFastFails([]string{
+					"--tag", "Owner=Some-Owner",
+				}, storage.State{IAAS: "gcp"})
+				Expect(err).To(MatchError(`Tag "Owner=Some-Owner" is not a valid GCP label. Keys and values may only contain lowercase letters, numbers, dashes and underscores.`))
+			})
+		})
+
+		Context("when the config file cannot be loaded", func() {
+			It("returns an error", func() {
+				fakeConfigFile.LoadCall.Returns.Error = errors.New("failed to load config file")
+
+				err := command.CheckFastFails([]string{"--config", "some-bbl.yml"}, storage.State{})
+				Expect(err).To(MatchError("failed to load config file"))
+				Expect(fakeConfigFile.LoadCall.Receives.Path).To(Equal("some-bbl.yml"))
+			})
+		})
+
+		Context("when --from-step is not a step of up", func() {
+			It("returns an error", func() {
+				err := command.CheckFastFails([]string{
+					"--from-step", "some-step",
+				}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError(`"some-step" is not a step of up, supported values are: [env-id, keypair, terraform, jumpbox, director, cloud-config]`))
+			})
+		})
 	})
 
 	Describe("Execute", func() {
+		Context("when tags are provided", func() {
+			It("merges the tags into the state", func() {
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--tag", "owner=some-new-owner",
+					"--tag", "env=some-env",
+				}, storage.State{
+					Tags: map[string]string{
+						"owner":       "some-owner",
+						"cost-center": "some-cost-center",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.State.Tags).To(Equal(map[string]string{
+					"owner":       "some-new-owner",
+					"cost-center": "some-cost-center",
+					"env":         "some-env",
+				}))
+			})
+		})
+
+		Context("when --no-runtime-config is provided", func() {
+			It("saves the opt-out to the state", func() {
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--no-runtime-config",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.State.NoRuntimeConfig).To(BeTrue())
+			})
+		})
+
+		Context("when --upload-stemcell is provided", func() {
+			It("saves the opt-in to the state", func() {
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--upload-stemcell",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.State.UploadStemcell).To(BeTrue())
+			})
+		})
+
+		Context("when --uaa is provided", func() {
+			It("saves the opt-in to the state", func() {
+				err := command.Execute([]string{
+					"--iaas", "gcp",
+					"--uaa",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGCPUp.ExecuteCall.Receives.State.UAA).To(BeTrue())
+				Expect(fakeGCPUp.ExecuteCall.Receives.State.CredHub).To(BeFalse())
+			})
+		})
+
+		Context("when --credhub is provided", func() {
+			It("saves the opt-in to the state along with the uaa it needs", func() {
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--credhub",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.State.CredHub).To(BeTrue())
+				Expect(fakeAWSUp.ExecuteCall.Receives.State.UAA).To(BeTrue())
+			})
+
+			It("keeps credhub enabled when a later up omits the flag", func() {
+				err := command.Execute([]string{}, storage.State{IAAS: "aws", UAA: true, CredHub: true})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.State.CredHub).To(BeTrue())
+			})
+		})
+
+		Context("when --from-step is provided", func() {
+			It("passes the step on to the iaas", func() {
+				err := command.Execute([]string{"--iaas", "aws", "--from-step", "director"}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.FromStep).To(Equal("director"))
+
+				err = command.Execute([]string{"--iaas", "gcp", "--from-step", "terraform"}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.FromStep).To(Equal("terraform"))
+			})
+		})
+
+		Context("when --confirm-env-id is provided", func() {
+			It("passes the env id on to the iaas", func() {
+				err := command.Execute([]string{"--iaas", "aws", "--confirm-env-id", "some-env-id"}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.ConfirmEnvID).To(Equal("some-env-id"))
+
+				err = command.Execute([]string{"--iaas", "gcp", "--confirm-env-id", "some-env-id"}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.ConfirmEnvID).To(Equal("some-env-id"))
+			})
+		})
+
+		Context("when --cpi-config is provided", func() {
+			It("saves the opt-in to the state", func() {
+				err := command.Execute([]string{
+					"--iaas", "gcp",
+					"--cpi-config",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGCPUp.ExecuteCall.Receives.State.CPIConfig).To(BeTrue())
+			})
+		})
+
+		Context("when a cloud-config ops file is provided", func() {
+			It("saves the ops file contents to the state", func() {
+				opsFile, err := ioutil.TempFile("", "cloud-config-ops")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = opsFile.WriteString("some-cloud-config-ops")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = command.Execute([]string{
+					"--iaas", "aws",
+					"--cloud-config-ops-file", opsFile.Name(),
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.State.CloudConfigOpsFile).To(Equal("some-cloud-config-ops"))
+			})
+
+			It("returns an error when the ops file cannot be read", func() {
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--cloud-config-ops-file", "/some/missing/file",
+				}, storage.State{})
+				Expect(err).To(MatchError("error reading cloud-config ops-file contents: open /some/missing/file: no such file or directory"))
+			})
+		})
+
 		Context("when aws args are provided through environment variables", func() {
 			BeforeEach(func() {
 				fakeEnvGetter.Values = map[string]string{
@@ -203,6 +399,307 @@ var _ = Describe("Up", func() {
 			)
 		})
 
+		Context("when a config file is provided", func() {
+			var secretsDir string
+
+			BeforeEach(func() {
+				var err error
+				secretsDir, err = ioutil.TempDir("", "")
+				Expect(err).NotTo(HaveOccurred())
+
+				for name, contents := range map[string]string{
+					"access-key-id":     "access-key-id-from-config",
+					"secret-access-key": "secret-access-key-from-config",
+				} {
+					err = ioutil.WriteFile(filepath.Join(secretsDir, name), []byte(contents), os.ModePerm)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				fakeConfigFile.LoadCall.Returns.ConfigFile = storage.ConfigFile{
+					IAAS:    "aws",
+					Name:    "name-from-config",
+					OpsFile: "ops-file-from-config",
+					AWS: storage.ConfigFileAWS{
+						Region:          "region-from-config",
+						AccessKeyID:     storage.Secret{File: filepath.Join(secretsDir, "access-key-id")},
+						SecretAccessKey: storage.Secret{File: filepath.Join(secretsDir, "secret-access-key")},
+					},
+					Path: "some-bbl.yml",
+				}
+				fakeStateStore.GetCall.Returns.State = storage.State{
+					IAAS:  "aws",
+					EnvID: "some-env-id",
+				}
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(secretsDir)
+			})
+
+			It("uses the values from the config file", func() {
+				err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeConfigFile.LoadCall.Receives.Path).To(Equal("some-bbl.yml"))
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig).To(Equal(commands.AWSUpConfig{
+					AccessKeyID:     "access-key-id-from-config",
+					SecretAccessKey: "secret-access-key-from-config",
+					Region:          "region-from-config",
+					OpsFilePath:     "ops-file-from-config",
+					Name:            "name-from-config",
+				}))
+			})
+
+			It("gives precedence to environment variables and then to command line args", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_AWS_REGION":        "region-from-env",
+					"BBL_AWS_ACCESS_KEY_ID": "access-key-id-from-env",
+				}
+
+				err := command.Execute([]string{
+					"--config", "some-bbl.yml",
+					"--aws-access-key-id", "access-key-id-from-args",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig).To(Equal(commands.AWSUpConfig{
+					AccessKeyID:     "access-key-id-from-args",
+					SecretAccessKey: "secret-access-key-from-config",
+					Region:          "region-from-env",
+					OpsFilePath:     "ops-file-from-config",
+					Name:            "name-from-config",
+				}))
+			})
+
+			It("does not resolve the credentials given by flags or environment variables", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_AWS_SECRET_ACCESS_KEY": "secret-access-key-from-env",
+				}
+				fakeConfigFile.LoadCall.Returns.ConfigFile.AWS.AccessKeyID = storage.Secret{Env: "BBL_UP_TEST_MISSING"}
+				fakeConfigFile.LoadCall.Returns.ConfigFile.AWS.SecretAccessKey = storage.Secret{File: filepath.Join(secretsDir, "missing")}
+
+				err := command.Execute([]string{
+					"--config", "some-bbl.yml",
+					"--aws-access-key-id", "access-key-id-from-args",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.AccessKeyID).To(Equal("access-key-id-from-args"))
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.SecretAccessKey).To(Equal("secret-access-key-from-env"))
+			})
+
+			It("lets flags turn off the switches in the config file", func() {
+				fakeConfigFile.LoadCall.Returns.ConfigFile.AWS.CredentialChain = true
+
+				err := command.Execute([]string{"--config", "some-bbl.yml", "--aws-credential-chain=false"}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.CredentialChain).To(BeFalse())
+			})
+
+			It("does not resolve the credentials the state already has", func() {
+				fakeConfigFile.LoadCall.Returns.ConfigFile.AWS.AccessKeyID = storage.Secret{Env: "BBL_UP_TEST_MISSING"}
+				fakeConfigFile.LoadCall.Returns.ConfigFile.AWS.SecretAccessKey = storage.Secret{Env: "BBL_UP_TEST_MISSING"}
+
+				err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{
+					IAAS: "aws",
+					AWS: storage.AWS{
+						AccessKeyID:     "access-key-id-from-state",
+						SecretAccessKey: "secret-access-key-from-state",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.AccessKeyID).To(BeEmpty())
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.SecretAccessKey).To(BeEmpty())
+			})
+
+			It("does not resolve the credentials of another iaas", func() {
+				fakeConfigFile.LoadCall.Returns.ConfigFile.GCP.ServiceAccountKey = storage.Secret{Env: "BBL_UP_TEST_MISSING"}
+
+				err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(1))
+			})
+
+			It("returns an error when a credential cannot be resolved", func() {
+				fakeConfigFile.LoadCall.Returns.ConfigFile.AWS.AccessKeyID = storage.Secret{Env: "BBL_UP_TEST_MISSING"}
+
+				err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+				Expect(err).To(MatchError("config file some-bbl.yml: aws.accessKeyID refers to env var BBL_UP_TEST_MISSING, which is not set"))
+				Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+			})
+
+			Context("when the config file describes a gcp environment", func() {
+				BeforeEach(func() {
+					fakeConfigFile.LoadCall.Returns.ConfigFile = storage.ConfigFile{
+						IAAS:    "gcp",
+						Jumpbox: true,
+						GCP: storage.ConfigFileGCP{
+							ApplicationDefaultCredentials: true,
+							DirectorServiceAccount:        true,
+						},
+						Path: "some-bbl.yml",
+					}
+				})
+
+				It("lets flags turn off the switches in the config file", func() {
+					err := command.Execute([]string{
+						"--config", "some-bbl.yml",
+						"--jumpbox=false",
+						"--gcp-application-default-credentials=false",
+						"--gcp-director-service-account=false",
+					}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.Jumpbox).To(BeFalse())
+					Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.ApplicationDefaultCredentials).To(BeFalse())
+					Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.DirectorServiceAccount).To(BeFalse())
+				})
+			})
+
+			It("does not create load balancers when the config file describes none", func() {
+				err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+
+			Context("when the config file describes load balancers", func() {
+				BeforeEach(func() {
+					fakeConfigFile.LoadCall.Returns.ConfigFile.LB = storage.ConfigFileLB{Type: "concourse"}
+				})
+
+				It("creates them after the environment is up", func() {
+					err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeStateStore.GetCall.CallCount).To(Equal(1))
+					Expect(fakeCreateLBs.CheckFastFailsCall.CallCount).To(Equal(1))
+					Expect(fakeCreateLBs.ExecuteCall.CallCount).To(Equal(1))
+					Expect(fakeCreateLBs.ExecuteCall.Receives.SubcommandFlags).To(Equal([]string{
+						"--config", "some-bbl.yml",
+					}))
+					Expect(fakeCreateLBs.ExecuteCall.Receives.State).To(Equal(storage.State{
+						IAAS:  "aws",
+						EnvID: "some-env-id",
+					}))
+				})
+
+				Context("when the environment already has load balancers", func() {
+					BeforeEach(func() {
+						for name, contents := range map[string]string{
+							"lb-cert": "some-cert",
+							"lb-key":  "some-key",
+						} {
+							err := ioutil.WriteFile(filepath.Join(secretsDir, name), []byte(contents), os.ModePerm)
+							Expect(err).NotTo(HaveOccurred())
+						}
+
+						fakeConfigFile.LoadCall.Returns.ConfigFile.LB = storage.ConfigFileLB{
+							Type: "concourse",
+							Cert: filepath.Join(secretsDir, "lb-cert"),
+							Key:  filepath.Join(secretsDir, "lb-key"),
+						}
+						fakeStateStore.GetCall.Returns.State.LB = storage.LB{
+							Type: "concourse",
+							Cert: "some-cert",
+							Key:  "some-key",
+						}
+					})
+
+					It("leaves them alone when they match the config file", func() {
+						err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(fakeCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+						Expect(fakeUpdateLBs.ExecuteCall.CallCount).To(Equal(0))
+					})
+
+					It("updates them when the certificate in the config file changed", func() {
+						fakeStateStore.GetCall.Returns.State.LB.Cert = "some-old-cert"
+
+						err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(fakeCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+						Expect(fakeUpdateLBs.CheckFastFailsCall.CallCount).To(Equal(1))
+						Expect(fakeUpdateLBs.ExecuteCall.CallCount).To(Equal(1))
+						Expect(fakeUpdateLBs.ExecuteCall.Receives.SubcommandFlags).To(Equal([]string{
+							"--cert", filepath.Join(secretsDir, "lb-cert"),
+							"--key", filepath.Join(secretsDir, "lb-key"),
+						}))
+					})
+
+					It("updates them when the domain in the config file changed", func() {
+						fakeConfigFile.LoadCall.Returns.ConfigFile.LB.Domain = "some-new-domain"
+						fakeStateStore.GetCall.Returns.State.LB.Domain = "some-domain"
+
+						err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(fakeUpdateLBs.ExecuteCall.Receives.SubcommandFlags).To(Equal([]string{
+							"--cert", filepath.Join(secretsDir, "lb-cert"),
+							"--key", filepath.Join(secretsDir, "lb-key"),
+							"--domain", "some-new-domain",
+						}))
+					})
+
+					It("returns an error when the config file describes another type", func() {
+						fakeStateStore.GetCall.Returns.State.LB.Type = "cf"
+
+						err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+						Expect(err).To(MatchError("some-bbl.yml describes a concourse load balancer, but the environment has a cf load balancer. Run `bbl delete-lbs` and then `bbl up` to replace it."))
+
+						Expect(fakeCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+						Expect(fakeUpdateLBs.ExecuteCall.CallCount).To(Equal(0))
+					})
+
+					It("returns an error when a certificate in the config file cannot be read", func() {
+						fakeConfigFile.LoadCall.Returns.ConfigFile.LB.Cert = filepath.Join(secretsDir, "missing")
+
+						err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+						Expect(err).To(HaveOccurred())
+						Expect(fakeUpdateLBs.ExecuteCall.CallCount).To(Equal(0))
+					})
+				})
+
+				It("does not create them when the environment fails to come up", func() {
+					fakeAWSUp.ExecuteCall.Returns.Error = errors.New("failed to up")
+
+					err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+					Expect(err).To(MatchError("failed to up"))
+
+					Expect(fakeCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				Context("failure cases", func() {
+					It("returns an error when the state cannot be read", func() {
+						fakeStateStore.GetCall.Returns.Error = errors.New("failed to get state")
+
+						err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+						Expect(err).To(MatchError("failed to get state"))
+					})
+
+					It("returns an error when the load balancers fail their fast fails", func() {
+						fakeCreateLBs.CheckFastFailsCall.Returns.Error = errors.New("invalid cert")
+
+						err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+						Expect(err).To(MatchError("invalid cert"))
+						Expect(fakeCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+					})
+
+					It("returns an error when the load balancers cannot be created", func() {
+						fakeCreateLBs.ExecuteCall.Returns.Error = errors.New("failed to create lbs")
+
+						err := command.Execute([]string{"--config", "some-bbl.yml"}, storage.State{})
+						Expect(err).To(MatchError("failed to create lbs"))
+					})
+				})
+			})
+		})
+
 		Context("when an ops-file is provided via command line flag", func() {
 			It("populates the aws config with the correct ops-file path", func() {
 				fakeEnvGetter.Values = map[string]string{
@@ -418,6 +915,52 @@ var _ = Describe("Up", func() {
 						}))
 					})
 				})
+
+				Context("when the --gcp-internal-only flag is specified", func() {
+					It("executes the GCP up with internal only mode", func() {
+						err := command.Execute([]string{
+							"--iaas", "gcp",
+							"--gcp-internal-only",
+							"--gcp-service-account-key", "some-service-account-key",
+							"--gcp-project-id", "some-project-id",
+							"--gcp-zone", "some-zone",
+							"--gcp-region", "some-region",
+						}, storage.State{})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig).To(Equal(commands.GCPUpConfig{
+							ServiceAccountKey: "some-service-account-key",
+							ProjectID:         "some-project-id",
+							Zone:              "some-zone",
+							Region:            "some-region",
+							InternalOnly:      true,
+						}))
+					})
+				})
+
+				Context("when application default credentials and impersonation are specified", func() {
+					It("executes the GCP up without a service account key", func() {
+						err := command.Execute([]string{
+							"--iaas", "gcp",
+							"--gcp-application-default-credentials",
+							"--gcp-impersonate-service-account", "bbl@some-project-id.iam.gserviceaccount.com",
+							"--gcp-director-service-account",
+							"--gcp-project-id", "some-project-id",
+							"--gcp-zone", "some-zone",
+							"--gcp-region", "some-region",
+						}, storage.State{})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig).To(Equal(commands.GCPUpConfig{
+							ApplicationDefaultCredentials: true,
+							ImpersonateServiceAccount:     "bbl@some-project-id.iam.gserviceaccount.com",
+							DirectorServiceAccount:        true,
+							ProjectID:                     "some-project-id",
+							Zone:                          "some-zone",
+							Region:                        "some-region",
+						}))
+					})
+				})
 			})
 
 			Context("when desired iaas is aws", func() {
@@ -428,6 +971,9 @@ var _ = Describe("Up", func() {
 						"--aws-secret-access-key", "some-secret-access-key",
 						"--aws-region", "some-region",
 						"--aws-bosh-az", "some-bosh-az",
+						"--aws-bosh-azs", "some-failover-az, other-failover-az",
+						"--aws-nat-type", "gateway",
+						"--aws-nat-ami", "some-nat-ami",
 					}, storage.State{})
 					Expect(err).NotTo(HaveOccurred())
 
@@ -437,6 +983,45 @@ var _ = Describe("Up", func() {
 						SecretAccessKey: "some-secret-access-key",
 						Region:          "some-region",
 						BOSHAZ:          "some-bosh-az",
+						BOSHAZs:         []string{"some-failover-az", "other-failover-az"},
+						NATType:         "gateway",
+						NATAMI:          "some-nat-ami",
+					}))
+				})
+			})
+
+			Context("when aws credentials come from a profile and an assumed role", func() {
+				It("executes the AWS up with them", func() {
+					err := command.Execute([]string{
+						"--iaas", "aws",
+						"--aws-profile", "some-profile",
+						"--aws-assume-role-arn", "some-role-arn",
+						"--aws-external-id", "some-external-id",
+						"--aws-session-name", "some-session-name",
+						"--aws-region", "some-region",
+					}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig).To(Equal(commands.AWSUpConfig{
+						Profile:       "some-profile",
+						AssumeRoleARN: "some-role-arn",
+						ExternalID:    "some-external-id",
+						SessionName:   "some-session-name",
+						Region:        "some-region",
+					}))
+				})
+
+				It("executes the AWS up with the default credential chain", func() {
+					err := command.Execute([]string{
+						"--iaas", "aws",
+						"--aws-credential-chain",
+						"--aws-region", "some-region",
+					}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig).To(Equal(commands.AWSUpConfig{
+						CredentialChain: true,
+						Region:          "some-region",
 					}))
 				})
 			})
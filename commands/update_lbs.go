@@ -8,11 +8,12 @@ import (
 const UpdateLBsCommand = "update-lbs"
 
 type updateLBConfig struct {
-	certPath      string
-	keyPath       string
-	chainPath     string
-	domain        string
-	skipIfMissing bool
+	certPath           string
+	keyPath            string
+	chainPath          string
+	domain             string
+	skipIfMissing      bool
+	cloudConfigOpsFile string
 }
 
 type UpdateLBs struct {
@@ -57,6 +58,11 @@ func (u UpdateLBs) Execute(subcommandFlags []string, state storage.State) error
 		return nil
 	}
 
+	state, err = readCloudConfigOpsFile(config.cloudConfigOpsFile, state)
+	if err != nil {
+		return err
+	}
+
 	switch state.IAAS {
 	case "gcp":
 		if err := u.gcpUpdateLBs.Execute(GCPCreateLBsConfig{
@@ -116,17 +122,25 @@ func (u UpdateLBs) CheckFastFails(subcommandFlags []string, state storage.State)
 	return nil
 }
 
-func (UpdateLBs) parseFlags(subcommandFlags []string) (updateLBConfig, error) {
-	lbFlags := flags.New("update-lbs")
+func (u UpdateLBs) Flags() flags.Flags {
+	return u.flags(&updateLBConfig{})
+}
 
-	config := updateLBConfig{}
+func (UpdateLBs) flags(config *updateLBConfig) flags.Flags {
+	lbFlags := flags.New("update-lbs")
 	lbFlags.String(&config.certPath, "cert", "")
 	lbFlags.String(&config.keyPath, "key", "")
 	lbFlags.String(&config.chainPath, "chain", "")
 	lbFlags.String(&config.domain, "domain", "")
 	lbFlags.Bool(&config.skipIfMissing, "skip-if-missing", "", false)
+	lbFlags.String(&config.cloudConfigOpsFile, "cloud-config-ops-file", "")
 
-	err := lbFlags.Parse(subcommandFlags)
+	return lbFlags
+}
+
+func (u UpdateLBs) parseFlags(subcommandFlags []string) (updateLBConfig, error) {
+	config := updateLBConfig{}
+	err := u.flags(&config).Parse(subcommandFlags)
 	if err != nil {
 		return config, err
 	}
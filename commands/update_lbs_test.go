@@ -2,6 +2,7 @@ package commands_test
 
 import (
 	"errors"
+	"io/ioutil"
 
 	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
@@ -175,6 +176,28 @@ var _ = Describe("Update LBs", func() {
 			})
 		})
 
+		Context("when a cloud-config ops file is provided", func() {
+			It("saves the ops file contents to the state", func() {
+				opsFile, err := ioutil.TempFile("", "cloud-config-ops")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = opsFile.WriteString("some-cloud-config-ops")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = command.Execute([]string{
+					"--cloud-config-ops-file", opsFile.Name(),
+				}, storage.State{
+					IAAS: "gcp",
+					LB: storage.LB{
+						Type: "cf",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpUpdateLBs.ExecuteCall.Receives.State.CloudConfigOpsFile).To(Equal("some-cloud-config-ops"))
+			})
+		})
+
 		Describe("failure cases", func() {
 			It("returns an error when invalid flags are provided", func() {
 				err := command.Execute([]string{
@@ -183,6 +206,19 @@ var _ = Describe("Update LBs", func() {
 
 				Expect(err).To(MatchError(ContainSubstring("flag provided but not defined")))
 			})
+
+			It("returns an error when the cloud-config ops file cannot be read", func() {
+				err := command.Execute([]string{
+					"--cloud-config-ops-file", "/some/missing/file",
+				}, storage.State{
+					IAAS: "gcp",
+					LB: storage.LB{
+						Type: "cf",
+					},
+				})
+
+				Expect(err).To(MatchError("error reading cloud-config ops-file contents: open /some/missing/file: no such file or directory"))
+			})
 		})
 	})
 })
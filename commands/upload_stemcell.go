@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	UploadStemcellCommand = "upload-stemcell"
+)
+
+type stemcellUploader interface {
+	Upload(state storage.State) error
+}
+
+type UploadStemcell struct {
+	stateValidator   stateValidator
+	stemcellUploader stemcellUploader
+}
+
+func NewUploadStemcell(stateValidator stateValidator, stemcellUploader stemcellUploader) UploadStemcell {
+	return UploadStemcell{
+		stateValidator:   stateValidator,
+		stemcellUploader: stemcellUploader,
+	}
+}
+
+func (u UploadStemcell) CheckFastFails(subcommandFlags []string, state storage.State) error {
+	err := u.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	if state.NoDirector || state.BOSH.IsEmpty() {
+		return errors.New("There is no director to upload a stemcell to.")
+	}
+
+	return nil
+}
+
+func (u UploadStemcell) Execute(subcommandFlags []string, state storage.State) error {
+	return u.stemcellUploader.Upload(state)
+}
@@ -0,0 +1,63 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UploadStemcell", func() {
+	var (
+		stateValidator   *fakes.StateValidator
+		stemcellUploader *fakes.StemcellUploader
+		command          commands.UploadStemcell
+		state            storage.State
+	)
+
+	BeforeEach(func() {
+		stateValidator = &fakes.StateValidator{}
+		stemcellUploader = &fakes.StemcellUploader{}
+
+		state = storage.State{
+			IAAS: "gcp",
+			BOSH: storage.BOSH{
+				DirectorAddress: "some-director-address",
+			},
+		}
+
+		command = commands.NewUploadStemcell(stateValidator, stemcellUploader)
+	})
+
+	Describe("CheckFastFails", func() {
+		It("returns an error when the state validator fails", func() {
+			stateValidator.ValidateCall.Returns.Error = errors.New("failed to validate state")
+			err := command.CheckFastFails([]string{}, state)
+			Expect(err).To(MatchError("failed to validate state"))
+		})
+
+		It("returns an error when there is no director", func() {
+			err := command.CheckFastFails([]string{}, storage.State{NoDirector: true})
+			Expect(err).To(MatchError("There is no director to upload a stemcell to."))
+		})
+	})
+
+	Describe("Execute", func() {
+		It("uploads the stemcell", func() {
+			err := command.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stemcellUploader.UploadCall.CallCount).To(Equal(1))
+			Expect(stemcellUploader.UploadCall.Receives.State).To(Equal(state))
+		})
+
+		It("returns an error when the stemcell fails to upload", func() {
+			stemcellUploader.UploadCall.Returns.Error = errors.New("failed to upload stemcell")
+			err := command.Execute([]string{}, state)
+			Expect(err).To(MatchError("failed to upload stemcell"))
+		})
+	})
+})
@@ -42,11 +42,15 @@ Global Options:
   --help      [-h]       Prints usage
   --state-dir            Directory containing bbl-state.json
   --debug                Prints debugging output
+  --log-format           Output format, "text" or "json" (default "text")
   --version              Prints version
 
 Commands:
+  audit                  Prints the audit log of the environment
   bosh-deployment-vars   Prints required variables for BOSH deployment
   cloud-config           Prints suggested cloud configuration for BOSH environment
+  completion             Prints a shell completion script for bbl
+  cpi-config             Prints cpi configuration for BOSH environment
   create-lbs             Attaches load balancer(s)
   delete-lbs             Deletes attached load balancer(s)
   destroy                Tears down BOSH director infrastructure
@@ -55,15 +59,26 @@ Commands:
   director-username      Prints BOSH director username
   director-password      Prints BOSH director password
   director-ca-cert       Prints BOSH director CA certificate
+  credhub-server         Prints CredHub server address
+  credhub-secret         Prints CredHub admin client secret
+  credhub-ca-cert        Prints CredHub and UAA CA certificates
+  uaa-admin-secret       Prints UAA admin client secret
   env-id                 Prints environment ID
   latest-error           Prints the output from the latest call to terraform
+  move-director          Moves the BOSH director to another availability zone
   print-env              Prints BOSH friendly environment variables
-  rotate                 Rotates the keypair for BOSH
+  protect                Protects the environment from destroy and IaaS changes
+  rotate                 Rotates the keypair or credentials for BOSH
+  rotate-certs           Regenerates the BOSH director certificates
+  runtime-config         Prints runtime configuration for BOSH environment
   help                   Prints usage
   lbs                    Prints attached load balancer(s)
   ssh-key                Prints SSH private key
+  status                 Prints health checks for the environment
+  unprotect              Removes the protection from the environment
   up                     Deploys BOSH director on an IAAS
   update-lbs             Updates load balancer(s)
+  upload-stemcell        Uploads the IaaS light stemcell to the BOSH director
   version                Prints version
 
   Use "bbl [command] --help" for more information about a command.
@@ -82,6 +97,7 @@ Global Options:
   --help      [-h]       Prints usage
   --state-dir            Directory containing bbl-state.json
   --debug                Prints debugging output
+  --log-format           Output format, "text" or "json" (default "text")
   --version              Prints version
 
 [my-command command options]
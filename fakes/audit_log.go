@@ -0,0 +1,36 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/storage"
+
+type AuditLog struct {
+	AppendCall struct {
+		CallCount int
+		Receives  struct {
+			Entry storage.AuditEntry
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	EntriesCall struct {
+		CallCount int
+		Returns   struct {
+			Entries []storage.AuditEntry
+			Error   error
+		}
+	}
+}
+
+func (a *AuditLog) Append(entry storage.AuditEntry) error {
+	a.AppendCall.CallCount++
+	a.AppendCall.Receives.Entry = entry
+
+	return a.AppendCall.Returns.Error
+}
+
+func (a *AuditLog) Entries() ([]storage.AuditEntry, error) {
+	a.EntriesCall.CallCount++
+
+	return a.EntriesCall.Returns.Entries, a.EntriesCall.Returns.Error
+}
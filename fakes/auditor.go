@@ -0,0 +1,30 @@
+package fakes
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/application"
+)
+
+type Auditor struct {
+	RecordCall struct {
+		CallCount int
+		Receives  struct {
+			Configuration application.Configuration
+			StartedAt     time.Time
+			CommandErr    error
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func (a *Auditor) Record(configuration application.Configuration, startedAt time.Time, commandErr error) error {
+	a.RecordCall.CallCount++
+	a.RecordCall.Receives.Configuration = configuration
+	a.RecordCall.Receives.StartedAt = startedAt
+	a.RecordCall.Receives.CommandErr = commandErr
+
+	return a.RecordCall.Returns.Error
+}
@@ -6,10 +6,90 @@ import (
 )
 
 type BOSHClient struct {
-	UpdateCloudConfigCall struct {
+	UpdateConfigCall struct {
 		CallCount int
 		Receives  struct {
-			Yaml []byte
+			Type    string
+			Name    string
+			Content []byte
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	LatestConfigCall struct {
+		CallCount int
+		Receives  struct {
+			Types []string
+			Names []string
+		}
+		Returns struct {
+			Configs map[string]bosh.Config
+			Error   error
+		}
+	}
+
+	DeleteConfigCall struct {
+		CallCount int
+		Receives  struct {
+			Type string
+			Name string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	StemcellsCall struct {
+		CallCount int
+		Returns   struct {
+			Stemcells []bosh.Stemcell
+			Error     error
+		}
+	}
+
+	UploadStemcellCall struct {
+		CallCount int
+		Receives  struct {
+			URL  string
+			SHA1 string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	DeploymentsCall struct {
+		CallCount int
+		Returns   struct {
+			Deployments []bosh.Deployment
+			Error       error
+		}
+	}
+
+	DeleteDeploymentCall struct {
+		CallCount int
+		Receives  struct {
+			Names []string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	OrphanedDisksCall struct {
+		CallCount int
+		Returns   struct {
+			OrphanedDisks []bosh.OrphanedDisk
+			Error         error
+		}
+	}
+
+	DeleteOrphanedDiskCall struct {
+		CallCount int
+		Receives  struct {
+			DiskCIDs []string
 		}
 		Returns struct {
 			Error error
@@ -32,10 +112,60 @@ type BOSHClient struct {
 	}
 }
 
-func (c *BOSHClient) UpdateCloudConfig(yaml []byte) error {
-	c.UpdateCloudConfigCall.CallCount++
-	c.UpdateCloudConfigCall.Receives.Yaml = yaml
-	return c.UpdateCloudConfigCall.Returns.Error
+func (c *BOSHClient) UpdateConfig(configType, name string, content []byte) error {
+	c.UpdateConfigCall.CallCount++
+	c.UpdateConfigCall.Receives.Type = configType
+	c.UpdateConfigCall.Receives.Name = name
+	c.UpdateConfigCall.Receives.Content = content
+	return c.UpdateConfigCall.Returns.Error
+}
+
+func (c *BOSHClient) LatestConfig(configType, name string) (bosh.Config, error) {
+	c.LatestConfigCall.CallCount++
+	c.LatestConfigCall.Receives.Types = append(c.LatestConfigCall.Receives.Types, configType)
+	c.LatestConfigCall.Receives.Names = append(c.LatestConfigCall.Receives.Names, name)
+	return c.LatestConfigCall.Returns.Configs[name], c.LatestConfigCall.Returns.Error
+}
+
+func (c *BOSHClient) DeleteConfig(configType, name string) error {
+	c.DeleteConfigCall.CallCount++
+	c.DeleteConfigCall.Receives.Type = configType
+	c.DeleteConfigCall.Receives.Name = name
+	return c.DeleteConfigCall.Returns.Error
+}
+
+func (c *BOSHClient) Stemcells() ([]bosh.Stemcell, error) {
+	c.StemcellsCall.CallCount++
+	return c.StemcellsCall.Returns.Stemcells, c.StemcellsCall.Returns.Error
+}
+
+func (c *BOSHClient) UploadStemcell(url, sha1 string) error {
+	c.UploadStemcellCall.CallCount++
+	c.UploadStemcellCall.Receives.URL = url
+	c.UploadStemcellCall.Receives.SHA1 = sha1
+	return c.UploadStemcellCall.Returns.Error
+}
+
+func (c *BOSHClient) Deployments() ([]bosh.Deployment, error) {
+	c.DeploymentsCall.CallCount++
+	return c.DeploymentsCall.Returns.Deployments, c.DeploymentsCall.Returns.Error
+}
+
+func (c *BOSHClient) DeleteDeployment(name string) error {
+	c.DeleteDeploymentCall.CallCount++
+	c.DeleteDeploymentCall.Receives.Names = append(c.DeleteDeploymentCall.Receives.Names, name)
+	return c.DeleteDeploymentCall.Returns.Error
+}
+
+func (c *BOSHClient) OrphanedDisks() ([]bosh.OrphanedDisk, error) {
+	c.OrphanedDisksCall.CallCount++
+	return c.OrphanedDisksCall.Returns.OrphanedDisks, c.OrphanedDisksCall.Returns.Error
+}
+
+func (c *BOSHClient) DeleteOrphanedDisk(diskCID string) error {
+	c.DeleteOrphanedDiskCall.CallCount++
+	c.DeleteOrphanedDiskCall.Receives.DiskCIDs = append(c.DeleteOrphanedDiskCall.Receives.DiskCIDs, diskCID)
+	return c.DeleteOrphanedDiskCall.Returns.Error
 }
 
 func (c *BOSHClient) ConfigureHTTPClient(socks5Client proxy.Dialer) {
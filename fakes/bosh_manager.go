@@ -24,6 +24,7 @@ type BOSHManager struct {
 			State storage.State
 			Error error
 		}
+		Stub func(storage.State, map[string]interface{}) (storage.State, error)
 	}
 	VersionCall struct {
 		CallCount int
@@ -88,6 +89,11 @@ func (b *BOSHManager) CreateDirector(state storage.State, terraformOutputs map[s
 	b.CreateDirectorCall.CallCount++
 	b.CreateDirectorCall.Receives.State = state
 	b.GetDeploymentVarsCall.Receives.TerraformOutputs = terraformOutputs
+
+	if b.CreateDirectorCall.Stub != nil {
+		return b.CreateDirectorCall.Stub(state, terraformOutputs)
+	}
+
 	state.BOSH = b.CreateDirectorCall.Returns.State.BOSH
 	return state, b.CreateDirectorCall.Returns.Error
 }
@@ -0,0 +1,37 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/storage"
+
+type CascadeDeleter struct {
+	DeploymentsCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			Deployments []string
+			Error       error
+		}
+	}
+	DeleteCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func (c *CascadeDeleter) Deployments(state storage.State) ([]string, error) {
+	c.DeploymentsCall.CallCount++
+	c.DeploymentsCall.Receives.State = state
+	return c.DeploymentsCall.Returns.Deployments, c.DeploymentsCall.Returns.Error
+}
+
+func (c *CascadeDeleter) Delete(state storage.State) error {
+	c.DeleteCall.CallCount++
+	c.DeleteCall.Receives.State = state
+	return c.DeleteCall.Returns.Error
+}
@@ -24,6 +24,36 @@ type CloudConfigManager struct {
 			Error       error
 		}
 	}
+	DiffCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			Diff  string
+			Error error
+		}
+	}
+	GenerateRuntimeConfigCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			RuntimeConfig string
+			Error         error
+		}
+	}
+	GenerateCPIConfigCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			CPIConfig string
+			Error     error
+		}
+	}
 }
 
 func (c *CloudConfigManager) Update(state storage.State) error {
@@ -37,3 +67,21 @@ func (c *CloudConfigManager) Generate(state storage.State) (string, error) {
 	c.GenerateCall.Receives.State = state
 	return c.GenerateCall.Returns.CloudConfig, c.GenerateCall.Returns.Error
 }
+
+func (c *CloudConfigManager) Diff(state storage.State) (string, error) {
+	c.DiffCall.CallCount++
+	c.DiffCall.Receives.State = state
+	return c.DiffCall.Returns.Diff, c.DiffCall.Returns.Error
+}
+
+func (c *CloudConfigManager) GenerateRuntimeConfig(state storage.State) (string, error) {
+	c.GenerateRuntimeConfigCall.CallCount++
+	c.GenerateRuntimeConfigCall.Receives.State = state
+	return c.GenerateRuntimeConfigCall.Returns.RuntimeConfig, c.GenerateRuntimeConfigCall.Returns.Error
+}
+
+func (c *CloudConfigManager) GenerateCPIConfig(state storage.State) (string, error) {
+	c.GenerateCPIConfigCall.CallCount++
+	c.GenerateCPIConfigCall.Receives.State = state
+	return c.GenerateCPIConfigCall.Returns.CPIConfig, c.GenerateCPIConfigCall.Returns.Error
+}
@@ -0,0 +1,23 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/storage"
+
+type ConfigFileLoader struct {
+	LoadCall struct {
+		CallCount int
+		Receives  struct {
+			Path string
+		}
+		Returns struct {
+			ConfigFile storage.ConfigFile
+			Error      error
+		}
+	}
+}
+
+func (c *ConfigFileLoader) Load(path string) (storage.ConfigFile, error) {
+	c.LoadCall.CallCount++
+	c.LoadCall.Receives.Path = path
+
+	return c.LoadCall.Returns.ConfigFile, c.LoadCall.Returns.Error
+}
@@ -0,0 +1,17 @@
+package fakes
+
+type CredentialsEnv struct {
+	CredentialsEnvCall struct {
+		CallCount int
+		Returns   struct {
+			Env   []string
+			Error error
+		}
+	}
+}
+
+func (c *CredentialsEnv) CredentialsEnv() ([]string, error) {
+	c.CredentialsEnvCall.CallCount++
+
+	return c.CredentialsEnvCall.Returns.Env, c.CredentialsEnvCall.Returns.Error
+}
@@ -73,6 +73,132 @@ type EC2Client struct {
 			Error  error
 		}
 	}
+
+	DescribeImagesCall struct {
+		Receives struct {
+			Input *awsec2.DescribeImagesInput
+		}
+		Returns struct {
+			Output *awsec2.DescribeImagesOutput
+			Error  error
+		}
+	}
+
+	DescribeVolumesCall struct {
+		Receives struct {
+			Input *awsec2.DescribeVolumesInput
+		}
+		Returns struct {
+			Output *awsec2.DescribeVolumesOutput
+			Error  error
+		}
+	}
+
+	StopInstancesCall struct {
+		Receives struct {
+			Input *awsec2.StopInstancesInput
+		}
+		Returns struct {
+			Output *awsec2.StopInstancesOutput
+			Error  error
+		}
+	}
+
+	WaitUntilInstanceStoppedCall struct {
+		Receives struct {
+			Input *awsec2.DescribeInstancesInput
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	TerminateInstancesCall struct {
+		Receives struct {
+			Input *awsec2.TerminateInstancesInput
+		}
+		Returns struct {
+			Output *awsec2.TerminateInstancesOutput
+			Error  error
+		}
+	}
+
+	WaitUntilInstanceTerminatedCall struct {
+		Receives struct {
+			Input *awsec2.DescribeInstancesInput
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	CreateSnapshotCall struct {
+		Receives struct {
+			Input *awsec2.CreateSnapshotInput
+		}
+		Returns struct {
+			Output *awsec2.Snapshot
+			Error  error
+		}
+	}
+
+	WaitUntilSnapshotCompletedCall struct {
+		Receives struct {
+			Input *awsec2.DescribeSnapshotsInput
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	CreateVolumeCall struct {
+		Receives struct {
+			Input *awsec2.CreateVolumeInput
+		}
+		Returns struct {
+			Output *awsec2.Volume
+			Error  error
+		}
+	}
+
+	WaitUntilVolumeAvailableCall struct {
+		Receives struct {
+			Input *awsec2.DescribeVolumesInput
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	DeleteVolumeCall struct {
+		CallCount int
+		Receives  struct {
+			Inputs []*awsec2.DeleteVolumeInput
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	DescribeAddressesCall struct {
+		Receives struct {
+			Input *awsec2.DescribeAddressesInput
+		}
+		Returns struct {
+			Output *awsec2.DescribeAddressesOutput
+			Error  error
+		}
+	}
+
+	ReleaseAddressCall struct {
+		CallCount int
+		Receives  struct {
+			Inputs []*awsec2.ReleaseAddressInput
+		}
+		Returns struct {
+			Error error
+		}
+	}
 }
 
 func (c *EC2Client) ImportKeyPair(input *awsec2.ImportKeyPairInput) (*awsec2.ImportKeyPairOutput, error) {
@@ -116,3 +242,83 @@ func (c *EC2Client) DescribeVpcs(input *awsec2.DescribeVpcsInput) (*awsec2.Descr
 
 	return c.DescribeVpcsCall.Returns.Output, c.DescribeVpcsCall.Returns.Error
 }
+
+func (c *EC2Client) DescribeImages(input *awsec2.DescribeImagesInput) (*awsec2.DescribeImagesOutput, error) {
+	c.DescribeImagesCall.Receives.Input = input
+
+	return c.DescribeImagesCall.Returns.Output, c.DescribeImagesCall.Returns.Error
+}
+
+func (c *EC2Client) DescribeVolumes(input *awsec2.DescribeVolumesInput) (*awsec2.DescribeVolumesOutput, error) {
+	c.DescribeVolumesCall.Receives.Input = input
+
+	return c.DescribeVolumesCall.Returns.Output, c.DescribeVolumesCall.Returns.Error
+}
+
+func (c *EC2Client) StopInstances(input *awsec2.StopInstancesInput) (*awsec2.StopInstancesOutput, error) {
+	c.StopInstancesCall.Receives.Input = input
+
+	return c.StopInstancesCall.Returns.Output, c.StopInstancesCall.Returns.Error
+}
+
+func (c *EC2Client) WaitUntilInstanceStopped(input *awsec2.DescribeInstancesInput) error {
+	c.WaitUntilInstanceStoppedCall.Receives.Input = input
+
+	return c.WaitUntilInstanceStoppedCall.Returns.Error
+}
+
+func (c *EC2Client) TerminateInstances(input *awsec2.TerminateInstancesInput) (*awsec2.TerminateInstancesOutput, error) {
+	c.TerminateInstancesCall.Receives.Input = input
+
+	return c.TerminateInstancesCall.Returns.Output, c.TerminateInstancesCall.Returns.Error
+}
+
+func (c *EC2Client) WaitUntilInstanceTerminated(input *awsec2.DescribeInstancesInput) error {
+	c.WaitUntilInstanceTerminatedCall.Receives.Input = input
+
+	return c.WaitUntilInstanceTerminatedCall.Returns.Error
+}
+
+func (c *EC2Client) CreateSnapshot(input *awsec2.CreateSnapshotInput) (*awsec2.Snapshot, error) {
+	c.CreateSnapshotCall.Receives.Input = input
+
+	return c.CreateSnapshotCall.Returns.Output, c.CreateSnapshotCall.Returns.Error
+}
+
+func (c *EC2Client) WaitUntilSnapshotCompleted(input *awsec2.DescribeSnapshotsInput) error {
+	c.WaitUntilSnapshotCompletedCall.Receives.Input = input
+
+	return c.WaitUntilSnapshotCompletedCall.Returns.Error
+}
+
+func (c *EC2Client) CreateVolume(input *awsec2.CreateVolumeInput) (*awsec2.Volume, error) {
+	c.CreateVolumeCall.Receives.Input = input
+
+	return c.CreateVolumeCall.Returns.Output, c.CreateVolumeCall.Returns.Error
+}
+
+func (c *EC2Client) WaitUntilVolumeAvailable(input *awsec2.DescribeVolumesInput) error {
+	c.WaitUntilVolumeAvailableCall.Receives.Input = input
+
+	return c.WaitUntilVolumeAvailableCall.Returns.Error
+}
+
+func (c *EC2Client) DeleteVolume(input *awsec2.DeleteVolumeInput) (*awsec2.DeleteVolumeOutput, error) {
+	c.DeleteVolumeCall.CallCount++
+	c.DeleteVolumeCall.Receives.Inputs = append(c.DeleteVolumeCall.Receives.Inputs, input)
+
+	return &awsec2.DeleteVolumeOutput{}, c.DeleteVolumeCall.Returns.Error
+}
+
+func (c *EC2Client) DescribeAddresses(input *awsec2.DescribeAddressesInput) (*awsec2.DescribeAddressesOutput, error) {
+	c.DescribeAddressesCall.Receives.Input = input
+
+	return c.DescribeAddressesCall.Returns.Output, c.DescribeAddressesCall.Returns.Error
+}
+
+func (c *EC2Client) ReleaseAddress(input *awsec2.ReleaseAddressInput) (*awsec2.ReleaseAddressOutput, error) {
+	c.ReleaseAddressCall.CallCount++
+	c.ReleaseAddressCall.Receives.Inputs = append(c.ReleaseAddressCall.Receives.Inputs, input)
+
+	return &awsec2.ReleaseAddressOutput{}, c.ReleaseAddressCall.Returns.Error
+}
@@ -0,0 +1,26 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/storage"
+
+type EnvIDConfirmer struct {
+	ConfirmCall struct {
+		CallCount int
+		Receives  struct {
+			State        storage.State
+			ConfirmEnvID string
+			Action       string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func (e *EnvIDConfirmer) Confirm(state storage.State, confirmEnvID, action string) error {
+	e.ConfirmCall.CallCount++
+	e.ConfirmCall.Receives.State = state
+	e.ConfirmCall.Receives.ConfirmEnvID = confirmEnvID
+	e.ConfirmCall.Receives.Action = action
+
+	return e.ConfirmCall.Returns.Error
+}
@@ -73,6 +73,48 @@ type GCPClient struct {
 			Error       error
 		}
 	}
+	ListLabeledInstancesCall struct {
+		CallCount int
+		Receives  struct {
+			Label string
+			Value string
+		}
+		Returns struct {
+			Instances []*compute.Instance
+			Error     error
+		}
+	}
+	ListLabeledDisksCall struct {
+		CallCount int
+		Receives  struct {
+			Label string
+			Value string
+		}
+		Returns struct {
+			Disks []*compute.Disk
+			Error error
+		}
+	}
+	DeleteInstanceCall struct {
+		CallCount int
+		Receives  struct {
+			Zones []string
+			Names []string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+	DeleteDiskCall struct {
+		CallCount int
+		Receives  struct {
+			Zones []string
+			Names []string
+		}
+		Returns struct {
+			Error error
+		}
+	}
 }
 
 func (g *GCPClient) ProjectID() string {
@@ -119,3 +161,31 @@ func (g *GCPClient) GetNetworks(name string) (*compute.NetworkList, error) {
 	g.GetNetworksCall.Receives.Name = name
 	return g.GetNetworksCall.Returns.NetworkList, g.GetNetworksCall.Returns.Error
 }
+
+func (g *GCPClient) ListLabeledInstances(label, value string) ([]*compute.Instance, error) {
+	g.ListLabeledInstancesCall.CallCount++
+	g.ListLabeledInstancesCall.Receives.Label = label
+	g.ListLabeledInstancesCall.Receives.Value = value
+	return g.ListLabeledInstancesCall.Returns.Instances, g.ListLabeledInstancesCall.Returns.Error
+}
+
+func (g *GCPClient) ListLabeledDisks(label, value string) ([]*compute.Disk, error) {
+	g.ListLabeledDisksCall.CallCount++
+	g.ListLabeledDisksCall.Receives.Label = label
+	g.ListLabeledDisksCall.Receives.Value = value
+	return g.ListLabeledDisksCall.Returns.Disks, g.ListLabeledDisksCall.Returns.Error
+}
+
+func (g *GCPClient) DeleteInstance(zone, name string) error {
+	g.DeleteInstanceCall.CallCount++
+	g.DeleteInstanceCall.Receives.Zones = append(g.DeleteInstanceCall.Receives.Zones, zone)
+	g.DeleteInstanceCall.Receives.Names = append(g.DeleteInstanceCall.Receives.Names, name)
+	return g.DeleteInstanceCall.Returns.Error
+}
+
+func (g *GCPClient) DeleteDisk(zone, name string) error {
+	g.DeleteDiskCall.CallCount++
+	g.DeleteDiskCall.Receives.Zones = append(g.DeleteDiskCall.Receives.Zones, zone)
+	g.DeleteDiskCall.Receives.Names = append(g.DeleteDiskCall.Receives.Names, name)
+	return g.DeleteDiskCall.Returns.Error
+}
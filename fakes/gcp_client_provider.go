@@ -12,10 +12,12 @@ type GCPClientProvider struct {
 	SetConfigCall struct {
 		CallCount int
 		Receives  struct {
-			ServiceAccountKey string
-			ProjectID         string
-			Region            string
-			Zone              string
+			ServiceAccountKey             string
+			ProjectID                     string
+			Region                        string
+			Zone                          string
+			ApplicationDefaultCredentials bool
+			ImpersonateServiceAccount     string
 		}
 		Returns struct {
 			Error error
@@ -29,12 +31,14 @@ func (g *GCPClientProvider) Client() gcp.Client {
 	return g.ClientCall.Returns.Client
 }
 
-func (g *GCPClientProvider) SetConfig(serviceAccountKey, projectID, region, zone string) error {
+func (g *GCPClientProvider) SetConfig(config gcp.Config) error {
 	g.SetConfigCall.CallCount++
-	g.SetConfigCall.Receives.ServiceAccountKey = serviceAccountKey
-	g.SetConfigCall.Receives.ProjectID = projectID
-	g.SetConfigCall.Receives.Region = region
-	g.SetConfigCall.Receives.Zone = zone
+	g.SetConfigCall.Receives.ServiceAccountKey = config.ServiceAccountKey
+	g.SetConfigCall.Receives.ProjectID = config.ProjectID
+	g.SetConfigCall.Receives.Region = config.Region
+	g.SetConfigCall.Receives.Zone = config.Zone
+	g.SetConfigCall.Receives.ApplicationDefaultCredentials = config.ApplicationDefaultCredentials
+	g.SetConfigCall.Receives.ImpersonateServiceAccount = config.ImpersonateServiceAccount
 
 	return g.SetConfigCall.Returns.Error
 }
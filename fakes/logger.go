@@ -48,6 +48,9 @@ type Logger struct {
 }
 
 func (l *Logger) Step(message string, a ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
 	l.StepCall.CallCount++
 	l.StepCall.Receives.Message = message
 	l.StepCall.Receives.Arguments = a
@@ -56,10 +59,16 @@ func (l *Logger) Step(message string, a ...interface{}) {
 }
 
 func (l *Logger) Dot() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
 	l.DotCall.CallCount++
 }
 
 func (l *Logger) Printf(message string, a ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
 	l.PrintfCall.CallCount++
 	l.PrintfCall.Receives.Message = message
 	l.PrintfCall.Receives.Arguments = a
@@ -82,6 +91,9 @@ func (l *Logger) Println(message string) {
 }
 
 func (l *Logger) Prompt(message string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
 	l.PromptCall.CallCount++
 	l.PromptCall.Receives.Message = message
 }
@@ -0,0 +1,16 @@
+package fakes
+
+type NATAMIResolver struct {
+	ResolveCall struct {
+		CallCount int
+		Returns   struct {
+			AMI   string
+			Error error
+		}
+	}
+}
+
+func (n *NATAMIResolver) Resolve() (string, error) {
+	n.ResolveCall.CallCount++
+	return n.ResolveCall.Returns.AMI, n.ResolveCall.Returns.Error
+}
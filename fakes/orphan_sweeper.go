@@ -0,0 +1,35 @@
+package fakes
+
+type OrphanSweeper struct {
+	OrphansCall struct {
+		CallCount int
+		Receives  struct {
+			DirectorName string
+		}
+		Returns struct {
+			Orphans []string
+			Error   error
+		}
+	}
+	DeleteOrphansCall struct {
+		CallCount int
+		Receives  struct {
+			DirectorName string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func (o *OrphanSweeper) Orphans(directorName string) ([]string, error) {
+	o.OrphansCall.CallCount++
+	o.OrphansCall.Receives.DirectorName = directorName
+	return o.OrphansCall.Returns.Orphans, o.OrphansCall.Returns.Error
+}
+
+func (o *OrphanSweeper) DeleteOrphans(directorName string) error {
+	o.DeleteOrphansCall.CallCount++
+	o.DeleteOrphansCall.Receives.DirectorName = directorName
+	return o.DeleteOrphansCall.Returns.Error
+}
@@ -0,0 +1,26 @@
+package fakes
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+type ProxiedBOSHClientProvider struct {
+	ClientCall struct {
+		CallCount int
+
+		Receives struct {
+			State storage.State
+		}
+		Returns struct {
+			Client bosh.Client
+			Error  error
+		}
+	}
+}
+
+func (p *ProxiedBOSHClientProvider) Client(state storage.State) (bosh.Client, error) {
+	p.ClientCall.CallCount++
+	p.ClientCall.Receives.State = state
+	return p.ClientCall.Returns.Client, p.ClientCall.Returns.Error
+}
@@ -11,10 +11,7 @@ type StateStore struct {
 
 	GetCall struct {
 		CallCount int
-		Receives  struct {
-			Dir string
-		}
-		Returns struct {
+		Returns   struct {
 			State storage.State
 			Error error
 		}
@@ -40,3 +37,9 @@ func (s *StateStore) Set(state storage.State) error {
 
 	return s.SetCall.Returns[s.SetCall.CallCount-1].Error
 }
+
+func (s *StateStore) Get() (storage.State, error) {
+	s.GetCall.CallCount++
+
+	return s.GetCall.Returns.State, s.GetCall.Returns.Error
+}
@@ -0,0 +1,24 @@
+package fakes
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/status"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+type StatusChecker struct {
+	CheckCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			Results []status.Result
+		}
+	}
+}
+
+func (s *StatusChecker) Check(state storage.State) []status.Result {
+	s.CheckCall.CallCount++
+	s.CheckCall.Receives.State = state
+	return s.CheckCall.Returns.Results
+}
@@ -0,0 +1,21 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/storage"
+
+type StemcellUploader struct {
+	UploadCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func (s *StemcellUploader) Upload(state storage.State) error {
+	s.UploadCall.CallCount++
+	s.UploadCall.Receives.State = state
+	return s.UploadCall.Returns.Error
+}
@@ -0,0 +1,18 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/storage"
+
+type StepRecorder struct {
+	StepDurationsCall struct {
+		CallCount int
+		Returns   struct {
+			Steps []storage.AuditStep
+		}
+	}
+}
+
+func (s *StepRecorder) StepDurations() []storage.AuditStep {
+	s.StepDurationsCall.CallCount++
+
+	return s.StepDurationsCall.Returns.Steps
+}
@@ -52,6 +52,12 @@ func main() {
 		}
 
 		fmt.Printf("working directory: %s\n", dir)
+		if accessKeyID := os.Getenv("AWS_ACCESS_KEY_ID"); accessKeyID != "" {
+			fmt.Printf("aws access key id: %s\n", accessKeyID)
+		}
+		if accessToken := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); accessToken != "" {
+			fmt.Printf("google access token: %s\n", accessToken)
+		}
 		fmt.Printf("terraform %s/n", removeBrackets(fmt.Sprintf("%+v", os.Args)))
 	}
 }
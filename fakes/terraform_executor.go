@@ -7,6 +7,11 @@ type Import struct {
 	ID   string
 }
 
+type Move struct {
+	From string
+	To   string
+}
+
 type TerraformExecutor struct {
 	ApplyCall struct {
 		CallCount int
@@ -32,6 +37,18 @@ type TerraformExecutor struct {
 			Error   error
 		}
 	}
+	PlanCall struct {
+		CallCount int
+		Receives  struct {
+			Inputs   map[string]string
+			Template string
+			TFState  string
+		}
+		Returns struct {
+			Changed bool
+			Error   error
+		}
+	}
 	ImportCall struct {
 		CallCount int
 		Receives  struct {
@@ -44,6 +61,17 @@ type TerraformExecutor struct {
 			Error   error
 		}
 	}
+	MoveStateCall struct {
+		CallCount int
+		Receives  struct {
+			TFState string
+			Moves   []Move
+		}
+		Returns struct {
+			TFState string
+			Error   error
+		}
+	}
 	VersionCall struct {
 		CallCount int
 		Returns   struct {
@@ -92,6 +120,14 @@ func (t *TerraformExecutor) Destroy(inputs map[string]string, template, tfState
 	return t.DestroyCall.Returns.TFState, t.DestroyCall.Returns.Error
 }
 
+func (t *TerraformExecutor) Plan(inputs map[string]string, template, tfState string) (bool, error) {
+	t.PlanCall.CallCount++
+	t.PlanCall.Receives.Inputs = inputs
+	t.PlanCall.Receives.Template = template
+	t.PlanCall.Receives.TFState = tfState
+	return t.PlanCall.Returns.Changed, t.PlanCall.Returns.Error
+}
+
 func (t *TerraformExecutor) Import(addr, id, tfstate string, creds storage.AWS) (string, error) {
 	t.ImportCall.CallCount++
 	t.ImportCall.Receives.Imports = append(t.ImportCall.Receives.Imports, Import{
@@ -104,6 +140,17 @@ func (t *TerraformExecutor) Import(addr, id, tfstate string, creds storage.AWS)
 	return t.ImportCall.Returns.TFState, t.ImportCall.Returns.Error
 }
 
+func (t *TerraformExecutor) MoveState(tfState, from, to string) (string, error) {
+	t.MoveStateCall.CallCount++
+	t.MoveStateCall.Receives.TFState = tfState
+	t.MoveStateCall.Receives.Moves = append(t.MoveStateCall.Receives.Moves, Move{
+		From: from,
+		To:   to,
+	})
+
+	return t.MoveStateCall.Returns.TFState, t.MoveStateCall.Returns.Error
+}
+
 func (t *TerraformExecutor) Version() (string, error) {
 	t.VersionCall.CallCount++
 	return t.VersionCall.Returns.Version, t.VersionCall.Returns.Error
@@ -25,6 +25,16 @@ type TerraformManager struct {
 			Error    error
 		}
 	}
+	PlanCall struct {
+		CallCount int
+		Receives  struct {
+			BBLState storage.State
+		}
+		Returns struct {
+			Changed bool
+			Error   error
+		}
+	}
 	ImportCall struct {
 		CallCount int
 		Receives  struct {
@@ -75,6 +85,13 @@ func (t *TerraformManager) Destroy(bblState storage.State) (storage.State, error
 	return t.DestroyCall.Returns.BBLState, t.DestroyCall.Returns.Error
 }
 
+func (t *TerraformManager) Plan(bblState storage.State) (bool, error) {
+	t.PlanCall.CallCount++
+	t.PlanCall.Receives.BBLState = bblState
+
+	return t.PlanCall.Returns.Changed, t.PlanCall.Returns.Error
+}
+
 func (t *TerraformManager) Import(bblState storage.State, outputs map[string]string) (storage.State, error) {
 	t.ImportCall.CallCount++
 	t.ImportCall.Receives.BBLState = bblState
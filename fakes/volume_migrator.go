@@ -0,0 +1,24 @@
+package fakes
+
+type VolumeMigrator struct {
+	MigrateCall struct {
+		CallCount int
+		Receives  struct {
+			InstanceID       string
+			VolumeID         string
+			AvailabilityZone string
+		}
+		Returns struct {
+			VolumeID string
+			Error    error
+		}
+	}
+}
+
+func (v *VolumeMigrator) Migrate(instanceID, volumeID, availabilityZone string) (string, error) {
+	v.MigrateCall.CallCount++
+	v.MigrateCall.Receives.InstanceID = instanceID
+	v.MigrateCall.Receives.VolumeID = volumeID
+	v.MigrateCall.Receives.AvailabilityZone = availabilityZone
+	return v.MigrateCall.Returns.VolumeID, v.MigrateCall.Returns.Error
+}
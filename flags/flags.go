@@ -3,10 +3,22 @@ package flags
 import (
 	"flag"
 	"io/ioutil"
+	"sort"
+	"strings"
 )
 
 type Flags struct {
-	set *flag.FlagSet
+	set    *flag.FlagSet
+	shorts map[string]string
+	values map[string][]string
+}
+
+// Definition describes a flag, so that shells can complete it.
+type Definition struct {
+	Name   string
+	Short  string
+	Bool   bool
+	Values []string
 }
 
 func New(name string) Flags {
@@ -15,14 +27,21 @@ func New(name string) Flags {
 	set.SetOutput(ioutil.Discard)
 
 	return Flags{
-		set: set,
+		set:    set,
+		shorts: map[string]string{},
+		values: map[string][]string{},
 	}
 }
 
 func (f Flags) Bool(v *bool, short, long string, value bool) {
+	if long == "" {
+		long, short = short, ""
+	}
+
 	f.set.BoolVar(v, long, value, "")
 	if short != "" {
 		f.set.BoolVar(v, short, value, "")
+		f.shorts[long] = short
 	}
 }
 
@@ -30,6 +49,16 @@ func (f Flags) String(v *string, name string, value string) {
 	f.set.StringVar(v, name, value, "")
 }
 
+func (f Flags) StringSlice(v *[]string, name string) {
+	f.set.Var((*stringSlice)(v), name, "")
+}
+
+// Values records the values a flag accepts, for shell completion. It does
+// not validate them when parsing.
+func (f Flags) Values(name string, values ...string) {
+	f.values[name] = values
+}
+
 func (f Flags) Parse(args []string) error {
 	return f.set.Parse(args)
 }
@@ -37,3 +66,47 @@ func (f Flags) Parse(args []string) error {
 func (f Flags) Args() []string {
 	return f.set.Args()
 }
+
+// Definitions returns the flags sorted by name. Short names are returned
+// along with the long name they stand for.
+func (f Flags) Definitions() []Definition {
+	short := map[string]bool{}
+	for _, name := range f.shorts {
+		short[name] = true
+	}
+
+	definitions := []Definition{}
+	f.set.VisitAll(func(fl *flag.Flag) {
+		if short[fl.Name] {
+			return
+		}
+
+		boolFlag, ok := fl.Value.(interface {
+			IsBoolFlag() bool
+		})
+
+		definitions = append(definitions, Definition{
+			Name:   fl.Name,
+			Short:  f.shorts[fl.Name],
+			Bool:   ok && boolFlag.IsBoolFlag(),
+			Values: f.values[fl.Name],
+		})
+	})
+
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+
+	return definitions
+}
+
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
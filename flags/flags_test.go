@@ -9,15 +9,18 @@ import (
 
 var _ = Describe("Flags", func() {
 	var (
-		f         flags.Flags
-		boolVal   bool
-		stringVal string
+		f              flags.Flags
+		boolVal        bool
+		stringVal      string
+		stringSliceVal []string
 	)
 
 	BeforeEach(func() {
 		f = flags.New("test")
 		f.Bool(&boolVal, "b", "bool", false)
 		f.String(&stringVal, "string", "")
+		stringSliceVal = nil
+		f.StringSlice(&stringSliceVal, "slice")
 	})
 
 	Describe("Parse", func() {
@@ -49,6 +52,39 @@ var _ = Describe("Flags", func() {
 				Expect(stringVal).To(Equal("string_value"))
 			})
 		})
+
+		Context("StringSlice flags", func() {
+			It("collects every occurrence of the flag", func() {
+				err := f.Parse([]string{"--slice", "first", "--slice", "second"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stringSliceVal).To(Equal([]string{"first", "second"}))
+			})
+		})
+	})
+
+	Describe("Definitions", func() {
+		It("describes every flag, sorted by name", func() {
+			f.Values("string", "first", "second")
+
+			Expect(f.Definitions()).To(Equal([]flags.Definition{
+				{Name: "bool", Short: "b", Bool: true},
+				{Name: "slice"},
+				{Name: "string", Values: []string{"first", "second"}},
+			}))
+		})
+
+		It("treats a bool flag given only a short name as a long one", func() {
+			f = flags.New("test")
+			f.Bool(&boolVal, "skip-if-missing", "", false)
+
+			Expect(f.Definitions()).To(Equal([]flags.Definition{
+				{Name: "skip-if-missing", Bool: true},
+			}))
+
+			err := f.Parse([]string{"--skip-if-missing"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(boolVal).To(BeTrue())
+		})
 	})
 
 	Describe("Args", func() {
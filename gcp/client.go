@@ -1,11 +1,16 @@
 package gcp
 
 import (
+	"context"
 	"fmt"
+	"path"
+	"time"
 
 	compute "google.golang.org/api/compute/v1"
 )
 
+var operationPollInterval = 2 * time.Second
+
 type Client interface {
 	ProjectID() string
 	GetProject() (*compute.Project, error)
@@ -15,6 +20,10 @@ type Client interface {
 	GetZone(zone string) (*compute.Zone, error)
 	GetRegion(region string) (*compute.Region, error)
 	GetNetworks(name string) (*compute.NetworkList, error)
+	ListLabeledInstances(label, value string) ([]*compute.Instance, error)
+	ListLabeledDisks(label, value string) ([]*compute.Disk, error)
+	DeleteInstance(zone, name string) error
+	DeleteDisk(zone, name string) error
 }
 
 type GCPClient struct {
@@ -74,3 +83,77 @@ func (c GCPClient) GetNetworks(name string) (*compute.NetworkList, error) {
 	networksListCall := c.service.Networks.List(c.projectID)
 	return networksListCall.Filter(fmt.Sprintf("name eq %s", name)).Do()
 }
+
+// ListLabeledInstances returns the instances in every zone of the project
+// that carry the label with the given value.
+func (c GCPClient) ListLabeledInstances(label, value string) ([]*compute.Instance, error) {
+	instances := []*compute.Instance{}
+	call := c.service.Instances.AggregatedList(c.projectID).Filter(fmt.Sprintf("labels.%s eq %s", label, value))
+	err := call.Pages(context.Background(), func(page *compute.InstanceAggregatedList) error {
+		for _, scopedList := range page.Items {
+			instances = append(instances, scopedList.Instances...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+// ListLabeledDisks returns the disks in every zone of the project that carry
+// the label with the given value.
+func (c GCPClient) ListLabeledDisks(label, value string) ([]*compute.Disk, error) {
+	disks := []*compute.Disk{}
+	call := c.service.Disks.AggregatedList(c.projectID).Filter(fmt.Sprintf("labels.%s eq %s", label, value))
+	err := call.Pages(context.Background(), func(page *compute.DiskAggregatedList) error {
+		for _, scopedList := range page.Items {
+			disks = append(disks, scopedList.Disks...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return disks, nil
+}
+
+// DeleteInstance deletes the instance and waits for the deletion to finish.
+func (c GCPClient) DeleteInstance(zone, name string) error {
+	operation, err := c.service.Instances.Delete(c.projectID, path.Base(zone), name).Do()
+	if err != nil {
+		return err
+	}
+
+	return c.waitForZoneOperation(path.Base(zone), operation)
+}
+
+// DeleteDisk deletes the disk and waits for the deletion to finish.
+func (c GCPClient) DeleteDisk(zone, name string) error {
+	operation, err := c.service.Disks.Delete(c.projectID, path.Base(zone), name).Do()
+	if err != nil {
+		return err
+	}
+
+	return c.waitForZoneOperation(path.Base(zone), operation)
+}
+
+func (c GCPClient) waitForZoneOperation(zone string, operation *compute.Operation) error {
+	var err error
+	for operation.Status != "DONE" {
+		time.Sleep(operationPollInterval)
+
+		operation, err = c.service.ZoneOperations.Get(c.projectID, zone, operation.Name).Do()
+		if err != nil {
+			return err
+		}
+	}
+
+	if operation.Error != nil && len(operation.Error.Errors) > 0 {
+		return fmt.Errorf("operation %s failed: %s", operation.Name, operation.Error.Errors[0].Message)
+	}
+
+	return nil
+}
@@ -2,27 +2,33 @@ package gcp
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
-	"golang.org/x/oauth2/jwt"
 
 	compute "google.golang.org/api/compute/v1"
 )
 
 const (
-	GoogleComputeAuth = "https://www.googleapis.com/auth/compute"
+	GoogleComputeAuth  = "https://www.googleapis.com/auth/compute"
+	CloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
 )
 
-func gcpHTTPClientFunc(config *jwt.Config) *http.Client {
-	return config.Client(context.Background())
+func gcpHTTPClientFunc(tokenSource oauth2.TokenSource) *http.Client {
+	return oauth2.NewClient(context.Background(), tokenSource)
 }
 
 var gcpHTTPClient = gcpHTTPClientFunc
 
+var defaultTokenSource = google.DefaultTokenSource
+
 type ClientProvider struct {
-	basePath string
-	client   Client
+	basePath    string
+	client      Client
+	config      Config
+	tokenSource oauth2.TokenSource
 }
 
 func NewClientProvider(gcpBasePath string) *ClientProvider {
@@ -31,17 +37,16 @@ func NewClientProvider(gcpBasePath string) *ClientProvider {
 	}
 }
 
-func (p *ClientProvider) SetConfig(serviceAccountKey, projectID, region, zone string) error {
-	config, err := google.JWTConfigFromJSON([]byte(serviceAccountKey), compute.ComputeScope)
+func (p *ClientProvider) SetConfig(config Config) error {
+	tokenSource, err := p.newTokenSource(config)
 	if err != nil {
 		return err
 	}
 
-	if p.basePath != "" {
-		config.TokenURL = p.basePath
-	}
+	p.config = config
+	p.tokenSource = tokenSource
 
-	service, err := compute.New(gcpHTTPClient(config))
+	service, err := compute.New(gcpHTTPClient(tokenSource))
 	if err != nil {
 		return err
 	}
@@ -52,16 +57,16 @@ func (p *ClientProvider) SetConfig(serviceAccountKey, projectID, region, zone st
 
 	p.client = GCPClient{
 		service:   service,
-		projectID: projectID,
-		zone:      zone,
+		projectID: config.ProjectID,
+		zone:      config.Zone,
 	}
 
-	_, err = p.client.GetRegion(region)
+	_, err = p.client.GetRegion(config.Region)
 	if err != nil {
 		return err
 	}
 
-	_, err = p.client.GetZone(zone)
+	_, err = p.client.GetZone(config.Zone)
 	if err != nil {
 		return err
 	}
@@ -69,6 +74,58 @@ func (p *ClientProvider) SetConfig(serviceAccountKey, projectID, region, zone st
 	return nil
 }
 
+// newTokenSource authenticates with the application default credentials when
+// they are asked for, or when a service account is impersonated without a
+// key, and otherwise with the service account key. Tokens that are not for
+// the key itself have the cloud-platform scope, so that they can be handed to
+// terraform as well.
+func (p *ClientProvider) newTokenSource(config Config) (oauth2.TokenSource, error) {
+	var tokenSource oauth2.TokenSource
+	if config.ApplicationDefaultCredentials || (config.ServiceAccountKey == "" && config.ImpersonateServiceAccount != "") {
+		var err error
+		tokenSource, err = defaultTokenSource(context.Background(), CloudPlatformScope)
+		if err != nil {
+			return nil, fmt.Errorf("finding application default credentials: %s", err)
+		}
+	} else {
+		jwtConfig, err := google.JWTConfigFromJSON([]byte(config.ServiceAccountKey), compute.ComputeScope)
+		if err != nil {
+			return nil, err
+		}
+
+		if p.basePath != "" {
+			jwtConfig.TokenURL = p.basePath
+		}
+
+		tokenSource = jwtConfig.TokenSource(context.Background())
+	}
+
+	if config.ImpersonateServiceAccount != "" {
+		tokenSource = impersonatedTokenSource{
+			source:         tokenSource,
+			serviceAccount: config.ImpersonateServiceAccount,
+			basePath:       iamCredentialsBasePath,
+		}
+	}
+
+	return oauth2.ReuseTokenSource(nil, tokenSource), nil
+}
+
 func (p *ClientProvider) Client() Client {
 	return p.client
 }
+
+// CredentialsEnv is the environment terraform authenticates to GCP with. It
+// is empty for a service account key, which terraform is given directly.
+func (p *ClientProvider) CredentialsEnv() ([]string, error) {
+	if p.tokenSource == nil || p.config.UsesServiceAccountKey() {
+		return nil, nil
+	}
+
+	token, err := p.tokenSource.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{fmt.Sprintf("GOOGLE_OAUTH_ACCESS_TOKEN=%s", token.AccessToken)}, nil
+}
@@ -2,6 +2,7 @@ package gcp_test
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -10,7 +11,8 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/gcp"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-	"golang.org/x/oauth2/jwt"
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
 )
 
 var _ = Describe("ClientProvider", func() {
@@ -20,7 +22,7 @@ var _ = Describe("ClientProvider", func() {
 	)
 
 	BeforeEach(func() {
-		gcp.SetGCPHTTPClient(func(*jwt.Config) *http.Client {
+		gcp.SetGCPHTTPClient(func(oauth2.TokenSource) *http.Client {
 			return &http.Client{
 				Transport: &http.Transport{
 					TLSClientConfig: &tls.Config{
@@ -54,15 +56,15 @@ var _ = Describe("ClientProvider", func() {
 		})
 
 		It("returns an error when the service account key is not valid json", func() {
-			err := clientProvider.SetConfig("1231:123", "proj-id", "region", "zone")
+			err := clientProvider.SetConfig(gcp.Config{ServiceAccountKey: "1231:123", ProjectID: "proj-id", Region: "region", Zone: "zone"})
 			Expect(err).To(MatchError("invalid character ':' after top-level value"))
 		})
 
 		It("returns an error when a service could not be created", func() {
-			gcp.SetGCPHTTPClient(func(*jwt.Config) *http.Client {
+			gcp.SetGCPHTTPClient(func(oauth2.TokenSource) *http.Client {
 				return nil
 			})
-			err := clientProvider.SetConfig(`{"type": "service_account"}`, "proj-id", "region", "zone")
+			err := clientProvider.SetConfig(gcp.Config{ServiceAccountKey: `{"type": "service_account"}`, ProjectID: "proj-id", Region: "region", Zone: "zone"})
 			Expect(err).To(MatchError("client is nil"))
 		})
 
@@ -72,7 +74,7 @@ var _ = Describe("ClientProvider", func() {
 				"private_key": %q
 			}`, privateKey)
 
-			err := clientProvider.SetConfig(serviceAccountKey, "proj-id", "region", "bad-zone")
+			err := clientProvider.SetConfig(gcp.Config{ServiceAccountKey: serviceAccountKey, ProjectID: "proj-id", Region: "region", Zone: "bad-zone"})
 			Expect(err).To(MatchError(ContainSubstring("googleapi")))
 			Expect(err).To(MatchError(ContainSubstring("404")))
 		})
@@ -82,9 +84,103 @@ var _ = Describe("ClientProvider", func() {
 				"type": "service_account",
 				"private_key": %q
 			}`, privateKey)
-			err := clientProvider.SetConfig(serviceAccountKey, "proj-id", "bad-region", "zone")
+			err := clientProvider.SetConfig(gcp.Config{ServiceAccountKey: serviceAccountKey, ProjectID: "proj-id", Region: "bad-region", Zone: "zone"})
 			Expect(err).To(MatchError(ContainSubstring("googleapi")))
 			Expect(err).To(MatchError(ContainSubstring("404")))
 		})
 	})
+
+	Describe("CredentialsEnv", func() {
+		var iamServer *httptest.Server
+
+		BeforeEach(func() {
+			gcp.SetDefaultTokenSource(func(context.Context, ...string) (oauth2.TokenSource, error) {
+				return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "some-default-token"}), nil
+			})
+
+			iamServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path != "/v1/projects/-/serviceAccounts/some-account@some-project.iam.gserviceaccount.com:generateAccessToken":
+					w.WriteHeader(http.StatusNotFound)
+				case r.Header.Get("Authorization") != "Bearer some-default-token":
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"error": "permission denied"}`))
+				default:
+					w.Write([]byte(`{"accessToken": "some-impersonated-token", "expireTime": "2099-01-01T00:00:00Z"}`))
+				}
+			}))
+			gcp.SetIAMCredentialsBasePath(iamServer.URL)
+		})
+
+		AfterEach(func() {
+			iamServer.Close()
+			gcp.ResetDefaultTokenSource()
+			gcp.ResetIAMCredentialsBasePath()
+			gcp.ResetGCPHTTPClient()
+		})
+
+		It("is empty for a service account key", func() {
+			serviceAccountKey := fmt.Sprintf(`{
+				"type": "service_account",
+				"private_key": %q
+			}`, privateKey)
+			err := clientProvider.SetConfig(gcp.Config{ServiceAccountKey: serviceAccountKey, ProjectID: "proj-id", Region: "region", Zone: "zone"})
+			Expect(err).NotTo(HaveOccurred())
+
+			env, err := clientProvider.CredentialsEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env).To(BeEmpty())
+		})
+
+		It("provides a token from the application default credentials", func() {
+			err := clientProvider.SetConfig(gcp.Config{ApplicationDefaultCredentials: true, ProjectID: "proj-id", Region: "region", Zone: "zone"})
+			Expect(err).NotTo(HaveOccurred())
+
+			env, err := clientProvider.CredentialsEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env).To(Equal([]string{"GOOGLE_OAUTH_ACCESS_TOKEN=some-default-token"}))
+		})
+
+		It("provides a token for the impersonated service account", func() {
+			err := clientProvider.SetConfig(gcp.Config{
+				ImpersonateServiceAccount: "some-account@some-project.iam.gserviceaccount.com",
+				ProjectID:                 "proj-id",
+				Region:                    "region",
+				Zone:                      "zone",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			env, err := clientProvider.CredentialsEnv()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(env).To(Equal([]string{"GOOGLE_OAUTH_ACCESS_TOKEN=some-impersonated-token"}))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the application default credentials cannot be found", func() {
+				gcp.SetDefaultTokenSource(func(context.Context, ...string) (oauth2.TokenSource, error) {
+					return nil, errors.New("could not find default credentials")
+				})
+
+				err := clientProvider.SetConfig(gcp.Config{ApplicationDefaultCredentials: true, ProjectID: "proj-id", Region: "region", Zone: "zone"})
+				Expect(err).To(MatchError("finding application default credentials: could not find default credentials"))
+			})
+
+			It("returns an error when the service account cannot be impersonated", func() {
+				gcp.SetDefaultTokenSource(func(context.Context, ...string) (oauth2.TokenSource, error) {
+					return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "some-other-token"}), nil
+				})
+
+				err := clientProvider.SetConfig(gcp.Config{
+					ImpersonateServiceAccount: "some-account@some-project.iam.gserviceaccount.com",
+					ProjectID:                 "proj-id",
+					Region:                    "region",
+					Zone:                      "zone",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = clientProvider.CredentialsEnv()
+				Expect(err).To(MatchError(ContainSubstring("impersonating service account some-account@some-project.iam.gserviceaccount.com: 403 Forbidden")))
+			})
+		})
+	})
 })
@@ -0,0 +1,20 @@
+package gcp
+
+// Config is what bbl authenticates to GCP with and the project, region and
+// zone it works in. Without a service account key, bbl uses the application
+// default credentials of the machine it runs on. Either of them may be used
+// to impersonate a service account instead.
+type Config struct {
+	ServiceAccountKey             string
+	ProjectID                     string
+	Region                        string
+	Zone                          string
+	ApplicationDefaultCredentials bool
+	ImpersonateServiceAccount     string
+}
+
+// UsesServiceAccountKey reports whether bbl authenticates with the service
+// account key itself, rather than with short-lived tokens.
+func (c Config) UsesServiceAccountKey() bool {
+	return !c.ApplicationDefaultCredentials && c.ImpersonateServiceAccount == ""
+}
@@ -3,13 +3,31 @@ package gcp
 import (
 	"net/http"
 
-	"golang.org/x/oauth2/jwt"
+	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
 )
 
-func SetGCPHTTPClient(f func(*jwt.Config) *http.Client) {
+func SetGCPHTTPClient(f func(oauth2.TokenSource) *http.Client) {
 	gcpHTTPClient = f
 }
 
 func ResetGCPHTTPClient() {
 	gcpHTTPClient = gcpHTTPClientFunc
 }
+
+func SetDefaultTokenSource(f func(context.Context, ...string) (oauth2.TokenSource, error)) {
+	defaultTokenSource = f
+}
+
+func ResetDefaultTokenSource() {
+	defaultTokenSource = google.DefaultTokenSource
+}
+
+func SetIAMCredentialsBasePath(basePath string) {
+	iamCredentialsBasePath = basePath
+}
+
+func ResetIAMCredentialsBasePath() {
+	iamCredentialsBasePath = "https://iamcredentials.googleapis.com"
+}
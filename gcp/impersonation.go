@@ -0,0 +1,64 @@
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+var iamCredentialsBasePath = "https://iamcredentials.googleapis.com"
+
+// impersonatedTokenSource exchanges the tokens of its source for short-lived
+// tokens of a service account with the IAM credentials API. The source needs
+// the Service Account Token Creator role on that service account.
+type impersonatedTokenSource struct {
+	source         oauth2.TokenSource
+	serviceAccount string
+	basePath       string
+}
+
+func (i impersonatedTokenSource) Token() (*oauth2.Token, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"scope": []string{CloudPlatformScope},
+	})
+	if err != nil {
+		return nil, err //not tested
+	}
+
+	url := fmt.Sprintf("%s/v1/projects/-/serviceAccounts/%s:generateAccessToken", i.basePath, i.serviceAccount)
+	response, err := oauth2.NewClient(context.Background(), i.source).Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("impersonating service account %s: %s", i.serviceAccount, err)
+	}
+	defer response.Body.Close()
+
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err //not tested
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("impersonating service account %s: %s: %s", i.serviceAccount, response.Status, bytes.TrimSpace(contents))
+	}
+
+	var token struct {
+		AccessToken string    `json:"accessToken"`
+		ExpireTime  time.Time `json:"expireTime"`
+	}
+	err = json.Unmarshal(contents, &token)
+	if err != nil {
+		return nil, fmt.Errorf("impersonating service account %s: %s", i.serviceAccount, err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: token.AccessToken,
+		TokenType:   "Bearer",
+		Expiry:      token.ExpireTime,
+	}, nil
+}
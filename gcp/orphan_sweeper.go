@@ -0,0 +1,79 @@
+package gcp
+
+import (
+	"fmt"
+	"path"
+)
+
+// OrphanSweeper finds and deletes the instances and disks that carry the
+// "director" label the BOSH google CPI puts on everything it creates.
+type OrphanSweeper struct {
+	clientProvider clientProvider
+	logger         logger
+}
+
+func NewOrphanSweeper(clientProvider clientProvider, logger logger) OrphanSweeper {
+	return OrphanSweeper{
+		clientProvider: clientProvider,
+		logger:         logger,
+	}
+}
+
+// Orphans describes every resource labelled for the director.
+func (o OrphanSweeper) Orphans(directorName string) ([]string, error) {
+	client := o.clientProvider.Client()
+
+	instances, err := client.ListLabeledInstances("director", directorName)
+	if err != nil {
+		return nil, err
+	}
+
+	disks, err := client.ListLabeledDisks("director", directorName)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := []string{}
+	for _, instance := range instances {
+		orphans = append(orphans, fmt.Sprintf("instance %s (%s)", instance.Name, path.Base(instance.Zone)))
+	}
+	for _, disk := range disks {
+		orphans = append(orphans, fmt.Sprintf("disk %s (%s)", disk.Name, path.Base(disk.Zone)))
+	}
+
+	return orphans, nil
+}
+
+// DeleteOrphans deletes the labelled instances and then the labelled disks,
+// which can only be deleted once no instance uses them.
+func (o OrphanSweeper) DeleteOrphans(directorName string) error {
+	client := o.clientProvider.Client()
+
+	instances, err := client.ListLabeledInstances("director", directorName)
+	if err != nil {
+		return err
+	}
+
+	for _, instance := range instances {
+		o.logger.Step("deleting instance %s", instance.Name)
+		err = client.DeleteInstance(instance.Zone, instance.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	disks, err := client.ListLabeledDisks("director", directorName)
+	if err != nil {
+		return err
+	}
+
+	for _, disk := range disks {
+		o.logger.Step("deleting disk %s", disk.Name)
+		err = client.DeleteDisk(disk.Zone, disk.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package gcp_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/gcp"
+	compute "google.golang.org/api/compute/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OrphanSweeper", func() {
+	var (
+		client         *fakes.GCPClient
+		clientProvider *fakes.GCPClientProvider
+		logger         *fakes.Logger
+		orphanSweeper  gcp.OrphanSweeper
+	)
+
+	BeforeEach(func() {
+		client = &fakes.GCPClient{}
+		clientProvider = &fakes.GCPClientProvider{}
+		clientProvider.ClientCall.Returns.Client = client
+		logger = &fakes.Logger{}
+
+		client.ListLabeledInstancesCall.Returns.Instances = []*compute.Instance{{
+			Name: "vm-1",
+			Zone: "https://www.googleapis.com/compute/v1/projects/some-project/zones/us-east1-b",
+		}}
+		client.ListLabeledDisksCall.Returns.Disks = []*compute.Disk{{
+			Name: "disk-1",
+			Zone: "https://www.googleapis.com/compute/v1/projects/some-project/zones/us-east1-c",
+		}}
+
+		orphanSweeper = gcp.NewOrphanSweeper(clientProvider, logger)
+	})
+
+	Describe("Orphans", func() {
+		It("describes every resource labelled for the director", func() {
+			orphans, err := orphanSweeper.Orphans("bosh-some-env-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(orphans).To(Equal([]string{
+				"instance vm-1 (us-east1-b)",
+				"disk disk-1 (us-east1-c)",
+			}))
+			Expect(client.ListLabeledInstancesCall.Receives.Label).To(Equal("director"))
+			Expect(client.ListLabeledInstancesCall.Receives.Value).To(Equal("bosh-some-env-id"))
+			Expect(client.ListLabeledDisksCall.Receives.Label).To(Equal("director"))
+			Expect(client.ListLabeledDisksCall.Receives.Value).To(Equal("bosh-some-env-id"))
+		})
+
+		It("returns an error when the disks cannot be listed", func() {
+			client.ListLabeledDisksCall.Returns.Error = errors.New("failed to list disks")
+
+			_, err := orphanSweeper.Orphans("bosh-some-env-id")
+			Expect(err).To(MatchError("failed to list disks"))
+		})
+	})
+
+	Describe("DeleteOrphans", func() {
+		It("deletes the instances and then the disks", func() {
+			err := orphanSweeper.DeleteOrphans("bosh-some-env-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.DeleteInstanceCall.Receives.Names).To(Equal([]string{"vm-1"}))
+			Expect(client.DeleteInstanceCall.Receives.Zones).To(Equal([]string{"https://www.googleapis.com/compute/v1/projects/some-project/zones/us-east1-b"}))
+			Expect(client.DeleteDiskCall.Receives.Names).To(Equal([]string{"disk-1"}))
+			Expect(logger.StepCall.Messages).To(Equal([]string{
+				"deleting instance vm-1",
+				"deleting disk disk-1",
+			}))
+		})
+
+		It("returns an error when an instance cannot be deleted", func() {
+			client.DeleteInstanceCall.Returns.Error = errors.New("failed to delete instance")
+
+			err := orphanSweeper.DeleteOrphans("bosh-some-env-id")
+			Expect(err).To(MatchError("failed to delete instance"))
+			Expect(client.DeleteDiskCall.CallCount).To(Equal(0))
+		})
+
+		It("returns an error when a disk cannot be deleted", func() {
+			client.DeleteDiskCall.Returns.Error = errors.New("failed to delete disk")
+
+			err := orphanSweeper.DeleteOrphans("bosh-some-env-id")
+			Expect(err).To(MatchError("failed to delete disk"))
+		})
+	})
+})
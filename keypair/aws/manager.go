@@ -69,6 +69,11 @@ func (m Manager) Rotate(state storage.State) (storage.State, error) {
 		AccessKeyID:     state.AWS.AccessKeyID,
 		SecretAccessKey: state.AWS.SecretAccessKey,
 		Region:          state.AWS.Region,
+		Profile:         state.AWS.Profile,
+		CredentialChain: state.AWS.CredentialChain,
+		AssumeRoleARN:   state.AWS.AssumeRoleARN,
+		ExternalID:      state.AWS.ExternalID,
+		SessionName:     state.AWS.SessionName,
 	})
 
 	err := m.keyPairDeleter.Delete(state.KeyPair.Name)
@@ -3,6 +3,7 @@ package gcp
 import (
 	"errors"
 
+	"github.com/cloudfoundry/bosh-bootloader/gcp"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
@@ -21,7 +22,7 @@ type keyPairDeleter interface {
 }
 
 type gcpClientProvider interface {
-	SetConfig(serviceAccountKey, projectID, region, zone string) error
+	SetConfig(config gcp.Config) error
 }
 
 func NewManager(keyPairUpdater keyPairUpdater, keyPairDeleter keyPairDeleter, gcpClientProvider gcpClientProvider) Manager {
@@ -49,7 +50,14 @@ func (m Manager) Rotate(state storage.State) (storage.State, error) {
 		return storage.State{}, errors.New("no key found to rotate")
 	}
 
-	err := m.gcpClientProvider.SetConfig(state.GCP.ServiceAccountKey, state.GCP.ProjectID, state.GCP.Region, state.GCP.Zone)
+	err := m.gcpClientProvider.SetConfig(gcp.Config{
+		ServiceAccountKey:             state.GCP.ServiceAccountKey,
+		ProjectID:                     state.GCP.ProjectID,
+		Region:                        state.GCP.Region,
+		Zone:                          state.GCP.Zone,
+		ApplicationDefaultCredentials: state.GCP.ApplicationDefaultCredentials,
+		ImpersonateServiceAccount:     state.GCP.ImpersonateServiceAccount,
+	})
 	if err != nil {
 		return storage.State{}, err
 	}
@@ -0,0 +1,52 @@
+package opsfile_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/cloudfoundry/bosh-bootloader/opsfile"
+	"github.com/pivotal-cf-experimental/gomegamatchers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Conformance", func() {
+	var fixturesDir = filepath.Join("..", "cloudconfig")
+
+	// The expected cloud configs are the output of bosh interpolate, written by
+	// scripts/generate_cloud_config_fixtures. Run it again when the base cloud
+	// config or the ops fixtures change.
+	DescribeTable("produces the same cloud config as bosh interpolate",
+		func(expectedFixture string, opsFixtures ...string) {
+			baseCloudConfig, err := ioutil.ReadFile(filepath.Join(fixturesDir, "fixtures", "base-cloud-config.yml"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var ops []byte
+			for _, opsFixture := range opsFixtures {
+				contents, err := ioutil.ReadFile(filepath.Join(fixturesDir, opsFixture))
+				Expect(err).NotTo(HaveOccurred())
+
+				ops = append(ops, '\n')
+				ops = append(ops, contents...)
+			}
+
+			expectedCloudConfig, err := ioutil.ReadFile(filepath.Join(fixturesDir, "fixtures", expectedFixture))
+			Expect(err).NotTo(HaveOccurred())
+
+			cloudConfig, err := opsfile.Apply(baseCloudConfig, ops)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cloudConfig).To(gomegamatchers.MatchYAML(expectedCloudConfig))
+		},
+		Entry("aws without a load balancer", "aws-cloud-config-no-lb.yml",
+			"aws/fixtures/aws-ops.yml"),
+		Entry("gcp without a load balancer", "gcp-cloud-config-no-lb.yml",
+			"gcp/fixtures/gcp-ops.yml"),
+		Entry("gcp with a cf load balancer", "gcp-cloud-config-cf-lb.yml",
+			"gcp/fixtures/gcp-ops.yml", "gcp/fixtures/gcp-cf-lb-ops.yml"),
+		Entry("gcp with a concourse load balancer", "gcp-cloud-config-concourse-lb.yml",
+			"gcp/fixtures/gcp-ops.yml", "gcp/fixtures/gcp-concourse-lb-ops.yml"),
+	)
+})
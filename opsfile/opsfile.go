@@ -0,0 +1,348 @@
+package opsfile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+type op struct {
+	Type  string
+	Path  string
+	Value interface{}
+}
+
+// Apply applies a BOSH ops file to a YAML document in the same way
+// `bosh interpolate -o` does. Variables are left untouched.
+func Apply(document, opsFile []byte) ([]byte, error) {
+	var doc interface{}
+	err := yaml.Unmarshal(document, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []op
+	err = yaml.Unmarshal(opsFile, &ops)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, o := range ops {
+		doc, err = applyOp(doc, o)
+		if err != nil {
+			return nil, fmt.Errorf("Error applying operation %d (%s %s): %s", i, o.Type, o.Path, err)
+		}
+	}
+
+	return yaml.Marshal(doc)
+}
+
+func applyOp(doc interface{}, o op) (interface{}, error) {
+	tokens, err := parsePath(o.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.Type {
+	case "replace":
+		return replace(doc, tokens, o.Value)
+	case "remove":
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("Cannot remove the root of the document")
+		}
+		return remove(doc, tokens)
+	default:
+		return nil, fmt.Errorf("Unknown operation type %q", o.Type)
+	}
+}
+
+type tokenKind int
+
+const (
+	keyToken tokenKind = iota
+	indexToken
+	afterLastIndexToken
+	matchingIndexToken
+)
+
+type token struct {
+	kind     tokenKind
+	key      string
+	value    string
+	index    int
+	optional bool
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case indexToken:
+		return strconv.Itoa(t.index)
+	case afterLastIndexToken:
+		return "-"
+	case matchingIndexToken:
+		return fmt.Sprintf("%s=%s", t.key, t.value)
+	default:
+		return t.key
+	}
+}
+
+func parsePath(path string) ([]token, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("Expected path %q to start with '/'", path)
+	}
+
+	if path == "/" {
+		return []token{}, nil
+	}
+
+	var tokens []token
+	optional := false
+	for _, raw := range strings.Split(path[1:], "/") {
+		raw = strings.Replace(strings.Replace(raw, "~1", "/", -1), "~0", "~", -1)
+
+		if raw == "-" {
+			tokens = append(tokens, token{kind: afterLastIndexToken})
+			continue
+		}
+
+		if index, err := strconv.Atoi(raw); err == nil {
+			tokens = append(tokens, token{kind: indexToken, index: index})
+			continue
+		}
+
+		if strings.HasSuffix(raw, "?") {
+			raw = strings.TrimSuffix(raw, "?")
+			optional = true
+		}
+
+		if parts := strings.SplitN(raw, "=", 2); len(parts) == 2 {
+			tokens = append(tokens, token{kind: matchingIndexToken, key: parts[0], value: parts[1], optional: optional})
+			continue
+		}
+
+		tokens = append(tokens, token{kind: keyToken, key: raw, optional: optional})
+	}
+
+	return tokens, nil
+}
+
+func replace(node interface{}, tokens []token, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	tok, rest := tokens[0], tokens[1:]
+
+	switch tok.kind {
+	case keyToken:
+		if node == nil && tok.optional {
+			node = map[interface{}]interface{}{}
+		}
+
+		m, ok := node.(map[interface{}]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected to find a map at %q but found %T", tok, node)
+		}
+
+		child, found := m[tok.key]
+		if !found && !tok.optional {
+			return nil, fmt.Errorf("Expected to find a map key %q", tok.key)
+		}
+
+		newChild, err := replace(child, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		m[tok.key] = newChild
+
+		return m, nil
+	case afterLastIndexToken:
+		if len(rest) > 0 {
+			return nil, fmt.Errorf("Expected not to find any path segments after '-'")
+		}
+
+		if node == nil {
+			node = []interface{}{}
+		}
+
+		a, ok := node.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected to find an array at %q but found %T", tok, node)
+		}
+
+		return append(a, value), nil
+	case indexToken:
+		a, ok := node.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected to find an array at %q but found %T", tok, node)
+		}
+
+		index, err := arrayIndex(a, tok.index)
+		if err != nil {
+			return nil, err
+		}
+
+		a[index], err = replace(a[index], rest, value)
+		if err != nil {
+			return nil, err
+		}
+
+		return a, nil
+	case matchingIndexToken:
+		if node == nil && tok.optional {
+			node = []interface{}{}
+		}
+
+		a, ok := node.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected to find an array at %q but found %T", tok, node)
+		}
+
+		index, err := matchingIndex(a, tok)
+		if err != nil {
+			return nil, err
+		}
+
+		if index == -1 {
+			a = append(a, map[interface{}]interface{}{tok.key: tok.value})
+			index = len(a) - 1
+		}
+
+		a[index], err = replace(a[index], rest, value)
+		if err != nil {
+			return nil, err
+		}
+
+		return a, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported path segment %q", tok)
+}
+
+func remove(node interface{}, tokens []token) (interface{}, error) {
+	tok, rest := tokens[0], tokens[1:]
+
+	switch tok.kind {
+	case keyToken:
+		m, ok := node.(map[interface{}]interface{})
+		if !ok {
+			if node == nil && tok.optional {
+				return node, nil
+			}
+			return nil, fmt.Errorf("Expected to find a map at %q but found %T", tok, node)
+		}
+
+		child, found := m[tok.key]
+		if !found {
+			if tok.optional {
+				return m, nil
+			}
+			return nil, fmt.Errorf("Expected to find a map key %q", tok.key)
+		}
+
+		if len(rest) == 0 {
+			delete(m, tok.key)
+			return m, nil
+		}
+
+		newChild, err := remove(child, rest)
+		if err != nil {
+			return nil, err
+		}
+		m[tok.key] = newChild
+
+		return m, nil
+	case indexToken:
+		a, ok := node.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Expected to find an array at %q but found %T", tok, node)
+		}
+
+		index, err := arrayIndex(a, tok.index)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rest) == 0 {
+			return append(a[:index], a[index+1:]...), nil
+		}
+
+		a[index], err = remove(a[index], rest)
+		if err != nil {
+			return nil, err
+		}
+
+		return a, nil
+	case matchingIndexToken:
+		a, ok := node.([]interface{})
+		if !ok {
+			if node == nil && tok.optional {
+				return node, nil
+			}
+			return nil, fmt.Errorf("Expected to find an array at %q but found %T", tok, node)
+		}
+
+		index, err := matchingIndex(a, tok)
+		if err != nil {
+			return nil, err
+		}
+
+		if index == -1 {
+			return a, nil
+		}
+
+		if len(rest) == 0 {
+			return append(a[:index], a[index+1:]...), nil
+		}
+
+		a[index], err = remove(a[index], rest)
+		if err != nil {
+			return nil, err
+		}
+
+		return a, nil
+	}
+
+	return nil, fmt.Errorf("Cannot remove path segment %q", tok)
+}
+
+func arrayIndex(a []interface{}, index int) (int, error) {
+	if index < 0 {
+		index = len(a) + index
+	}
+
+	if index < 0 || index >= len(a) {
+		return 0, fmt.Errorf("Expected to find array index %d but found array of length %d", index, len(a))
+	}
+
+	return index, nil
+}
+
+// matchingIndex returns the index of the single item whose key matches the
+// token, or -1 when the token is optional and nothing matches.
+func matchingIndex(a []interface{}, tok token) (int, error) {
+	var matches []int
+	for i, item := range a {
+		m, ok := item.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+
+		if v, ok := m[tok.key]; ok && fmt.Sprintf("%v", v) == tok.value {
+			matches = append(matches, i)
+		}
+	}
+
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) == 0 && tok.optional:
+		return -1, nil
+	case len(matches) == 0:
+		return 0, fmt.Errorf("Expected to find exactly one matching array item for %q but found 0", tok)
+	default:
+		return 0, fmt.Errorf("Expected to find exactly one matching array item for %q but found %d", tok, len(matches))
+	}
+}
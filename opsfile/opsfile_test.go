@@ -0,0 +1,162 @@
+package opsfile_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/opsfile"
+	"github.com/pivotal-cf-experimental/gomegamatchers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Apply", func() {
+	const document = `
+name: some-name
+instance_groups:
+- name: bosh
+  properties:
+    director:
+      name: some-director
+- name: jumpbox
+  instances: 1
+`
+
+	DescribeTable("applies the ops to the document",
+		func(ops, expectedDocument string) {
+			result, err := opsfile.Apply([]byte(document), []byte(ops))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(gomegamatchers.MatchYAML(expectedDocument))
+		},
+		Entry("replaces a map key", `
+- type: replace
+  path: /name
+  value: some-other-name
+`, `
+name: some-other-name
+instance_groups:
+- name: bosh
+  properties:
+    director:
+      name: some-director
+- name: jumpbox
+  instances: 1
+`),
+		Entry("creates optional keys", `
+- type: replace
+  path: /instance_groups/name=bosh/properties/director/tags?/owner
+  value: some-owner
+`, `
+name: some-name
+instance_groups:
+- name: bosh
+  properties:
+    director:
+      name: some-director
+      tags:
+        owner: some-owner
+- name: jumpbox
+  instances: 1
+`),
+		Entry("appends to an array", `
+- type: replace
+  path: /instance_groups/-
+  value:
+    name: some-group
+`, `
+name: some-name
+instance_groups:
+- name: bosh
+  properties:
+    director:
+      name: some-director
+- name: jumpbox
+  instances: 1
+- name: some-group
+`),
+		Entry("replaces an array item by index", `
+- type: replace
+  path: /instance_groups/-1/instances
+  value: 2
+`, `
+name: some-name
+instance_groups:
+- name: bosh
+  properties:
+    director:
+      name: some-director
+- name: jumpbox
+  instances: 2
+`),
+		Entry("appends an optional array item that does not match", `
+- type: replace
+  path: /instance_groups/name=some-group?/instances
+  value: 3
+`, `
+name: some-name
+instance_groups:
+- name: bosh
+  properties:
+    director:
+      name: some-director
+- name: jumpbox
+  instances: 1
+- name: some-group
+  instances: 3
+`),
+		Entry("removes an array item", `
+- type: remove
+  path: /instance_groups/name=jumpbox
+`, `
+name: some-name
+instance_groups:
+- name: bosh
+  properties:
+    director:
+      name: some-director
+`),
+		Entry("ignores removing missing optional keys", `
+- type: remove
+  path: /instance_groups/name=bosh/properties/director/tags?
+`, document),
+		Entry("unescapes path segments", `
+- type: replace
+  path: /some~1key?
+  value: some-value
+- type: remove
+  path: /name
+`, `
+some/key: some-value
+instance_groups:
+- name: bosh
+  properties:
+    director:
+      name: some-director
+- name: jumpbox
+  instances: 1
+`),
+	)
+
+	DescribeTable("returns an error",
+		func(ops, expectedError string) {
+			_, err := opsfile.Apply([]byte(document), []byte(ops))
+			Expect(err).To(MatchError(expectedError))
+		},
+		Entry("when a map key is missing", `
+- type: replace
+  path: /missing/key
+  value: some-value
+`, `Error applying operation 0 (replace /missing/key): Expected to find a map key "missing"`),
+		Entry("when no array item matches", `
+- type: remove
+  path: /instance_groups/name=missing
+`, `Error applying operation 0 (remove /instance_groups/name=missing): Expected to find exactly one matching array item for "name=missing" but found 0`),
+		Entry("when an array index is out of range", `
+- type: remove
+  path: /instance_groups/5
+`, `Error applying operation 0 (remove /instance_groups/5): Expected to find array index 5 but found array of length 2`),
+		Entry("when the operation type is unknown", `
+- type: test
+  path: /name
+`, `Error applying operation 0 (test /name): Unknown operation type "test"`),
+	)
+})
@@ -35,6 +35,14 @@ var (
 		"LoadBalancerRouteTable":          "aws_route_table.lb_route_table",
 		"LoadBalancerCert":                "aws_iam_server_certificate.lb_cert",
 	}
+
+	// When the internal subnets route through a NAT gateway per availability
+	// zone, the single CloudFormation route table becomes the first of the
+	// per-AZ route tables. The NAT instance resources are still imported so that
+	// terraform tears them down once the gateways are in place.
+	natGatewayPerAZStackOutputToTerraformAddr = map[string]string{
+		"InternalRouteTable": "aws_route_table.internal_route_tables[0]",
+	}
 )
 
 //go:generate counterfeiter -o ./fakes/tf.go --fake-name TF . tf
@@ -122,6 +130,10 @@ func (m Migrator) Migrate(state storage.State) (storage.State, error) {
 
 	for key, value := range stack.Outputs {
 		addr := stackOutputToTerraformAddr[key]
+		if natAddr, ok := natGatewayPerAZStackOutputToTerraformAddr[key]; ok && state.AWS.NATType == "gateway-per-az" {
+			addr = natAddr
+		}
+
 		if strings.Contains(key, "InternalSubnet") {
 			addr = fmt.Sprintf("aws_subnet.internal_subnets[%d]", internalSubnetIndex)
 			internalSubnetIndex++
@@ -216,6 +216,34 @@ var _ = Describe("Migrate", func() {
 		)))
 	})
 
+	Context("when a nat gateway per availability zone is requested", func() {
+		BeforeEach(func() {
+			incomingState.AWS.NATType = "gateway-per-az"
+		})
+
+		It("maps the internal route table to the first per-az route table", func() {
+			_, err := migrator.Migrate(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			importInputs := []terraform.ImportInput{}
+			for _, importCall := range tf.Invocations()["Import"] {
+				importInputs = append(importInputs, importCall[0].(terraform.ImportInput))
+			}
+
+			Expect(tf.ImportCallCount()).To(Equal(27))
+			Expect(importInputs).To(ContainElement(terraform.ImportInput{
+				TerraformAddr: "aws_route_table.internal_route_tables[0]",
+				AWSResourceID: "some-internal-route-table",
+				Creds:         storage.AWS{NATType: "gateway-per-az"},
+			}))
+			Expect(importInputs).To(ContainElement(terraform.ImportInput{
+				TerraformAddr: "aws_instance.nat",
+				AWSResourceID: "some-nat-instance",
+				Creds:         storage.AWS{NATType: "gateway-per-az"},
+			}))
+		})
+	})
+
 	Context("when there is no stack", func() {
 		BeforeEach(func() {
 			incomingState.Stack = storage.Stack{
@@ -0,0 +1,250 @@
+package status
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	Pass = "pass"
+	Fail = "fail"
+	Skip = "skip"
+)
+
+var timeNow func() time.Time = time.Now
+
+// lbOutputs maps each load balancer type to the terraform output that only
+// exists once the load balancer has been created.
+var lbOutputs = map[string]map[string]string{
+	"aws": {
+		"cf":        "cf_router_lb_name",
+		"concourse": "concourse_lb_name",
+	},
+	"gcp": {
+		"cf":        "router_lb_ip",
+		"concourse": "concourse_lb_ip",
+	},
+}
+
+type Checker struct {
+	boshClientProvider boshClientProvider
+	terraformManager   terraformManager
+	sshKeyGetter       sshKeyGetter
+	hostKeyGetter      hostKeyGetter
+	cloudConfigManager cloudConfigManager
+}
+
+type Result struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+type boshClientProvider interface {
+	Client(storage.State) (bosh.Client, error)
+}
+
+type terraformManager interface {
+	Plan(storage.State) (bool, error)
+	GetOutputs(storage.State) (map[string]interface{}, error)
+}
+
+type sshKeyGetter interface {
+	Get(storage.State) (string, error)
+}
+
+type hostKeyGetter interface {
+	Get(string, string) (ssh.PublicKey, error)
+}
+
+type cloudConfigManager interface {
+	Diff(storage.State) (string, error)
+}
+
+func NewChecker(boshClientProvider boshClientProvider, terraformManager terraformManager, sshKeyGetter sshKeyGetter,
+	hostKeyGetter hostKeyGetter, cloudConfigManager cloudConfigManager) Checker {
+	return Checker{
+		boshClientProvider: boshClientProvider,
+		terraformManager:   terraformManager,
+		sshKeyGetter:       sshKeyGetter,
+		hostKeyGetter:      hostKeyGetter,
+		cloudConfigManager: cloudConfigManager,
+	}
+}
+
+// Check runs every health check against the environment. A failing check
+// does not stop the remaining checks from running.
+func (c Checker) Check(state storage.State) []Result {
+	return []Result{
+		c.checkTerraform(state),
+		c.checkJumpbox(state),
+		c.checkDirector(state),
+		c.checkLoadBalancers(state),
+		c.checkCloudConfig(state),
+	}
+}
+
+func (c Checker) checkTerraform(state storage.State) Result {
+	result := Result{Name: "terraform"}
+
+	if state.TFState == "" {
+		return skip(result, "no terraform state")
+	}
+
+	changed, err := c.terraformManager.Plan(state)
+	if err != nil {
+		return fail(result, err.Error())
+	}
+
+	if changed {
+		return fail(result, "infrastructure has drifted from the terraform state")
+	}
+
+	return pass(result, "infrastructure matches the terraform state")
+}
+
+func (c Checker) checkJumpbox(state storage.State) Result {
+	result := Result{Name: "jumpbox"}
+
+	if !state.Jumpbox.Enabled {
+		return skip(result, "no jumpbox")
+	}
+
+	jumpboxURL, privateKey, err := c.jumpbox(state)
+	if err != nil {
+		return fail(result, err.Error())
+	}
+
+	_, err = c.hostKeyGetter.Get(privateKey, jumpboxURL)
+	if err != nil {
+		return fail(result, fmt.Sprintf("ssh is not reachable at %s: %s", jumpboxURL, err))
+	}
+
+	return pass(result, fmt.Sprintf("ssh is reachable at %s", jumpboxURL))
+}
+
+func (c Checker) checkDirector(state storage.State) Result {
+	result := Result{Name: "director"}
+
+	if state.NoDirector || state.BOSH.IsEmpty() {
+		return skip(result, "no director")
+	}
+
+	boshClient, err := c.boshClientProvider.Client(state)
+	if err != nil {
+		return fail(result, err.Error())
+	}
+
+	info, err := boshClient.Info()
+	if err != nil {
+		return fail(result, err.Error())
+	}
+
+	return pass(result, fmt.Sprintf("name: %s, version: %s, uuid: %s, cpi: %s", info.Name, info.Version, info.UUID, info.CPI))
+}
+
+func (c Checker) checkLoadBalancers(state storage.State) Result {
+	result := Result{Name: "load balancers"}
+
+	outputName, ok := lbOutputs[state.IAAS][state.LB.Type]
+	if !ok {
+		return skip(result, "no load balancers")
+	}
+
+	terraformOutputs, err := c.terraformManager.GetOutputs(state)
+	if err != nil {
+		return fail(result, err.Error())
+	}
+
+	if _, ok := terraformOutputs[outputName]; !ok {
+		return fail(result, fmt.Sprintf("%s load balancer does not exist", state.LB.Type))
+	}
+
+	if state.LB.Cert == "" {
+		return pass(result, fmt.Sprintf("%s load balancer exists", state.LB.Type))
+	}
+
+	notAfter, err := certificateExpiry(state.LB.Cert)
+	if err != nil {
+		return fail(result, err.Error())
+	}
+
+	if timeNow().After(notAfter) {
+		return fail(result, fmt.Sprintf("%s load balancer certificate expired on %s", state.LB.Type, notAfter.Format("2006-01-02")))
+	}
+
+	return pass(result, fmt.Sprintf("%s load balancer exists, certificate expires on %s", state.LB.Type, notAfter.Format("2006-01-02")))
+}
+
+func (c Checker) checkCloudConfig(state storage.State) Result {
+	result := Result{Name: "cloud config"}
+
+	if state.NoDirector || state.BOSH.IsEmpty() {
+		return skip(result, "no director")
+	}
+
+	diff, err := c.cloudConfigManager.Diff(state)
+	if err != nil {
+		return fail(result, err.Error())
+	}
+
+	if diff != "" {
+		return fail(result, "cloud config differs from the one bbl would generate, see `bbl cloud-config --diff`")
+	}
+
+	return pass(result, "cloud config matches the one bbl would generate")
+}
+
+func (c Checker) jumpbox(state storage.State) (string, string, error) {
+	privateKey, err := c.sshKeyGetter.Get(state)
+	if err != nil {
+		return "", "", err
+	}
+
+	terraformOutputs, err := c.terraformManager.GetOutputs(state)
+	if err != nil {
+		return "", "", err
+	}
+
+	return fmt.Sprintf("%s:%d", terraformOutputs["external_ip"], 22), privateKey, nil
+}
+
+func certificateExpiry(cert string) (time.Time, error) {
+	block, _ := pem.Decode([]byte(cert))
+	if block == nil {
+		return time.Time{}, errors.New("load balancer certificate is not PEM encoded")
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("load balancer certificate could not be parsed: %s", err)
+	}
+
+	return certificate.NotAfter, nil
+}
+
+func pass(result Result, detail string) Result {
+	result.Status = Pass
+	result.Detail = detail
+	return result
+}
+
+func fail(result Result, detail string) Result {
+	result.Status = Fail
+	result.Detail = detail
+	return result
+}
+
+func skip(result Result, detail string) Result {
+	result.Status = Skip
+	result.Detail = detail
+	return result
+}
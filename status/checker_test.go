@@ -0,0 +1,262 @@
+package status_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/status"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Checker", func() {
+	var (
+		boshClientProvider *fakes.ProxiedBOSHClientProvider
+		boshClient         *fakes.BOSHClient
+		terraformManager   *fakes.TerraformManager
+		sshKeyGetter       *fakes.SSHKeyGetter
+		hostKeyGetter      *fakes.HostKeyGetter
+		cloudConfigManager *fakes.CloudConfigManager
+		checker            status.Checker
+
+		incomingState storage.State
+	)
+
+	BeforeEach(func() {
+		boshClient = &fakes.BOSHClient{}
+		boshClientProvider = &fakes.ProxiedBOSHClientProvider{}
+		terraformManager = &fakes.TerraformManager{}
+		sshKeyGetter = &fakes.SSHKeyGetter{}
+		hostKeyGetter = &fakes.HostKeyGetter{}
+		cloudConfigManager = &fakes.CloudConfigManager{}
+
+		boshClientProvider.ClientCall.Returns.Client = boshClient
+		boshClient.InfoCall.Returns.Info = bosh.Info{
+			Name:    "some-director",
+			UUID:    "some-uuid",
+			Version: "some-version",
+			CPI:     "some-cpi",
+		}
+
+		incomingState = storage.State{
+			IAAS:    "gcp",
+			TFState: "some-tf-state",
+			BOSH: storage.BOSH{
+				DirectorAddress:  "some-director-address",
+				DirectorUsername: "some-director-username",
+				DirectorPassword: "some-director-password",
+			},
+		}
+
+		checker = status.NewChecker(boshClientProvider, terraformManager, sshKeyGetter, hostKeyGetter, cloudConfigManager)
+	})
+
+	AfterEach(func() {
+		status.ResetTimeNow()
+	})
+
+	It("runs every check", func() {
+		results := checker.Check(incomingState)
+
+		Expect(results).To(Equal([]status.Result{
+			{Name: "terraform", Status: status.Pass, Detail: "infrastructure matches the terraform state"},
+			{Name: "jumpbox", Status: status.Skip, Detail: "no jumpbox"},
+			{Name: "director", Status: status.Pass, Detail: "name: some-director, version: some-version, uuid: some-uuid, cpi: some-cpi"},
+			{Name: "load balancers", Status: status.Skip, Detail: "no load balancers"},
+			{Name: "cloud config", Status: status.Pass, Detail: "cloud config matches the one bbl would generate"},
+		}))
+
+		Expect(terraformManager.PlanCall.Receives.BBLState).To(Equal(incomingState))
+		Expect(boshClientProvider.ClientCall.Receives.State).To(Equal(incomingState))
+		Expect(cloudConfigManager.DiffCall.Receives.State).To(Equal(incomingState))
+	})
+
+	Describe("terraform", func() {
+		It("skips the check when there is no terraform state", func() {
+			incomingState.TFState = ""
+
+			results := checker.Check(incomingState)
+			Expect(results[0]).To(Equal(status.Result{Name: "terraform", Status: status.Skip, Detail: "no terraform state"}))
+			Expect(terraformManager.PlanCall.CallCount).To(Equal(0))
+		})
+
+		It("fails when the infrastructure has drifted", func() {
+			terraformManager.PlanCall.Returns.Changed = true
+
+			results := checker.Check(incomingState)
+			Expect(results[0]).To(Equal(status.Result{Name: "terraform", Status: status.Fail, Detail: "infrastructure has drifted from the terraform state"}))
+		})
+
+		It("fails when the plan fails", func() {
+			terraformManager.PlanCall.Returns.Error = errors.New("failed to plan")
+
+			results := checker.Check(incomingState)
+			Expect(results[0]).To(Equal(status.Result{Name: "terraform", Status: status.Fail, Detail: "failed to plan"}))
+		})
+	})
+
+	Context("when a jumpbox exists", func() {
+		BeforeEach(func() {
+			incomingState.Jumpbox.Enabled = true
+			terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+				"external_ip": "some-external-ip",
+			}
+			sshKeyGetter.GetCall.Returns.PrivateKey = "some-private-key"
+		})
+
+		It("checks that the jumpbox ssh port answers", func() {
+			results := checker.Check(incomingState)
+			Expect(results[1]).To(Equal(status.Result{Name: "jumpbox", Status: status.Pass, Detail: "ssh is reachable at some-external-ip:22"}))
+
+			Expect(hostKeyGetter.GetCall.Receives.PrivateKey).To(Equal("some-private-key"))
+			Expect(hostKeyGetter.GetCall.Receives.ServerURL).To(Equal("some-external-ip:22"))
+		})
+
+		It("fails when the jumpbox ssh port does not answer", func() {
+			hostKeyGetter.GetCall.Returns.Error = errors.New("connection refused")
+
+			results := checker.Check(incomingState)
+			Expect(results[1]).To(Equal(status.Result{Name: "jumpbox", Status: status.Fail, Detail: "ssh is not reachable at some-external-ip:22: connection refused"}))
+		})
+
+		It("fails the director check when the socks5 proxy fails to start", func() {
+			boshClientProvider.ClientCall.Returns.Error = errors.New("failed to start socks5 proxy")
+
+			results := checker.Check(incomingState)
+			Expect(results[2]).To(Equal(status.Result{Name: "director", Status: status.Fail, Detail: "failed to start socks5 proxy"}))
+		})
+	})
+
+	Describe("director", func() {
+		It("skips the check when there is no director", func() {
+			incomingState.NoDirector = true
+
+			results := checker.Check(incomingState)
+			Expect(results[2]).To(Equal(status.Result{Name: "director", Status: status.Skip, Detail: "no director"}))
+			Expect(results[4]).To(Equal(status.Result{Name: "cloud config", Status: status.Skip, Detail: "no director"}))
+			Expect(boshClient.InfoCall.CallCount).To(Equal(0))
+			Expect(cloudConfigManager.DiffCall.CallCount).To(Equal(0))
+		})
+
+		It("fails when the director does not respond", func() {
+			boshClient.InfoCall.Returns.Error = errors.New("failed to get info")
+
+			results := checker.Check(incomingState)
+			Expect(results[2]).To(Equal(status.Result{Name: "director", Status: status.Fail, Detail: "failed to get info"}))
+		})
+	})
+
+	Describe("load balancers", func() {
+		BeforeEach(func() {
+			incomingState.LB = storage.LB{
+				Type: "cf",
+				Cert: certificateExpiringOn(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)),
+			}
+			terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+				"router_lb_ip": "some-router-lb-ip",
+			}
+			status.SetTimeNow(func() time.Time {
+				return time.Date(2029, time.January, 1, 0, 0, 0, 0, time.UTC)
+			})
+		})
+
+		It("passes when the load balancer exists and its certificate is valid", func() {
+			results := checker.Check(incomingState)
+			Expect(results[3]).To(Equal(status.Result{Name: "load balancers", Status: status.Pass, Detail: "cf load balancer exists, certificate expires on 2030-01-01"}))
+		})
+
+		It("looks up the aws load balancer output on aws", func() {
+			incomingState.IAAS = "aws"
+			terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+				"cf_router_lb_name": "some-router-lb-name",
+			}
+
+			results := checker.Check(incomingState)
+			Expect(results[3].Status).To(Equal(status.Pass))
+		})
+
+		It("passes without a certificate check when there is no certificate", func() {
+			incomingState.LB = storage.LB{Type: "concourse"}
+			terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+				"concourse_lb_ip": "some-concourse-lb-ip",
+			}
+
+			results := checker.Check(incomingState)
+			Expect(results[3]).To(Equal(status.Result{Name: "load balancers", Status: status.Pass, Detail: "concourse load balancer exists"}))
+		})
+
+		It("fails when the load balancer does not exist", func() {
+			terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{}
+
+			results := checker.Check(incomingState)
+			Expect(results[3]).To(Equal(status.Result{Name: "load balancers", Status: status.Fail, Detail: "cf load balancer does not exist"}))
+		})
+
+		It("fails when the certificate has expired", func() {
+			status.SetTimeNow(func() time.Time {
+				return time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC)
+			})
+
+			results := checker.Check(incomingState)
+			Expect(results[3]).To(Equal(status.Result{Name: "load balancers", Status: status.Fail, Detail: "cf load balancer certificate expired on 2030-01-01"}))
+		})
+
+		It("fails when the certificate is not PEM encoded", func() {
+			incomingState.LB.Cert = "some-cert"
+
+			results := checker.Check(incomingState)
+			Expect(results[3]).To(Equal(status.Result{Name: "load balancers", Status: status.Fail, Detail: "load balancer certificate is not PEM encoded"}))
+		})
+
+		It("fails when the terraform outputs cannot be read", func() {
+			terraformManager.GetOutputsCall.Returns.Error = errors.New("failed to get outputs")
+
+			results := checker.Check(incomingState)
+			Expect(results[3]).To(Equal(status.Result{Name: "load balancers", Status: status.Fail, Detail: "failed to get outputs"}))
+		})
+	})
+
+	Describe("cloud config", func() {
+		It("fails when the cloud config on the director differs", func() {
+			cloudConfigManager.DiffCall.Returns.Diff = "some-diff"
+
+			results := checker.Check(incomingState)
+			Expect(results[4]).To(Equal(status.Result{Name: "cloud config", Status: status.Fail, Detail: "cloud config differs from the one bbl would generate, see `bbl cloud-config --diff`"}))
+		})
+
+		It("fails when the diff fails", func() {
+			cloudConfigManager.DiffCall.Returns.Error = errors.New("failed to diff")
+
+			results := checker.Check(incomingState)
+			Expect(results[4]).To(Equal(status.Result{Name: "cloud config", Status: status.Fail, Detail: "failed to diff"}))
+		})
+	})
+})
+
+func certificateExpiringOn(notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "some-common-name"},
+		NotBefore:    notAfter.AddDate(-2, 0, 0),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
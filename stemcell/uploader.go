@@ -0,0 +1,117 @@
+package stemcell
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const directorStemcellPath = "/resource_pools/name=vms/stemcell?"
+
+type Uploader struct {
+	logger             logger
+	boshClientProvider boshClientProvider
+}
+
+type logger interface {
+	Step(string, ...interface{})
+}
+
+type boshClientProvider interface {
+	Client(storage.State) (bosh.Client, error)
+}
+
+type Stemcell struct {
+	Name    string
+	Version string
+	URL     string
+	SHA1    string
+}
+
+func NewUploader(logger logger, boshClientProvider boshClientProvider) Uploader {
+	return Uploader{
+		logger:             logger,
+		boshClientProvider: boshClientProvider,
+	}
+}
+
+// Upload uploads the light stemcell for the IaaS to the director, unless a
+// stemcell with the same name and version is already there.
+func (u Uploader) Upload(state storage.State) error {
+	stemcell, err := ForIAAS(state.IAAS)
+	if err != nil {
+		return err
+	}
+
+	boshClient, err := u.boshClientProvider.Client(state)
+	if err != nil {
+		return err
+	}
+
+	stemcells, err := boshClient.Stemcells()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range stemcells {
+		if s.Name == stemcell.Name && s.Version == stemcell.Version {
+			u.logger.Step("stemcell %s/%s already uploaded", stemcell.Name, stemcell.Version)
+			return nil
+		}
+	}
+
+	u.logger.Step("uploading stemcell %s/%s", stemcell.Name, stemcell.Version)
+	err = boshClient.UploadStemcell(stemcell.URL, stemcell.SHA1)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ForIAAS returns the stemcell the vendored bosh-deployment uses for the
+// director on the given IaaS.
+func ForIAAS(iaas string) (Stemcell, error) {
+	cpiOpsFile, err := bosh.Asset(fmt.Sprintf("vendor/github.com/cloudfoundry/bosh-deployment/%s/cpi.yml", iaas))
+	if err != nil {
+		return Stemcell{}, fmt.Errorf("no stemcell is known for iaas %q", iaas)
+	}
+
+	var ops []struct {
+		Path  string      `yaml:"path"`
+		Value interface{} `yaml:"value"`
+	}
+	err = yaml.Unmarshal(cpiOpsFile, &ops)
+	if err != nil {
+		return Stemcell{}, err
+	}
+
+	for _, op := range ops {
+		if op.Path != directorStemcellPath {
+			continue
+		}
+
+		value, _ := op.Value.(map[interface{}]interface{})
+		rawURL, _ := value["url"].(string)
+		sha1, _ := value["sha1"].(string)
+
+		stemcellURL, err := url.Parse(rawURL)
+		if err != nil {
+			return Stemcell{}, err
+		}
+
+		return Stemcell{
+			Name:    path.Base(stemcellURL.Path),
+			Version: stemcellURL.Query().Get("v"),
+			URL:     rawURL,
+			SHA1:    sha1,
+		}, nil
+	}
+
+	return Stemcell{}, fmt.Errorf("no stemcell is known for iaas %q", iaas)
+}
@@ -0,0 +1,123 @@
+package stemcell_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/stemcell"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Uploader", func() {
+	var (
+		logger             *fakes.Logger
+		boshClientProvider *fakes.ProxiedBOSHClientProvider
+		boshClient         *fakes.BOSHClient
+		uploader           stemcell.Uploader
+
+		incomingState storage.State
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		boshClient = &fakes.BOSHClient{}
+		boshClientProvider = &fakes.ProxiedBOSHClientProvider{}
+
+		boshClientProvider.ClientCall.Returns.Client = boshClient
+
+		incomingState = storage.State{
+			IAAS: "gcp",
+			BOSH: storage.BOSH{
+				DirectorAddress:  "some-director-address",
+				DirectorUsername: "some-director-username",
+				DirectorPassword: "some-director-password",
+			},
+		}
+
+		uploader = stemcell.NewUploader(logger, boshClientProvider)
+	})
+
+	Describe("Upload", func() {
+		It("uploads the stemcell for the iaas", func() {
+			err := uploader.Upload(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(boshClientProvider.ClientCall.Receives.State).To(Equal(incomingState))
+
+			Expect(boshClient.UploadStemcellCall.CallCount).To(Equal(1))
+			Expect(boshClient.UploadStemcellCall.Receives.URL).To(Equal("https://bosh.io/d/stemcells/bosh-google-kvm-ubuntu-trusty-go_agent?v=3421.9"))
+			Expect(boshClient.UploadStemcellCall.Receives.SHA1).To(Equal("408f78a2091d108bb5418964026e73c822def32d"))
+			Expect(logger.StepCall.Messages).To(Equal([]string{
+				"uploading stemcell bosh-google-kvm-ubuntu-trusty-go_agent/3421.9",
+			}))
+		})
+
+		It("skips the upload when the stemcell version is already present", func() {
+			boshClient.StemcellsCall.Returns.Stemcells = []bosh.Stemcell{
+				{Name: "bosh-google-kvm-ubuntu-trusty-go_agent", Version: "3421.9"},
+			}
+
+			err := uploader.Upload(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(boshClient.UploadStemcellCall.CallCount).To(Equal(0))
+			Expect(logger.StepCall.Messages).To(Equal([]string{
+				"stemcell bosh-google-kvm-ubuntu-trusty-go_agent/3421.9 already uploaded",
+			}))
+		})
+
+		It("uploads the stemcell when only another version is present", func() {
+			boshClient.StemcellsCall.Returns.Stemcells = []bosh.Stemcell{
+				{Name: "bosh-google-kvm-ubuntu-trusty-go_agent", Version: "3421.3"},
+			}
+
+			err := uploader.Upload(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(boshClient.UploadStemcellCall.CallCount).To(Equal(1))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the iaas has no known stemcell", func() {
+				incomingState.IAAS = "some-iaas"
+				err := uploader.Upload(incomingState)
+				Expect(err).To(MatchError(`no stemcell is known for iaas "some-iaas"`))
+			})
+
+			It("returns an error when the bosh client cannot be created", func() {
+				boshClientProvider.ClientCall.Returns.Error = errors.New("failed to start socks5 proxy")
+				err := uploader.Upload(incomingState)
+				Expect(err).To(MatchError("failed to start socks5 proxy"))
+			})
+
+			It("returns an error when the stemcells cannot be listed", func() {
+				boshClient.StemcellsCall.Returns.Error = errors.New("failed to list stemcells")
+				err := uploader.Upload(incomingState)
+				Expect(err).To(MatchError("failed to list stemcells"))
+			})
+
+			It("returns an error when the stemcell fails to upload", func() {
+				boshClient.UploadStemcellCall.Returns.Error = errors.New("failed to upload stemcell")
+				err := uploader.Upload(incomingState)
+				Expect(err).To(MatchError("failed to upload stemcell"))
+			})
+		})
+	})
+
+	DescribeTable("ForIAAS",
+		func(iaas, name, version string) {
+			s, err := stemcell.ForIAAS(iaas)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Name).To(Equal(name))
+			Expect(s.Version).To(Equal(version))
+			Expect(s.SHA1).NotTo(BeEmpty())
+		},
+		Entry("aws", "aws", "bosh-aws-xen-hvm-ubuntu-trusty-go_agent", "3421.9"),
+		Entry("gcp", "gcp", "bosh-google-kvm-ubuntu-trusty-go_agent", "3421.9"),
+	)
+})
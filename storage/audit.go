@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const AuditFileName = "bbl-audit.jsonl"
+
+type AuditStep struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+// AuditEntry records a single run of a bbl command.
+type AuditEntry struct {
+	Command          string      `json:"command"`
+	Flags            []string    `json:"flags"`
+	User             string      `json:"user"`
+	Host             string      `json:"host"`
+	BBLVersion       string      `json:"bblVersion"`
+	TerraformVersion string      `json:"terraformVersion,omitempty"`
+	BOSHVersion      string      `json:"boshVersion,omitempty"`
+	StartedAt        time.Time   `json:"startedAt"`
+	FinishedAt       time.Time   `json:"finishedAt"`
+	Steps            []AuditStep `json:"steps,omitempty"`
+	Outcome          string      `json:"outcome"`
+	Error            string      `json:"error,omitempty"`
+}
+
+// AuditLog is the append-only log of the bbl commands run against the
+// environment in a state directory, one json entry per line.
+type AuditLog struct {
+	auditFile string
+}
+
+func NewAuditLog(dir string) AuditLog {
+	return AuditLog{
+		auditFile: filepath.Join(dir, AuditFileName),
+	}
+}
+
+func (a AuditLog) Append(entry AuditEntry) error {
+	contents, err := json.Marshal(entry)
+	if err != nil {
+		return err //not tested
+	}
+
+	file, err := os.OpenFile(a.auditFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, OS_READ_WRITE_MODE)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(contents, '\n'))
+	return err
+}
+
+// Entries returns the entries of the log, oldest first.
+func (a AuditLog) Entries() ([]AuditEntry, error) {
+	file, err := os.Open(a.auditFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []AuditEntry{}, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := []AuditEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry AuditEntry
+		err := json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %s", AuditFileName, line, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
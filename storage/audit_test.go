@@ -0,0 +1,97 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuditLog", func() {
+	var (
+		dir      string
+		auditLog storage.AuditLog
+		entry    storage.AuditEntry
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		auditLog = storage.NewAuditLog(dir)
+
+		startedAt := time.Date(2017, 8, 1, 12, 0, 0, 0, time.UTC)
+		entry = storage.AuditEntry{
+			Command:    "up",
+			Flags:      []string{"--iaas", "gcp"},
+			User:       "some-user",
+			Host:       "some-host",
+			BBLVersion: "some-bbl-version",
+			StartedAt:  startedAt,
+			FinishedAt: startedAt.Add(time.Minute),
+			Steps:      []storage.AuditStep{{Name: "creating keypair", Duration: time.Second}},
+			Outcome:    "success",
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("returns the appended entries, oldest first", func() {
+		err := auditLog.Append(entry)
+		Expect(err).NotTo(HaveOccurred())
+
+		failed := entry
+		failed.Command = "destroy"
+		failed.Outcome = "failure"
+		failed.Error = "some-error"
+		err = auditLog.Append(failed)
+		Expect(err).NotTo(HaveOccurred())
+
+		entries, err := auditLog.Entries()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(Equal([]storage.AuditEntry{entry, failed}))
+	})
+
+	It("writes one json entry per line", func() {
+		err := auditLog.Append(entry)
+		Expect(err).NotTo(HaveOccurred())
+		err = auditLog.Append(entry)
+		Expect(err).NotTo(HaveOccurred())
+
+		contents, err := ioutil.ReadFile(filepath.Join(dir, storage.AuditFileName))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(HavePrefix(`{"command":"up","flags":["--iaas","gcp"]`))
+		Expect(string(contents)).To(HaveSuffix("}\n"))
+	})
+
+	It("returns no entries when nothing was recorded", func() {
+		entries, err := auditLog.Entries()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when a line is not valid json", func() {
+			err := ioutil.WriteFile(filepath.Join(dir, storage.AuditFileName), []byte("{}\n%%%\n"), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = auditLog.Entries()
+			Expect(err).To(MatchError(ContainSubstring("bbl-audit.jsonl line 2: invalid character")))
+		})
+
+		It("returns an error when the log cannot be written", func() {
+			auditLog = storage.NewAuditLog(filepath.Join(dir, "missing"))
+
+			err := auditLog.Append(entry)
+			Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+		})
+	})
+})
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const ConfigFileName = "bbl.yml"
+
+// Secret is a credential in bbl.yml. It is given by reference, either as the
+// name of an environment variable or as the path of a file, so that the
+// config file can be checked in without the credential itself.
+type Secret struct {
+	Env  string `yaml:"env"`
+	File string `yaml:"file"`
+}
+
+type ConfigFileAWS struct {
+	Region          string `yaml:"region"`
+	AccessKeyID     Secret `yaml:"accessKeyID"`
+	SecretAccessKey Secret `yaml:"secretAccessKey"`
+	Profile         string `yaml:"profile"`
+	CredentialChain bool   `yaml:"credentialChain"`
+	AssumeRoleARN   string `yaml:"assumeRoleARN"`
+	ExternalID      string `yaml:"externalID"`
+	SessionName     string `yaml:"sessionName"`
+}
+
+type ConfigFileGCP struct {
+	ProjectID         string `yaml:"projectID"`
+	Region            string `yaml:"region"`
+	Zone              string `yaml:"zone"`
+	ServiceAccountKey Secret `yaml:"serviceAccountKey"`
+
+	ApplicationDefaultCredentials bool   `yaml:"applicationDefaultCredentials"`
+	ImpersonateServiceAccount     string `yaml:"impersonateServiceAccount"`
+	DirectorServiceAccount        bool   `yaml:"directorServiceAccount"`
+}
+
+type ConfigFileLB struct {
+	Type   string `yaml:"type"`
+	Cert   string `yaml:"cert"`
+	Key    string `yaml:"key"`
+	Chain  string `yaml:"chain"`
+	Domain string `yaml:"domain"`
+}
+
+// ConfigFile is the declarative description of an environment read from
+// bbl.yml. Its values are the defaults for the up and create-lbs flags.
+type ConfigFile struct {
+	IAAS               string        `yaml:"iaas"`
+	Name               string        `yaml:"name"`
+	OpsFile            string        `yaml:"opsFile"`
+	CloudConfigOpsFile string        `yaml:"cloudConfigOpsFile"`
+	Jumpbox            bool          `yaml:"jumpbox"`
+	AWS                ConfigFileAWS `yaml:"aws"`
+	GCP                ConfigFileGCP `yaml:"gcp"`
+	LB                 ConfigFileLB  `yaml:"lb"`
+
+	// Path is the config file the values were read from, or empty when
+	// there is no config file.
+	Path string `yaml:"-"`
+}
+
+type ConfigFileLoader struct {
+	dir string
+}
+
+func NewConfigFileLoader(dir string) ConfigFileLoader {
+	return ConfigFileLoader{
+		dir: dir,
+	}
+}
+
+// Load reads the config file at path, or the bbl.yml in the state directory
+// when path is empty. A missing bbl.yml in the state directory is not an
+// error and loads an empty config file. Relative paths in the config file
+// are relative to the directory it is in.
+func (c ConfigFileLoader) Load(path string) (ConfigFile, error) {
+	if path == "" {
+		path = filepath.Join(c.dir, ConfigFileName)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return ConfigFile{}, nil
+		}
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ConfigFile{}, fmt.Errorf("reading config file: %s", err)
+	}
+
+	var configFile ConfigFile
+	err = yaml.Unmarshal(contents, &configFile)
+	if err != nil {
+		return ConfigFile{}, fmt.Errorf("parsing config file %s: %s", path, err)
+	}
+
+	configFile.Path = path
+
+	dir := filepath.Dir(path)
+	for _, filePath := range []*string{
+		&configFile.OpsFile,
+		&configFile.CloudConfigOpsFile,
+		&configFile.LB.Cert,
+		&configFile.LB.Key,
+		&configFile.LB.Chain,
+	} {
+		*filePath = relativeTo(dir, *filePath)
+	}
+
+	for _, secret := range []*Secret{
+		&configFile.AWS.AccessKeyID,
+		&configFile.AWS.SecretAccessKey,
+		&configFile.GCP.ServiceAccountKey,
+	} {
+		secret.File = relativeTo(dir, secret.File)
+	}
+
+	return configFile, nil
+}
+
+// Resolve returns the credential the secret refers to, or an empty string
+// when the secret refers to nothing. Credentials are only resolved when
+// they are used, so that a flag or an environment variable can stand in for
+// a credential that is not available. name is the key of the secret in the
+// config file, for error messages.
+func (s Secret) Resolve(name string) (string, error) {
+	switch {
+	case s.Env != "" && s.File != "":
+		return "", fmt.Errorf("%s must refer to either an env var or a file, not both", name)
+	case s.Env != "":
+		value := os.Getenv(s.Env)
+		if value == "" {
+			return "", fmt.Errorf("%s refers to env var %s, which is not set", name, s.Env)
+		}
+		return value, nil
+	case s.File != "":
+		contents, err := ioutil.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("%s refers to a file that cannot be read: %s", name, err)
+		}
+		return strings.TrimSpace(string(contents)), nil
+	}
+
+	return "", nil
+}
+
+func relativeTo(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
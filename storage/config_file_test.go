@@ -0,0 +1,179 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConfigFileLoader", func() {
+	var (
+		stateDir  string
+		configDir string
+		loader    storage.ConfigFileLoader
+	)
+
+	BeforeEach(func() {
+		var err error
+		stateDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		configDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		loader = storage.NewConfigFileLoader(stateDir)
+
+		os.Setenv("BBL_CONFIG_TEST_ACCESS_KEY_ID", "some-access-key-id")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(stateDir)
+		os.RemoveAll(configDir)
+		os.Unsetenv("BBL_CONFIG_TEST_ACCESS_KEY_ID")
+	})
+
+	writeConfigFile := func(dir, contents string) string {
+		path := filepath.Join(dir, "bbl.yml")
+		err := ioutil.WriteFile(path, []byte(contents), os.ModePerm)
+		Expect(err).NotTo(HaveOccurred())
+		return path
+	}
+
+	Describe("Load", func() {
+		It("loads the given config file and resolves its paths", func() {
+			path := writeConfigFile(configDir, `---
+iaas: aws
+name: some-name
+opsFile: ops.yml
+cloudConfigOpsFile: /some/cloud-config-ops.yml
+jumpbox: true
+aws:
+  region: some-region
+  accessKeyID:
+    env: BBL_CONFIG_TEST_ACCESS_KEY_ID
+  secretAccessKey:
+    file: secret-access-key
+lb:
+  type: cf
+  cert: cert.pem
+  key: key.pem
+  domain: some-domain
+`)
+
+			configFile, err := loader.Load(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configFile).To(Equal(storage.ConfigFile{
+				IAAS:               "aws",
+				Name:               "some-name",
+				OpsFile:            filepath.Join(configDir, "ops.yml"),
+				CloudConfigOpsFile: "/some/cloud-config-ops.yml",
+				Jumpbox:            true,
+				AWS: storage.ConfigFileAWS{
+					Region: "some-region",
+					AccessKeyID: storage.Secret{
+						Env: "BBL_CONFIG_TEST_ACCESS_KEY_ID",
+					},
+					SecretAccessKey: storage.Secret{
+						File: filepath.Join(configDir, "secret-access-key"),
+					},
+				},
+				LB: storage.ConfigFileLB{
+					Type:   "cf",
+					Cert:   filepath.Join(configDir, "cert.pem"),
+					Key:    filepath.Join(configDir, "key.pem"),
+					Domain: "some-domain",
+				},
+				Path: path,
+			}))
+		})
+
+		It("loads the bbl.yml in the state dir when no config file is given", func() {
+			path := writeConfigFile(stateDir, "iaas: gcp\n")
+
+			configFile, err := loader.Load("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configFile).To(Equal(storage.ConfigFile{
+				IAAS: "gcp",
+				Path: path,
+			}))
+		})
+
+		It("loads an empty config file when there is no bbl.yml in the state dir", func() {
+			configFile, err := loader.Load("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configFile).To(Equal(storage.ConfigFile{}))
+		})
+
+		It("does not read the credentials", func() {
+			path := writeConfigFile(configDir, "gcp:\n  serviceAccountKey:\n    file: missing.json\n")
+
+			configFile, err := loader.Load(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configFile.GCP.ServiceAccountKey).To(Equal(storage.Secret{
+				File: filepath.Join(configDir, "missing.json"),
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the given config file does not exist", func() {
+				_, err := loader.Load(filepath.Join(configDir, "missing.yml"))
+				Expect(err).To(MatchError(ContainSubstring("reading config file")))
+			})
+
+			It("returns an error when the config file is not valid yaml", func() {
+				path := writeConfigFile(configDir, "%%%")
+
+				_, err := loader.Load(path)
+				Expect(err).To(MatchError(ContainSubstring("parsing config file")))
+			})
+		})
+	})
+
+	Describe("Secret", func() {
+		Describe("Resolve", func() {
+			It("returns the value of the env var it refers to", func() {
+				value, err := storage.Secret{Env: "BBL_CONFIG_TEST_ACCESS_KEY_ID"}.Resolve("aws.accessKeyID")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(value).To(Equal("some-access-key-id"))
+			})
+
+			It("returns the contents of the file it refers to", func() {
+				path := filepath.Join(configDir, "secret-access-key")
+				err := ioutil.WriteFile(path, []byte("some-secret-access-key\n"), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+
+				value, err := storage.Secret{File: path}.Resolve("aws.secretAccessKey")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(value).To(Equal("some-secret-access-key"))
+			})
+
+			It("returns an empty value when it refers to nothing", func() {
+				value, err := storage.Secret{}.Resolve("aws.accessKeyID")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(value).To(BeEmpty())
+			})
+
+			Context("failure cases", func() {
+				It("returns an error when it refers to an env var that is not set", func() {
+					_, err := storage.Secret{Env: "BBL_CONFIG_TEST_MISSING"}.Resolve("gcp.serviceAccountKey")
+					Expect(err).To(MatchError("gcp.serviceAccountKey refers to env var BBL_CONFIG_TEST_MISSING, which is not set"))
+				})
+
+				It("returns an error when it refers to a file that cannot be read", func() {
+					_, err := storage.Secret{File: filepath.Join(configDir, "missing.json")}.Resolve("gcp.serviceAccountKey")
+					Expect(err).To(MatchError(ContainSubstring("gcp.serviceAccountKey refers to a file that cannot be read")))
+				})
+
+				It("returns an error when it refers to both an env var and a file", func() {
+					_, err := storage.Secret{Env: "SOME_ENV", File: "some-file"}.Resolve("aws.accessKeyID")
+					Expect(err).To(MatchError("aws.accessKeyID must refer to either an env var or a file, not both"))
+				})
+			})
+		})
+	})
+})
@@ -26,17 +26,30 @@ type logger interface {
 }
 
 type AWS struct {
-	AccessKeyID     string `json:"accessKeyId"`
-	SecretAccessKey string `json:"secretAccessKey"`
-	Region          string `json:"region"`
+	AccessKeyID     string   `json:"accessKeyId"`
+	SecretAccessKey string   `json:"secretAccessKey"`
+	Region          string   `json:"region"`
+	Profile         string   `json:"profile,omitempty"`
+	CredentialChain bool     `json:"credentialChain,omitempty"`
+	AssumeRoleARN   string   `json:"assumeRoleARN,omitempty"`
+	ExternalID      string   `json:"externalID,omitempty"`
+	SessionName     string   `json:"sessionName,omitempty"`
+	NATType         string   `json:"natType,omitempty"`
+	NATAMI          string   `json:"natAMI,omitempty"`
+	BOSHAZs         []string `json:"boshAZs,omitempty"`
+	DirectorAZ      string   `json:"directorAZ,omitempty"`
 }
 
 type GCP struct {
-	ServiceAccountKey string   `json:"serviceAccountKey"`
-	ProjectID         string   `json:"projectID"`
-	Zone              string   `json:"zone"`
-	Region            string   `json:"region"`
-	Zones             []string `json:"zones"`
+	ServiceAccountKey             string   `json:"serviceAccountKey"`
+	ProjectID                     string   `json:"projectID"`
+	Zone                          string   `json:"zone"`
+	Region                        string   `json:"region"`
+	Zones                         []string `json:"zones"`
+	InternalOnly                  bool     `json:"internalOnly,omitempty"`
+	ApplicationDefaultCredentials bool     `json:"applicationDefaultCredentials,omitempty"`
+	ImpersonateServiceAccount     string   `json:"impersonateServiceAccount,omitempty"`
+	DirectorServiceAccount        bool     `json:"directorServiceAccount,omitempty"`
 }
 
 type Stack struct {
@@ -62,21 +75,37 @@ type Jumpbox struct {
 	State     map[string]interface{} `json:"state"`
 }
 
+// UpStep records a step of bbl up that completed, and a hash of the inputs
+// it completed with.
+type UpStep struct {
+	Name      string `json:"name"`
+	InputHash string `json:"inputHash"`
+}
+
 type State struct {
-	Version                    int     `json:"version"`
-	IAAS                       string  `json:"iaas"`
-	NoDirector                 bool    `json:"noDirector"`
-	MigratedFromCloudFormation bool    `json:"migratedFromCloudFormation"`
-	AWS                        AWS     `json:"aws,omitempty"`
-	GCP                        GCP     `json:"gcp,omitempty"`
-	KeyPair                    KeyPair `json:"keyPair,omitempty"`
-	Jumpbox                    Jumpbox `json:"jumpbox,omitempty"`
-	BOSH                       BOSH    `json:"bosh,omitempty"`
-	Stack                      Stack   `json:"stack"`
-	EnvID                      string  `json:"envID"`
-	TFState                    string  `json:"tfState"`
-	LB                         LB      `json:"lb"`
-	LatestTFOutput             string  `json:"latestTFOutput"`
+	Version                    int               `json:"version"`
+	IAAS                       string            `json:"iaas"`
+	NoDirector                 bool              `json:"noDirector"`
+	MigratedFromCloudFormation bool              `json:"migratedFromCloudFormation"`
+	AWS                        AWS               `json:"aws,omitempty"`
+	GCP                        GCP               `json:"gcp,omitempty"`
+	KeyPair                    KeyPair           `json:"keyPair,omitempty"`
+	Jumpbox                    Jumpbox           `json:"jumpbox,omitempty"`
+	BOSH                       BOSH              `json:"bosh,omitempty"`
+	Stack                      Stack             `json:"stack"`
+	EnvID                      string            `json:"envID"`
+	TFState                    string            `json:"tfState"`
+	LB                         LB                `json:"lb"`
+	LatestTFOutput             string            `json:"latestTFOutput"`
+	Tags                       map[string]string `json:"tags,omitempty"`
+	CloudConfigOpsFile         string            `json:"cloudConfigOpsFile,omitempty"`
+	NoRuntimeConfig            bool              `json:"noRuntimeConfig,omitempty"`
+	CPIConfig                  bool              `json:"cpiConfig,omitempty"`
+	UploadStemcell             bool              `json:"uploadStemcell,omitempty"`
+	UAA                        bool              `json:"uaa,omitempty"`
+	CredHub                    bool              `json:"credhub,omitempty"`
+	UpJournal                  []UpStep          `json:"upJournal,omitempty"`
+	Protected                  bool              `json:"protected,omitempty"`
 }
 
 type Store struct {
@@ -120,6 +149,26 @@ func (s Store) Set(state State) error {
 	return nil
 }
 
+// Get reads the state that was last set.
+func (s Store) Get() (State, error) {
+	return GetState(filepath.Dir(s.stateFile))
+}
+
+// UsesStaticKeys reports whether the access keys in the state are the
+// credentials bbl uses, rather than credentials it resolves when it runs from
+// a profile, the default credential chain or an assumed role.
+func (a AWS) UsesStaticKeys() bool {
+	return a.Profile == "" && !a.CredentialChain && a.AssumeRoleARN == ""
+}
+
+// UsesServiceAccountKey reports whether the service account key in the state
+// is the credential bbl and the director use, rather than tokens bbl gets when
+// it runs from the application default credentials or an impersonated
+// service account.
+func (g GCP) UsesServiceAccountKey() bool {
+	return !g.ApplicationDefaultCredentials && g.ImpersonateServiceAccount == ""
+}
+
 func (g GCP) Empty() bool {
 	return g.ServiceAccountKey == "" && g.ProjectID == "" && g.Region == "" && g.Zone == ""
 }
@@ -264,6 +264,24 @@ var _ = Describe("Store", func() {
 		})
 	})
 
+	Describe("Get", func() {
+		It("returns the state that was last set", func() {
+			err := store.Set(storage.State{
+				IAAS:  "gcp",
+				EnvID: "some-env-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err := store.Get()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(storage.State{
+				Version: 8,
+				IAAS:    "gcp",
+				EnvID:   "some-env-id",
+			}))
+		})
+	})
+
 	Describe("GCP", func() {
 		Describe("Empty", func() {
 			It("returns true when all fields are blank", func() {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
+	"github.com/cloudfoundry/bosh-bootloader/terraform"
 )
 
 type InputGenerator struct {
@@ -37,6 +38,16 @@ func (i InputGenerator) Generate(state storage.State) (map[string]string, error)
 		return map[string]string{}, err
 	}
 
+	boshFailoverAZs := state.AWS.BOSHAZs
+	if boshFailoverAZs == nil {
+		boshFailoverAZs = []string{}
+	}
+
+	boshFailoverAZsString, err := jsonMarshal(boshFailoverAZs)
+	if err != nil {
+		return map[string]string{}, err
+	}
+
 	shortEnvID := state.EnvID
 	if len(shortEnvID) > terraformNameCharLimit {
 		sha1 := fmt.Sprintf("%x", sha1.Sum([]byte(state.EnvID)))
@@ -44,14 +55,28 @@ func (i InputGenerator) Generate(state storage.State) (map[string]string, error)
 	}
 
 	inputs := map[string]string{
-		"env_id":                 state.EnvID,
-		"short_env_id":           shortEnvID,
-		"nat_ssh_key_pair_name":  state.KeyPair.Name,
-		"access_key":             state.AWS.AccessKeyID,
-		"secret_key":             state.AWS.SecretAccessKey,
-		"region":                 state.AWS.Region,
-		"bosh_availability_zone": state.Stack.BOSHAZ,
-		"availability_zones":     string(azsString),
+		"env_id":                           state.EnvID,
+		"short_env_id":                     shortEnvID,
+		"nat_ssh_key_pair_name":            state.KeyPair.Name,
+		"access_key":                       state.AWS.AccessKeyID,
+		"secret_key":                       state.AWS.SecretAccessKey,
+		"region":                           state.AWS.Region,
+		"bosh_availability_zone":           state.Stack.BOSHAZ,
+		"availability_zones":               string(azsString),
+		"bosh_failover_availability_zones": string(boshFailoverAZsString),
+		"tags":                             terraform.MapVar(state.Tags),
+	}
+
+	// Credentials that are not static keys reach terraform through its
+	// environment, so the provider must not be given the keys.
+	if !state.AWS.UsesStaticKeys() {
+		inputs["access_key"] = ""
+		inputs["secret_key"] = ""
+	}
+
+	switch state.AWS.NATType {
+	case "", "instance":
+		inputs["nat_ami"] = state.AWS.NATAMI
 	}
 
 	if state.LB.Type == "cf" || state.LB.Type == "concourse" {
@@ -66,6 +66,7 @@ var _ = Describe("InputGenerator", func() {
 					AccessKeyID:     "some-access-key-id",
 					SecretAccessKey: "some-secret-access-key",
 					Region:          "some-region",
+					NATAMI:          "some-nat-ami",
 				},
 				KeyPair: storage.KeyPair{
 					Name: "some-key-pair-name",
@@ -83,16 +84,89 @@ var _ = Describe("InputGenerator", func() {
 			Expect(availabilityZoneRetriever.RetrieveCall.Receives.Region).To(Equal("some-region"))
 
 			Expect(inputs).To(Equal(map[string]string{
-				"env_id":                 "some-env-id",
-				"short_env_id":           "some-env-id",
-				"nat_ssh_key_pair_name":  "some-key-pair-name",
-				"access_key":             "some-access-key-id",
-				"secret_key":             "some-secret-access-key",
-				"region":                 "some-region",
-				"bosh_availability_zone": "some-zone",
-				"availability_zones":     `["z1","z2","z3"]`,
+				"env_id":                           "some-env-id",
+				"short_env_id":                     "some-env-id",
+				"nat_ssh_key_pair_name":            "some-key-pair-name",
+				"nat_ami":                          "some-nat-ami",
+				"access_key":                       "some-access-key-id",
+				"secret_key":                       "some-secret-access-key",
+				"region":                           "some-region",
+				"bosh_availability_zone":           "some-zone",
+				"availability_zones":               `["z1","z2","z3"]`,
+				"bosh_failover_availability_zones": `[]`,
+				"tags":                             "{}",
 			}))
 		})
+
+		Context("when a nat gateway is used", func() {
+			It("does not provide a nat ami", func() {
+				inputs, err := inputGenerator.Generate(storage.State{
+					IAAS:  "aws",
+					EnvID: "some-env-id",
+					AWS: storage.AWS{
+						Region:  "some-region",
+						NATType: "gateway",
+						NATAMI:  "some-nat-ami",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(inputs).NotTo(HaveKey("nat_ami"))
+			})
+		})
+
+		Context("when bosh failover azs exist", func() {
+			It("provides the failover azs", func() {
+				inputs, err := inputGenerator.Generate(storage.State{
+					IAAS:  "aws",
+					EnvID: "some-env-id",
+					AWS: storage.AWS{
+						Region:  "some-region",
+						BOSHAZs: []string{"z2", "z3"},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(inputs["bosh_failover_availability_zones"]).To(Equal(`["z2","z3"]`))
+			})
+		})
+
+		Context("when an assumed role is used", func() {
+			It("does not provide the access keys", func() {
+				inputs, err := inputGenerator.Generate(storage.State{
+					IAAS:  "aws",
+					EnvID: "some-env-id",
+					AWS: storage.AWS{
+						AccessKeyID:     "some-access-key-id",
+						SecretAccessKey: "some-secret-access-key",
+						AssumeRoleARN:   "some-role-arn",
+						Region:          "some-region",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(inputs["access_key"]).To(Equal(""))
+				Expect(inputs["secret_key"]).To(Equal(""))
+			})
+		})
+
+		Context("when tags exist", func() {
+			It("provides the tags as a terraform map", func() {
+				inputs, err := inputGenerator.Generate(storage.State{
+					IAAS:  "aws",
+					EnvID: "some-env-id",
+					AWS: storage.AWS{
+						Region: "some-region",
+					},
+					Tags: map[string]string{
+						"owner": "some-owner",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(inputs["tags"]).To(Equal(`{"owner" = "some-owner"}`))
+			})
+		})
 	})
 
 	Context("when a cf lb exists", func() {
@@ -133,16 +207,19 @@ var _ = Describe("InputGenerator", func() {
 			Expect(availabilityZoneRetriever.RetrieveCall.Receives.Region).To(Equal("some-region"))
 
 			Expect(inputs).To(Equal(map[string]string{
-				"env_id":                      "some-env-id",
-				"short_env_id":                "some-env-id",
-				"nat_ssh_key_pair_name":       "some-key-pair-name",
-				"access_key":                  "some-access-key-id",
-				"secret_key":                  "some-secret-access-key",
-				"region":                      "some-region",
-				"bosh_availability_zone":      "some-zone",
-				"availability_zones":          `["z1","z2","z3"]`,
-				"ssl_certificate_name_prefix": "",
-				"ssl_certificate_name":        "some-certificate-name",
+				"env_id":                           "some-env-id",
+				"short_env_id":                     "some-env-id",
+				"nat_ssh_key_pair_name":            "some-key-pair-name",
+				"nat_ami":                          "",
+				"access_key":                       "some-access-key-id",
+				"secret_key":                       "some-secret-access-key",
+				"region":                           "some-region",
+				"bosh_availability_zone":           "some-zone",
+				"availability_zones":               `["z1","z2","z3"]`,
+				"bosh_failover_availability_zones": `[]`,
+				"tags":                             "{}",
+				"ssl_certificate_name_prefix":      "",
+				"ssl_certificate_name":             "some-certificate-name",
 			}))
 		})
 
@@ -158,17 +235,20 @@ var _ = Describe("InputGenerator", func() {
 				Expect(availabilityZoneRetriever.RetrieveCall.Receives.Region).To(Equal("some-region"))
 
 				Expect(inputs).To(Equal(map[string]string{
-					"env_id":                      "some-env-id",
-					"short_env_id":                "some-env-id",
-					"nat_ssh_key_pair_name":       "some-key-pair-name",
-					"access_key":                  "some-access-key-id",
-					"secret_key":                  "some-secret-access-key",
-					"region":                      "some-region",
-					"bosh_availability_zone":      "some-zone",
-					"availability_zones":          `["z1","z2","z3"]`,
-					"ssl_certificate_name":        "some-certificate-name",
-					"ssl_certificate_name_prefix": "",
-					"system_domain":               "some-domain",
+					"env_id":                           "some-env-id",
+					"short_env_id":                     "some-env-id",
+					"nat_ssh_key_pair_name":            "some-key-pair-name",
+					"nat_ami":                          "",
+					"access_key":                       "some-access-key-id",
+					"secret_key":                       "some-secret-access-key",
+					"region":                           "some-region",
+					"bosh_availability_zone":           "some-zone",
+					"availability_zones":               `["z1","z2","z3"]`,
+					"bosh_failover_availability_zones": `[]`,
+					"tags":                             "{}",
+					"ssl_certificate_name":             "some-certificate-name",
+					"ssl_certificate_name_prefix":      "",
+					"system_domain":                    "some-domain",
 				}))
 			})
 		})
@@ -211,19 +291,22 @@ var _ = Describe("InputGenerator", func() {
 			Expect(availabilityZoneRetriever.RetrieveCall.Receives.Region).To(Equal("some-region"))
 
 			Expect(inputs).To(Equal(map[string]string{
-				"env_id":                      "some-env-id",
-				"short_env_id":                "some-env-id",
-				"nat_ssh_key_pair_name":       "some-key-pair-name",
-				"access_key":                  "some-access-key-id",
-				"secret_key":                  "some-secret-access-key",
-				"region":                      "some-region",
-				"bosh_availability_zone":      "some-zone",
-				"availability_zones":          `["z1","z2","z3"]`,
-				"ssl_certificate":             "some-cert",
-				"ssl_certificate_chain":       "some-chain",
-				"ssl_certificate_private_key": "some-key",
-				"ssl_certificate_name":        "",
-				"ssl_certificate_name_prefix": "some-env-id",
+				"env_id":                           "some-env-id",
+				"short_env_id":                     "some-env-id",
+				"nat_ssh_key_pair_name":            "some-key-pair-name",
+				"nat_ami":                          "",
+				"access_key":                       "some-access-key-id",
+				"secret_key":                       "some-secret-access-key",
+				"region":                           "some-region",
+				"bosh_availability_zone":           "some-zone",
+				"availability_zones":               `["z1","z2","z3"]`,
+				"bosh_failover_availability_zones": `[]`,
+				"tags":                             "{}",
+				"ssl_certificate":                  "some-cert",
+				"ssl_certificate_chain":            "some-chain",
+				"ssl_certificate_private_key":      "some-key",
+				"ssl_certificate_name":             "",
+				"ssl_certificate_name_prefix":      "some-env-id",
 			}))
 		})
 	})
@@ -2,28 +2,12 @@ package aws
 
 import (
 	"bytes"
-	"encoding/json"
 	"strings"
 	"text/template"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
-var AMIs = `{
-    "us-east-1":      "ami-68115b02",
-    "us-east-2":      "ami-6893b20d",
-    "us-gov-west-1":  "ami-c39610a2",
-    "us-west-1":      "ami-ef1a718f",
-    "us-west-2":      "ami-77a4b816",
-    "eu-west-1":      "ami-c0993ab3",
-    "eu-central-1":   "ami-0b322e67",
-    "ap-southeast-1": "ami-e2fc3f81",
-    "ap-southeast-2": "ami-e3217a80",
-    "ap-northeast-1": "ami-f885ae96",
-    "ap-northeast-2": "ami-4118d72f",
-    "sa-east-1":      "ami-8631b5ea"
-}`
-
 type TemplateGenerator struct{}
 
 type TemplateData struct {
@@ -40,7 +24,6 @@ type TemplateData struct {
 	TCPLBInternalDescription       string
 	SSLCertificateNameProperty     string
 	IgnoreSSLCertificateProperties string
-	AWSNATAMIs                     map[string]string
 }
 
 func NewTemplateGenerator() TemplateGenerator {
@@ -50,6 +33,15 @@ func NewTemplateGenerator() TemplateGenerator {
 func (tg TemplateGenerator) Generate(state storage.State) string {
 	t := BaseTemplate
 
+	switch state.AWS.NATType {
+	case "gateway":
+		t = strings.Join([]string{t, NATGatewayTemplate}, "\n")
+	case "gateway-per-az":
+		t = strings.Join([]string{t, NATGatewayPerAZTemplate}, "\n")
+	default:
+		t = strings.Join([]string{t, NATInstanceTemplate}, "\n")
+	}
+
 	switch state.LB.Type {
 	case "concourse":
 		t = strings.Join([]string{t, LBSubnetTemplate, ConcourseLBTemplate, SSLCertificateTemplate}, "\n")
@@ -61,13 +53,6 @@ func (tg TemplateGenerator) Generate(state storage.State) string {
 		}
 	}
 
-	var ami map[string]string
-
-	err := json.Unmarshal([]byte(AMIs), &ami)
-	if err != nil {
-		panic(err)
-	}
-
 	var templateData TemplateData
 	if state.MigratedFromCloudFormation {
 		templateData = TemplateData{
@@ -83,7 +68,6 @@ func (tg TemplateGenerator) Generate(state storage.State) string {
 			TCPLBDescription:             "CF TCP",
 			TCPLBInternalDescription:     "CF TCP Internal",
 			SSLCertificateNameProperty:   `name              = "${var.ssl_certificate_name}"`,
-			AWSNATAMIs:                   ami,
 		}
 	} else {
 		templateData = TemplateData{
@@ -99,7 +83,6 @@ func (tg TemplateGenerator) Generate(state storage.State) string {
 			TCPLBDescription:             "CF TCP",
 			TCPLBInternalDescription:     "CF TCP Internal",
 			SSLCertificateNameProperty:   `name_prefix       = "${var.ssl_certificate_name_prefix}"`,
-			AWSNATAMIs:                   ami,
 		}
 	}
 
@@ -108,7 +91,7 @@ func (tg TemplateGenerator) Generate(state storage.State) string {
 	}
 
 	tmpl := template.New("descriptions")
-	tmpl, err = tmpl.Parse(t)
+	tmpl, err := tmpl.Parse(t)
 	if err != nil {
 		panic(err)
 	}
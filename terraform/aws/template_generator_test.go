@@ -22,11 +22,14 @@ var _ = Describe("TemplateGenerator", func() {
 
 	Describe("Generate", func() {
 		DescribeTable("generates a terraform template for aws",
-			func(fixtureFilename, lbType, domain string) {
+			func(fixtureFilename, natType, lbType, domain string) {
 				expectedTemplate, err := ioutil.ReadFile(fixtureFilename)
 				Expect(err).NotTo(HaveOccurred())
 
 				template := templateGenerator.Generate(storage.State{
+					AWS: storage.AWS{
+						NATType: natType,
+					},
 					LB: storage.LB{
 						Type:   lbType,
 						Domain: domain,
@@ -35,10 +38,12 @@ var _ = Describe("TemplateGenerator", func() {
 
 				Expect(template).To(Equal(string(expectedTemplate)))
 			},
-			Entry("when no lb type is provided", "fixtures/template_no_lb.tf", "", ""),
-			Entry("when a concourse lb type is provided", "fixtures/template_concourse_lb.tf", "concourse", ""),
-			Entry("when a cf lb type is provided", "fixtures/template_cf_lb.tf", "cf", ""),
-			Entry("when a cf lb type is provided with a system domain", "fixtures/template_cf_lb_with_domain.tf", "cf", "some-domain"),
+			Entry("when no lb type is provided", "fixtures/template_no_lb.tf", "", "", ""),
+			Entry("when a concourse lb type is provided", "fixtures/template_concourse_lb.tf", "", "concourse", ""),
+			Entry("when a cf lb type is provided", "fixtures/template_cf_lb.tf", "", "cf", ""),
+			Entry("when a cf lb type is provided with a system domain", "fixtures/template_cf_lb_with_domain.tf", "", "cf", "some-domain"),
+			Entry("when a nat gateway is requested", "fixtures/template_nat_gateway.tf", "gateway", "", ""),
+			Entry("when a nat gateway per availability zone is requested", "fixtures/template_nat_gateway_per_az.tf", "gateway-per-az", "", ""),
 		)
 
 		Context("when migrated from CloudFormation", func() {
@@ -2,18 +2,32 @@ package terraform
 
 import (
 	"io"
+	"os"
 	"os/exec"
 )
 
 type Cmd struct {
-	stderr       io.Writer
-	outputBuffer io.Writer
+	stdout          io.Writer
+	stderr          io.Writer
+	outputBuffer    io.Writer
+	credentialsEnvs []credentialsEnv
 }
 
-func NewCmd(stderr, outputBuffer io.Writer) Cmd {
+// credentialsEnv provides the environment terraform authenticates to the
+// IaaS with, for credentials that are not kept in the state.
+type credentialsEnv interface {
+	CredentialsEnv() ([]string, error)
+}
+
+// NewCmd returns a Cmd that writes what terraform prints to the terminal to
+// stdout and stderr, and that runs terraform with the environment of each of
+// the credentialsEnvs.
+func NewCmd(stdout, stderr, outputBuffer io.Writer, credentialsEnvs ...credentialsEnv) Cmd {
 	return Cmd{
-		stderr:       stderr,
-		outputBuffer: outputBuffer,
+		stdout:          stdout,
+		stderr:          stderr,
+		outputBuffer:    outputBuffer,
+		credentialsEnvs: credentialsEnvs,
 	}
 }
 
@@ -21,6 +35,23 @@ func (c Cmd) Run(stdout io.Writer, workingDirectory string, args []string, debug
 	command := exec.Command("terraform", args...)
 	command.Dir = workingDirectory
 
+	var env []string
+	for _, credentialsEnv := range c.credentialsEnvs {
+		credentials, err := credentialsEnv.CredentialsEnv()
+		if err != nil {
+			return err
+		}
+		env = append(env, credentials...)
+	}
+	if len(env) > 0 {
+		command.Env = append(os.Environ(), env...)
+	}
+
+	// The executors pass os.Stdout for output that is meant for the terminal.
+	if stdout == os.Stdout {
+		stdout = c.stdout
+	}
+
 	if debug {
 		command.Stdout = io.MultiWriter(stdout, c.outputBuffer)
 		command.Stderr = io.MultiWriter(c.stderr, c.outputBuffer)
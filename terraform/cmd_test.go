@@ -3,6 +3,7 @@ package terraform_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
 	"github.com/cloudfoundry/bosh-bootloader/terraform"
 
 	. "github.com/onsi/ginkgo"
@@ -25,7 +27,9 @@ var _ = Describe("Run", func() {
 		stderr       *bytes.Buffer
 		outputBuffer *bytes.Buffer
 
-		cmd terraform.Cmd
+		cmd               terraform.Cmd
+		awsCredentialsEnv *fakes.CredentialsEnv
+		gcpCredentialsEnv *fakes.CredentialsEnv
 
 		fakeTerraformBackendServer *httptest.Server
 		pathToTerraform            string
@@ -53,7 +57,10 @@ var _ = Describe("Run", func() {
 		stderr = bytes.NewBuffer([]byte{})
 		outputBuffer = bytes.NewBuffer([]byte{})
 
-		cmd = terraform.NewCmd(stderr, outputBuffer)
+		awsCredentialsEnv = &fakes.CredentialsEnv{}
+		gcpCredentialsEnv = &fakes.CredentialsEnv{}
+
+		cmd = terraform.NewCmd(os.Stdout, stderr, outputBuffer, awsCredentialsEnv, gcpCredentialsEnv)
 
 		fakeTerraformBackendServer = httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
 			if getFastFailTerraform() {
@@ -120,7 +127,27 @@ var _ = Describe("Run", func() {
 		Expect(stdout).To(ContainSubstring("apply some-arg"))
 	})
 
+	It("runs terraform with the credentials env", func() {
+		awsCredentialsEnv.CredentialsEnvCall.Returns.Env = []string{"AWS_ACCESS_KEY_ID=some-session-access-key-id"}
+		gcpCredentialsEnv.CredentialsEnvCall.Returns.Env = []string{"GOOGLE_OAUTH_ACCESS_TOKEN=some-access-token"}
+
+		err := cmd.Run(stdout, "/tmp", []string{"apply", "some-arg"}, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(awsCredentialsEnv.CredentialsEnvCall.CallCount).To(Equal(1))
+		Expect(gcpCredentialsEnv.CredentialsEnvCall.CallCount).To(Equal(1))
+		Expect(stdout).To(ContainSubstring("aws access key id: some-session-access-key-id"))
+		Expect(stdout).To(ContainSubstring("google access token: some-access-token"))
+	})
+
 	Context("failure case", func() {
+		It("returns an error when the credentials env cannot be resolved", func() {
+			awsCredentialsEnv.CredentialsEnvCall.Returns.Error = errors.New("failed to assume role")
+
+			err := cmd.Run(stdout, "/tmp", []string{"apply", "some-arg"}, false)
+			Expect(err).To(MatchError("failed to assume role"))
+		})
+
 		BeforeEach(func() {
 			setFastFailTerraform(true)
 		})
@@ -8,9 +8,11 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"syscall"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
@@ -125,6 +127,49 @@ func (e Executor) Destroy(input map[string]string, template, prevTFState string)
 	return string(tfState), nil
 }
 
+// Plan refreshes the given terraform state against the live infrastructure
+// and reports whether applying the template would make any changes.
+func (e Executor) Plan(input map[string]string, template, prevTFState string) (bool, error) {
+	tempDir, err := tempDir("", "")
+	if err != nil {
+		return false, err
+	}
+
+	err = writeFile(filepath.Join(tempDir, "template.tf"), []byte(template), os.ModePerm)
+	if err != nil {
+		return false, err
+	}
+
+	if prevTFState != "" {
+		err = writeFile(filepath.Join(tempDir, "terraform.tfstate"), []byte(prevTFState), os.ModePerm)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = e.cmd.Run(os.Stdout, tempDir, []string{"init"}, e.debug)
+	if err != nil {
+		return false, err
+	}
+
+	args := []string{"plan", "-detailed-exitcode", "-input=false"}
+	for k, v := range input {
+		args = append(args, makeVar(k, v)...)
+	}
+	err = e.cmd.Run(os.Stdout, tempDir, args, e.debug)
+	if err != nil {
+		// -detailed-exitcode exits 2 when the plan succeeded with changes.
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.ExitStatus() == 2 {
+				return true, nil
+			}
+		}
+		return false, fmt.Errorf("failed to plan: %s", err)
+	}
+
+	return false, nil
+}
+
 func (e Executor) Import(input ImportInput) (string, error) {
 	tempDir, err := tempDir("", "")
 	if err != nil {
@@ -135,6 +180,12 @@ func (e Executor) Import(input ImportInput) (string, error) {
 	resourceName := strings.Split(input.TerraformAddr, ".")[1]
 	resourceName = strings.Split(resourceName, "[")[0]
 
+	creds := input.Creds
+	if !creds.UsesStaticKeys() {
+		creds.AccessKeyID = ""
+		creds.SecretAccessKey = ""
+	}
+
 	template := fmt.Sprintf(`
 provider "aws" {
 	region     = %q
@@ -143,7 +194,7 @@ provider "aws" {
 }
 
 resource %q %q {
-}`, input.Creds.Region, input.Creds.AccessKeyID, input.Creds.SecretAccessKey, resourceType, resourceName)
+}`, creds.Region, creds.AccessKeyID, creds.SecretAccessKey, resourceType, resourceName)
 
 	err = writeFile(filepath.Join(tempDir, "template.tf"), []byte(template), os.ModePerm)
 	if err != nil {
@@ -173,6 +224,37 @@ resource %q %q {
 	return string(tfStateContents), nil
 }
 
+// MoveState renames a resource in the terraform state, so that terraform keeps
+// the resource instead of replacing it when a template changes its address.
+func (e Executor) MoveState(tfState, from, to string) (string, error) {
+	tempDir, err := tempDir("", "")
+	if err != nil {
+		return "", err
+	}
+
+	err = writeFile(filepath.Join(tempDir, "terraform.tfstate"), []byte(tfState), os.ModePerm)
+	if err != nil {
+		return "", err
+	}
+
+	err = e.cmd.Run(os.Stdout, tempDir, []string{"init"}, e.debug)
+	if err != nil {
+		return "", err
+	}
+
+	err = e.cmd.Run(os.Stdout, tempDir, []string{"state", "mv", from, to}, e.debug)
+	if err != nil {
+		return "", fmt.Errorf("failed to move %s to %s: %s", from, to, err)
+	}
+
+	tfStateContents, err := readFile(filepath.Join(tempDir, "terraform.tfstate"))
+	if err != nil {
+		return "", err
+	}
+
+	return string(tfStateContents), nil
+}
+
 func (e Executor) Version() (string, error) {
 	buffer := bytes.NewBuffer([]byte{})
 	err := e.cmd.Run(buffer, "/tmp", []string{"version"}, true)
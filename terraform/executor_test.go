@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -369,6 +370,109 @@ var _ = Describe("Executor", func() {
 		})
 	})
 
+	Describe("Plan", func() {
+		It("writes the template and tf state to a temp dir", func() {
+			_, err := executor.Plan(input, "some-template", "some-tf-state")
+			Expect(err).NotTo(HaveOccurred())
+
+			templateContents, err := ioutil.ReadFile(filepath.Join(tempDir, "template.tf"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(templateContents)).To(Equal("some-template"))
+
+			tfStateContents, err := ioutil.ReadFile(filepath.Join(tempDir, "terraform.tfstate"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(tfStateContents)).To(Equal("some-tf-state"))
+		})
+
+		It("passes the correct args and dir to run command", func() {
+			_, err := executor.Plan(input, "some-template", "some-tf-state")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cmd.RunCall.Receives.WorkingDirectory).To(Equal(tempDir))
+			Expect(cmd.RunCall.Receives.Args).To(ConsistOf([]string{
+				"plan",
+				"-detailed-exitcode",
+				"-input=false",
+				"-var", "project_id=some-project-id",
+				"-var", "env_id=some-env-id",
+				"-var", "region=some-region",
+				"-var", "zone=some-zone",
+				"-var", "ssl_certificate=some/certificate/path",
+				"-var", "ssl_certificate_private_key=some/key/path",
+				"-var", "credentials=some/credentials/path",
+				"-var", "system_domain=some-domain",
+			}))
+			Expect(cmd.RunCall.Receives.Debug).To(BeTrue())
+		})
+
+		It("returns false when the plan has no changes", func() {
+			changed, err := executor.Plan(input, "some-template", "some-tf-state")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeFalse())
+		})
+
+		It("returns true when terraform exits with the changes present status", func() {
+			exitErr := exec.Command("sh", "-c", "exit 2").Run()
+			cmd.RunCall.Returns.Errors = []error{nil, exitErr}
+
+			changed, err := executor.Plan(input, "some-template", "some-tf-state")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeTrue())
+		})
+
+		Context("when an error occurs", func() {
+			It("returns an error when it fails to create a temp dir", func() {
+				terraform.SetTempDir(func(dir, prefix string) (string, error) {
+					return "", errors.New("failed to make temp dir")
+				})
+
+				_, err := executor.Plan(input, "some-template", "")
+				Expect(err).To(MatchError("failed to make temp dir"))
+			})
+
+			It("returns an error when it fails to write the template file", func() {
+				terraform.SetWriteFile(func(file string, data []byte, perm os.FileMode) error {
+					if strings.Contains(file, "template.tf") {
+						return errors.New("failed to write template file")
+					}
+
+					return nil
+				})
+
+				_, err := executor.Plan(input, "some-template", "")
+				Expect(err).To(MatchError("failed to write template file"))
+			})
+
+			It("returns an error when it fails to write the tfstate file", func() {
+				terraform.SetWriteFile(func(file string, data []byte, perm os.FileMode) error {
+					if strings.Contains(file, "terraform.tfstate") {
+						return errors.New("failed to write tf state file")
+					}
+
+					return nil
+				})
+
+				_, err := executor.Plan(input, "some-template", "some-tf-state")
+				Expect(err).To(MatchError("failed to write tf state file"))
+			})
+
+			It("returns an error when terraform init fails", func() {
+				cmd.RunCall.Returns.Errors = []error{errors.New("failed to initialize terraform")}
+
+				_, err := executor.Plan(input, "some-template", "")
+				Expect(err).To(MatchError("failed to initialize terraform"))
+			})
+
+			It("returns an error when terraform plan fails", func() {
+				exitErr := exec.Command("sh", "-c", "exit 1").Run()
+				cmd.RunCall.Returns.Errors = []error{nil, exitErr}
+
+				_, err := executor.Plan(input, "some-template", "")
+				Expect(err).To(MatchError("failed to plan: exit status 1"))
+			})
+		})
+	})
+
 	Describe("Import", func() {
 		var (
 			receivedTFState    string
@@ -566,6 +670,46 @@ resource "some-resource-type" "some-addr" {
 		})
 	})
 
+	Describe("MoveState", func() {
+		It("moves the resource in the terraform state and returns the new state", func() {
+			var receivedTFState string
+			cmd.RunCall.Stub = func(stdout io.Writer) {
+				fileContents, err := ioutil.ReadFile(filepath.Join(tempDir, "terraform.tfstate"))
+				Expect(err).NotTo(HaveOccurred())
+				receivedTFState = string(fileContents)
+
+				err = ioutil.WriteFile(filepath.Join(tempDir, "terraform.tfstate"), []byte("some-moved-tf-state"), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			terraform.ResetReadFile()
+
+			tfState, err := executor.MoveState("some-tf-state", "some-type.some-name", "some-type.some-names[0]")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(receivedTFState).To(Equal("some-tf-state"))
+			Expect(cmd.RunCall.Receives.WorkingDirectory).To(Equal(tempDir))
+			Expect(cmd.RunCall.Receives.Args).To(Equal([]string{"state", "mv", "some-type.some-name", "some-type.some-names[0]"}))
+			Expect(tfState).To(Equal("some-moved-tf-state"))
+		})
+
+		Context("when an error occurs", func() {
+			It("returns an error when it fails to create a temp dir", func() {
+				terraform.SetTempDir(func(dir, prefix string) (string, error) {
+					return "", errors.New("failed to make temp dir")
+				})
+				_, err := executor.MoveState("some-tf-state", "some-type.some-name", "some-type.some-names[0]")
+				Expect(err).To(MatchError("failed to make temp dir"))
+			})
+
+			It("returns an error when terraform state mv fails", func() {
+				cmd.RunCall.Returns.Errors = []error{nil, errors.New("failed to run terraform command")}
+
+				_, err := executor.MoveState("some-tf-state", "some-type.some-name", "some-type.some-names[0]")
+				Expect(err).To(MatchError("failed to move some-type.some-name to some-type.some-names[0]: failed to run terraform command"))
+			})
+		})
+	})
+
 	Describe("Output", func() {
 		It("returns an output from the terraform state", func() {
 			cmd.RunCall.Stub = func(stdout io.Writer) {
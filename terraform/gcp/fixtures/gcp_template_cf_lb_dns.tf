@@ -18,6 +18,11 @@ variable "credentials" {
 	type = "string"
 }
 
+variable "labels" {
+	type = "map"
+	default = {}
+}
+
 provider "google" {
 	credentials = "${file("${var.credentials}")}"
 	project = "${var.project_id}"
@@ -64,6 +69,7 @@ resource "google_compute_subnetwork" "bbl-subnet" {
 
 resource "google_compute_address" "bosh-external-ip" {
   name = "${var.env_id}-bosh-external-ip"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_firewall" "external" {
@@ -191,6 +197,7 @@ resource "google_compute_firewall" "firewall-cf" {
 
 resource "google_compute_global_address" "cf-address" {
   name = "${var.env_id}-cf"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_global_forwarding_rule" "cf-http-forwarding-rule" {
@@ -198,6 +205,7 @@ resource "google_compute_global_forwarding_rule" "cf-http-forwarding-rule" {
   ip_address = "${google_compute_global_address.cf-address.address}"
   target     = "${google_compute_target_http_proxy.cf-http-lb-proxy.self_link}"
   port_range = "80"
+  labels     = "${var.labels}"
 }
 
 resource "google_compute_global_forwarding_rule" "cf-https-forwarding-rule" {
@@ -205,6 +213,7 @@ resource "google_compute_global_forwarding_rule" "cf-https-forwarding-rule" {
   ip_address = "${google_compute_global_address.cf-address.address}"
   target     = "${google_compute_target_https_proxy.cf-https-lb-proxy.self_link}"
   port_range = "443"
+  labels     = "${var.labels}"
 }
 
 resource "google_compute_target_http_proxy" "cf-http-lb-proxy" {
@@ -262,6 +271,7 @@ output "ssh_proxy_target_pool" {
 
 resource "google_compute_address" "cf-ssh-proxy" {
   name = "${var.env_id}-cf-ssh-proxy"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_firewall" "cf-ssh-proxy" {
@@ -289,6 +299,7 @@ resource "google_compute_forwarding_rule" "cf-ssh-proxy" {
   port_range  = "2222"
   ip_protocol = "TCP"
   ip_address  = "${google_compute_address.cf-ssh-proxy.address}"
+  labels      = "${var.labels}"
 }
 
 output "tcp_router_target_pool" {
@@ -310,6 +321,7 @@ resource "google_compute_firewall" "cf-tcp-router" {
 
 resource "google_compute_address" "cf-tcp-router" {
   name = "${var.env_id}-cf-tcp-router"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_http_health_check" "cf-tcp-router" {
@@ -334,6 +346,7 @@ resource "google_compute_forwarding_rule" "cf-tcp-router" {
   port_range  = "1024-32768"
   ip_protocol = "TCP"
   ip_address  = "${google_compute_address.cf-tcp-router.address}"
+  labels      = "${var.labels}"
 }
 
 output "ws_target_pool" {
@@ -342,6 +355,7 @@ output "ws_target_pool" {
 
 resource "google_compute_address" "cf-ws" {
   name = "${var.env_id}-cf-ws"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_target_pool" "cf-ws" {
@@ -358,6 +372,7 @@ resource "google_compute_forwarding_rule" "cf-ws-https" {
   port_range  = "443"
   ip_protocol = "TCP"
   ip_address  = "${google_compute_address.cf-ws.address}"
+  labels      = "${var.labels}"
 }
 
 resource "google_compute_forwarding_rule" "cf-ws-http" {
@@ -366,6 +381,7 @@ resource "google_compute_forwarding_rule" "cf-ws-http" {
   port_range  = "80"
   ip_protocol = "TCP"
   ip_address  = "${google_compute_address.cf-ws.address}"
+  labels      = "${var.labels}"
 }
 
 resource "google_compute_instance_group" "router-lb-0" {
@@ -416,6 +432,7 @@ resource "google_dns_managed_zone" "env_dns_zone" {
   name        = "${var.env_id}-zone"
   dns_name    = "${var.system_domain}."
   description = "DNS zone for the ${var.env_id} environment"
+  labels      = "${var.labels}"
 }
 
 output "system_domain_dns_servers" {
@@ -18,6 +18,11 @@ variable "credentials" {
 	type = "string"
 }
 
+variable "labels" {
+	type = "map"
+	default = {}
+}
+
 provider "google" {
 	credentials = "${file("${var.credentials}")}"
 	project = "${var.project_id}"
@@ -64,6 +69,7 @@ resource "google_compute_subnetwork" "bbl-subnet" {
 
 resource "google_compute_address" "bosh-external-ip" {
   name = "${var.env_id}-bosh-external-ip"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_firewall" "external" {
@@ -168,6 +174,7 @@ resource "google_compute_firewall" "firewall-concourse" {
 
 resource "google_compute_address" "concourse-address" {
   name = "${var.env_id}-concourse"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_target_pool" "target-pool" {
@@ -182,6 +189,7 @@ resource "google_compute_forwarding_rule" "ssh-forwarding-rule" {
   port_range  = "2222"
   ip_protocol = "TCP"
   ip_address  = "${google_compute_address.concourse-address.address}"
+  labels      = "${var.labels}"
 }
 
 resource "google_compute_forwarding_rule" "https-forwarding-rule" {
@@ -190,4 +198,5 @@ resource "google_compute_forwarding_rule" "https-forwarding-rule" {
   port_range  = "443"
   ip_protocol = "TCP"
   ip_address  = "${google_compute_address.concourse-address.address}"
+  labels      = "${var.labels}"
 }
@@ -0,0 +1,208 @@
+variable "project_id" {
+	type = "string"
+}
+
+variable "region" {
+	type = "string"
+}
+
+variable "zone" {
+	type = "string"
+}
+
+variable "env_id" {
+	type = "string"
+}
+
+variable "credentials" {
+	type = "string"
+}
+
+variable "labels" {
+	type = "map"
+	default = {}
+}
+
+provider "google" {
+	credentials = "${file("${var.credentials}")}"
+	project = "${var.project_id}"
+	region = "${var.region}"
+}
+
+output "network_name" {
+    value = "${google_compute_network.bbl-network.name}"
+}
+
+output "subnetwork_name" {
+    value = "${google_compute_subnetwork.bbl-subnet.name}"
+}
+
+output "bosh_open_tag_name" {
+    value = "${google_compute_firewall.bosh-open.name}"
+}
+
+output "bosh_director_tag_name" {
+	value = "${google_compute_firewall.bosh-director.name}"
+}
+
+output "internal_tag_name" {
+    value = "${google_compute_firewall.internal.name}"
+}
+
+output "director_address" {
+	value = "https://10.0.0.6:25555"
+}
+
+resource "google_compute_network" "bbl-network" {
+  name		 = "${var.env_id}-network"
+}
+
+resource "google_compute_subnetwork" "bbl-subnet" {
+  name			= "${var.env_id}-subnet"
+  ip_cidr_range = "10.0.0.0/16"
+  network		= "${google_compute_network.bbl-network.self_link}"
+
+  private_ip_google_access = true
+}
+
+resource "google_compute_router" "nat-router" {
+  name    = "${var.env_id}-nat-router"
+  region  = "${var.region}"
+  network = "${google_compute_network.bbl-network.self_link}"
+}
+
+resource "google_compute_router_nat" "nat" {
+  name                               = "${var.env_id}-nat"
+  router                             = "${google_compute_router.nat-router.name}"
+  region                             = "${var.region}"
+  nat_ip_allocate_option             = "AUTO_ONLY"
+  source_subnetwork_ip_ranges_to_nat = "ALL_SUBNETWORKS_ALL_IP_RANGES"
+}
+
+resource "google_compute_firewall" "external" {
+  name    = "${var.env_id}-external"
+  network = "${google_compute_network.bbl-network.name}"
+
+  source_ranges = ["10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"]
+
+  allow {
+    ports = ["22", "6868", "25555"]
+    protocol = "tcp"
+  }
+
+  target_tags = ["${var.env_id}-bosh-open"]
+}
+
+resource "google_compute_firewall" "bosh-open" {
+  name    = "${var.env_id}-bosh-open"
+  network = "${google_compute_network.bbl-network.name}"
+
+  source_tags = ["${var.env_id}-bosh-open"]
+
+  allow {
+    ports = ["22", "6868", "8443", "25555"]
+    protocol = "tcp"
+  }
+
+  target_tags = ["${var.env_id}-bosh-director"]
+}
+
+resource "google_compute_firewall" "bosh-director" {
+  name    = "${var.env_id}-bosh-director"
+  network = "${google_compute_network.bbl-network.name}"
+
+  source_tags = ["${var.env_id}-bosh-director"]
+
+  allow {
+    protocol = "tcp"
+  }
+
+  target_tags = ["${var.env_id}-internal"]
+}
+
+resource "google_compute_firewall" "internal-to-director" {
+  name    = "${var.env_id}-internal-to-director"
+  network = "${google_compute_network.bbl-network.name}"
+
+  source_tags = ["${var.env_id}-internal"]
+
+  allow {
+    ports = ["4222", "25250", "25777"]
+    protocol = "tcp"
+  }
+
+  target_tags = ["${var.env_id}-bosh-director"]
+}
+
+resource "google_compute_firewall" "internal" {
+  name    = "${var.env_id}-internal"
+  network = "${google_compute_network.bbl-network.name}"
+
+  source_tags = ["${var.env_id}-internal"]
+
+  allow {
+    protocol = "icmp"
+  }
+
+  allow {
+    protocol = "tcp"
+  }
+
+  allow {
+    protocol = "udp"
+  }
+
+  target_tags = ["${var.env_id}-internal"]
+}
+
+output "concourse_backend_service" {
+	value = "${google_compute_region_backend_service.concourse.name}"
+}
+
+output "concourse_lb_ip" {
+    value = "${google_compute_forwarding_rule.concourse.ip_address}"
+}
+
+resource "google_compute_firewall" "firewall-concourse" {
+  name    = "${var.env_id}-concourse-open"
+  network = "${google_compute_network.bbl-network.name}"
+
+  source_ranges = ["10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "35.191.0.0/16", "130.211.0.0/22"]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["443", "2222"]
+  }
+
+  target_tags = ["concourse"]
+}
+
+resource "google_compute_health_check" "concourse" {
+  name = "${var.env_id}-concourse"
+
+  tcp_health_check {
+    port = 443
+  }
+}
+
+resource "google_compute_region_backend_service" "concourse" {
+  name          = "${var.env_id}-concourse"
+  region        = "${var.region}"
+  protocol      = "TCP"
+  health_checks = ["${google_compute_health_check.concourse.self_link}"]
+
+  lifecycle {
+    ignore_changes = ["backend"]
+  }
+}
+
+resource "google_compute_forwarding_rule" "concourse" {
+  name                  = "${var.env_id}-concourse"
+  region                = "${var.region}"
+  load_balancing_scheme = "INTERNAL"
+  backend_service       = "${google_compute_region_backend_service.concourse.self_link}"
+  ports                 = ["443", "2222"]
+  network               = "${google_compute_network.bbl-network.self_link}"
+  subnetwork            = "${google_compute_subnetwork.bbl-subnet.self_link}"
+  labels                = "${var.labels}"
+}
@@ -20,6 +20,11 @@ variable "credentials" {
 	type = "string"
 }
 
+variable "labels" {
+	type = "map"
+	default = {}
+}
+
 provider "google" {
 	credentials = "${file("${var.credentials}")}"
 	project = "${var.project_id}"
@@ -67,6 +72,7 @@ resource "google_compute_subnetwork" "bbl-subnet" {
 
 resource "google_compute_address" "bosh-external-ip" {
   name = "${var.env_id}-bosh-external-ip"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_firewall" "external" {
@@ -150,6 +156,165 @@ output "jumpbox_url" {
 }
 `
 
+const DirectorServiceAccountTemplate = `variable "director_service_account_id" {
+	type = "string"
+}
+
+variable "director_service_account_roles" {
+	type = "list"
+	default = [
+		"roles/compute.instanceAdmin.v1",
+		"roles/compute.storageAdmin",
+		"roles/compute.networkUser",
+		"roles/compute.loadBalancerAdmin",
+		"roles/iam.serviceAccountUser",
+	]
+}
+
+resource "google_service_account" "bosh-director" {
+  account_id   = "${var.director_service_account_id}"
+  display_name = "${var.env_id} BOSH director"
+}
+
+resource "google_project_iam_member" "bosh-director" {
+  count   = "${length(var.director_service_account_roles)}"
+  project = "${var.project_id}"
+  role    = "${element(var.director_service_account_roles, count.index)}"
+  member  = "serviceAccount:${google_service_account.bosh-director.email}"
+}
+
+output "director_service_account_email" {
+  value = "${google_service_account.bosh-director.email}"
+}
+`
+
+const BOSHDirectorInternalTemplate = `output "network_name" {
+    value = "${google_compute_network.bbl-network.name}"
+}
+
+output "subnetwork_name" {
+    value = "${google_compute_subnetwork.bbl-subnet.name}"
+}
+
+output "bosh_open_tag_name" {
+    value = "${google_compute_firewall.bosh-open.name}"
+}
+
+output "bosh_director_tag_name" {
+	value = "${google_compute_firewall.bosh-director.name}"
+}
+
+output "internal_tag_name" {
+    value = "${google_compute_firewall.internal.name}"
+}
+
+output "director_address" {
+	value = "https://10.0.0.6:25555"
+}
+
+resource "google_compute_network" "bbl-network" {
+  name		 = "${var.env_id}-network"
+}
+
+resource "google_compute_subnetwork" "bbl-subnet" {
+  name			= "${var.env_id}-subnet"
+  ip_cidr_range = "10.0.0.0/16"
+  network		= "${google_compute_network.bbl-network.self_link}"
+
+  private_ip_google_access = true
+}
+
+resource "google_compute_router" "nat-router" {
+  name    = "${var.env_id}-nat-router"
+  region  = "${var.region}"
+  network = "${google_compute_network.bbl-network.self_link}"
+}
+
+resource "google_compute_router_nat" "nat" {
+  name                               = "${var.env_id}-nat"
+  router                             = "${google_compute_router.nat-router.name}"
+  region                             = "${var.region}"
+  nat_ip_allocate_option             = "AUTO_ONLY"
+  source_subnetwork_ip_ranges_to_nat = "ALL_SUBNETWORKS_ALL_IP_RANGES"
+}
+
+resource "google_compute_firewall" "external" {
+  name    = "${var.env_id}-external"
+  network = "${google_compute_network.bbl-network.name}"
+
+  source_ranges = ["10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"]
+
+  allow {
+    ports = ["22", "6868", "25555"]
+    protocol = "tcp"
+  }
+
+  target_tags = ["${var.env_id}-bosh-open"]
+}
+
+resource "google_compute_firewall" "bosh-open" {
+  name    = "${var.env_id}-bosh-open"
+  network = "${google_compute_network.bbl-network.name}"
+
+  source_tags = ["${var.env_id}-bosh-open"]
+
+  allow {
+    ports = ["22", "6868", "8443", "25555"]
+    protocol = "tcp"
+  }
+
+  target_tags = ["${var.env_id}-bosh-director"]
+}
+
+resource "google_compute_firewall" "bosh-director" {
+  name    = "${var.env_id}-bosh-director"
+  network = "${google_compute_network.bbl-network.name}"
+
+  source_tags = ["${var.env_id}-bosh-director"]
+
+  allow {
+    protocol = "tcp"
+  }
+
+  target_tags = ["${var.env_id}-internal"]
+}
+
+resource "google_compute_firewall" "internal-to-director" {
+  name    = "${var.env_id}-internal-to-director"
+  network = "${google_compute_network.bbl-network.name}"
+
+  source_tags = ["${var.env_id}-internal"]
+
+  allow {
+    ports = ["4222", "25250", "25777"]
+    protocol = "tcp"
+  }
+
+  target_tags = ["${var.env_id}-bosh-director"]
+}
+
+resource "google_compute_firewall" "internal" {
+  name    = "${var.env_id}-internal"
+  network = "${google_compute_network.bbl-network.name}"
+
+  source_tags = ["${var.env_id}-internal"]
+
+  allow {
+    protocol = "icmp"
+  }
+
+  allow {
+    protocol = "tcp"
+  }
+
+  allow {
+    protocol = "udp"
+  }
+
+  target_tags = ["${var.env_id}-internal"]
+}
+`
+
 const ConcourseLBTemplate = `output "concourse_target_pool" {
 	value = "${google_compute_target_pool.target-pool.name}"
 }
@@ -172,6 +337,7 @@ resource "google_compute_firewall" "firewall-concourse" {
 
 resource "google_compute_address" "concourse-address" {
   name = "${var.env_id}-concourse"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_target_pool" "target-pool" {
@@ -186,6 +352,7 @@ resource "google_compute_forwarding_rule" "ssh-forwarding-rule" {
   port_range  = "2222"
   ip_protocol = "TCP"
   ip_address  = "${google_compute_address.concourse-address.address}"
+  labels      = "${var.labels}"
 }
 
 resource "google_compute_forwarding_rule" "https-forwarding-rule" {
@@ -194,6 +361,60 @@ resource "google_compute_forwarding_rule" "https-forwarding-rule" {
   port_range  = "443"
   ip_protocol = "TCP"
   ip_address  = "${google_compute_address.concourse-address.address}"
+  labels      = "${var.labels}"
+}
+`
+
+const ConcourseInternalLBTemplate = `output "concourse_backend_service" {
+	value = "${google_compute_region_backend_service.concourse.name}"
+}
+
+output "concourse_lb_ip" {
+    value = "${google_compute_forwarding_rule.concourse.ip_address}"
+}
+
+resource "google_compute_firewall" "firewall-concourse" {
+  name    = "${var.env_id}-concourse-open"
+  network = "${google_compute_network.bbl-network.name}"
+
+  source_ranges = ["10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "35.191.0.0/16", "130.211.0.0/22"]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["443", "2222"]
+  }
+
+  target_tags = ["concourse"]
+}
+
+resource "google_compute_health_check" "concourse" {
+  name = "${var.env_id}-concourse"
+
+  tcp_health_check {
+    port = 443
+  }
+}
+
+resource "google_compute_region_backend_service" "concourse" {
+  name          = "${var.env_id}-concourse"
+  region        = "${var.region}"
+  protocol      = "TCP"
+  health_checks = ["${google_compute_health_check.concourse.self_link}"]
+
+  lifecycle {
+    ignore_changes = ["backend"]
+  }
+}
+
+resource "google_compute_forwarding_rule" "concourse" {
+  name                  = "${var.env_id}-concourse"
+  region                = "${var.region}"
+  load_balancing_scheme = "INTERNAL"
+  backend_service       = "${google_compute_region_backend_service.concourse.self_link}"
+  ports                 = ["443", "2222"]
+  network               = "${google_compute_network.bbl-network.self_link}"
+  subnetwork            = "${google_compute_subnetwork.bbl-subnet.self_link}"
+  labels                = "${var.labels}"
 }
 `
 
@@ -242,6 +463,7 @@ resource "google_compute_firewall" "firewall-cf" {
 
 resource "google_compute_global_address" "cf-address" {
   name = "${var.env_id}-cf"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_global_forwarding_rule" "cf-http-forwarding-rule" {
@@ -249,6 +471,7 @@ resource "google_compute_global_forwarding_rule" "cf-http-forwarding-rule" {
   ip_address = "${google_compute_global_address.cf-address.address}"
   target     = "${google_compute_target_http_proxy.cf-http-lb-proxy.self_link}"
   port_range = "80"
+  labels     = "${var.labels}"
 }
 
 resource "google_compute_global_forwarding_rule" "cf-https-forwarding-rule" {
@@ -256,6 +479,7 @@ resource "google_compute_global_forwarding_rule" "cf-https-forwarding-rule" {
   ip_address = "${google_compute_global_address.cf-address.address}"
   target     = "${google_compute_target_https_proxy.cf-https-lb-proxy.self_link}"
   port_range = "443"
+  labels     = "${var.labels}"
 }
 
 resource "google_compute_target_http_proxy" "cf-http-lb-proxy" {
@@ -313,6 +537,7 @@ output "ssh_proxy_target_pool" {
 
 resource "google_compute_address" "cf-ssh-proxy" {
   name = "${var.env_id}-cf-ssh-proxy"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_firewall" "cf-ssh-proxy" {
@@ -340,6 +565,7 @@ resource "google_compute_forwarding_rule" "cf-ssh-proxy" {
   port_range  = "2222"
   ip_protocol = "TCP"
   ip_address  = "${google_compute_address.cf-ssh-proxy.address}"
+  labels      = "${var.labels}"
 }
 
 output "tcp_router_target_pool" {
@@ -361,6 +587,7 @@ resource "google_compute_firewall" "cf-tcp-router" {
 
 resource "google_compute_address" "cf-tcp-router" {
   name = "${var.env_id}-cf-tcp-router"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_http_health_check" "cf-tcp-router" {
@@ -385,6 +612,7 @@ resource "google_compute_forwarding_rule" "cf-tcp-router" {
   port_range  = "1024-32768"
   ip_protocol = "TCP"
   ip_address  = "${google_compute_address.cf-tcp-router.address}"
+  labels      = "${var.labels}"
 }
 
 output "ws_target_pool" {
@@ -393,6 +621,7 @@ output "ws_target_pool" {
 
 resource "google_compute_address" "cf-ws" {
   name = "${var.env_id}-cf-ws"
+  labels = "${var.labels}"
 }
 
 resource "google_compute_target_pool" "cf-ws" {
@@ -409,6 +638,7 @@ resource "google_compute_forwarding_rule" "cf-ws-https" {
   port_range  = "443"
   ip_protocol = "TCP"
   ip_address  = "${google_compute_address.cf-ws.address}"
+  labels      = "${var.labels}"
 }
 
 resource "google_compute_forwarding_rule" "cf-ws-http" {
@@ -417,6 +647,115 @@ resource "google_compute_forwarding_rule" "cf-ws-http" {
   port_range  = "80"
   ip_protocol = "TCP"
   ip_address  = "${google_compute_address.cf-ws.address}"
+  labels      = "${var.labels}"
+}
+`
+
+const CFInternalLBTemplate = `output "router_backend_service" {
+  value = "${google_compute_region_backend_service.cf-router.name}"
+}
+
+output "router_lb_ip" {
+    value = "${google_compute_forwarding_rule.cf-router.ip_address}"
+}
+
+output "ssh_proxy_backend_service" {
+  value = "${google_compute_region_backend_service.cf-ssh-proxy.name}"
+}
+
+output "ssh_proxy_lb_ip" {
+    value = "${google_compute_forwarding_rule.cf-ssh-proxy.ip_address}"
+}
+
+resource "google_compute_firewall" "firewall-cf" {
+  name       = "${var.env_id}-cf-open"
+  depends_on = ["google_compute_network.bbl-network"]
+  network    = "${google_compute_network.bbl-network.name}"
+
+  allow {
+    protocol = "tcp"
+    ports    = ["80", "443", "8080"]
+  }
+
+  source_ranges = ["10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "35.191.0.0/16", "130.211.0.0/22"]
+
+  target_tags = ["${google_compute_region_backend_service.cf-router.name}"]
+}
+
+resource "google_compute_health_check" "cf-router" {
+  name = "${var.env_id}-cf-router"
+
+  http_health_check {
+    port         = 8080
+    request_path = "/health"
+  }
+}
+
+resource "google_compute_region_backend_service" "cf-router" {
+  name          = "${var.env_id}-cf-router"
+  region        = "${var.region}"
+  protocol      = "TCP"
+  health_checks = ["${google_compute_health_check.cf-router.self_link}"]
+
+  lifecycle {
+    ignore_changes = ["backend"]
+  }
+}
+
+resource "google_compute_forwarding_rule" "cf-router" {
+  name                  = "${var.env_id}-cf-router"
+  region                = "${var.region}"
+  load_balancing_scheme = "INTERNAL"
+  backend_service       = "${google_compute_region_backend_service.cf-router.self_link}"
+  ports                 = ["80", "443"]
+  network               = "${google_compute_network.bbl-network.self_link}"
+  subnetwork            = "${google_compute_subnetwork.bbl-subnet.self_link}"
+  labels                = "${var.labels}"
+}
+
+resource "google_compute_firewall" "cf-ssh-proxy" {
+  name       = "${var.env_id}-cf-ssh-proxy-open"
+  depends_on = ["google_compute_network.bbl-network"]
+  network    = "${google_compute_network.bbl-network.name}"
+
+  allow {
+    protocol = "tcp"
+    ports    = ["2222"]
+  }
+
+  source_ranges = ["10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "35.191.0.0/16", "130.211.0.0/22"]
+
+  target_tags = ["${google_compute_region_backend_service.cf-ssh-proxy.name}"]
+}
+
+resource "google_compute_health_check" "cf-ssh-proxy" {
+  name = "${var.env_id}-cf-ssh-proxy"
+
+  tcp_health_check {
+    port = 2222
+  }
+}
+
+resource "google_compute_region_backend_service" "cf-ssh-proxy" {
+  name          = "${var.env_id}-cf-ssh-proxy"
+  region        = "${var.region}"
+  protocol      = "TCP"
+  health_checks = ["${google_compute_health_check.cf-ssh-proxy.self_link}"]
+
+  lifecycle {
+    ignore_changes = ["backend"]
+  }
+}
+
+resource "google_compute_forwarding_rule" "cf-ssh-proxy" {
+  name                  = "${var.env_id}-cf-ssh-proxy"
+  region                = "${var.region}"
+  load_balancing_scheme = "INTERNAL"
+  backend_service       = "${google_compute_region_backend_service.cf-ssh-proxy.self_link}"
+  ports                 = ["2222"]
+  network               = "${google_compute_network.bbl-network.self_link}"
+  subnetwork            = "${google_compute_subnetwork.bbl-subnet.self_link}"
+  labels                = "${var.labels}"
 }
 `
 
@@ -428,6 +767,7 @@ resource "google_dns_managed_zone" "env_dns_zone" {
   name        = "${var.env_id}-zone"
   dns_name    = "${var.system_domain}."
   description = "DNS zone for the ${var.env_id} environment"
+  labels      = "${var.labels}"
 }
 
 output "system_domain_dns_servers" {
@@ -1,13 +1,20 @@
 package gcp
 
 import (
+	"crypto/sha1"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
+	"github.com/cloudfoundry/bosh-bootloader/terraform"
 )
 
+// serviceAccountIDCharLimit is the longest account id GCP allows for a
+// service account.
+const serviceAccountIDCharLimit = 30
+
 var tempDir func(dir, prefix string) (string, error) = ioutil.TempDir
 var writeFile func(file string, data []byte, perm os.FileMode) error = ioutil.WriteFile
 
@@ -24,8 +31,15 @@ func (i InputGenerator) Generate(state storage.State) (map[string]string, error)
 		return map[string]string{}, err
 	}
 
+	// Without a service account key the credentials file is empty, and
+	// terraform authenticates with the access token in its environment.
+	var serviceAccountKey string
+	if state.GCP.UsesServiceAccountKey() {
+		serviceAccountKey = state.GCP.ServiceAccountKey
+	}
+
 	credentialsPath := filepath.Join(dir, "credentials.json")
-	err = writeFile(credentialsPath, []byte(state.GCP.ServiceAccountKey), os.ModePerm)
+	err = writeFile(credentialsPath, []byte(serviceAccountKey), os.ModePerm)
 	if err != nil {
 		return map[string]string{}, err
 	}
@@ -37,6 +51,11 @@ func (i InputGenerator) Generate(state storage.State) (map[string]string, error)
 		"zone":          state.GCP.Zone,
 		"credentials":   credentialsPath,
 		"system_domain": state.LB.Domain,
+		"labels":        terraform.MapVar(state.Tags),
+	}
+
+	if state.GCP.DirectorServiceAccount {
+		input["director_service_account_id"] = directorServiceAccountID(state.EnvID)
 	}
 
 	if state.LB.Cert != "" && state.LB.Key != "" {
@@ -57,3 +76,17 @@ func (i InputGenerator) Generate(state storage.State) (map[string]string, error)
 
 	return input, nil
 }
+
+// directorServiceAccountID names the director's service account after the
+// env id, shortened with a hash of the env id when it would be too long.
+func directorServiceAccountID(envID string) string {
+	const suffix = "-director"
+
+	id := envID + suffix
+	if len(id) > serviceAccountIDCharLimit {
+		sum := fmt.Sprintf("%x", sha1.Sum([]byte(envID)))
+		id = fmt.Sprintf("%s-%s%s", envID[:serviceAccountIDCharLimit-len(suffix)-8], sum[:7], suffix)
+	}
+
+	return id
+}
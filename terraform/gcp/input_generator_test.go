@@ -65,6 +65,7 @@ var _ = Describe("InputGenerator", func() {
 			"zone":          state.GCP.Zone,
 			"credentials":   filepath.Join(tempDir, "credentials.json"),
 			"system_domain": state.LB.Domain,
+			"labels":        "{}",
 		}))
 
 		credentials, err := ioutil.ReadFile(inputs["credentials"])
@@ -72,6 +73,51 @@ var _ = Describe("InputGenerator", func() {
 		Expect(string(credentials)).To(Equal("some-service-account-key"))
 	})
 
+	Context("when the director has a service account", func() {
+		BeforeEach(func() {
+			state.GCP.DirectorServiceAccount = true
+		})
+
+		It("names the service account after the env id", func() {
+			inputs, err := inputGenerator.Generate(state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(inputs["director_service_account_id"]).To(Equal("some-env-id-director"))
+		})
+
+		It("shortens the name of the service account for a long env id", func() {
+			state.EnvID = "some-env-id-that-is-pretty-long"
+
+			inputs, err := inputGenerator.Generate(state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(inputs["director_service_account_id"]).To(Equal("some-env-id-t-1fc794e-director"))
+			Expect(len(inputs["director_service_account_id"])).To(BeNumerically("<=", 30))
+		})
+	})
+
+	It("writes an empty credentials file without a service account key", func() {
+		state.GCP.ImpersonateServiceAccount = "some-account@some-project.iam.gserviceaccount.com"
+
+		inputs, err := inputGenerator.Generate(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		credentials, err := ioutil.ReadFile(inputs["credentials"])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(credentials)).To(BeEmpty())
+	})
+
+	It("returns the tags as labels", func() {
+		state.Tags = map[string]string{
+			"owner": "some-owner",
+		}
+
+		inputs, err := inputGenerator.Generate(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(inputs["labels"]).To(Equal(`{"owner" = "some-owner"}`))
+	})
+
 	It("returns a map containing cert and key variables when cert/key are provided", func() {
 		state.LB.Cert = "some-cert"
 		state.LB.Key = "some-key"
@@ -88,6 +134,7 @@ var _ = Describe("InputGenerator", func() {
 			"ssl_certificate":             filepath.Join(tempDir, "cert"),
 			"ssl_certificate_private_key": filepath.Join(tempDir, "key"),
 			"system_domain":               state.LB.Domain,
+			"labels":                      "{}",
 		}))
 
 		sslCertificate, err := ioutil.ReadFile(inputs["ssl_certificate"])
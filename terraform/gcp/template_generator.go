@@ -25,8 +25,16 @@ func NewTemplateGenerator() TemplateGenerator {
 }
 
 func (t TemplateGenerator) Generate(state storage.State) string {
+	if state.GCP.InternalOnly {
+		return t.generateInternal(state)
+	}
+
 	template := strings.Join([]string{VarsTemplate, BOSHDirectorTemplate}, "\n")
 
+	if state.GCP.DirectorServiceAccount {
+		template = strings.Join([]string{template, DirectorServiceAccountTemplate}, "\n")
+	}
+
 	switch state.LB.Type {
 	case "concourse":
 		template = strings.Join([]string{template, ConcourseLBTemplate}, "\n")
@@ -43,6 +51,24 @@ func (t TemplateGenerator) Generate(state storage.State) string {
 	return template
 }
 
+// generateInternal builds a template without public IPs. Egress goes through
+// Cloud NAT and load balancers are regional and internal.
+func (t TemplateGenerator) generateInternal(state storage.State) string {
+	template := strings.Join([]string{VarsTemplate, BOSHDirectorInternalTemplate}, "\n")
+
+	if state.GCP.DirectorServiceAccount {
+		template = strings.Join([]string{template, DirectorServiceAccountTemplate}, "\n")
+	}
+
+	switch state.LB.Type {
+	case "concourse":
+		template = strings.Join([]string{template, ConcourseInternalLBTemplate}, "\n")
+	case "cf":
+		template = strings.Join([]string{template, CFInternalLBTemplate}, "\n")
+	}
+	return template
+}
+
 func (t TemplateGenerator) GenerateBackendService(zoneList []string) string {
 	var backends string
 	for i := 0; i < len(zoneList); i++ {
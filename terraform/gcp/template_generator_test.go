@@ -24,14 +24,15 @@ var _ = Describe("TemplateGenerator", func() {
 	})
 
 	Describe("Generate", func() {
-		DescribeTable("generates a terraform template for gcp", func(fixtureFilename, region, lbType, domain string) {
+		DescribeTable("generates a terraform template for gcp", func(fixtureFilename, region, lbType, domain string, internalOnly bool) {
 			expectedTemplate, err := ioutil.ReadFile(fixtureFilename)
 			Expect(err).NotTo(HaveOccurred())
 
 			template := templateGenerator.Generate(storage.State{
 				GCP: storage.GCP{
-					Region: region,
-					Zones:  zones,
+					Region:       region,
+					Zones:        zones,
+					InternalOnly: internalOnly,
 				},
 				LB: storage.LB{
 					Type:   lbType,
@@ -40,13 +41,48 @@ var _ = Describe("TemplateGenerator", func() {
 			})
 			Expect(template).To(Equal(string(expectedTemplate)))
 		},
-			Entry("when no lb type is provided", "fixtures/gcp_template_no_lb.tf", "some-region", "", ""),
-			Entry("when a concourse lb type is provided", "fixtures/gcp_template_concourse_lb.tf", "some-region", "concourse", ""),
-			Entry("when a cf lb type is provided", "fixtures/gcp_template_cf_lb.tf", "some-region", "cf", ""),
-			Entry("when a cf lb type is provided with a domain", "fixtures/gcp_template_cf_lb_dns.tf", "some-region", "cf", "some-domain"),
+			Entry("when no lb type is provided", "fixtures/gcp_template_no_lb.tf", "some-region", "", "", false),
+			Entry("when a concourse lb type is provided", "fixtures/gcp_template_concourse_lb.tf", "some-region", "concourse", "", false),
+			Entry("when a cf lb type is provided", "fixtures/gcp_template_cf_lb.tf", "some-region", "cf", "", false),
+			Entry("when a cf lb type is provided with a domain", "fixtures/gcp_template_cf_lb_dns.tf", "some-region", "cf", "some-domain", false),
+			Entry("when internal only and no lb type is provided", "fixtures/gcp_template_internal_no_lb.tf", "some-region", "", "", true),
+			Entry("when internal only and a concourse lb type is provided", "fixtures/gcp_template_internal_concourse_lb.tf", "some-region", "concourse", "", true),
+			Entry("when internal only and a cf lb type is provided", "fixtures/gcp_template_internal_cf_lb.tf", "some-region", "cf", "", true),
 		)
 	})
 
+	Describe("director service account", func() {
+		It("creates a service account for the director when it has one", func() {
+			template := templateGenerator.Generate(storage.State{
+				GCP: storage.GCP{
+					Region:                 "some-region",
+					DirectorServiceAccount: true,
+				},
+			})
+			Expect(template).To(ContainSubstring(gcp.DirectorServiceAccountTemplate))
+		})
+
+		It("creates a service account for an internal only director when it has one", func() {
+			template := templateGenerator.Generate(storage.State{
+				GCP: storage.GCP{
+					Region:                 "some-region",
+					InternalOnly:           true,
+					DirectorServiceAccount: true,
+				},
+			})
+			Expect(template).To(ContainSubstring(gcp.DirectorServiceAccountTemplate))
+		})
+
+		It("does not create a service account for the director otherwise", func() {
+			template := templateGenerator.Generate(storage.State{
+				GCP: storage.GCP{
+					Region: "some-region",
+				},
+			})
+			Expect(template).NotTo(ContainSubstring("google_service_account"))
+		})
+	})
+
 	Describe("GenerateBackendService", func() {
 		BeforeEach(func() {
 			var err error
@@ -2,8 +2,10 @@ package terraform
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 	"github.com/coreos/go-semver/semver"
@@ -24,6 +26,19 @@ type executor interface {
 	Version() (string, error)
 	Destroy(inputs map[string]string, terraformTemplate, tfState string) (string, error)
 	Apply(inputs map[string]string, terraformTemplate, tfState string) (string, error)
+	Plan(inputs map[string]string, terraformTemplate, tfState string) (bool, error)
+	MoveState(tfState, from, to string) (string, error)
+}
+
+// movedResources are resources that a template can declare under a new
+// address while the infrastructure stays the same, such as the internal route
+// table when the AWS NAT type becomes gateway-per-az.
+var movedResources = []struct {
+	from string
+	to   string
+}{
+	{from: "aws_route_table.internal_route_table", to: "aws_route_table.internal_route_tables[0]"},
+	{from: "aws_route.internal_route_table", to: "aws_route.internal_route_tables[0]"},
 }
 
 type templateGenerator interface {
@@ -130,6 +145,11 @@ func (m Manager) Apply(bblState storage.State) (storage.State, error) {
 		return storage.State{}, err
 	}
 
+	bblState.TFState, err = m.moveResources(template, bblState.TFState)
+	if err != nil {
+		return storage.State{}, err
+	}
+
 	tfState, err := m.executor.Apply(
 		input,
 		template,
@@ -183,6 +203,29 @@ func (m Manager) Destroy(bblState storage.State) (storage.State, error) {
 	return bblState, nil
 }
 
+// Plan reports whether the infrastructure has drifted from the terraform
+// state stored in bbl state.
+func (m Manager) Plan(bblState storage.State) (bool, error) {
+	if bblState.TFState == "" {
+		return false, nil
+	}
+
+	template := m.templateGenerator.Generate(bblState)
+
+	input, err := m.inputGenerator.Generate(bblState)
+	if err != nil {
+		return false, err
+	}
+
+	changed, err := m.executor.Plan(input, template, bblState.TFState)
+	readAndReset(m.terraformOutputBuffer)
+	if err != nil {
+		return false, err
+	}
+
+	return changed, nil
+}
+
 func (m Manager) GetOutputs(state storage.State) (map[string]interface{}, error) {
 	switch state.IAAS {
 	case "gcp":
@@ -194,6 +237,57 @@ func (m Manager) GetOutputs(state storage.State) (map[string]interface{}, error)
 	}
 }
 
+func (m Manager) moveResources(template, tfState string) (string, error) {
+	if tfState == "" {
+		return tfState, nil
+	}
+
+	var moves []int
+	for i, resource := range movedResources {
+		if declares(template, resource.to) && !declares(template, resource.from) && hasResource(tfState, resource.from) {
+			moves = append(moves, i)
+		}
+	}
+
+	for _, i := range moves {
+		resource := movedResources[i]
+		m.logger.Step("moving %s to %s in the terraform state", resource.from, resource.to)
+
+		var err error
+		tfState, err = m.executor.MoveState(tfState, resource.from, resource.to)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return tfState, nil
+}
+
+func declares(template, address string) bool {
+	parts := strings.SplitN(strings.Split(address, "[")[0], ".", 2)
+	return strings.Contains(template, fmt.Sprintf("resource %q %q", parts[0], parts[1]))
+}
+
+func hasResource(tfState, address string) bool {
+	var state struct {
+		Modules []struct {
+			Resources map[string]interface{} `json:"resources"`
+		} `json:"modules"`
+	}
+
+	if err := json.Unmarshal([]byte(tfState), &state); err != nil {
+		return false
+	}
+
+	for _, module := range state.Modules {
+		if _, ok := module.Resources[address]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
 func readAndReset(buf *bytes.Buffer) string {
 	contents := buf.Bytes()
 	buf.Reset()
@@ -133,6 +133,61 @@ var _ = Describe("Manager", func() {
 			Expect(state).To(Equal(expectedState))
 		})
 
+		Context("when the template declares the internal route table per availability zone", func() {
+			BeforeEach(func() {
+				incomingState.TFState = `{"modules": [{"path": ["root"], "resources": {
+					"aws_route_table.internal_route_table": {},
+					"aws_route.internal_route_table": {}
+				}}]}`
+				migrator.MigrateReturns(incomingState, nil)
+
+				templateGenerator.GenerateCall.Returns.Template = `resource "aws_route_table" "internal_route_tables" {}
+resource "aws_route" "internal_route_tables" {}`
+				executor.MoveStateCall.Returns.TFState = "some-moved-tf-state"
+			})
+
+			It("moves the existing route table and route so that they are kept", func() {
+				_, err := manager.Apply(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executor.MoveStateCall.Receives.Moves).To(Equal([]fakes.Move{
+					{From: "aws_route_table.internal_route_table", To: "aws_route_table.internal_route_tables[0]"},
+					{From: "aws_route.internal_route_table", To: "aws_route.internal_route_tables[0]"},
+				}))
+				Expect(executor.ApplyCall.Receives.TFState).To(Equal("some-moved-tf-state"))
+				Expect(logger.StepCall.Messages).To(ContainElement("moving aws_route_table.internal_route_table to aws_route_table.internal_route_tables[0] in the terraform state"))
+			})
+
+			It("does not move anything when the template still declares the single route table", func() {
+				templateGenerator.GenerateCall.Returns.Template = `resource "aws_route_table" "internal_route_table" {}
+resource "aws_route" "internal_route_table" {}`
+
+				_, err := manager.Apply(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executor.MoveStateCall.CallCount).To(Equal(0))
+				Expect(executor.ApplyCall.Receives.TFState).To(Equal(incomingState.TFState))
+			})
+
+			It("does not move anything that is not in the terraform state", func() {
+				incomingState.TFState = `{"modules": [{"path": ["root"], "resources": {}}]}`
+				migrator.MigrateReturns(incomingState, nil)
+
+				_, err := manager.Apply(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(executor.MoveStateCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the move fails", func() {
+				executor.MoveStateCall.Returns.Error = errors.New("failed to move")
+
+				_, err := manager.Apply(incomingState)
+				Expect(err).To(MatchError("failed to move"))
+				Expect(executor.ApplyCall.CallCount).To(Equal(0))
+			})
+		})
+
 		Context("when an error occurs", func() {
 			Context("when the stack cannot be migrated", func() {
 				It("returns an error", func() {
@@ -203,6 +258,71 @@ var _ = Describe("Manager", func() {
 		})
 	})
 
+	Describe("Plan", func() {
+		var incomingState storage.State
+
+		BeforeEach(func() {
+			incomingState = storage.State{
+				IAAS:    "gcp",
+				EnvID:   "some-env-id",
+				TFState: "some-tf-state",
+			}
+
+			templateGenerator.GenerateCall.Returns.Template = "some-gcp-terraform-template"
+			inputGenerator.GenerateCall.Returns.Inputs = map[string]string{
+				"env_id": incomingState.EnvID,
+			}
+		})
+
+		It("calls Executor.Plan with the generated template and inputs", func() {
+			_, err := manager.Plan(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(templateGenerator.GenerateCall.Receives.State).To(Equal(incomingState))
+			Expect(inputGenerator.GenerateCall.Receives.State).To(Equal(incomingState))
+
+			Expect(executor.PlanCall.Receives.Inputs).To(Equal(map[string]string{
+				"env_id": incomingState.EnvID,
+			}))
+			Expect(executor.PlanCall.Receives.Template).To(Equal("some-gcp-terraform-template"))
+			Expect(executor.PlanCall.Receives.TFState).To(Equal("some-tf-state"))
+		})
+
+		It("returns whether the plan has changes", func() {
+			executor.PlanCall.Returns.Changed = true
+
+			changed, err := manager.Plan(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeTrue())
+		})
+
+		Context("when the bbl state has no tf state", func() {
+			It("does not plan", func() {
+				changed, err := manager.Plan(storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(changed).To(BeFalse())
+
+				Expect(executor.PlanCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when an error occurs", func() {
+			It("returns an error when the inputs cannot be generated", func() {
+				inputGenerator.GenerateCall.Returns.Error = errors.New("failed to generate inputs")
+
+				_, err := manager.Plan(incomingState)
+				Expect(err).To(MatchError("failed to generate inputs"))
+			})
+
+			It("returns an error when the plan fails", func() {
+				executor.PlanCall.Returns.Error = errors.New("failed to plan")
+
+				_, err := manager.Plan(incomingState)
+				Expect(err).To(MatchError("failed to plan"))
+			})
+		})
+	})
+
 	Describe("Destroy", func() {
 		Context("when the bbl state contains a non-empty TFState", func() {
 			var (
@@ -0,0 +1,24 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MapVar renders values as an HCL map literal so it can be passed to
+// terraform with -var.
+func MapVar(values map[string]string) string {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%q = %q", key, values[key]))
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(pairs, ", "))
+}
@@ -0,0 +1,21 @@
+package terraform_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/terraform"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MapVar", func() {
+	It("renders the values as a sorted hcl map", func() {
+		Expect(terraform.MapVar(map[string]string{
+			"owner":       "some-owner",
+			"cost-center": "some-cost-center",
+		})).To(Equal(`{"cost-center" = "some-cost-center", "owner" = "some-owner"}`))
+	})
+
+	It("renders an empty map when there are no values", func() {
+		Expect(terraform.MapVar(nil)).To(Equal("{}"))
+	})
+})